@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"github.com/dotslashbit/ecommerce-api/internal/analytics"
+	"github.com/dotslashbit/ecommerce-api/internal/backinstock"
+	"github.com/dotslashbit/ecommerce-api/internal/brand"
+	"github.com/dotslashbit/ecommerce-api/internal/catalogpolicy"
+	"github.com/dotslashbit/ecommerce-api/internal/category"
+	"github.com/dotslashbit/ecommerce-api/internal/export"
+	"github.com/dotslashbit/ecommerce-api/internal/feed"
+	"github.com/dotslashbit/ecommerce-api/internal/giftcard"
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/question"
+	"github.com/dotslashbit/ecommerce-api/internal/recentlyviewed"
+	"github.com/dotslashbit/ecommerce-api/internal/report"
+	"github.com/dotslashbit/ecommerce-api/internal/returns"
+	"github.com/dotslashbit/ecommerce-api/internal/sitemap"
+	"github.com/dotslashbit/ecommerce-api/internal/snapshot"
+	"github.com/dotslashbit/ecommerce-api/internal/stats"
+	"github.com/dotslashbit/ecommerce-api/internal/store"
+	"github.com/dotslashbit/ecommerce-api/internal/vendor"
+	"github.com/dotslashbit/ecommerce-api/internal/webhook"
+	"github.com/dotslashbit/ecommerce-api/pkg/admin"
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/botguard"
+	"github.com/dotslashbit/ecommerce-api/pkg/crypto"
+	"github.com/dotslashbit/ecommerce-api/pkg/database"
+	"github.com/dotslashbit/ecommerce-api/pkg/deprecation"
+	"github.com/dotslashbit/ecommerce-api/pkg/events"
+	"github.com/dotslashbit/ecommerce-api/pkg/featureflags"
+	"github.com/dotslashbit/ecommerce-api/pkg/idempotency"
+	"github.com/dotslashbit/ecommerce-api/pkg/inbound"
+	"github.com/dotslashbit/ecommerce-api/pkg/jobs"
+	"github.com/dotslashbit/ecommerce-api/pkg/mailer"
+	"github.com/dotslashbit/ecommerce-api/pkg/outbox"
+	"github.com/dotslashbit/ecommerce-api/pkg/reportcache"
+	"github.com/dotslashbit/ecommerce-api/pkg/resilience"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// app holds the services, handlers and background loops of the API, wired
+// the same way for every subcommand that needs them.
+type app struct {
+	flags        *featureflags.Flags
+	worker       *jobs.Worker
+	publisher    events.Publisher
+	statements   *database.Statements
+	relay        *outbox.Relay
+	reports      *reportcache.Cache
+	deprecations *deprecation.Tracker
+
+	productService  product.Service
+	categoryService category.Service
+	brandService    brand.Service
+
+	tenants  *tenant.Middleware
+	apiKeys  *apikey.Authenticator
+	handlers []interface{ RegisterRoutes(*httprouter.Router) }
+}
+
+// newApp wires the API on db. Call close when done with it.
+func newApp(cfg *config.Config, db *sqlx.DB, logger *zap.Logger) *app {
+	a := &app{}
+
+	// Initialize feature flags from the config file, overridden by the
+	// environment
+	a.flags = featureflags.New(cfg.FeatureFlagRefreshInterval, logger,
+		featureflags.NewConfigProvider(cfg.FeatureFlags), featureflags.NewEnvProvider())
+	if err := a.flags.Load(context.Background()); err != nil {
+		logger.Fatal("Failed to load feature flags", zap.Error(err))
+	}
+
+	// Initialize job queue and workers
+	jobQueue := jobs.NewQueue(db)
+	a.worker = jobs.NewWorker(jobQueue, logger, cfg.WorkerConcurrency)
+	jobsHandler := jobs.NewHandler(jobQueue, logger)
+
+	// Initialize PII encryption; without keys customer emails stay plaintext
+	var pii *crypto.Keyring
+	if len(cfg.PIIEncryptionKeys) > 0 {
+		var err error
+		pii, err = crypto.NewKeyring(cfg.PIIEncryptionKeys, cfg.PIIBlindIndexKey)
+		if err != nil {
+			logger.Fatal("Failed to initialize PII encryption", zap.Error(err))
+		}
+		fields := append(append([]crypto.Field{}, returns.EncryptedFields...), backinstock.EncryptedFields...)
+		fields = append(fields, vendor.EncryptedFields...)
+		a.worker.RegisterPeriodic(crypto.JobReencrypt, 24*time.Hour, pii.Reencrypt(db, fields...))
+	}
+
+	// Initialize mailer; emails are delivered by the job workers
+	mail, err := mailer.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+	mail = mailer.NewResilientMailer(mail,
+		resilience.NewBreaker("mailer", cfg.BreakerFailureThreshold, cfg.BreakerCooldown),
+		resilience.Retry{Attempts: cfg.RetryAttempts, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second})
+	a.worker.Register(mailer.JobSendEmail, mailer.SendEmailJob(mail))
+
+	// Initialize event publisher for the configured broker
+	a.publisher, err = events.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize event publisher", zap.Error(err))
+	}
+
+	// Initialize webhooks; deliveries run as jobs
+	webhookRepo := webhook.NewRepository(db)
+	webhookService := webhook.NewService(webhookRepo, jobQueue)
+	webhookHandler := webhook.NewHandler(webhookService, logger)
+	a.worker.Register(webhook.JobDeliver, webhookService.Deliver)
+
+	// Initialize product repository; its hot lookups are prepared once and
+	// released before the pool closes
+	a.statements = database.NewStatements(db)
+	productRepo := product.NewRepository(db, a.statements)
+
+	// Admin reports are cached and refreshed in the background
+	a.reports = reportcache.New(cfg.ReportRefreshInterval, logger)
+
+	// Initialize catalog policies, enforced by the product service
+	policyRepo := catalogpolicy.NewRepository(db)
+	policyService := catalogpolicy.NewService(policyRepo, productRepo, a.reports)
+	policyHandler := catalogpolicy.NewHandler(policyService, logger)
+
+	// Initialize product service
+	a.productService = product.NewService(productRepo, policyService, cfg.LowStockThreshold, cfg.DefaultLocale, cfg.ImportBatchSize)
+	a.worker.RegisterPeriodic(product.JobRefreshRelated, cfg.RelatedRefreshInterval, a.productService.RefreshRelated)
+	a.worker.RegisterPeriodic(product.JobApplySchedules, cfg.ScheduleInterval, a.productService.ApplySchedules)
+
+	// Initialize bot detection for the public catalog
+	var bots *botguard.Guard
+	if cfg.BotDetectionEnabled {
+		bots = botguard.New(botguard.Config{
+			Action:        botguard.Action(cfg.BotAction),
+			RateLimit:     cfg.BotRateLimit,
+			BlockDuration: cfg.BotBlockDuration,
+			ChallengeURL:  cfg.BotChallengeURL,
+			HoneypotPaths: cfg.BotHoneypotPaths,
+		}, nil, logger)
+	}
+
+	// Initialize deprecated API usage tracking
+	a.deprecations = deprecation.NewTracker(db, logger)
+	deprecationHandler := deprecation.NewHandler(a.deprecations, logger)
+
+	// Initialize API keys for machine clients
+	apiKeyStore := apikey.NewStore(db)
+	apiKeys := apikey.NewAuthenticator(apiKeyStore, logger, cfg.APIKeyRequired)
+	if err := apiKeys.LimitRoutes(cfg.AnonymousRateLimit, cfg.RouteCosts); err != nil {
+		logger.Fatal("Failed to configure route costs", zap.Error(err))
+	}
+	a.apiKeys = apiKeys
+	apiKeyHandler := apikey.NewHandler(apiKeyStore, apiKeys, logger, cfg.APIKeyRateLimit)
+
+	// Initialize product handler; creates honour Idempotency-Key
+	idempotent := idempotency.NewMiddleware(idempotency.NewStore(db), logger, cfg.IdempotencyKeyTTL)
+	productHandler := product.NewHandler(a.productService, logger, idempotent, bots, apiKeys)
+
+	// Initialize marketplace vendors; approved vendors get vendor-bound API keys
+	vendorService := vendor.NewService(vendor.NewRepository(db, pii), apiKeyStore, a.productService, cfg.APIKeyRateLimit)
+	vendorHandler := vendor.NewHandler(vendorService, logger, apiKeys)
+
+	// Initialize back-in-stock notifications
+	backInStockRepo := backinstock.NewRepository(db, pii)
+	backInStockService := backinstock.NewService(backInStockRepo, a.productService,
+		mailer.NewQueuedMailer(jobQueue), cfg.StorefrontURL, cfg.PublicAPIURL)
+	backInStockHandler := backinstock.NewHandler(backInStockService, logger)
+
+	// Initialize the category tree
+	a.categoryService = category.NewService(category.NewRepository(db), cfg.CategoryTreeTTL)
+	categoryHandler := category.NewHandler(a.categoryService, logger)
+
+	// Initialize product questions and answers
+	questionService := question.NewService(question.NewRepository(db), a.productService)
+	questionHandler := question.NewHandler(questionService, logger, apiKeys)
+
+	// The outbox relay feeds the broker, webhook subscribers, back-in-stock
+	// notifications and, when an ops address is configured, low-stock emails
+	subscribers := []events.Publisher{
+		a.publisher,
+		webhook.NewPublisher(webhookService),
+		backinstock.NewPublisher(backInStockService),
+	}
+	if cfg.LowStockAlertEmail != "" {
+		subscribers = append(subscribers, product.NewLowStockMailer(mailer.NewQueuedMailer(jobQueue), cfg.LowStockAlertEmail))
+	}
+	a.relay = outbox.NewRelay(db, events.NewOutboxPublisher(events.NewMultiPublisher(subscribers...)), logger)
+
+	// Initialize recently viewed tracking
+	recentlyViewedRepo := recentlyviewed.NewRepository(db)
+	recentlyViewedService := recentlyviewed.NewService(recentlyViewedRepo, a.productService)
+	recentlyViewedHandler := recentlyviewed.NewHandler(recentlyViewedService, logger)
+
+	// Initialize product analytics; monthly event partitions are created ahead
+	analyticsRepo := analytics.NewRepository(db)
+	analyticsService := analytics.NewService(analyticsRepo, a.productService)
+	analyticsHandler := analytics.NewHandler(analyticsService, logger)
+	a.worker.RegisterPeriodic(analytics.JobCreatePartitions, 24*time.Hour, analyticsService.CreatePartitions)
+
+	// Initialize catalog feeds; they are regenerated in the background and
+	// served from object storage
+	blobs, err := blobstore.New(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize blob store", zap.Error(err))
+	}
+	storeService := store.NewService(store.NewRepository(db))
+	feedService := feed.NewService(feed.NewRepository(db), storeService, a.productService, blobs, cfg.StorefrontURL, cfg.FeedCurrency)
+	feedHandler := feed.NewHandler(feedService, logger)
+	a.worker.RegisterPeriodic(feed.JobGenerate, cfg.FeedRefreshInterval, feedService.Generate)
+
+	// Initialize sitemaps; they are rebuilt when a store's catalog changes
+	sitemapService := sitemap.NewService(sitemap.NewRepository(db), storeService, blobs, cfg.StorefrontURL)
+	sitemapHandler := sitemap.NewHandler(sitemapService, logger)
+	a.worker.RegisterPeriodic(sitemap.JobRebuild, cfg.SitemapInterval, sitemapService.Rebuild)
+
+	// Initialize catalog snapshots for refreshing other environments
+	snapshotService := snapshot.NewService(snapshot.NewRepository(db), blobs)
+	snapshotHandler := snapshot.NewHandler(snapshotService, logger)
+
+	// Initialize exports; files are generated by a job and downloaded from
+	// object storage
+	exportService := export.NewService(export.NewRepository(db), blobs, jobQueue, cfg.PublicAPIURL)
+	exportHandler := export.NewHandler(exportService, logger)
+	a.worker.Register(export.JobGenerate, exportService.Generate)
+
+	// Initialize scheduled reports; due reports are emailed or uploaded to
+	// object storage
+	reportService := report.NewService(report.NewRepository(db), mailer.NewQueuedMailer(jobQueue), blobs)
+	reportHandler := report.NewHandler(reportService, logger)
+	a.worker.RegisterPeriodic(report.JobDeliver, cfg.ReportDeliveryInterval, reportService.DeliverDue)
+
+	// Initialize brands; logos are kept in object storage
+	a.brandService = brand.NewService(brand.NewRepository(db), a.productService, blobs)
+	brandHandler := brand.NewHandler(a.brandService, logger)
+
+	// Initialize gift cards; expired balances are cleared daily
+	giftCardService := giftcard.NewService(giftcard.NewRepository(db))
+	giftCardHandler := giftcard.NewHandler(giftCardService, logger, apiKeys)
+	a.worker.RegisterPeriodic(giftcard.JobExpire, 24*time.Hour, giftCardService.ExpireCards)
+
+	// Initialize returns repository, service and handler. No payment or
+	// carrier integration is configured, so admins upload labels and
+	// confirm refunds, and carrier tracking events are refused
+	returnsRepo := returns.NewRepository(db, pii)
+	returnsService := returns.NewService(returnsRepo, nil, nil, mailer.NewQueuedMailer(jobQueue), jobQueue)
+	webhookReceipts := inbound.NewStore(db, cfg.WebhookTolerance)
+	webhookVerifier := inbound.NewVerifier(webhookReceipts, logger, cfg.WebhookTolerance)
+	a.worker.RegisterPeriodic(inbound.JobPrune, 24*time.Hour, webhookReceipts.Prune)
+	returnsHandler := returns.NewHandler(returnsService, logger, webhookVerifier, cfg.CarrierWebhookSecret)
+	a.worker.Register(returns.JobGenerateLabel, returnsService.GenerateLabel)
+
+	// Initialize admin dashboard statistics
+	statsHandler := stats.NewHandler(stats.NewService(stats.NewRepository(db), a.reports), logger)
+
+	// Initialize admin sign-in; failed attempts are throttled per account
+	// and per client IP
+	adminStore := admin.NewStore(db)
+	adminHandler := admin.NewHandler(adminStore, admin.NewThrottle(admin.ThrottleConfig{
+		DelayAfter:      cfg.LoginDelayAfter,
+		MaxDelay:        cfg.LoginMaxDelay,
+		LockoutAfter:    cfg.LoginLockoutAfter,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		IPLockoutAfter:  cfg.LoginIPLockoutAfter,
+		Window:          cfg.LoginFailureWindow,
+	}), logger, cfg.AdminSessionTTL)
+	a.worker.RegisterPeriodic(admin.JobPruneSessions, 24*time.Hour, adminStore.PruneSessions)
+
+	// Initialize audit log viewer; entries are written by the repositories
+	auditHandler := audit.NewHandler(audit.NewLog(db), logger)
+
+	// Scope every request to a store, picked by subdomain or X-Store header
+	storeHandler := store.NewHandler(storeService, logger)
+	a.tenants = tenant.NewMiddleware(storeService, logger, cfg.TenantBaseDomain)
+
+	// Routes are registered in this order
+	a.handlers = []interface{ RegisterRoutes(*httprouter.Router) }{
+		storeHandler,
+		productHandler,
+		vendorHandler,
+	}
+	// Bot honeypot routes
+	if bots != nil {
+		a.handlers = append(a.handlers, bots)
+	}
+	a.handlers = append(a.handlers,
+		backInStockHandler,
+		categoryHandler,
+		questionHandler,
+		brandHandler,
+		recentlyViewedHandler,
+		analyticsHandler,
+		policyHandler,
+		feedHandler,
+		sitemapHandler,
+		snapshotHandler,
+		exportHandler,
+		reportHandler,
+		giftCardHandler,
+		returnsHandler,
+		webhookHandler,
+		jobsHandler,
+		adminHandler,
+		auditHandler,
+		statsHandler,
+		apiKeyHandler,
+		deprecationHandler,
+	)
+
+	return a
+}
+
+// registerRoutes registers the routes of every handler on router.
+func (a *app) registerRoutes(router *httprouter.Router) {
+	for _, h := range a.handlers {
+		h.RegisterRoutes(router)
+	}
+}
+
+// start runs the background loops until ctx is cancelled. withWorkers
+// starts the job workers and the outbox relay; report refreshes,
+// deprecated usage flushes and feature flag refreshes always run, as every
+// API process keeps its own.
+func (a *app) start(ctx context.Context, withWorkers bool) {
+	if withWorkers {
+		a.worker.Start(ctx)
+		a.relay.Start(ctx)
+	}
+	a.reports.Start(ctx)
+	a.deprecations.Start(ctx)
+	a.flags.Start(ctx)
+}
+
+// wait blocks until the loops started by start have returned, letting
+// in-flight jobs finish.
+func (a *app) wait() {
+	a.worker.Wait()
+	a.relay.Wait()
+	a.reports.Wait()
+	a.deprecations.Wait()
+	a.flags.Wait()
+}
+
+// close releases the prepared statements and the event publisher.
+func (a *app) close() {
+	a.statements.Close()
+	a.publisher.Close()
+}
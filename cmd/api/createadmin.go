@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/admin"
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newCreateAdminCommand(b *bootstrap) *cobra.Command {
+	var email string
+
+	cmd := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create an admin user",
+		Long: `Create an admin user with the email given by --email or ADMIN_EMAIL.
+
+The password is read from ADMIN_PASSWORD. Without it a password is
+generated and printed once on stdout; it is not logged.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if email == "" {
+				email = b.cfg.AdminEmail
+			}
+			if email == "" {
+				return errors.New("an email is required, set --email or ADMIN_EMAIL")
+			}
+
+			password := b.cfg.AdminPassword
+			generated := password == ""
+			if generated {
+				var err error
+				if password, err = admin.GeneratePassword(); err != nil {
+					return err
+				}
+			}
+
+			db := b.openDB()
+			defer db.Close()
+
+			ctx := audit.WithActor(context.Background(), audit.Actor{Name: "cli"})
+			user, err := admin.NewStore(db).Create(ctx, email, password, admin.RoleAdmin)
+			if err != nil {
+				return err
+			}
+			b.logger.Info("Created admin user", zap.Int64("id", user.ID), zap.String("email", user.Email))
+
+			if generated {
+				fmt.Fprintf(os.Stdout, "Admin user %s created with password: %s\n", user.Email, password)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "email of the admin user (default ADMIN_EMAIL)")
+	return cmd
+}
+
+// bootstrapAdmin creates the first admin user from admin_email and
+// admin_password when both are set and no admin user exists yet, so a
+// fresh deployment is usable without running create-admin.
+func bootstrapAdmin(b *bootstrap, store *admin.Store) {
+	if b.cfg.AdminEmail == "" || b.cfg.AdminPassword == "" {
+		return
+	}
+
+	ctx := audit.WithActor(context.Background(), audit.Actor{Name: "bootstrap"})
+	user, err := store.Bootstrap(ctx, b.cfg.AdminEmail, b.cfg.AdminPassword)
+	// Another replica starting at the same time may have created it
+	if err != nil && !errors.Is(err, admin.ErrEmailTaken) {
+		b.logger.Fatal("Failed to create the first admin user", zap.Error(err))
+	}
+	if user != nil {
+		b.logger.Info("Created the first admin user", zap.Int64("id", user.ID), zap.String("email", user.Email))
+	}
+}
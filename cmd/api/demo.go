@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"github.com/dotslashbit/ecommerce-api/internal/brand"
+	"github.com/dotslashbit/ecommerce-api/internal/category"
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/i18n"
+	"github.com/dotslashbit/ecommerce-api/pkg/server"
+	"go.uber.org/zap"
+)
+
+// runDemo serves the catalog API from in-memory repositories seeded with
+// sample data, so the API can be tried without Postgres. Only the
+// storefront reads of products, categories and brands are served, all in
+// the default store.
+func runDemo(cfg *config.Config, logger *zap.Logger) {
+	logger.Warn("Running in demo mode; data is kept in memory and lost on exit")
+
+	brandRepo := brand.NewMemoryRepository()
+	productRepo := product.NewMemoryRepository(func(ctx context.Context, slug string) (int64, error) {
+		b, err := brandRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return 0, err
+		}
+		return b.ID, nil
+	})
+
+	productService := product.NewService(productRepo, nil, cfg.LowStockThreshold, cfg.DefaultLocale, cfg.ImportBatchSize)
+	categoryService := category.NewService(category.NewMemoryRepository(productService), cfg.CategoryTreeTTL)
+
+	blobs, err := blobstore.New(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize blob store", zap.Error(err))
+	}
+	brandService := brand.NewService(brandRepo, productService, blobs)
+
+	if err := seedDemo(context.Background(), productService, categoryService, brandService); err != nil {
+		logger.Fatal("Failed to seed demo data", zap.Error(err))
+	}
+
+	srv := server.NewServer(nil, logger)
+
+	// Bound how long clients may hold connections
+	srv.SetLimits(serverLimits(cfg))
+
+	// Serve profiles and runtime variables on the internal debug address
+	srv.EnableDebug(cfg.DebugAddr, cfg.DebugToken)
+
+	messages, err := i18n.New(cfg.DefaultLocale)
+	if err != nil {
+		logger.Fatal("Failed to load message catalogs", zap.Error(err))
+	}
+	srv.Use(messages.Wrap)
+
+	// There are no API keys or admin sessions to guard the rest
+	srv.Use(storefrontReadsOnly)
+
+	product.NewHandler(productService, logger, nil, nil, nil).RegisterRoutes(srv.Router)
+	category.NewHandler(categoryService, logger).RegisterRoutes(srv.Router)
+	brand.NewHandler(brandService, logger).RegisterRoutes(srv.Router)
+
+	// Without the job queue, scheduled publishing, price changes and
+	// related products are refreshed on plain tickers
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go every(ctx, cfg.ScheduleInterval, logger, productService.ApplySchedules)
+	go every(ctx, cfg.RelatedRefreshInterval, logger, productService.RefreshRelated)
+
+	logger.Info("Starting demo server", zap.String("port", cfg.ServerPort))
+	if err := srv.Start(":" + cfg.ServerPort); err != nil {
+		logger.Fatal("Server failed to start", zap.Error(err))
+	}
+}
+
+// storefrontReadsOnly answers 404 to writes and to the admin routes, so
+// the demo can be exposed without anyone changing its catalog.
+func storefrontReadsOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := r.URL.Path == "/admin" || strings.HasPrefix(r.URL.Path, "/admin/")
+		if admin || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// every runs task right away and then every interval until ctx is done
+func every(ctx context.Context, interval time.Duration, logger *zap.Logger, task func(context.Context, json.RawMessage) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := task(ctx, nil); err != nil {
+			logger.Error("Demo task failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// demoProducts are the sample products of the demo catalog
+var demoProducts = []struct {
+	product.CreateProductInput
+	Brand string
+}{
+	{product.CreateProductInput{
+		SKU: strPtr("TRAIL-01"), Name: "Trail Running Shoe", Price: 129.90,
+		Description: "Lightweight shoe with a grippy outsole for rough trails.",
+		Categories:  []string{"Running"}, StockQuantity: 24,
+		Attributes: product.Attributes{"color": "blue", "weight_kg": 0.28},
+	}, "summit"},
+	{product.CreateProductInput{
+		SKU: strPtr("ROAD-02"), Name: "Road Racing Shoe", Price: 149.00,
+		Description: "Carbon plated racer for road marathons.",
+		Categories:  []string{"Running"}, StockQuantity: 3,
+		Attributes: product.Attributes{"color": "red", "weight_kg": 0.22},
+	}, "summit"},
+	{product.CreateProductInput{
+		SKU: strPtr("HIKE-03"), Name: "Hiking Boot", Price: 189.50,
+		Description: "Waterproof leather boot for multi-day hikes.",
+		Categories:  []string{"Hiking"}, StockQuantity: 12,
+		Attributes: product.Attributes{"color": "brown", "waterproof": true},
+	}, "northline"},
+	{product.CreateProductInput{
+		SKU: strPtr("PACK-04"), Name: "Daypack 20L", Price: 79.00,
+		Description: "Ventilated backpack with a hydration sleeve.",
+		Categories:  []string{"Hiking", "Accessories"}, StockQuantity: 40,
+		Attributes: product.Attributes{"volume_l": 20.0},
+	}, "northline"},
+	{product.CreateProductInput{
+		SKU: strPtr("SOCK-05"), Name: "Merino Running Socks", Price: 18.00,
+		Description: "Cushioned merino wool socks that stay dry.",
+		Categories:  []string{"Running", "Accessories"}, StockQuantity: 0,
+	}, ""},
+}
+
+// seedDemo creates the sample brands, categories and products
+func seedDemo(ctx context.Context, products product.Service, categories category.Service, brands brand.Service) error {
+	brandIDs := make(map[string]int64)
+	for _, input := range []brand.CreateBrandInput{
+		{Name: "Summit", Slug: "summit", Description: "Shoes for going fast."},
+		{Name: "Northline", Slug: "northline", Description: "Gear for going far."},
+	} {
+		b, err := brands.CreateBrand(ctx, input)
+		if err != nil {
+			return err
+		}
+		brandIDs[b.Slug] = b.ID
+	}
+
+	outdoor, err := categories.CreateCategory(ctx, category.CreateCategoryInput{Name: "Outdoor"})
+	if err != nil {
+		return err
+	}
+	for i, name := range []string{"Running", "Hiking", "Accessories"} {
+		input := category.CreateCategoryInput{Name: name, ParentID: &outdoor.ID, Position: i}
+		if _, err := categories.CreateCategory(ctx, input); err != nil {
+			return err
+		}
+	}
+
+	for _, demo := range demoProducts {
+		input := demo.CreateProductInput
+		if demo.Brand != "" {
+			brandID := brandIDs[demo.Brand]
+			input.BrandID = &brandID
+		}
+		if _, err := products.CreateProduct(ctx, input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -1,54 +1,88 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	config "github.com/dotslashbit/ecommerce-api/configs"
-	"github.com/dotslashbit/ecommerce-api/internal/product" // New import
 	"github.com/dotslashbit/ecommerce-api/pkg/database"
 	"github.com/dotslashbit/ecommerce-api/pkg/server"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
+// bootstrap is the configuration and logger shared by every subcommand,
+// set up before the subcommand runs
+type bootstrap struct {
+	cfg    *config.Config
+	logger *zap.Logger
+}
+
 func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the CLI. Run without a subcommand it serves the
+// API, as the binary did before it had subcommands.
+func newRootCommand() *cobra.Command {
+	b := &bootstrap{}
+
+	root := &cobra.Command{
+		Use:          "api",
+		Short:        "E-commerce catalog API",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return b.init()
+		},
+		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
+			b.logger.Sync()
+		},
+	}
+
+	serve := newServeCommand(b)
+	root.RunE = serve.RunE
+	root.Flags().AddFlagSet(serve.Flags())
+
+	root.AddCommand(serve, newWorkerCommand(b), newMigrateCommand(b), newSeedCommand(b), newCreateAdminCommand(b))
+	return root
+}
+
+// init creates the logger and loads the configuration
+func (b *bootstrap) init() error {
 	// Initialize logger
 	logger, err := zap.NewDevelopment() // Using Development logger for more verbose output
 	if err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
+		return err
 	}
-	defer logger.Sync()
+	b.logger = logger
 
 	// Load configuration
-	cfg, err := config.LoadConfig(logger)
+	b.cfg, err = config.LoadConfig(logger)
 	if err != nil {
-		logger.Fatal("Failed to load configuration", zap.Error(err))
+		logger.Error("Failed to load configuration", zap.Error(err))
+		return err
 	}
+	return nil
+}
 
-	// Initialize database
-	db, err := database.NewDB(cfg, logger)
+// openDB connects to the database, exiting when it cannot
+func (b *bootstrap) openDB() *sqlx.DB {
+	db, err := database.NewDB(b.cfg, b.logger)
 	if err != nil {
-		logger.Fatal("Failed to connect to database", zap.Error(err))
+		b.logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
-	defer db.Close()
-
-	// Initialize product repository
-	productRepo := product.NewRepository(db)
-
-	// Initialize product service
-	productService := product.NewService(productRepo)
-
-	// Initialize product handler
-	productHandler := product.NewHandler(productService, logger)
-
-	// Initialize server
-	srv := server.NewServer(db, logger)
-
-	// Register product routes
-	productHandler.RegisterRoutes(srv.Router)
+	return db
+}
 
-	// Start server
-	logger.Info("Starting server", zap.String("port", cfg.ServerPort))
-	if err := srv.Start(":" + cfg.ServerPort); err != nil {
-		logger.Fatal("Server failed to start", zap.Error(err))
+// serverLimits returns the configured connection limits of the API listener
+func serverLimits(cfg *config.Config) server.Limits {
+	return server.Limits{
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
 	}
 }
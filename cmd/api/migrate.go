@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/database"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newMigrateCommand(b *bootstrap) *cobra.Command {
+	var dir string
+	var baseline int
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending database migrations",
+		Long: `Apply the up migrations in --dir that the schema_migrations table does not
+list yet, in version order.
+
+Databases migrated by hand before this command existed must be baselined
+once with --baseline <last applied version>, which records the migrations
+up to that version as applied without running them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db := b.openDB()
+			defer db.Close()
+
+			ctx := context.Background()
+			if baseline > 0 {
+				recorded, err := database.Baseline(ctx, db, dir, baseline)
+				if err != nil {
+					return err
+				}
+				b.logger.Info("Baselined migrations", zap.Int("version", baseline), zap.Int("recorded", recorded))
+			}
+
+			applied, err := database.Migrate(ctx, db, dir, b.logger)
+			if err != nil {
+				return err
+			}
+			b.logger.Info("Database is up to date", zap.Int("applied", applied))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "migrations", "directory of the *.up.sql migrations")
+	cmd.Flags().IntVar(&baseline, "baseline", 0, "record migrations up to this version as applied without running them")
+	return cmd
+}
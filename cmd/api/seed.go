@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newSeedCommand(b *bootstrap) *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Load the demo catalog into the database",
+		Long: `Create the sample brands, categories and products of the demo catalog in
+the default store. Seeding a database that already holds them fails on
+the duplicate brand slugs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db := b.openDB()
+			defer db.Close()
+
+			a := newApp(b.cfg, db, b.logger)
+			defer a.close()
+
+			if err := seedDemo(context.Background(), a.productService, a.categoryService, a.brandService); err != nil {
+				return err
+			}
+			b.logger.Info("Seeded demo catalog")
+			return nil
+		},
+	}
+}
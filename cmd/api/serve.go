@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/admin"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/dotslashbit/ecommerce-api/pkg/i18n"
+	"github.com/dotslashbit/ecommerce-api/pkg/ipfilter"
+	"github.com/dotslashbit/ecommerce-api/pkg/server"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newServeCommand(b *bootstrap) *cobra.Command {
+	var demo, workers bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the API",
+		Long: `Serve the API until interrupted. Job workers and the outbox relay run in
+the same process unless --workers=false, for deployments that run them
+with the worker command instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if demo {
+				runDemo(b.cfg, b.logger)
+				return nil
+			}
+			runServe(b, workers)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&demo, "demo", false, "serve the catalog from sample data kept in memory, without Postgres")
+	cmd.Flags().BoolVar(&workers, "workers", true, "run the job workers and outbox relay in this process")
+	return cmd
+}
+
+func runServe(b *bootstrap, withWorkers bool) {
+	cfg, logger := b.cfg, b.logger
+
+	db := b.openDB()
+	defer db.Close()
+
+	// Provision the first admin user of a fresh deployment
+	bootstrapAdmin(b, admin.NewStore(db))
+
+	a := newApp(cfg, db, logger)
+	defer a.close()
+
+	// Initialize server
+	srv := server.NewServer(db, logger)
+
+	// Bound how long clients may hold connections
+	srv.SetLimits(serverLimits(cfg))
+
+	// Serve profiles and runtime variables on the internal debug address
+	srv.EnableDebug(cfg.DebugAddr, cfg.DebugToken)
+
+	// Resolve client IPs from forwarding headers set by trusted proxies,
+	// for rate limits, logs and the audit log
+	proxies, err := clientip.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Failed to configure trusted proxies", zap.Error(err))
+	}
+	srv.TrustProxies(proxies)
+
+	// Keep admin routes to the allowed networks
+	filter, err := ipfilter.New(ipfilter.Config{
+		Paths: cfg.IPFilterPaths,
+		Allow: cfg.IPAllowlist,
+		Deny:  cfg.IPDenylist,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to configure IP filter", zap.Error(err))
+	}
+	srv.Use(filter.Wrap)
+
+	// Translate error messages into the client's Accept-Language
+	messages, err := i18n.New(cfg.DefaultLocale)
+	if err != nil {
+		logger.Fatal("Failed to load message catalogs", zap.Error(err))
+	}
+	srv.Use(messages.Wrap)
+
+	// Scope every request to a store, picked by subdomain or X-Store header,
+	// before API keys are checked against it
+	srv.Use(a.tenants.Wrap)
+
+	// Rate limit by route cost, per API key or per anonymous client IP
+	srv.Use(a.apiKeys.Limit)
+
+	// Require a signed-in admin on the admin routes
+	srv.Use(admin.NewGuard(admin.NewStore(db), logger).Wrap)
+
+	// Register the routes of every handler
+	a.registerRoutes(srv.Router)
+
+	// Start the background loops
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	a.start(workerCtx, withWorkers)
+
+	// Start server
+	logger.Info("Starting server", zap.String("port", cfg.ServerPort))
+	if err := srv.Start(":" + cfg.ServerPort); err != nil {
+		logger.Fatal("Server failed to start", zap.Error(err))
+	}
+
+	// Let in-flight jobs finish once the server has shut down
+	stopWorkers()
+	a.wait()
+}
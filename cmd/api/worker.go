@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorkerCommand(b *bootstrap) *cobra.Command {
+	return &cobra.Command{
+		Use:   "worker",
+		Short: "Run the job workers and outbox relay without serving HTTP",
+		Long: `Run the job workers and outbox relay until interrupted, letting in-flight
+jobs finish on SIGINT or SIGTERM. Pair it with "serve --workers=false" to
+scale background work separately from the API.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			db := b.openDB()
+			defer db.Close()
+
+			a := newApp(b.cfg, db, b.logger)
+			defer a.close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			b.logger.Info("Starting workers")
+			a.start(ctx, true)
+			<-ctx.Done()
+			b.logger.Info("Stopping workers")
+			a.wait()
+			return nil
+		},
+	}
+}
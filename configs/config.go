@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -16,6 +17,126 @@ type Config struct {
 	DBPassword string `mapstructure:"db_password"`
 	DBName     string `mapstructure:"db_name"`
 	ServerPort string `mapstructure:"server_port"`
+
+	// DBConnectTimeout is how long start-up keeps retrying to reach the
+	// database
+	DBConnectTimeout time.Duration `mapstructure:"db_connect_timeout"`
+
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+
+	DebugAddr  string `mapstructure:"debug_addr"`
+	DebugToken string `mapstructure:"debug_token"`
+
+	MailDriver     string `mapstructure:"mail_driver"`
+	MailFrom       string `mapstructure:"mail_from"`
+	SMTPHost       string `mapstructure:"smtp_host"`
+	SMTPPort       string `mapstructure:"smtp_port"`
+	SMTPUsername   string `mapstructure:"smtp_username"`
+	SMTPPassword   string `mapstructure:"smtp_password"`
+	SendGridAPIKey string `mapstructure:"sendgrid_api_key"`
+
+	WorkerConcurrency int `mapstructure:"worker_concurrency"`
+
+	BreakerFailureThreshold int           `mapstructure:"breaker_failure_threshold"`
+	BreakerCooldown         time.Duration `mapstructure:"breaker_cooldown"`
+	RetryAttempts           int           `mapstructure:"retry_attempts"`
+
+	EventsDriver      string `mapstructure:"events_driver"`
+	KafkaBrokers      string `mapstructure:"kafka_brokers"`
+	KafkaTopic        string `mapstructure:"kafka_topic"`
+	NATSURL           string `mapstructure:"nats_url"`
+	NATSSubjectPrefix string `mapstructure:"nats_subject_prefix"`
+
+	CarrierWebhookSecret string        `mapstructure:"carrier_webhook_secret"`
+	WebhookTolerance     time.Duration `mapstructure:"webhook_tolerance"`
+
+	IdempotencyKeyTTL time.Duration `mapstructure:"idempotency_key_ttl"`
+
+	PIIEncryptionKeys []string `mapstructure:"pii_encryption_keys"`
+	PIIBlindIndexKey  string   `mapstructure:"pii_blind_index_key"`
+
+	APIKeyRequired  bool `mapstructure:"api_key_required"`
+	APIKeyRateLimit int  `mapstructure:"api_key_rate_limit"`
+
+	AnonymousRateLimit int         `mapstructure:"anonymous_rate_limit"`
+	RouteCosts         []RouteCost `mapstructure:"route_costs"`
+
+	LowStockThreshold  int    `mapstructure:"low_stock_threshold"`
+	LowStockAlertEmail string `mapstructure:"low_stock_alert_email"`
+
+	ImportBatchSize int `mapstructure:"import_batch_size"`
+
+	TenantBaseDomain string `mapstructure:"tenant_base_domain"`
+
+	StorefrontURL string `mapstructure:"storefront_url"`
+	PublicAPIURL  string `mapstructure:"public_api_url"`
+
+	ReportRefreshInterval  time.Duration `mapstructure:"report_refresh_interval"`
+	RelatedRefreshInterval time.Duration `mapstructure:"related_refresh_interval"`
+	ScheduleInterval       time.Duration `mapstructure:"schedule_interval"`
+	ReportDeliveryInterval time.Duration `mapstructure:"report_delivery_interval"`
+
+	BlobDriver  string `mapstructure:"blob_driver"`
+	BlobDir     string `mapstructure:"blob_dir"`
+	S3Endpoint  string `mapstructure:"s3_endpoint"`
+	S3Region    string `mapstructure:"s3_region"`
+	S3Bucket    string `mapstructure:"s3_bucket"`
+	S3AccessKey string `mapstructure:"s3_access_key"`
+	S3SecretKey string `mapstructure:"s3_secret_key"`
+
+	FeedRefreshInterval time.Duration `mapstructure:"feed_refresh_interval"`
+	FeedCurrency        string        `mapstructure:"feed_currency"`
+
+	SitemapInterval time.Duration `mapstructure:"sitemap_interval"`
+
+	CategoryTreeTTL time.Duration `mapstructure:"category_tree_ttl"`
+
+	DefaultLocale string `mapstructure:"default_locale"`
+
+	BotDetectionEnabled bool          `mapstructure:"bot_detection_enabled"`
+	BotAction           string        `mapstructure:"bot_action"`
+	BotRateLimit        int           `mapstructure:"bot_rate_limit"`
+	BotBlockDuration    time.Duration `mapstructure:"bot_block_duration"`
+	BotChallengeURL     string        `mapstructure:"bot_challenge_url"`
+	BotHoneypotPaths    []string      `mapstructure:"bot_honeypot_paths"`
+
+	AdminEmail    string `mapstructure:"admin_email"`
+	AdminPassword string `mapstructure:"admin_password"`
+
+	AdminSessionTTL      time.Duration `mapstructure:"admin_session_ttl"`
+	LoginDelayAfter      int           `mapstructure:"login_delay_after"`
+	LoginMaxDelay        time.Duration `mapstructure:"login_max_delay"`
+	LoginLockoutAfter    int           `mapstructure:"login_lockout_after"`
+	LoginLockoutDuration time.Duration `mapstructure:"login_lockout_duration"`
+	LoginIPLockoutAfter  int           `mapstructure:"login_ip_lockout_after"`
+	LoginFailureWindow   time.Duration `mapstructure:"login_failure_window"`
+
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	IPFilterPaths []string `mapstructure:"ip_filter_paths"`
+	IPAllowlist   []string `mapstructure:"ip_allowlist"`
+	IPDenylist    []string `mapstructure:"ip_denylist"`
+
+	FeatureFlags               map[string]FeatureFlag `mapstructure:"feature_flags"`
+	FeatureFlagRefreshInterval time.Duration          `mapstructure:"feature_flag_refresh_interval"`
+}
+
+// RouteCost weighs requests to a route against the rate limits. Route is
+// a method and path pattern such as "GET /products/:id" or "GET /feeds/*".
+type RouteCost struct {
+	Route string `mapstructure:"route"`
+	Cost  int    `mapstructure:"cost"`
+}
+
+// FeatureFlag is the config file rule of a feature flag
+type FeatureFlag struct {
+	Enabled        bool    `mapstructure:"enabled"`
+	Stores         []int64 `mapstructure:"stores"`          // store IDs the flag is on for regardless
+	DisabledStores []int64 `mapstructure:"disabled_stores"` // store IDs the flag is off for regardless
 }
 
 func LoadConfig(logger *zap.Logger) (*Config, error) {
@@ -25,6 +146,82 @@ func LoadConfig(logger *zap.Logger) (*Config, error) {
 	viper.AddConfigPath("./configs")
 	viper.AutomaticEnv()
 
+	// Defaults for optional settings
+	viper.SetDefault("db_connect_timeout", "30s")
+	viper.SetDefault("read_header_timeout", "5s")
+	viper.SetDefault("read_timeout", "30s")
+	viper.SetDefault("write_timeout", "60s")
+	viper.SetDefault("idle_timeout", "120s")
+	viper.SetDefault("max_header_bytes", 1<<20)
+	viper.SetDefault("debug_addr", "")
+	viper.SetDefault("debug_token", "")
+	viper.SetDefault("mail_driver", "log")
+	viper.SetDefault("mail_from", "no-reply@localhost")
+	viper.SetDefault("smtp_host", "localhost")
+	viper.SetDefault("smtp_port", "25")
+	viper.SetDefault("smtp_username", "")
+	viper.SetDefault("smtp_password", "")
+	viper.SetDefault("sendgrid_api_key", "")
+	viper.SetDefault("worker_concurrency", 4)
+	viper.SetDefault("breaker_failure_threshold", 5)
+	viper.SetDefault("breaker_cooldown", "30s")
+	viper.SetDefault("retry_attempts", 3)
+	viper.SetDefault("events_driver", "log")
+	viper.SetDefault("kafka_brokers", "localhost:9092")
+	viper.SetDefault("kafka_topic", "ecommerce.events")
+	viper.SetDefault("nats_url", "nats://localhost:4222")
+	viper.SetDefault("nats_subject_prefix", "ecommerce")
+	viper.SetDefault("carrier_webhook_secret", "")
+	viper.SetDefault("webhook_tolerance", "5m")
+	viper.SetDefault("idempotency_key_ttl", "24h")
+	viper.SetDefault("pii_encryption_keys", []string{})
+	viper.SetDefault("pii_blind_index_key", "")
+	viper.SetDefault("api_key_required", false)
+	viper.SetDefault("api_key_rate_limit", 600)
+	viper.SetDefault("anonymous_rate_limit", 300)
+	viper.SetDefault("low_stock_threshold", 5)
+	viper.SetDefault("low_stock_alert_email", "")
+	viper.SetDefault("import_batch_size", 1000)
+	viper.SetDefault("tenant_base_domain", "")
+	viper.SetDefault("storefront_url", "http://localhost:3000")
+	viper.SetDefault("public_api_url", "http://localhost:8080")
+	viper.SetDefault("report_refresh_interval", "5m")
+	viper.SetDefault("related_refresh_interval", "1h")
+	viper.SetDefault("schedule_interval", "1m")
+	viper.SetDefault("report_delivery_interval", "15m")
+	viper.SetDefault("blob_driver", "local")
+	viper.SetDefault("blob_dir", "./data/blobs")
+	viper.SetDefault("s3_endpoint", "")
+	viper.SetDefault("s3_region", "us-east-1")
+	viper.SetDefault("s3_bucket", "")
+	viper.SetDefault("s3_access_key", "")
+	viper.SetDefault("s3_secret_key", "")
+	viper.SetDefault("feed_refresh_interval", "1h")
+	viper.SetDefault("feed_currency", "USD")
+	viper.SetDefault("sitemap_interval", "5m")
+	viper.SetDefault("default_locale", "en")
+	viper.SetDefault("category_tree_ttl", "1m")
+	viper.SetDefault("bot_detection_enabled", true)
+	viper.SetDefault("bot_action", "throttle")
+	viper.SetDefault("bot_rate_limit", 120)
+	viper.SetDefault("bot_block_duration", "1h")
+	viper.SetDefault("bot_challenge_url", "")
+	viper.SetDefault("bot_honeypot_paths", []string{"/catalog/full-export"})
+	viper.SetDefault("feature_flag_refresh_interval", "1m")
+	viper.SetDefault("trusted_proxies", []string{})
+	viper.SetDefault("ip_filter_paths", []string{"/admin", "/metrics"})
+	viper.SetDefault("ip_allowlist", []string{})
+	viper.SetDefault("ip_denylist", []string{})
+	viper.SetDefault("admin_email", "")
+	viper.SetDefault("admin_password", "")
+	viper.SetDefault("admin_session_ttl", "12h")
+	viper.SetDefault("login_delay_after", 3)
+	viper.SetDefault("login_max_delay", "30s")
+	viper.SetDefault("login_lockout_after", 10)
+	viper.SetDefault("login_lockout_duration", "15m")
+	viper.SetDefault("login_ip_lockout_after", 50)
+	viper.SetDefault("login_failure_window", "15m")
+
 	// Log current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -62,7 +259,54 @@ func LoadConfig(logger *zap.Logger) (*Config, error) {
 		zap.String("db_port", config.DBPort),
 		zap.String("db_user", config.DBUser),
 		zap.String("db_name", config.DBName),
-		zap.String("server_port", config.ServerPort))
+		zap.Duration("db_connect_timeout", config.DBConnectTimeout),
+		zap.String("server_port", config.ServerPort),
+		zap.Duration("read_header_timeout", config.ReadHeaderTimeout),
+		zap.Duration("read_timeout", config.ReadTimeout),
+		zap.Duration("write_timeout", config.WriteTimeout),
+		zap.Duration("idle_timeout", config.IdleTimeout),
+		zap.Int("max_header_bytes", config.MaxHeaderBytes),
+		zap.String("debug_addr", config.DebugAddr),
+		zap.Bool("debug_token_set", config.DebugToken != ""),
+		zap.String("mail_driver", config.MailDriver),
+		zap.Int("worker_concurrency", config.WorkerConcurrency),
+		zap.Int("breaker_failure_threshold", config.BreakerFailureThreshold),
+		zap.Duration("breaker_cooldown", config.BreakerCooldown),
+		zap.Int("retry_attempts", config.RetryAttempts),
+		zap.String("events_driver", config.EventsDriver),
+		zap.Bool("carrier_webhook_enabled", config.CarrierWebhookSecret != ""),
+		zap.Duration("webhook_tolerance", config.WebhookTolerance),
+		zap.Duration("idempotency_key_ttl", config.IdempotencyKeyTTL),
+		zap.Bool("pii_encryption_enabled", len(config.PIIEncryptionKeys) > 0),
+		zap.Bool("api_key_required", config.APIKeyRequired),
+		zap.Int("api_key_rate_limit", config.APIKeyRateLimit),
+		zap.Int("anonymous_rate_limit", config.AnonymousRateLimit),
+		zap.Int("route_costs", len(config.RouteCosts)),
+		zap.Int("low_stock_threshold", config.LowStockThreshold),
+		zap.Int("import_batch_size", config.ImportBatchSize),
+		zap.String("tenant_base_domain", config.TenantBaseDomain),
+		zap.Bool("bot_detection_enabled", config.BotDetectionEnabled),
+		zap.String("bot_action", config.BotAction),
+		zap.Bool("admin_bootstrap", config.AdminEmail != "" && config.AdminPassword != ""),
+		zap.Duration("admin_session_ttl", config.AdminSessionTTL),
+		zap.Int("login_lockout_after", config.LoginLockoutAfter),
+		zap.Duration("login_lockout_duration", config.LoginLockoutDuration),
+		zap.Strings("trusted_proxies", config.TrustedProxies),
+		zap.Strings("ip_filter_paths", config.IPFilterPaths),
+		zap.Strings("ip_allowlist", config.IPAllowlist),
+		zap.Strings("ip_denylist", config.IPDenylist),
+		zap.Int("feature_flags", len(config.FeatureFlags)),
+		zap.Duration("feature_flag_refresh_interval", config.FeatureFlagRefreshInterval),
+		zap.Duration("report_refresh_interval", config.ReportRefreshInterval),
+		zap.Duration("related_refresh_interval", config.RelatedRefreshInterval),
+		zap.Duration("schedule_interval", config.ScheduleInterval),
+		zap.Duration("report_delivery_interval", config.ReportDeliveryInterval),
+		zap.String("blob_driver", config.BlobDriver),
+		zap.Duration("feed_refresh_interval", config.FeedRefreshInterval),
+		zap.String("feed_currency", config.FeedCurrency),
+		zap.Duration("sitemap_interval", config.SitemapInterval),
+		zap.String("default_locale", config.DefaultLocale),
+		zap.Duration("category_tree_ttl", config.CategoryTreeTTL))
 
 	// Validate required fields
 	if config.DBHost == "" || config.DBPort == "" || config.DBUser == "" || config.DBName == "" || config.ServerPort == "" {
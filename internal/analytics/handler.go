@@ -0,0 +1,165 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// HeaderSessionID identifies the storefront session that sent the events
+const HeaderSessionID = "X-Session-ID"
+
+// maxSessionIDLength matches the session_id column
+const maxSessionIDLength = 128
+
+// defaultRangeDays is the report window when from is not given
+const defaultRangeDays = 30
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/analytics/events", h.IngestEvents)
+	router.GET("/admin/analytics/products/:id/funnel", h.GetFunnel)
+	router.GET("/admin/analytics/trends", h.GetDailyTrends)
+}
+
+func (h *Handler) IngestEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	sessionID := r.Header.Get(HeaderSessionID)
+	if sessionID == "" || len(sessionID) > maxSessionIDLength {
+		http.Error(w, "Missing or invalid "+HeaderSessionID+" header", http.StatusBadRequest)
+		return
+	}
+
+	var input IngestInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode analytics events", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.Ingest(r.Context(), sessionID, input)
+	if err != nil {
+		h.logger.Error("Failed to ingest analytics events", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) GetFunnel(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	dates, ok := h.dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	funnel, err := h.service.GetFunnel(r.Context(), id, dates)
+	if err != nil {
+		h.logger.Error("Failed to get analytics funnel", zap.Error(err))
+		h.writeReportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(funnel)
+}
+
+func (h *Handler) GetDailyTrends(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var productID *int64
+	if raw := r.URL.Query().Get("product_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid product ID", http.StatusBadRequest)
+			return
+		}
+		productID = &id
+	}
+
+	dates, ok := h.dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	trends, err := h.service.GetDailyTrends(r.Context(), productID, dates)
+	if err != nil {
+		h.logger.Error("Failed to get analytics trends", zap.Error(err))
+		h.writeReportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		ProductID *int64        `json:"product_id,omitempty"`
+		Days      []*DailyTrend `json:"days"`
+	}{
+		ProductID: productID,
+		Days:      trends,
+	})
+}
+
+// dateRange parses the from and to query parameters as YYYY-MM-DD UTC days.
+// to defaults to today and from to defaultRangeDays before to.
+func (h *Handler) dateRange(w http.ResponseWriter, r *http.Request) (DateRange, bool) {
+	query := r.URL.Query()
+	now := time.Now().UTC()
+	dates := DateRange{To: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return DateRange{}, false
+		}
+		dates.To = t
+	}
+
+	dates.From = dates.To.AddDate(0, 0, -(defaultRangeDays - 1))
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return DateRange{}, false
+		}
+		dates.From = t
+	}
+
+	return dates, true
+}
+
+func (h *Handler) writeReportError(w http.ResponseWriter, err error) {
+	switch err {
+	case product.ErrProductNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, "Invalid date range, at most 366 days with from before to", http.StatusBadRequest)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
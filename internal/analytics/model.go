@@ -0,0 +1,68 @@
+package analytics
+
+import "time"
+
+type EventType string
+
+const (
+	EventView      EventType = "view"
+	EventAddToCart EventType = "add_to_cart"
+	EventPurchase  EventType = "purchase"
+)
+
+type Event struct {
+	ID         int64     `db:"id" json:"id"`
+	Type       EventType `db:"type" json:"type"`
+	ProductID  int64     `db:"product_id" json:"product_id"`
+	SessionID  string    `db:"session_id" json:"-"`
+	Quantity   int       `db:"quantity" json:"quantity"`
+	OccurredAt time.Time `db:"occurred_at" json:"occurred_at"`
+}
+
+type EventInput struct {
+	Type      EventType `json:"type" validate:"required,oneof=view add_to_cart purchase"`
+	ProductID int64     `json:"product_id" validate:"required,min=1"`
+	// Quantity applies to add_to_cart and purchase events and defaults to 1
+	Quantity int `json:"quantity" validate:"min=0,max=10000"`
+}
+
+type IngestInput struct {
+	Events []EventInput `json:"events" validate:"required,min=1,max=100,dive"`
+}
+
+// DateRange selects whole UTC days, From and To inclusive.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// FunnelCounts are the distinct sessions reaching each funnel stage.
+type FunnelCounts struct {
+	Views        int `db:"views"`
+	AddToCarts   int `db:"add_to_carts"`
+	Purchases    int `db:"purchases"`
+	UnitsOrdered int `db:"units_ordered"`
+}
+
+// Funnel is a product's view to purchase conversion over a date range.
+// Rates are fractions of the previous stage, and 0 when it is empty.
+type Funnel struct {
+	ProductID          int64     `json:"product_id"`
+	From               time.Time `json:"from"`
+	To                 time.Time `json:"to"`
+	Views              int       `json:"views"`
+	AddToCarts         int       `json:"add_to_carts"`
+	Purchases          int       `json:"purchases"`
+	UnitsOrdered       int       `json:"units_ordered"`
+	ViewToCartRate     float64   `json:"view_to_cart_rate"`
+	CartToPurchaseRate float64   `json:"cart_to_purchase_rate"`
+	ConversionRate     float64   `json:"conversion_rate"`
+}
+
+// DailyTrend is the number of events of each type on one UTC day.
+type DailyTrend struct {
+	Day        time.Time `db:"day" json:"day"`
+	Views      int       `db:"views" json:"views"`
+	AddToCarts int       `db:"add_to_carts" json:"add_to_carts"`
+	Purchases  int       `db:"purchases" json:"purchases"`
+}
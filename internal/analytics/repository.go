@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for analytics data operations
+type Repository interface {
+	Insert(ctx context.Context, sessionID string, events []EventInput) error
+	Funnel(ctx context.Context, productID int64, dates DateRange) (*FunnelCounts, error)
+	DailyTrends(ctx context.Context, productID *int64, dates DateRange) ([]*DailyTrend, error)
+	CreatePartition(ctx context.Context, month time.Time) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Insert stores a batch of events for a session in one statement
+func (r *repository) Insert(ctx context.Context, sessionID string, events []EventInput) error {
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*4)
+	argID := 1
+
+	for _, event := range events {
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d)", argID, argID+1, argID+2, argID+3))
+		args = append(args, event.Type, event.ProductID, sessionID, event.Quantity)
+		argID += 4
+	}
+
+	query := `INSERT INTO analytics_events (type, product_id, session_id, quantity) VALUES ` + strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error inserting analytics events: %w", err)
+	}
+	return nil
+}
+
+// Funnel counts the distinct sessions that viewed, added to cart and
+// purchased a product in the date range
+func (r *repository) Funnel(ctx context.Context, productID int64, dates DateRange) (*FunnelCounts, error) {
+	var counts FunnelCounts
+	query := `
+		SELECT
+			COUNT(DISTINCT session_id) FILTER (WHERE type = 'view') AS views,
+			COUNT(DISTINCT session_id) FILTER (WHERE type = 'add_to_cart') AS add_to_carts,
+			COUNT(DISTINCT session_id) FILTER (WHERE type = 'purchase') AS purchases,
+			COALESCE(SUM(quantity) FILTER (WHERE type = 'purchase'), 0) AS units_ordered
+		FROM analytics_events
+		WHERE product_id = $1 AND occurred_at >= $2 AND occurred_at < $3`
+
+	if err := r.db.GetContext(ctx, &counts, query, productID, dates.From, dates.To.AddDate(0, 0, 1)); err != nil {
+		return nil, fmt.Errorf("error computing analytics funnel: %w", err)
+	}
+	return &counts, nil
+}
+
+// DailyTrends counts events per type for each UTC day in the date range,
+// for one product or, when productID is nil, the whole catalog. Days
+// without events are included with zero counts.
+func (r *repository) DailyTrends(ctx context.Context, productID *int64, dates DateRange) ([]*DailyTrend, error) {
+	trends := []*DailyTrend{}
+	query := `
+		SELECT
+			d.day::date AS day,
+			COUNT(e.id) FILTER (WHERE e.type = 'view') AS views,
+			COUNT(e.id) FILTER (WHERE e.type = 'add_to_cart') AS add_to_carts,
+			COUNT(e.id) FILTER (WHERE e.type = 'purchase') AS purchases
+		FROM generate_series($1::timestamptz, $2::timestamptz, INTERVAL '1 day') AS d(day)
+		LEFT JOIN analytics_events e
+			ON e.occurred_at >= d.day AND e.occurred_at < d.day + INTERVAL '1 day'
+			AND ($3::integer IS NULL OR e.product_id = $3)
+		GROUP BY d.day
+		ORDER BY d.day`
+
+	if err := r.db.SelectContext(ctx, &trends, query, dates.From, dates.To, productID); err != nil {
+		return nil, fmt.Errorf("error computing analytics trends: %w", err)
+	}
+	return trends, nil
+}
+
+// CreatePartition creates the analytics_events partition for the calendar
+// month (UTC) containing month, if it does not exist yet
+func (r *repository) CreatePartition(ctx context.Context, month time.Time) error {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	name := fmt.Sprintf("analytics_events_%04d_%02d", start.Year(), start.Month())
+
+	// DDL cannot take bind parameters; the name and bounds are generated here
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF analytics_events FOR VALUES FROM ('%s') TO ('%s')`,
+		name, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error creating analytics partition %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/go-playground/validator"
+)
+
+// JobCreatePartitions is the periodic job that creates upcoming monthly
+// analytics_events partitions
+const JobCreatePartitions = "analytics_partitions"
+
+// partitionsAhead is how many months past the current one are created in
+// advance, so a late job run never sends events to the default partition
+const partitionsAhead = 2
+
+// MaxRangeDays bounds funnel and trend queries
+const MaxRangeDays = 366
+
+var (
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrMissingSession = errors.New("missing session")
+)
+
+// Catalog looks up the product a report is for.
+type Catalog interface {
+	GetProductByID(ctx context.Context, id int64) (*product.Product, error)
+}
+
+type Service interface {
+	Ingest(ctx context.Context, sessionID string, input IngestInput) error
+	GetFunnel(ctx context.Context, productID int64, dates DateRange) (*Funnel, error)
+	GetDailyTrends(ctx context.Context, productID *int64, dates DateRange) ([]*DailyTrend, error)
+	// CreatePartitions is the JobCreatePartitions job handler.
+	CreatePartitions(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	catalog   Catalog
+	validator *validator.Validate
+}
+
+func NewService(repo Repository, catalog Catalog) Service {
+	return &service{
+		repo:      repo,
+		catalog:   catalog,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) Ingest(ctx context.Context, sessionID string, input IngestInput) error {
+	if sessionID == "" {
+		return ErrMissingSession
+	}
+	if err := s.validator.Struct(input); err != nil {
+		return ErrInvalidInput
+	}
+
+	for i := range input.Events {
+		if input.Events[i].Type == EventView || input.Events[i].Quantity == 0 {
+			input.Events[i].Quantity = 1
+		}
+	}
+
+	return s.repo.Insert(ctx, sessionID, input.Events)
+}
+
+func (s *service) GetFunnel(ctx context.Context, productID int64, dates DateRange) (*Funnel, error) {
+	if !validRange(dates) {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.catalog.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.Funnel(ctx, productID, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Funnel{
+		ProductID:          productID,
+		From:               dates.From,
+		To:                 dates.To,
+		Views:              counts.Views,
+		AddToCarts:         counts.AddToCarts,
+		Purchases:          counts.Purchases,
+		UnitsOrdered:       counts.UnitsOrdered,
+		ViewToCartRate:     rate(counts.AddToCarts, counts.Views),
+		CartToPurchaseRate: rate(counts.Purchases, counts.AddToCarts),
+		ConversionRate:     rate(counts.Purchases, counts.Views),
+	}, nil
+}
+
+func (s *service) GetDailyTrends(ctx context.Context, productID *int64, dates DateRange) ([]*DailyTrend, error) {
+	if !validRange(dates) {
+		return nil, ErrInvalidInput
+	}
+	if productID != nil {
+		if _, err := s.catalog.GetProductByID(ctx, *productID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.DailyTrends(ctx, productID, dates)
+}
+
+func (s *service) CreatePartitions(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= partitionsAhead; i++ {
+		if err := s.repo.CreatePartition(ctx, month.AddDate(0, i, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validRange(dates DateRange) bool {
+	return !dates.To.Before(dates.From) && dates.To.Sub(dates.From) < MaxRangeDays*24*time.Hour
+}
+
+func rate(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,83 @@
+package backinstock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/products/:id/notify-me", h.Subscribe)
+	router.GET("/back-in-stock/unsubscribe/:token", h.Unsubscribe)
+}
+
+func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	productID, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input SubscribeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode back in stock subscription input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.service.Subscribe(r.Context(), productID, input)
+	if err != nil {
+		h.logger.Error("Failed to subscribe to back in stock notification", zap.Error(err))
+		switch err {
+		case product.ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrInStock:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// Unsubscribe is linked from notification emails, so it is a GET that a
+// mail client can open directly.
+func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	err := h.service.Unsubscribe(r.Context(), ps.ByName("token"))
+	if err != nil {
+		h.logger.Error("Failed to unsubscribe from back in stock notification", zap.Error(err))
+		if err == ErrSubscriptionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("You have been unsubscribed from this notification.\n"))
+}
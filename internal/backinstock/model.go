@@ -0,0 +1,19 @@
+package backinstock
+
+import (
+	"time"
+)
+
+type Subscription struct {
+	ID         int64      `db:"id" json:"id"`
+	ProductID  int64      `db:"product_id" json:"product_id"`
+	Email      string     `db:"email" json:"email"`
+	EmailHash  *string    `db:"email_hash" json:"-"`
+	Token      string     `db:"token" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	NotifiedAt *time.Time `db:"notified_at" json:"notified_at,omitempty"`
+}
+
+type SubscribeInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
@@ -0,0 +1,109 @@
+package backinstock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/crypto"
+	"github.com/jmoiron/sqlx"
+)
+
+// EncryptedFields lists the columns sealed with the PII keyring
+var EncryptedFields = []crypto.Field{
+	{Table: "back_in_stock_subscriptions", Column: "email", HashColumn: "email_hash"},
+}
+
+// Repository defines the interface for back-in-stock subscription data operations
+type Repository interface {
+	Create(ctx context.Context, sub *Subscription) error
+	ListPending(ctx context.Context, productID int64) ([]*Subscription, error)
+	MarkNotified(ctx context.Context, id int64) error
+	DeleteByToken(ctx context.Context, token string) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db  *sqlx.DB
+	pii *crypto.Keyring
+}
+
+// NewRepository creates a new instance of the SQL repository. Subscriber
+// emails are encrypted with pii; nil stores them in plaintext.
+func NewRepository(db *sqlx.DB, pii *crypto.Keyring) Repository {
+	return &repository{db: db, pii: pii}
+}
+
+// Create adds a subscription. If the email already waits on the product the
+// existing subscription is returned instead.
+func (r *repository) Create(ctx context.Context, sub *Subscription) error {
+	email, err := r.pii.Encrypt(sub.Email)
+	if err != nil {
+		return fmt.Errorf("error encrypting subscriber email: %w", err)
+	}
+
+	query := `
+		INSERT INTO back_in_stock_subscriptions (product_id, email, email_hash, token)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, email_hash) WHERE notified_at IS NULL DO UPDATE SET email_hash = EXCLUDED.email_hash
+		RETURNING *`
+
+	err = r.db.QueryRowxContext(ctx, query, sub.ProductID, email, r.pii.BlindIndex(sub.Email), sub.Token).StructScan(sub)
+	if err != nil {
+		return fmt.Errorf("error creating back in stock subscription: %w", err)
+	}
+	return r.decrypt(sub)
+}
+
+// ListPending retrieves the subscriptions to a product not notified yet
+func (r *repository) ListPending(ctx context.Context, productID int64) ([]*Subscription, error) {
+	var subs []*Subscription
+	query := `SELECT * FROM back_in_stock_subscriptions WHERE product_id = $1 AND notified_at IS NULL ORDER BY id`
+	if err := r.db.SelectContext(ctx, &subs, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing back in stock subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		if err := r.decrypt(sub); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+// MarkNotified closes a subscription once its email has been sent
+func (r *repository) MarkNotified(ctx context.Context, id int64) error {
+	query := `UPDATE back_in_stock_subscriptions SET notified_at = NOW() WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error marking back in stock subscription notified: %w", err)
+	}
+	return nil
+}
+
+// DeleteByToken removes the subscription identified by an unsubscribe token
+func (r *repository) DeleteByToken(ctx context.Context, token string) error {
+	query := `DELETE FROM back_in_stock_subscriptions WHERE token = $1`
+	result, err := r.db.ExecContext(ctx, query, token)
+	if err != nil {
+		return fmt.Errorf("error deleting back in stock subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("back in stock subscription not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// decrypt replaces the stored subscriber email with its plaintext
+func (r *repository) decrypt(sub *Subscription) error {
+	email, err := r.pii.Decrypt(sub.Email)
+	if err != nil {
+		return fmt.Errorf("error decrypting email of back in stock subscription %d: %w", sub.ID, err)
+	}
+	sub.Email = email
+	return nil
+}
@@ -0,0 +1,167 @@
+package backinstock
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/events"
+	"github.com/dotslashbit/ecommerce-api/pkg/mailer"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("back in stock subscription not found")
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrInStock              = errors.New("product is in stock")
+)
+
+// Catalog looks up the products customers subscribe to.
+type Catalog interface {
+	GetProductByID(ctx context.Context, id int64) (*product.Product, error)
+}
+
+type Service interface {
+	Subscribe(ctx context.Context, productID int64, input SubscribeInput) (*Subscription, error)
+	Unsubscribe(ctx context.Context, token string) error
+	// NotifyProduct emails every pending subscriber of a restocked product.
+	NotifyProduct(ctx context.Context, restock *product.BackInStock) error
+}
+
+type service struct {
+	repo          Repository
+	catalog       Catalog
+	mail          mailer.Mailer
+	storefrontURL string
+	publicAPIURL  string
+	validator     *validator.Validate
+}
+
+// NewService creates the back-in-stock service. Emails link to the product
+// on storefrontURL and to the unsubscribe endpoint on publicAPIURL.
+func NewService(repo Repository, catalog Catalog, mail mailer.Mailer, storefrontURL, publicAPIURL string) Service {
+	return &service{
+		repo:          repo,
+		catalog:       catalog,
+		mail:          mail,
+		storefrontURL: storefrontURL,
+		publicAPIURL:  publicAPIURL,
+		validator:     validator.New(),
+	}
+}
+
+func (s *service) Subscribe(ctx context.Context, productID int64, input SubscribeInput) (*Subscription, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	p, err := s.catalog.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.StockQuantity > 0 {
+		return nil, ErrInStock
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		ProductID: productID,
+		Email:     input.Email,
+		Token:     token,
+	}
+	if err := s.repo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (s *service) Unsubscribe(ctx context.Context, token string) error {
+	err := s.repo.DeleteByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// NotifyProduct sends one email per pending subscription and closes each
+// subscription as it goes, so a retried event only reaches the subscribers
+// that were missed.
+func (s *service) NotifyProduct(ctx context.Context, restock *product.BackInStock) error {
+	subs, err := s.repo.ListPending(ctx, restock.ID)
+	if err != nil {
+		return err
+	}
+
+	// Events recorded before products had slugs only carry the ID
+	productURL := s.storefrontURL + "/products/" + restock.Slug
+	if restock.Slug == "" {
+		productURL = s.storefrontURL + "/products/" + strconv.FormatInt(restock.ID, 10)
+	}
+	for _, sub := range subs {
+		msg, err := mailer.Render(mailer.TemplateBackInStock, sub.Email, mailer.BackInStockData{
+			ProductName:    restock.Name,
+			ProductURL:     productURL,
+			UnsubscribeURL: s.publicAPIURL + "/back-in-stock/unsubscribe/" + sub.Token,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.mail.Send(ctx, msg); err != nil {
+			return fmt.Errorf("error sending back in stock email: %w", err)
+		}
+		if err := s.repo.MarkNotified(ctx, sub.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating unsubscribe token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// publisher notifies subscribers when a product.back_in_stock event is published
+type publisher struct {
+	service Service
+}
+
+// NewPublisher adapts the service to events.Publisher so the outbox relay
+// triggers notifications on restock
+func NewPublisher(service Service) events.Publisher {
+	return &publisher{service: service}
+}
+
+func (p *publisher) Publish(ctx context.Context, envelope *events.Envelope) error {
+	if envelope.Type != product.EventBackInStock {
+		return nil
+	}
+
+	var restock product.BackInStock
+	if err := json.Unmarshal(envelope.Data, &restock); err != nil {
+		return fmt.Errorf("error decoding back in stock event: %w", err)
+	}
+	return p.service.NotifyProduct(ctx, &restock)
+}
+
+func (p *publisher) Close() error {
+	return nil
+}
@@ -0,0 +1,233 @@
+package brand
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/locale"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/brands", h.ListBrands)
+	router.GET("/brands/:slug", h.GetBrandBySlug)
+	router.GET("/brands/:slug/logo", h.GetLogo)
+	router.GET("/brands/:slug/products", h.GetLandingPage)
+
+	router.POST("/admin/brands", request.Schema("brand.create", h.CreateBrand))
+	router.GET("/admin/brands", h.ListBrands)
+	router.GET("/admin/brands/:id", h.GetBrand)
+	router.PUT("/admin/brands/:id", request.Schema("brand.update", h.UpdateBrand))
+	router.DELETE("/admin/brands/:id", h.DeleteBrand)
+	router.PUT("/admin/brands/:id/logo", h.SetLogo)
+}
+
+func (h *Handler) ListBrands(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	brands, err := h.service.ListBrands(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list brands", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brands)
+}
+
+func (h *Handler) GetBrandBySlug(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	brand, err := h.service.GetBrandBySlug(r.Context(), ps.ByName("slug"))
+	if err != nil {
+		h.logger.Error("Failed to get brand", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *Handler) GetLogo(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	logo, err := h.service.GetLogo(r.Context(), ps.ByName("slug"))
+	if err != nil {
+		h.logger.Error("Failed to get brand logo", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", logo.ContentType)
+	if !logo.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", logo.ModTime.UTC().Format(http.TimeFormat))
+	}
+	w.Write(logo.Data)
+}
+
+// GetLandingPage lists the published products of a brand with the brand
+// itself, for brand landing pages.
+func (h *Handler) GetLandingPage(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	pagination := product.PaginationParams{Page: page, Limit: limit}
+
+	landing, err := h.service.GetLandingPage(r.Context(), ps.ByName("slug"), locale.Preferred(r), pagination)
+	if err != nil {
+		h.logger.Error("Failed to get brand landing page", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+	response.List(w, r, landing, landing.TotalCount, landing.Page, landing.Limit)
+}
+
+func (h *Handler) CreateBrand(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateBrandInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create brand input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.service.CreateBrand(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create brand", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *Handler) GetBrand(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	brand, err := h.service.GetBrand(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get brand", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *Handler) UpdateBrand(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input UpdateBrandInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update brand input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.service.UpdateBrand(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update brand", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *Handler) DeleteBrand(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBrand(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete brand", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetLogo takes the image as the raw request body
+func (h *Handler) SetLogo(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	// Read one byte past the limit so oversized logos are told apart
+	data, err := io.ReadAll(io.LimitReader(r.Body, MaxLogoSize+1))
+	if err != nil {
+		h.logger.Error("Failed to read brand logo", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.service.SetLogo(r.Context(), id, data)
+	if err != nil {
+		h.logger.Error("Failed to set brand logo", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+func (h *Handler) parseID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid brand ID", zap.Error(err))
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrBrandNotFound, ErrLogoNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput, ErrInvalidSlug:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrSlugTaken, ErrBrandInUse:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case ErrLogoTooLarge:
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case ErrUnsupportedLogo:
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,177 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+)
+
+// memoryRepository is an in-memory implementation of the Repository
+// interface, for tests and the demo mode. Products are kept elsewhere, so
+// unlike the SQL repository it deletes brands that still have products.
+type memoryRepository struct {
+	mu     sync.Mutex
+	brands map[int64]*Brand
+	lastID int64
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() Repository {
+	return &memoryRepository{brands: make(map[int64]*Brand)}
+}
+
+// Create adds a new brand
+func (r *memoryRepository) Create(ctx context.Context, brand *Brand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *brand
+	stored.StoreID = tenant.StoreIDOrDefault(ctx)
+	if r.slugTaken(&stored) {
+		return ErrSlugTaken
+	}
+
+	r.lastID++
+	now := time.Now()
+	stored.ID = r.lastID
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.brands[stored.ID] = &stored
+	*brand = stored
+	return nil
+}
+
+// GetByID retrieves a single brand by its ID
+func (r *memoryRepository) GetByID(ctx context.Context, id int64) (*Brand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	brand, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *brand
+	return &copied, nil
+}
+
+// GetBySlug retrieves a brand of the current store by its slug
+func (r *memoryRepository) GetBySlug(ctx context.Context, slug string) (*Brand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storeID := tenant.StoreIDOrDefault(ctx)
+	for _, brand := range r.brands {
+		if brand.StoreID == storeID && brand.Slug == slug {
+			copied := *brand
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("brand not found: %w", sql.ErrNoRows)
+}
+
+// List retrieves the brands of the current store
+func (r *memoryRepository) List(ctx context.Context) ([]*Brand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storeID := tenant.StoreIDOrDefault(ctx)
+	brands := []*Brand{}
+	for _, brand := range r.brands {
+		if brand.StoreID == storeID {
+			copied := *brand
+			brands = append(brands, &copied)
+		}
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		a, b := strings.ToLower(brands[i].Name), strings.ToLower(brands[j].Name)
+		if a != b {
+			return a < b
+		}
+		return brands[i].ID < brands[j].ID
+	})
+	return brands, nil
+}
+
+// Update modifies an existing brand
+func (r *memoryRepository) Update(ctx context.Context, id int64, input UpdateBrandInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	brand := *before
+	if input.Name != nil {
+		brand.Name = *input.Name
+	}
+	if input.Slug != nil {
+		brand.Slug = *input.Slug
+	}
+	if input.Description != nil {
+		brand.Description = *input.Description
+	}
+	if r.slugTaken(&brand) {
+		return ErrSlugTaken
+	}
+
+	brand.UpdatedAt = time.Now()
+	r.brands[id] = &brand
+	return nil
+}
+
+// SetLogo stores the logo's content type on the brand
+func (r *memoryRepository) SetLogo(ctx context.Context, id int64, contentType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	brand := *before
+	brand.LogoContentType = &contentType
+	brand.UpdatedAt = time.Now()
+	r.brands[id] = &brand
+	return nil
+}
+
+// Delete removes a brand
+func (r *memoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.scoped(ctx, id); err != nil {
+		return err
+	}
+	delete(r.brands, id)
+	return nil
+}
+
+// slugTaken reports whether another brand of the store uses the slug of
+// brand. r.mu must be held.
+func (r *memoryRepository) slugTaken(brand *Brand) bool {
+	for _, b := range r.brands {
+		if b.ID != brand.ID && b.StoreID == brand.StoreID && b.Slug == brand.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+// scoped returns the stored brand id when the current store may see it.
+// r.mu must be held.
+func (r *memoryRepository) scoped(ctx context.Context, id int64) (*Brand, error) {
+	brand, ok := r.brands[id]
+	if storeID, scoped := tenant.StoreID(ctx); !ok || (scoped && brand.StoreID != storeID) {
+		return nil, fmt.Errorf("brand not found: %w", sql.ErrNoRows)
+	}
+	return brand, nil
+}
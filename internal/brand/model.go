@@ -0,0 +1,56 @@
+package brand
+
+import (
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+)
+
+// Brand is a manufacturer or label products of its store may belong to.
+// Brands are addressed by slug on the storefront.
+type Brand struct {
+	ID              int64     `db:"id" json:"id"`
+	StoreID         int64     `db:"store_id" json:"-"`
+	Name            string    `db:"name" json:"name"`
+	Slug            string    `db:"slug" json:"slug"`
+	Description     string    `db:"description" json:"description"`
+	LogoContentType *string   `db:"logo_content_type" json:"-"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+
+	// LogoURL is the public path of the logo, set when one was uploaded
+	LogoURL string `db:"-" json:"logo_url,omitempty"`
+}
+
+// CreateBrandInput creates a brand. The slug is derived from the name when
+// it is empty.
+type CreateBrandInput struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Slug        string `json:"slug" validate:"omitempty,max=100"`
+	Description string `json:"description"`
+}
+
+// UpdateBrandInput changes the fields that are set. Renaming a brand keeps
+// its slug, so existing links stay valid.
+type UpdateBrandInput struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Slug        *string `json:"slug" validate:"omitempty,max=100"`
+	Description *string `json:"description"`
+}
+
+// Logo is an uploaded brand logo
+type Logo struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
+
+// LandingPage is a brand with a page of its published products. The
+// pagination fields are written to the response meta, not the data.
+type LandingPage struct {
+	Brand      *Brand             `json:"brand"`
+	Products   []*product.Product `json:"products"`
+	TotalCount int                `json:"-"`
+	Page       int                `json:"-"`
+	Limit      int                `json:"-"`
+}
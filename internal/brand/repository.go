@@ -0,0 +1,160 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Repository defines the interface for brand data operations
+type Repository interface {
+	Create(ctx context.Context, brand *Brand) error
+	GetByID(ctx context.Context, id int64) (*Brand, error)
+	GetBySlug(ctx context.Context, slug string) (*Brand, error)
+	// List retrieves every brand of the current store ordered by name
+	List(ctx context.Context) ([]*Brand, error)
+	Update(ctx context.Context, id int64, input UpdateBrandInput) error
+	// SetLogo records the content type of a brand's uploaded logo
+	SetLogo(ctx context.Context, id int64, contentType string) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository. Brands are
+// scoped to the store in the request context.
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new brand
+func (r *repository) Create(ctx context.Context, brand *Brand) error {
+	query := `
+		INSERT INTO brands (store_id, name, slug, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx), brand.Name, brand.Slug, brand.Description).
+		StructScan(brand)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrSlugTaken
+		}
+		return fmt.Errorf("error creating brand: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single brand by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*Brand, error) {
+	var brand Brand
+	query := `SELECT * FROM brands WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.GetContext(ctx, &brand, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("brand not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting brand: %w", err)
+	}
+	return &brand, nil
+}
+
+// GetBySlug retrieves a brand of the current store by its slug
+func (r *repository) GetBySlug(ctx context.Context, slug string) (*Brand, error) {
+	var brand Brand
+	query := `SELECT * FROM brands WHERE slug = $1 AND store_id = $2`
+	if err := r.db.GetContext(ctx, &brand, query, slug, tenant.StoreIDOrDefault(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("brand not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting brand: %w", err)
+	}
+	return &brand, nil
+}
+
+// List retrieves the brands of the current store
+func (r *repository) List(ctx context.Context) ([]*Brand, error) {
+	brands := []*Brand{}
+	query := `SELECT * FROM brands WHERE store_id = $1 ORDER BY lower(name), id`
+	if err := r.db.SelectContext(ctx, &brands, query, tenant.StoreIDOrDefault(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing brands: %w", err)
+	}
+	return brands, nil
+}
+
+// Update modifies an existing brand
+func (r *repository) Update(ctx context.Context, id int64, input UpdateBrandInput) error {
+	query := `UPDATE brands SET `
+	args := []interface{}{}
+	argID := 1
+
+	if input.Name != nil {
+		query += fmt.Sprintf("name = $%d, ", argID)
+		args = append(args, *input.Name)
+		argID++
+	}
+	if input.Slug != nil {
+		query += fmt.Sprintf("slug = $%d, ", argID)
+		args = append(args, *input.Slug)
+		argID++
+	}
+	if input.Description != nil {
+		query += fmt.Sprintf("description = $%d, ", argID)
+		args = append(args, *input.Description)
+		argID++
+	}
+
+	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d AND ($%d::integer IS NULL OR store_id = $%d)", argID, argID+1, argID+1)
+	args = append(args, id, tenant.StoreArg(ctx))
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrSlugTaken
+		}
+		return fmt.Errorf("error updating brand: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// SetLogo stores the logo's content type on the brand
+func (r *repository) SetLogo(ctx context.Context, id int64, contentType string) error {
+	query := `
+		UPDATE brands SET logo_content_type = $1, updated_at = NOW()
+		WHERE id = $2 AND ($3::integer IS NULL OR store_id = $3)`
+	result, err := r.db.ExecContext(ctx, query, contentType, id, tenant.StoreArg(ctx))
+	if err != nil {
+		return fmt.Errorf("error setting brand logo: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// Delete removes a brand no product belongs to
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM brands WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return ErrBrandInUse
+		}
+		return fmt.Errorf("error deleting brand: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func checkAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("brand not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrBrandNotFound   = errors.New("brand not found")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrInvalidSlug     = errors.New("slug may only contain lowercase letters, digits and single hyphens")
+	ErrSlugTaken       = errors.New("brand slug already in use")
+	ErrBrandInUse      = errors.New("brand still has products")
+	ErrLogoNotFound    = errors.New("brand has no logo")
+	ErrLogoTooLarge    = errors.New("logo is too large")
+	ErrUnsupportedLogo = errors.New("logo must be a PNG, JPEG or WebP image")
+)
+
+// MaxLogoSize is the largest logo accepted, in bytes
+const MaxLogoSize = 2 << 20
+
+// logoTypes are the accepted logo formats
+var logoTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/webp": true,
+}
+
+// Catalog reads the products of a brand.
+type Catalog interface {
+	ListProducts(ctx context.Context, filter product.ProductFilter, pagination product.PaginationParams) ([]*product.Product, int, error)
+	Localize(ctx context.Context, locales []string, products ...*product.Product) error
+}
+
+type Service interface {
+	CreateBrand(ctx context.Context, input CreateBrandInput) (*Brand, error)
+	GetBrand(ctx context.Context, id int64) (*Brand, error)
+	GetBrandBySlug(ctx context.Context, slug string) (*Brand, error)
+	ListBrands(ctx context.Context) ([]*Brand, error)
+	UpdateBrand(ctx context.Context, id int64, input UpdateBrandInput) (*Brand, error)
+	// DeleteBrand removes a brand. Its products must be moved to another
+	// brand or cleared first.
+	DeleteBrand(ctx context.Context, id int64) error
+	// SetLogo stores a brand's logo, replacing any previous one. The format
+	// is sniffed from data.
+	SetLogo(ctx context.Context, id int64, data []byte) (*Brand, error)
+	GetLogo(ctx context.Context, slug string) (*Logo, error)
+	// GetLandingPage returns a brand with a page of its published products,
+	// priced now and translated to the first of locales available.
+	GetLandingPage(ctx context.Context, slug string, locales []string, pagination product.PaginationParams) (*LandingPage, error)
+}
+
+type service struct {
+	repo      Repository
+	catalog   Catalog
+	blobs     blobstore.Store
+	validator *validator.Validate
+}
+
+// NewService creates the brand service. Logos are kept in blobs.
+func NewService(repo Repository, catalog Catalog, blobs blobstore.Store) Service {
+	return &service{
+		repo:      repo,
+		catalog:   catalog,
+		blobs:     blobs,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) CreateBrand(ctx context.Context, input CreateBrandInput) (*Brand, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Slug == "" {
+		input.Slug = slug.Make(input.Name)
+	}
+	if !slug.Valid(input.Slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	brand := &Brand{
+		Name:        input.Name,
+		Slug:        input.Slug,
+		Description: input.Description,
+	}
+	if err := s.repo.Create(ctx, brand); err != nil {
+		return nil, err
+	}
+	return withLogoURL(brand), nil
+}
+
+func (s *service) GetBrand(ctx context.Context, id int64) (*Brand, error) {
+	brand, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBrandNotFound
+		}
+		return nil, err
+	}
+	return withLogoURL(brand), nil
+}
+
+func (s *service) GetBrandBySlug(ctx context.Context, slug string) (*Brand, error) {
+	brand, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBrandNotFound
+		}
+		return nil, err
+	}
+	return withLogoURL(brand), nil
+}
+
+func (s *service) ListBrands(ctx context.Context) ([]*Brand, error) {
+	brands, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, brand := range brands {
+		withLogoURL(brand)
+	}
+	return brands, nil
+}
+
+func (s *service) UpdateBrand(ctx context.Context, id int64, input UpdateBrandInput) (*Brand, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Slug != nil && !slug.Valid(*input.Slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	if err := s.repo.Update(ctx, id, input); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBrandNotFound
+		}
+		return nil, err
+	}
+	return s.GetBrand(ctx, id)
+}
+
+func (s *service) DeleteBrand(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBrandNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) SetLogo(ctx context.Context, id int64, data []byte) (*Brand, error) {
+	if len(data) > MaxLogoSize {
+		return nil, ErrLogoTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !logoTypes[contentType] {
+		return nil, ErrUnsupportedLogo
+	}
+
+	brand, err := s.GetBrand(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, logoKey(brand), contentType, data); err != nil {
+		return nil, fmt.Errorf("error storing brand logo: %w", err)
+	}
+	if err := s.repo.SetLogo(ctx, id, contentType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBrandNotFound
+		}
+		return nil, err
+	}
+	return s.GetBrand(ctx, id)
+}
+
+func (s *service) GetLogo(ctx context.Context, slug string) (*Logo, error) {
+	brand, err := s.GetBrandBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if brand.LogoContentType == nil {
+		return nil, ErrLogoNotFound
+	}
+
+	object, err := s.blobs.Get(ctx, logoKey(brand))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, ErrLogoNotFound
+		}
+		return nil, fmt.Errorf("error reading brand logo: %w", err)
+	}
+	return &Logo{Data: object.Data, ContentType: *brand.LogoContentType, ModTime: object.ModTime}, nil
+}
+
+func (s *service) GetLandingPage(ctx context.Context, slug string, locales []string, pagination product.PaginationParams) (*LandingPage, error) {
+	brand, err := s.GetBrandBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	published := product.StatusPublished
+	filter := product.ProductFilter{Status: &published, Brand: &brand.Slug}
+	products, totalCount, err := s.catalog.ListProducts(ctx, filter, pagination)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, p := range products {
+		p.ApplySale(now)
+	}
+	// Untranslated content is still usable, so a failed lookup serves the
+	// default locale rather than an error
+	_ = s.catalog.Localize(ctx, locales, products...)
+
+	return &LandingPage{
+		Brand:      brand,
+		Products:   products,
+		TotalCount: totalCount,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	}, nil
+}
+
+// logoKey is where a brand's logo is kept in the blob store
+func logoKey(brand *Brand) string {
+	return fmt.Sprintf("brands/%d/%d/logo", brand.StoreID, brand.ID)
+}
+
+// withLogoURL sets the public logo path of brand
+func withLogoURL(brand *Brand) *Brand {
+	brand.LogoURL = ""
+	if brand.LogoContentType != nil {
+		brand.LogoURL = "/brands/" + brand.Slug + "/logo"
+	}
+	return brand
+}
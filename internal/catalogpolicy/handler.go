@@ -0,0 +1,233 @@
+package catalogpolicy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/catalog-policies", h.CreatePolicy)
+	router.GET("/admin/catalog-policies", h.ListPolicies)
+	router.GET("/admin/catalog-policies/report", h.GetReport)
+	router.PUT("/admin/catalog-policies/:id", h.UpdatePolicy)
+	router.DELETE("/admin/catalog-policies/:id", h.DeletePolicy)
+
+	router.POST("/admin/attribute-definitions", h.CreateAttribute)
+	router.GET("/admin/attribute-definitions", h.ListAttributes)
+	router.PUT("/admin/attribute-definitions/:id", h.UpdateAttribute)
+	router.DELETE("/admin/attribute-definitions/:id", h.DeleteAttribute)
+}
+
+func (h *Handler) CreatePolicy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreatePolicyInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create catalog policy input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create catalog policy", zap.Error(err))
+		if err == ErrInvalidInput {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(policy)
+}
+
+func (h *Handler) ListPolicies(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	policies, err := h.service.ListPolicies(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list catalog policies", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	report, err := h.service.Report(r.Context(), refresh)
+	if err != nil {
+		h.logger.Error("Failed to build catalog policy report", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *Handler) UpdatePolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid catalog policy ID", zap.Error(err))
+		http.Error(w, "Invalid catalog policy ID", http.StatusBadRequest)
+		return
+	}
+
+	var input UpdatePolicyInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update catalog policy input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.UpdatePolicy(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update catalog policy", zap.Error(err))
+		switch err {
+		case ErrPolicyNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DeletePolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid catalog policy ID", zap.Error(err))
+		http.Error(w, "Invalid catalog policy ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeletePolicy(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to delete catalog policy", zap.Error(err))
+		if err == ErrPolicyNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) CreateAttribute(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateAttributeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create attribute definition input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	definition, err := h.service.CreateAttribute(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create attribute definition", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrAttributeExists:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(definition)
+}
+
+func (h *Handler) ListAttributes(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	definitions, err := h.service.ListAttributes(r.Context(), r.URL.Query().Get("category"))
+	if err != nil {
+		h.logger.Error("Failed to list attribute definitions", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(definitions)
+}
+
+func (h *Handler) UpdateAttribute(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid attribute definition ID", zap.Error(err))
+		http.Error(w, "Invalid attribute definition ID", http.StatusBadRequest)
+		return
+	}
+
+	var input UpdateAttributeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update attribute definition input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.UpdateAttribute(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update attribute definition", zap.Error(err))
+		switch err {
+		case ErrAttributeNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteAttribute(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid attribute definition ID", zap.Error(err))
+		http.Error(w, "Invalid attribute definition ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteAttribute(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to delete attribute definition", zap.Error(err))
+		if err == ErrAttributeNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
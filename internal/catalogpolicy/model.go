@@ -0,0 +1,107 @@
+package catalogpolicy
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Rule string
+
+const (
+	// RuleDescriptionMinLength requires descriptions of at least MinValue characters.
+	RuleDescriptionMinLength Rule = "description_min_length"
+	// RuleBannedWords rejects names and descriptions containing any of Words.
+	RuleBannedWords Rule = "banned_words"
+	// RuleMinCategories requires at least MinValue categories.
+	RuleMinCategories Rule = "min_categories"
+)
+
+type Policy struct {
+	ID        int64          `db:"id" json:"id"`
+	StoreID   int64          `db:"store_id" json:"store_id"`
+	Name      string         `db:"name" json:"name"`
+	Rule      Rule           `db:"rule" json:"rule"`
+	Category  *string        `db:"category" json:"category,omitempty"`
+	MinValue  *int           `db:"min_value" json:"min_value,omitempty"`
+	Words     pq.StringArray `db:"words" json:"words"`
+	Enabled   bool           `db:"enabled" json:"enabled"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+type CreatePolicyInput struct {
+	Name     string   `json:"name" validate:"required"`
+	Rule     Rule     `json:"rule" validate:"required"`
+	Category *string  `json:"category"`
+	MinValue *int     `json:"min_value" validate:"omitempty,min=0"`
+	Words    []string `json:"words"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+type UpdatePolicyInput struct {
+	Name     *string   `json:"name"`
+	Category *string   `json:"category"`
+	MinValue *int      `json:"min_value" validate:"omitempty,min=0"`
+	Words    *[]string `json:"words"`
+	Enabled  *bool     `json:"enabled"`
+}
+
+// AttributeType is the kind of value an attribute holds
+type AttributeType string
+
+const (
+	AttributeText    AttributeType = "text"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+)
+
+// AttributeDefinition describes an attribute of the products in a category.
+// Products missing a required attribute, or holding a value of the wrong
+// type or outside AllowedValues, violate the catalog policies.
+type AttributeDefinition struct {
+	ID            int64          `db:"id" json:"id"`
+	StoreID       int64          `db:"store_id" json:"store_id"`
+	Category      string         `db:"category" json:"category"`
+	Key           string         `db:"key" json:"key"`
+	Label         string         `db:"label" json:"label"`
+	Type          AttributeType  `db:"type" json:"type"`
+	Unit          *string        `db:"unit" json:"unit,omitempty"`
+	AllowedValues pq.StringArray `db:"allowed_values" json:"allowed_values"`
+	Required      bool           `db:"required" json:"required"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+type CreateAttributeInput struct {
+	Category      string        `json:"category" validate:"required"`
+	Key           string        `json:"key" validate:"required,max=64"`
+	Label         string        `json:"label" validate:"required"`
+	Type          AttributeType `json:"type" validate:"required,oneof=text number boolean"`
+	Unit          *string       `json:"unit" validate:"omitempty,max=32"`
+	AllowedValues []string      `json:"allowed_values"`
+	Required      bool          `json:"required"`
+}
+
+type UpdateAttributeInput struct {
+	Label         *string   `json:"label"`
+	Unit          *string   `json:"unit" validate:"omitempty,max=32"`
+	AllowedValues *[]string `json:"allowed_values"`
+	Required      *bool     `json:"required"`
+}
+
+// ProductReport lists the policy violations of a single product.
+type ProductReport struct {
+	ProductID  int64    `json:"product_id"`
+	StoreID    int64    `json:"-"`
+	Name       string   `json:"name"`
+	Violations []string `json:"violations"`
+}
+
+// Report is the catalog integrity report: every product violating at least
+// one enabled policy.
+type Report struct {
+	Products   []*ProductReport `json:"products"`
+	ComputedAt time.Time        `json:"computed_at"`
+	Stale      bool             `json:"stale"`
+}
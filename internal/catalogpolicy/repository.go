@@ -0,0 +1,254 @@
+package catalogpolicy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Repository defines the interface for catalog policy data operations
+type Repository interface {
+	Create(ctx context.Context, policy *Policy) error
+	// List retrieves the policies of the current store, or of every store
+	// outside a request, optionally only the enabled ones.
+	List(ctx context.Context, enabledOnly bool) ([]*Policy, error)
+	Update(ctx context.Context, id int64, input UpdatePolicyInput) error
+	Delete(ctx context.Context, id int64) error
+
+	CreateAttribute(ctx context.Context, definition *AttributeDefinition) error
+	// ListAttributes retrieves the attribute definitions of the current
+	// store, or of every store outside a request. An empty category lists
+	// the definitions of all categories.
+	ListAttributes(ctx context.Context, category string) ([]*AttributeDefinition, error)
+	UpdateAttribute(ctx context.Context, id int64, input UpdateAttributeInput) error
+	DeleteAttribute(ctx context.Context, id int64) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new policy to the database
+func (r *repository) Create(ctx context.Context, policy *Policy) error {
+	query := `
+		INSERT INTO catalog_policies (store_id, name, rule, category, min_value, words, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, store_id, created_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx),
+		policy.Name, policy.Rule, policy.Category, policy.MinValue, policy.Words, policy.Enabled).
+		StructScan(policy)
+
+	if err != nil {
+		return fmt.Errorf("error creating catalog policy: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves policies ordered by ID
+func (r *repository) List(ctx context.Context, enabledOnly bool) ([]*Policy, error) {
+	query := `SELECT * FROM catalog_policies WHERE ($1::integer IS NULL OR store_id = $1)`
+	if enabledOnly {
+		query += ` AND enabled`
+	}
+	query += ` ORDER BY id`
+
+	var policies []*Policy
+	if err := r.db.SelectContext(ctx, &policies, query, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing catalog policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Update modifies an existing policy
+func (r *repository) Update(ctx context.Context, id int64, input UpdatePolicyInput) error {
+	query := `UPDATE catalog_policies SET `
+	args := []interface{}{}
+	argID := 1
+
+	if input.Name != nil {
+		query += fmt.Sprintf("name = $%d, ", argID)
+		args = append(args, *input.Name)
+		argID++
+	}
+	if input.Category != nil {
+		query += fmt.Sprintf("category = NULLIF($%d, ''), ", argID)
+		args = append(args, *input.Category)
+		argID++
+	}
+	if input.MinValue != nil {
+		query += fmt.Sprintf("min_value = $%d, ", argID)
+		args = append(args, *input.MinValue)
+		argID++
+	}
+	if input.Words != nil {
+		query += fmt.Sprintf("words = $%d, ", argID)
+		args = append(args, pq.StringArray(*input.Words))
+		argID++
+	}
+	if input.Enabled != nil {
+		query += fmt.Sprintf("enabled = $%d, ", argID)
+		args = append(args, *input.Enabled)
+		argID++
+	}
+
+	query = strings.TrimSuffix(query, ", ")
+	if len(args) == 0 {
+		query += "updated_at = NOW()"
+	} else {
+		query += ", updated_at = NOW()"
+	}
+	query += fmt.Sprintf(" WHERE id = $%d AND ($%d::integer IS NULL OR store_id = $%d)", argID, argID+1, argID+1)
+	args = append(args, id, tenant.StoreArg(ctx))
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating catalog policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("catalog policy not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// Delete removes a policy from the database
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM catalog_policies WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		return fmt.Errorf("error deleting catalog policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("catalog policy not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// CreateAttribute adds a new attribute definition. It returns
+// ErrAttributeExists when the category already defines the key.
+func (r *repository) CreateAttribute(ctx context.Context, definition *AttributeDefinition) error {
+	query := `
+		INSERT INTO attribute_definitions (store_id, category, key, label, type, unit, allowed_values, required)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *`
+
+	err := r.db.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx), definition.Category, definition.Key,
+		definition.Label, definition.Type, definition.Unit, definition.AllowedValues, definition.Required).
+		StructScan(definition)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrAttributeExists
+		}
+		return fmt.Errorf("error creating attribute definition: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttributes retrieves attribute definitions ordered by category and key
+func (r *repository) ListAttributes(ctx context.Context, category string) ([]*AttributeDefinition, error) {
+	query := `
+		SELECT * FROM attribute_definitions
+		WHERE ($1::integer IS NULL OR store_id = $1) AND ($2 = '' OR lower(category) = lower($2))
+		ORDER BY lower(category), key`
+
+	definitions := []*AttributeDefinition{}
+	if err := r.db.SelectContext(ctx, &definitions, query, tenant.StoreArg(ctx), category); err != nil {
+		return nil, fmt.Errorf("error listing attribute definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// UpdateAttribute modifies an existing attribute definition
+func (r *repository) UpdateAttribute(ctx context.Context, id int64, input UpdateAttributeInput) error {
+	query := `UPDATE attribute_definitions SET `
+	args := []interface{}{}
+	argID := 1
+
+	if input.Label != nil {
+		query += fmt.Sprintf("label = $%d, ", argID)
+		args = append(args, *input.Label)
+		argID++
+	}
+	if input.Unit != nil {
+		query += fmt.Sprintf("unit = NULLIF($%d, ''), ", argID)
+		args = append(args, *input.Unit)
+		argID++
+	}
+	if input.AllowedValues != nil {
+		query += fmt.Sprintf("allowed_values = $%d, ", argID)
+		args = append(args, pq.StringArray(*input.AllowedValues))
+		argID++
+	}
+	if input.Required != nil {
+		query += fmt.Sprintf("required = $%d, ", argID)
+		args = append(args, *input.Required)
+		argID++
+	}
+
+	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d AND ($%d::integer IS NULL OR store_id = $%d)", argID, argID+1, argID+1)
+	args = append(args, id, tenant.StoreArg(ctx))
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating attribute definition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attribute definition not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// DeleteAttribute removes an attribute definition; product values are kept
+func (r *repository) DeleteAttribute(ctx context.Context, id int64) error {
+	query := `DELETE FROM attribute_definitions WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		return fmt.Errorf("error deleting attribute definition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attribute definition not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
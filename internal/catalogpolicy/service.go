@@ -0,0 +1,415 @@
+package catalogpolicy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/reportcache"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrPolicyNotFound = errors.New("catalog policy not found")
+	ErrInvalidInput   = errors.New("invalid input")
+
+	ErrAttributeNotFound = errors.New("attribute definition not found")
+	ErrAttributeExists   = errors.New("attribute already defined for category")
+)
+
+// reportPageSize is the number of products loaded per page while building
+// the catalog integrity report.
+const reportPageSize = 100
+
+// ReportName is the reportcache key of the catalog integrity report
+const ReportName = "catalog_policy_report"
+
+type Service interface {
+	CreatePolicy(ctx context.Context, input CreatePolicyInput) (*Policy, error)
+	ListPolicies(ctx context.Context) ([]*Policy, error)
+	UpdatePolicy(ctx context.Context, id int64, input UpdatePolicyInput) error
+	DeletePolicy(ctx context.Context, id int64) error
+	CreateAttribute(ctx context.Context, input CreateAttributeInput) (*AttributeDefinition, error)
+	ListAttributes(ctx context.Context, category string) ([]*AttributeDefinition, error)
+	UpdateAttribute(ctx context.Context, id int64, input UpdateAttributeInput) error
+	DeleteAttribute(ctx context.Context, id int64) error
+	// Check implements product.PolicyChecker.
+	Check(ctx context.Context, p *product.Product) ([]string, error)
+	// Report returns the cached integrity report, recomputing it when
+	// refresh is set.
+	Report(ctx context.Context, refresh bool) (*Report, error)
+}
+
+type service struct {
+	repo      Repository
+	products  product.Repository
+	reports   *reportcache.Cache
+	validator *validator.Validate
+}
+
+// NewService creates the catalog policy service and registers the integrity
+// report with reports for background refresh.
+func NewService(repo Repository, products product.Repository, reports *reportcache.Cache) Service {
+	s := &service{
+		repo:      repo,
+		products:  products,
+		reports:   reports,
+		validator: validator.New(),
+	}
+	reports.Register(ReportName, s.computeReport)
+	return s
+}
+
+func (s *service) CreatePolicy(ctx context.Context, input CreatePolicyInput) (*Policy, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	policy := &Policy{
+		Name:     input.Name,
+		Rule:     input.Rule,
+		Category: input.Category,
+		MinValue: input.MinValue,
+		Words:    input.Words,
+		Enabled:  true,
+	}
+	if policy.Words == nil {
+		policy.Words = []string{}
+	}
+	if input.Enabled != nil {
+		policy.Enabled = *input.Enabled
+	}
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return policy, nil
+}
+
+func (s *service) ListPolicies(ctx context.Context) ([]*Policy, error) {
+	return s.repo.List(ctx, false)
+}
+
+func (s *service) UpdatePolicy(ctx context.Context, id int64, input UpdatePolicyInput) error {
+	if err := s.validator.Struct(input); err != nil {
+		return ErrInvalidInput
+	}
+
+	err := s.repo.Update(ctx, id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPolicyNotFound
+		}
+		return err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return nil
+}
+
+func (s *service) DeletePolicy(ctx context.Context, id int64) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPolicyNotFound
+		}
+		return err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return nil
+}
+
+func (s *service) CreateAttribute(ctx context.Context, input CreateAttributeInput) (*AttributeDefinition, error) {
+	if err := s.validator.Struct(input); err != nil || !product.ValidAttributeKey(input.Key) {
+		return nil, ErrInvalidInput
+	}
+	// Allowed values are only matched against text attributes
+	if len(input.AllowedValues) > 0 && input.Type != AttributeText {
+		return nil, ErrInvalidInput
+	}
+
+	definition := &AttributeDefinition{
+		Category:      strings.TrimSpace(input.Category),
+		Key:           input.Key,
+		Label:         input.Label,
+		Type:          input.Type,
+		Unit:          input.Unit,
+		AllowedValues: input.AllowedValues,
+		Required:      input.Required,
+	}
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = []string{}
+	}
+
+	if err := s.repo.CreateAttribute(ctx, definition); err != nil {
+		return nil, err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return definition, nil
+}
+
+func (s *service) ListAttributes(ctx context.Context, category string) ([]*AttributeDefinition, error) {
+	return s.repo.ListAttributes(ctx, category)
+}
+
+func (s *service) UpdateAttribute(ctx context.Context, id int64, input UpdateAttributeInput) error {
+	if err := s.validator.Struct(input); err != nil {
+		return ErrInvalidInput
+	}
+
+	err := s.repo.UpdateAttribute(ctx, id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttributeNotFound
+		}
+		return err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return nil
+}
+
+func (s *service) DeleteAttribute(ctx context.Context, id int64) error {
+	err := s.repo.DeleteAttribute(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttributeNotFound
+		}
+		return err
+	}
+	s.reports.Invalidate(ReportName)
+
+	return nil
+}
+
+func (s *service) Check(ctx context.Context, p *product.Product) ([]string, error) {
+	policies, err := s.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := s.repo.ListAttributes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(evaluate(policies, p), evaluateAttributes(definitions, p)...), nil
+}
+
+func (s *service) Report(ctx context.Context, refresh bool) (*Report, error) {
+	// The cached report covers every store and is cut down to the
+	// requesting one
+	result, err := s.reports.Get(tenant.WithoutStore(ctx), ReportName, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	products := result.Data.([]*ProductReport)
+	if storeID, ok := tenant.StoreID(ctx); ok {
+		products = []*ProductReport{}
+		for _, report := range result.Data.([]*ProductReport) {
+			if report.StoreID == storeID {
+				products = append(products, report)
+			}
+		}
+	}
+
+	return &Report{
+		Products:   products,
+		ComputedAt: result.ComputedAt,
+		Stale:      result.Stale,
+	}, nil
+}
+
+func (s *service) computeReport(ctx context.Context) (interface{}, error) {
+	policies, err := s.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := s.repo.ListAttributes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []*ProductReport{}
+	if len(policies) == 0 && len(definitions) == 0 {
+		return reports, nil
+	}
+
+	for page := 1; ; page++ {
+		pagination := product.PaginationParams{Page: page, Limit: reportPageSize}
+		products, total, err := s.products.List(ctx, product.ProductFilter{}, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range products {
+			violations := append(evaluate(policies, p), evaluateAttributes(definitions, p)...)
+			if len(violations) > 0 {
+				reports = append(reports, &ProductReport{
+					ProductID:  p.ID,
+					StoreID:    p.StoreID,
+					Name:       p.Name,
+					Violations: violations,
+				})
+			}
+		}
+
+		if page*reportPageSize >= total {
+			break
+		}
+	}
+
+	return reports, nil
+}
+
+func validatePolicy(policy *Policy) error {
+	switch policy.Rule {
+	case RuleDescriptionMinLength, RuleMinCategories:
+		if policy.MinValue == nil {
+			return ErrInvalidInput
+		}
+	case RuleBannedWords:
+		if len(policy.Words) == 0 {
+			return ErrInvalidInput
+		}
+	default:
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// evaluate returns a human readable message for every policy of p's store
+// that p violates.
+func evaluate(policies []*Policy, p *product.Product) []string {
+	var violations []string
+	for _, policy := range policies {
+		if policy.StoreID != p.StoreID || !appliesTo(policy, p) {
+			continue
+		}
+
+		switch policy.Rule {
+		case RuleDescriptionMinLength:
+			if policy.MinValue != nil && len([]rune(strings.TrimSpace(p.Description))) < *policy.MinValue {
+				violations = append(violations,
+					fmt.Sprintf("%s: description must be at least %d characters", policy.Name, *policy.MinValue))
+			}
+		case RuleMinCategories:
+			if policy.MinValue != nil && len(p.Categories) < *policy.MinValue {
+				violations = append(violations,
+					fmt.Sprintf("%s: at least %d categories are required", policy.Name, *policy.MinValue))
+			}
+		case RuleBannedWords:
+			if word, ok := containsBannedWord(p.Name+" "+p.Description, policy.Words); ok {
+				violations = append(violations,
+					fmt.Sprintf("%s: banned word %q is not allowed", policy.Name, word))
+			}
+		}
+	}
+	return violations
+}
+
+// evaluateAttributes returns a message for every required attribute p is
+// missing and every value that does not match its definition. Attributes
+// without a definition are accepted as they are.
+func evaluateAttributes(definitions []*AttributeDefinition, p *product.Product) []string {
+	var violations []string
+	for _, definition := range definitions {
+		if definition.StoreID != p.StoreID || !inCategory(p, definition.Category) {
+			continue
+		}
+
+		value, ok := p.Attributes[definition.Key]
+		if !ok {
+			if definition.Required {
+				violations = append(violations,
+					fmt.Sprintf("attribute %s is required for %s products", definition.Key, definition.Category))
+			}
+			continue
+		}
+
+		switch definition.Type {
+		case AttributeText:
+			text, ok := value.(string)
+			if !ok {
+				violations = append(violations, fmt.Sprintf("attribute %s must be text", definition.Key))
+			} else if len(definition.AllowedValues) > 0 && !allowed(definition.AllowedValues, text) {
+				violations = append(violations, fmt.Sprintf("attribute %s must be one of %s",
+					definition.Key, strings.Join(definition.AllowedValues, ", ")))
+			}
+		case AttributeNumber:
+			if _, ok := value.(float64); !ok {
+				violations = append(violations, fmt.Sprintf("attribute %s must be a number", definition.Key))
+			}
+		case AttributeBoolean:
+			if _, ok := value.(bool); !ok {
+				violations = append(violations, fmt.Sprintf("attribute %s must be true or false", definition.Key))
+			}
+		}
+	}
+	return violations
+}
+
+func allowed(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether a policy scoped to a category covers p.
+func appliesTo(policy *Policy, p *product.Product) bool {
+	if policy.Category == nil || *policy.Category == "" {
+		return true
+	}
+	return inCategory(p, *policy.Category)
+}
+
+func inCategory(p *product.Product, category string) bool {
+	for _, c := range p.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsBannedWord matches single words against whole tokens so "ass" does
+// not match "glass", and multi-word phrases as case-insensitive substrings.
+func containsBannedWord(text string, words []string) (string, bool) {
+	lower := strings.ToLower(text)
+	tokens := map[string]bool{}
+	for _, token := range strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		tokens[token] = true
+	}
+
+	for _, word := range words {
+		w := strings.ToLower(strings.TrimSpace(word))
+		if w == "" {
+			continue
+		}
+		if strings.ContainsAny(w, " -") {
+			if strings.Contains(lower, w) {
+				return word, true
+			}
+		} else if tokens[w] {
+			return word, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,162 @@
+package category
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/categories/tree", h.GetTree)
+	router.GET("/categories/tree/:slug", h.GetSubtree)
+
+	router.POST("/admin/categories", request.Schema("category.create", h.CreateCategory))
+	router.GET("/admin/categories/:id", h.GetCategory)
+	router.PUT("/admin/categories/:id", request.Schema("category.update", h.UpdateCategory))
+	router.DELETE("/admin/categories/:id", h.DeleteCategory)
+}
+
+func (h *Handler) GetTree(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	tree, err := h.service.GetTree(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get category tree", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// GetSubtree returns a category with its descendants. Former slugs are
+// redirected permanently to the current one.
+func (h *Handler) GetSubtree(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	node, err := h.service.GetSubtree(r.Context(), ps.ByName("slug"))
+	if err != nil {
+		h.logger.Error("Failed to get category subtree", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+	if node.Slug != ps.ByName("slug") {
+		http.Redirect(w, r, "/categories/tree/"+node.Slug, http.StatusMovedPermanently)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(node)
+}
+
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateCategoryInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create category input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.CreateCategory(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create category", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	category, err := h.service.GetCategory(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get category", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input UpdateCategoryInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update category input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.UpdateCategory(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update category", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCategory(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete category", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) parseID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid category ID", zap.Error(err))
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrCategoryNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrNameTaken, ErrSlugTaken, ErrHasChildren, ErrCycle:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,313 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+)
+
+// Catalog lists the products the in-memory repository counts
+type Catalog interface {
+	ListProducts(ctx context.Context, filter product.ProductFilter, pagination product.PaginationParams) ([]*product.Product, int, error)
+}
+
+// memoryRepository is an in-memory implementation of the Repository
+// interface, for tests and the demo mode
+type memoryRepository struct {
+	catalog Catalog
+
+	mu         sync.Mutex
+	categories map[int64]*Category
+	redirects  map[redirectKey]int64
+	lastID     int64
+}
+
+// redirectKey is a former slug in a store
+type redirectKey struct {
+	storeID int64
+	slug    string
+}
+
+// NewMemoryRepository creates an empty in-memory repository. Product counts
+// are taken from the published products catalog lists; a nil catalog
+// counts none.
+func NewMemoryRepository(catalog Catalog) Repository {
+	return &memoryRepository{
+		catalog:    catalog,
+		categories: make(map[int64]*Category),
+		redirects:  make(map[redirectKey]int64),
+	}
+}
+
+// Create adds a new category, deriving a unique slug from its name when it
+// has none
+func (r *memoryRepository) Create(ctx context.Context, category *Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storeID := tenant.StoreIDOrDefault(ctx)
+	if category.Slug == "" {
+		base := slug.Make(category.Name)
+		if base == "" {
+			base = entityType
+		}
+		category.Slug = slug.Pick(base, func(s string) bool { return r.slugTaken(storeID, s) })
+	}
+
+	stored := *category
+	stored.StoreID = storeID
+	if err := r.checkUnique(&stored); err != nil {
+		return err
+	}
+	if stored.ParentID != nil {
+		if parent, ok := r.categories[*stored.ParentID]; !ok || parent.StoreID != storeID {
+			return fmt.Errorf("error creating category: parent %d does not exist", *stored.ParentID)
+		}
+	}
+
+	r.lastID++
+	now := time.Now()
+	stored.ID = r.lastID
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.categories[stored.ID] = &stored
+	*category = stored
+	return nil
+}
+
+// slugTaken reports whether a category of storeID uses s, now or before a
+// rename. r.mu must be held.
+func (r *memoryRepository) slugTaken(storeID int64, s string) bool {
+	if _, ok := r.redirects[redirectKey{storeID, s}]; ok {
+		return true
+	}
+	for _, c := range r.categories {
+		if c.StoreID == storeID && c.Slug == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnique returns ErrSlugTaken or ErrNameTaken when another category of
+// the store has the slug or name of category. r.mu must be held.
+func (r *memoryRepository) checkUnique(category *Category) error {
+	for _, c := range r.categories {
+		if c.ID == category.ID || c.StoreID != category.StoreID {
+			continue
+		}
+		if c.Slug == category.Slug {
+			return ErrSlugTaken
+		}
+		if strings.EqualFold(c.Name, category.Name) {
+			return ErrNameTaken
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves a single category by its ID
+func (r *memoryRepository) GetByID(ctx context.Context, id int64) (*Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *category
+	return &copied, nil
+}
+
+// ResolveSlug looks former slugs up in the redirects kept by Update
+func (r *memoryRepository) ResolveSlug(ctx context.Context, s string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.redirects[redirectKey{tenant.StoreIDOrDefault(ctx), s}]
+	if !ok {
+		return 0, fmt.Errorf("slug redirect not found: %w", sql.ErrNoRows)
+	}
+	return id, nil
+}
+
+// Update modifies an existing category and records a redirect from its old
+// slug when the slug changes
+func (r *memoryRepository) Update(ctx context.Context, id int64, input UpdateCategoryInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	category := *before
+	if input.Name != nil {
+		category.Name = *input.Name
+	}
+	if input.Slug != nil {
+		category.Slug = *input.Slug
+	}
+	if input.ParentID != nil {
+		category.ParentID = nil
+		if *input.ParentID != 0 {
+			parentID := *input.ParentID
+			category.ParentID = &parentID
+		}
+	}
+	if input.Position != nil {
+		category.Position = *input.Position
+	}
+	if err := r.checkUnique(&category); err != nil {
+		return err
+	}
+
+	if category.Slug != before.Slug {
+		r.redirects[redirectKey{category.StoreID, before.Slug}] = id
+	}
+	category.UpdatedAt = time.Now()
+	r.categories[id] = &category
+	return nil
+}
+
+// Delete removes a category without subcategories
+func (r *memoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.scoped(ctx, id); err != nil {
+		return err
+	}
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			return ErrHasChildren
+		}
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+// IsDescendant walks up from candidate to find id
+func (r *memoryRepository) IsDescendant(ctx context.Context, id, candidate int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for current, ok := r.categories[candidate]; ok; current, ok = r.parent(current) {
+		if current.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListCounted counts every product once per category even when it is
+// tagged with several categories of the same subtree
+func (r *memoryRepository) ListCounted(ctx context.Context, storeID int64) ([]*CountedCategory, error) {
+	tags, err := r.publishedTags(tenant.WithStore(ctx, storeID))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored []*Category
+	for _, c := range r.categories {
+		if c.StoreID == storeID {
+			stored = append(stored, c)
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		a, b := stored[i], stored[j]
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		if !strings.EqualFold(a.Name, b.Name) {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.ID < b.ID
+	})
+
+	categories := []*CountedCategory{}
+	for _, c := range stored {
+		names := map[string]bool{}
+		for _, node := range stored {
+			for current, ok := node, true; ok; current, ok = r.parent(current) {
+				if current.ID == c.ID {
+					names[strings.ToLower(node.Name)] = true
+					break
+				}
+			}
+		}
+
+		count := 0
+		for _, productTags := range tags {
+			for _, tag := range productTags {
+				if names[strings.ToLower(tag)] {
+					count++
+					break
+				}
+			}
+		}
+		categories = append(categories, &CountedCategory{
+			ID:           c.ID,
+			ParentID:     c.ParentID,
+			Name:         c.Name,
+			Slug:         c.Slug,
+			ProductCount: count,
+		})
+	}
+	return categories, nil
+}
+
+// publishedTags returns the categories of every published product in the
+// store ctx is scoped to
+func (r *memoryRepository) publishedTags(ctx context.Context) ([][]string, error) {
+	if r.catalog == nil {
+		return nil, nil
+	}
+
+	published := product.StatusPublished
+	filter := product.ProductFilter{Status: &published}
+	var tags [][]string
+	for page := 1; ; page++ {
+		products, total, err := r.catalog.ListProducts(ctx, filter, product.PaginationParams{Page: page, Limit: 100})
+		if err != nil {
+			return nil, fmt.Errorf("error listing categorized products: %w", err)
+		}
+		for _, p := range products {
+			tags = append(tags, p.Categories)
+		}
+		if len(products) == 0 || page*100 >= total {
+			return tags, nil
+		}
+	}
+}
+
+// scoped returns the stored category id when the current store may see
+// it. r.mu must be held.
+func (r *memoryRepository) scoped(ctx context.Context, id int64) (*Category, error) {
+	category, ok := r.categories[id]
+	if storeID, scoped := tenant.StoreID(ctx); !ok || (scoped && category.StoreID != storeID) {
+		return nil, fmt.Errorf("category not found: %w", sql.ErrNoRows)
+	}
+	return category, nil
+}
+
+// parent returns the parent of c, or false for top-level categories. r.mu
+// must be held.
+func (r *memoryRepository) parent(c *Category) (*Category, bool) {
+	if c.ParentID == nil {
+		return nil, false
+	}
+	parent, ok := r.categories[*c.ParentID]
+	return parent, ok
+}
@@ -0,0 +1,57 @@
+package category
+
+import (
+	"time"
+)
+
+// Category is a node of a store's category tree. Products are tagged with
+// category names, so a category contains the products tagged with its
+// name.
+type Category struct {
+	ID        int64     `db:"id" json:"id"`
+	StoreID   int64     `db:"store_id" json:"-"`
+	ParentID  *int64    `db:"parent_id" json:"parent_id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	Position  int       `db:"position" json:"position"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CountedCategory is a category with the number of published products in
+// it or any of its descendants
+type CountedCategory struct {
+	ID           int64  `db:"id"`
+	ParentID     *int64 `db:"parent_id"`
+	Name         string `db:"name"`
+	Slug         string `db:"slug"`
+	ProductCount int    `db:"product_count"`
+}
+
+// TreeNode is a category in the category tree
+type TreeNode struct {
+	ID           int64       `json:"id"`
+	Name         string      `json:"name"`
+	Slug         string      `json:"slug"`
+	ProductCount int         `json:"product_count"`
+	Children     []*TreeNode `json:"children"`
+}
+
+// CreateCategoryInput creates a category. The slug is derived from the
+// name when empty.
+type CreateCategoryInput struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	Slug     string `json:"slug" validate:"omitempty,max=100"`
+	ParentID *int64 `json:"parent_id" validate:"omitempty,min=1"`
+	Position int    `json:"position"`
+}
+
+// UpdateCategoryInput changes the fields that are set. A ParentID of 0
+// moves the category to the top level. Renaming keeps the slug; a new
+// slug leaves the old one redirecting to the category.
+type UpdateCategoryInput struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Slug     *string `json:"slug" validate:"omitempty,max=100"`
+	ParentID *int64  `json:"parent_id" validate:"omitempty,min=0"`
+	Position *int    `json:"position"`
+}
@@ -0,0 +1,231 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// entityType names categories in the slug redirect table
+const entityType = "category"
+
+// Repository defines the interface for category data operations
+type Repository interface {
+	Create(ctx context.Context, category *Category) error
+	GetByID(ctx context.Context, id int64) (*Category, error)
+	// ResolveSlug returns the ID of the category a former slug redirects to
+	ResolveSlug(ctx context.Context, slug string) (int64, error)
+	Update(ctx context.Context, id int64, input UpdateCategoryInput) error
+	Delete(ctx context.Context, id int64) error
+	// IsDescendant reports whether candidate is id or lies below it
+	IsDescendant(ctx context.Context, id, candidate int64) (bool, error)
+	// ListCounted retrieves every category of a store with its published
+	// product count, ordered by position and name
+	ListCounted(ctx context.Context, storeID int64) ([]*CountedCategory, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository. Categories
+// are scoped to the store in the request context.
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new category, deriving a unique slug from its name when it
+// has none
+func (r *repository) Create(ctx context.Context, category *Category) error {
+	storeID := tenant.StoreIDOrDefault(ctx)
+	if category.Slug == "" {
+		unique, err := slug.Unique(ctx, r.db, "categories", entityType, storeID, slug.Make(category.Name), entityType)
+		if err != nil {
+			return err
+		}
+		category.Slug = unique
+	}
+
+	query := `
+		INSERT INTO categories (store_id, parent_id, name, slug, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, storeID, category.ParentID, category.Name, category.Slug, category.Position).
+		StructScan(category)
+	if err != nil {
+		if dup := duplicate(err); dup != nil {
+			return dup
+		}
+		return fmt.Errorf("error creating category: %w", err)
+	}
+	return nil
+}
+
+// duplicate maps unique violations of the name and slug indexes to
+// ErrNameTaken and ErrSlugTaken, and returns nil for other errors
+func duplicate(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23505" {
+		return nil
+	}
+	if pqErr.Constraint == "idx_categories_slug" {
+		return ErrSlugTaken
+	}
+	return ErrNameTaken
+}
+
+// GetByID retrieves a single category by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*Category, error) {
+	var category Category
+	query := `SELECT * FROM categories WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.GetContext(ctx, &category, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting category: %w", err)
+	}
+	return &category, nil
+}
+
+// ResolveSlug looks former slugs up in the redirect table
+func (r *repository) ResolveSlug(ctx context.Context, s string) (int64, error) {
+	return slug.Resolve(ctx, r.db, tenant.StoreIDOrDefault(ctx), entityType, s)
+}
+
+// Update modifies an existing category and records a redirect from its old
+// slug when the slug changes
+func (r *repository) Update(ctx context.Context, id int64, input UpdateCategoryInput) error {
+	query := `UPDATE categories SET `
+	args := []interface{}{}
+	argID := 1
+
+	if input.Name != nil {
+		query += fmt.Sprintf("name = $%d, ", argID)
+		args = append(args, *input.Name)
+		argID++
+	}
+	if input.Slug != nil {
+		query += fmt.Sprintf("slug = $%d, ", argID)
+		args = append(args, *input.Slug)
+		argID++
+	}
+	if input.ParentID != nil {
+		query += fmt.Sprintf("parent_id = NULLIF($%d, 0), ", argID)
+		args = append(args, *input.ParentID)
+		argID++
+	}
+	if input.Position != nil {
+		query += fmt.Sprintf("position = $%d, ", argID)
+		args = append(args, *input.Position)
+		argID++
+	}
+
+	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d AND ($%d::integer IS NULL OR store_id = $%d) RETURNING *", argID, argID+1, argID+1)
+	args = append(args, id, tenant.StoreArg(ctx))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Category
+	scoped := `SELECT * FROM categories WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("category not found: %w", err)
+		}
+		return fmt.Errorf("error getting category: %w", err)
+	}
+
+	var category Category
+	if err := tx.GetContext(ctx, &category, query, args...); err != nil {
+		if dup := duplicate(err); dup != nil {
+			return dup
+		}
+		return fmt.Errorf("error updating category: %w", err)
+	}
+	if category.Slug != before.Slug {
+		if err := slug.Redirect(ctx, tx, category.StoreID, entityType, id, before.Slug); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing category update: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a category without subcategories
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM categories WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return ErrHasChildren
+		}
+		return fmt.Errorf("error deleting category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// IsDescendant walks down from id to find candidate
+func (r *repository) IsDescendant(ctx context.Context, id, candidate int64) (bool, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`
+	var found bool
+	if err := r.db.GetContext(ctx, &found, query, id, candidate); err != nil {
+		return false, fmt.Errorf("error checking category ancestry: %w", err)
+	}
+	return found, nil
+}
+
+// ListCounted counts every product once per category even when it is
+// tagged with several categories of the same subtree
+func (r *repository) ListCounted(ctx context.Context, storeID int64) ([]*CountedCategory, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id AS root_id, id, name FROM categories WHERE store_id = $1
+			UNION
+			SELECT s.root_id, c.id, c.name FROM categories c JOIN subtree s ON c.parent_id = s.id
+		),
+		counts AS (
+			SELECT s.root_id, COUNT(DISTINCT p.id) AS product_count
+			FROM subtree s
+			JOIN products p ON p.store_id = $1 AND p.status = 'published'
+				AND EXISTS (SELECT 1 FROM unnest(p.categories) category WHERE lower(category) = lower(s.name))
+			GROUP BY s.root_id
+		)
+		SELECT c.id, c.parent_id, c.name, c.slug, COALESCE(counts.product_count, 0) AS product_count
+		FROM categories c
+		LEFT JOIN counts ON counts.root_id = c.id
+		WHERE c.store_id = $1
+		ORDER BY c.position, lower(c.name), c.id`
+
+	categories := []*CountedCategory{}
+	if err := r.db.SelectContext(ctx, &categories, query, storeID); err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,226 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrNameTaken        = errors.New("category name already in use")
+	ErrSlugTaken        = errors.New("category slug already in use")
+	ErrHasChildren      = errors.New("category has subcategories")
+	ErrCycle            = errors.New("category cannot be moved below itself")
+)
+
+type Service interface {
+	CreateCategory(ctx context.Context, input CreateCategoryInput) (*Category, error)
+	GetCategory(ctx context.Context, id int64) (*Category, error)
+	UpdateCategory(ctx context.Context, id int64, input UpdateCategoryInput) (*Category, error)
+	// DeleteCategory removes a category. Subcategories must be moved or
+	// deleted first.
+	DeleteCategory(ctx context.Context, id int64) error
+	// GetTree returns the store's category tree with published product
+	// counts. Trees are cached for the configured TTL; category changes
+	// are visible immediately, product changes after the TTL.
+	GetTree(ctx context.Context) ([]*TreeNode, error)
+	// GetSubtree returns the cached tree below the category with the given
+	// slug, or with a slug it had before. Callers compare the slugs to
+	// redirect to the current one.
+	GetSubtree(ctx context.Context, slug string) (*TreeNode, error)
+}
+
+type cachedTree struct {
+	nodes   []*TreeNode
+	expires time.Time
+}
+
+type service struct {
+	repo      Repository
+	treeTTL   time.Duration
+	validator *validator.Validate
+
+	mu    sync.Mutex
+	trees map[int64]cachedTree
+}
+
+// NewService creates the category service. treeTTL is how long category
+// trees are cached.
+func NewService(repo Repository, treeTTL time.Duration) Service {
+	return &service{
+		repo:      repo,
+		treeTTL:   treeTTL,
+		validator: validator.New(),
+		trees:     make(map[int64]cachedTree),
+	}
+}
+
+func (s *service) CreateCategory(ctx context.Context, input CreateCategoryInput) (*Category, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Slug != "" && !slug.Valid(input.Slug) {
+		return nil, ErrInvalidInput
+	}
+	if input.ParentID != nil {
+		if _, err := s.GetCategory(ctx, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	category := &Category{
+		ParentID: input.ParentID,
+		Name:     input.Name,
+		Slug:     input.Slug,
+		Position: input.Position,
+	}
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return category, nil
+}
+
+func (s *service) GetCategory(ctx context.Context, id int64) (*Category, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *service) UpdateCategory(ctx context.Context, id int64, input UpdateCategoryInput) (*Category, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Slug != nil && !slug.Valid(*input.Slug) {
+		return nil, ErrInvalidInput
+	}
+	if input.ParentID != nil && *input.ParentID != 0 {
+		if _, err := s.GetCategory(ctx, *input.ParentID); err != nil {
+			return nil, err
+		}
+		below, err := s.repo.IsDescendant(ctx, id, *input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if below {
+			return nil, ErrCycle
+		}
+	}
+
+	if err := s.repo.Update(ctx, id, input); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return s.GetCategory(ctx, id)
+}
+
+func (s *service) DeleteCategory(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+func (s *service) GetTree(ctx context.Context) ([]*TreeNode, error) {
+	storeID := tenant.StoreIDOrDefault(ctx)
+
+	s.mu.Lock()
+	cached, ok := s.trees[storeID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.nodes, nil
+	}
+
+	categories, err := s.repo.ListCounted(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	nodes := buildTree(categories)
+
+	s.mu.Lock()
+	s.trees[storeID] = cachedTree{nodes: nodes, expires: time.Now().Add(s.treeTTL)}
+	s.mu.Unlock()
+	return nodes, nil
+}
+
+func (s *service) GetSubtree(ctx context.Context, slug string) (*TreeNode, error) {
+	tree, err := s.GetTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if node := findNode(tree, func(n *TreeNode) bool { return n.Slug == slug }); node != nil {
+		return node, nil
+	}
+
+	id, err := s.repo.ResolveSlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	if node := findNode(tree, func(n *TreeNode) bool { return n.ID == id }); node != nil {
+		return node, nil
+	}
+	return nil, ErrCategoryNotFound
+}
+
+func (s *service) invalidate(ctx context.Context) {
+	s.mu.Lock()
+	delete(s.trees, tenant.StoreIDOrDefault(ctx))
+	s.mu.Unlock()
+}
+
+// buildTree nests categories under their parents, keeping their order
+func buildTree(categories []*CountedCategory) []*TreeNode {
+	nodes := make(map[int64]*TreeNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &TreeNode{ID: c.ID, Name: c.Name, Slug: c.Slug, ProductCount: c.ProductCount, Children: []*TreeNode{}}
+	}
+
+	roots := []*TreeNode{}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, nodes[c.ID])
+				continue
+			}
+		}
+		roots = append(roots, nodes[c.ID])
+	}
+	return roots
+}
+
+// findNode returns the first node of the tree, depth first, that match
+// reports true for
+func findNode(nodes []*TreeNode, match func(*TreeNode) bool) *TreeNode {
+	for _, node := range nodes {
+		if match(node) {
+			return node
+		}
+		if found := findNode(node.Children, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/admin/exports/accounting", h.RequestAccounting)
+	router.GET("/admin/exports/accounting/:id", h.GetExport)
+	router.GET("/admin/exports/accounting/:id/download", h.Download)
+}
+
+// RequestAccounting starts an accounting export and answers 202 Accepted
+// with the export to poll until its download link is set.
+func (h *Handler) RequestAccounting(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	input := AccountingInput{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	if _, err := time.Parse(time.DateOnly, input.From); err != nil {
+		http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse(time.DateOnly, input.To); err != nil {
+		http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.service.RequestAccounting(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to request accounting export", zap.Error(err))
+		if err == ErrInvalidInput {
+			http.Error(w, "Invalid date range, at most 366 days with from before to", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/admin/exports/accounting/%d", export.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(export)
+}
+
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export ID", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.service.GetExport(r.Context(), id)
+	if err != nil {
+		if err == ErrExportNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to get export", zap.Int64("id", id), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(export)
+}
+
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export ID", http.StatusBadRequest)
+		return
+	}
+
+	file, err := h.service.Download(r.Context(), id)
+	if err != nil {
+		switch err {
+		case ErrExportNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrExportNotReady:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error("Failed to download export", zap.Int64("id", id), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="accounting-%d.csv"`, id))
+	w.Write(file.Data)
+}
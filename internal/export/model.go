@@ -0,0 +1,50 @@
+package export
+
+import "time"
+
+// Status is where an export is in its generation
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// KindAccounting is the CSV of money movements for accounting software
+const KindAccounting = "accounting"
+
+// dateLayout is the layout of the from and to dates of an export
+const dateLayout = "2006-01-02"
+
+// Export is a file generated in the background. DownloadURL is set once it
+// has completed.
+type Export struct {
+	ID          int64      `db:"id" json:"id"`
+	Kind        string     `db:"kind" json:"kind"`
+	Status      Status     `db:"status" json:"status"`
+	From        time.Time  `db:"from_date" json:"from"`
+	To          time.Time  `db:"to_date" json:"to"`
+	Rows        int        `db:"row_count" json:"rows"`
+	Error       *string    `db:"error" json:"error,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	DownloadURL string     `db:"-" json:"download_url,omitempty"`
+}
+
+// AccountingInput is the inclusive date range of an accounting export
+type AccountingInput struct {
+	From string `validate:"required,datetime=2006-01-02"`
+	To   string `validate:"required,datetime=2006-01-02"`
+}
+
+// Entry is a line of the accounting export. Refunds are negative; gift
+// card lines carry the change in the card's balance, which the shop owes,
+// so cards sold are positive and redemptions and expiries negative.
+type Entry struct {
+	Date        time.Time `db:"date"`
+	Amount      float64   `db:"amount"`
+	Description string    `db:"description"`
+	Reference   string    `db:"reference"`
+	Type        string    `db:"type"`
+}
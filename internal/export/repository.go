@@ -0,0 +1,103 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for export data operations
+type Repository interface {
+	Create(ctx context.Context, export *Export) error
+	GetByID(ctx context.Context, id int64) (*Export, error)
+	Complete(ctx context.Context, id int64, rows int) error
+	Fail(ctx context.Context, id int64, reason string) error
+	// AccountingEntries returns the refunds and gift card movements dated
+	// from from up to, but not including, to, oldest first
+	AccountingEntries(ctx context.Context, from, to time.Time) ([]*Entry, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, export *Export) error {
+	query := `
+		INSERT INTO exports (kind, from_date, to_date)
+		VALUES ($1, $2, $3)
+		RETURNING *`
+	if err := r.db.QueryRowxContext(ctx, query, export.Kind, export.From, export.To).StructScan(export); err != nil {
+		return fmt.Errorf("error creating export: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*Export, error) {
+	var export Export
+	if err := r.db.GetContext(ctx, &export, `SELECT * FROM exports WHERE id = $1`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("export not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting export: %w", err)
+	}
+	return &export, nil
+}
+
+func (r *repository) Complete(ctx context.Context, id int64, rows int) error {
+	query := `
+		UPDATE exports SET status = $1, row_count = $2, error = NULL, completed_at = NOW()
+		WHERE id = $3`
+	if _, err := r.db.ExecContext(ctx, query, StatusCompleted, rows, id); err != nil {
+		return fmt.Errorf("error completing export: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Fail(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE exports SET status = $1, error = $2 WHERE id = $3`
+	if _, err := r.db.ExecContext(ctx, query, StatusFailed, reason, id); err != nil {
+		return fmt.Errorf("error failing export: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) AccountingEntries(ctx context.Context, from, to time.Time) ([]*Entry, error) {
+	// Returns are refunded when they enter the refunded status; payments
+	// and orders live outside this module
+	entries := []*Entry{}
+	query := `
+		SELECT h.created_at AS date,
+			-COALESCE(r.refund_amount, 0) AS amount,
+			'Refund for return ' || r.id AS description,
+			'Order ' || r.order_id AS reference,
+			'refund' AS type
+		FROM return_requests r
+		JOIN return_status_history h ON h.return_id = r.id AND h.to_status = 'refunded'
+		WHERE h.created_at >= $1 AND h.created_at < $2
+
+		UNION ALL
+
+		SELECT l.created_at AS date,
+			l.amount AS amount,
+			'Gift card ' || l.kind || ', card ending ' || g.last_four AS description,
+			l.reference AS reference,
+			'gift_card_' || l.kind AS type
+		FROM gift_card_ledger l
+		JOIN gift_cards g ON g.id = l.gift_card_id
+		WHERE l.created_at >= $1 AND l.created_at < $2
+
+		ORDER BY date`
+	if err := r.db.SelectContext(ctx, &entries, query, from, to); err != nil {
+		return nil, fmt.Errorf("error listing accounting entries: %w", err)
+	}
+	return entries, nil
+}
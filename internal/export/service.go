@@ -0,0 +1,186 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/jobs"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportNotReady = errors.New("export has not completed yet")
+	ErrInvalidInput   = errors.New("invalid input")
+)
+
+// JobGenerate is the job type that generates a requested export
+const JobGenerate = "export_generate"
+
+// MaxRange is the longest date range one export may cover
+const MaxRange = 366 * 24 * time.Hour
+
+// accountingHeader names the columns of the accounting CSV. Xero statement
+// imports and QuickBooks bank uploads both map Date, Amount and Description
+// on upload; Reference and Type help match lines to their source.
+var accountingHeader = []string{"Date", "Amount", "Description", "Reference", "Type"}
+
+type Service interface {
+	// RequestAccounting queues an accounting export of the inclusive date
+	// range of input.
+	RequestAccounting(ctx context.Context, input AccountingInput) (*Export, error)
+	GetExport(ctx context.Context, id int64) (*Export, error)
+	// Download returns the file of a completed export.
+	Download(ctx context.Context, id int64) (*blobstore.Object, error)
+	// Generate is the JobGenerate job handler.
+	Generate(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	blobs     blobstore.Store
+	queue     jobs.Enqueuer
+	publicURL string
+	validator *validator.Validate
+}
+
+// NewService creates the export service. Generated files are kept in
+// blobs and linked from publicURL, the address clients reach the API at.
+func NewService(repo Repository, blobs blobstore.Store, queue jobs.Enqueuer, publicURL string) Service {
+	return &service{
+		repo:      repo,
+		blobs:     blobs,
+		queue:     queue,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		validator: validator.New(),
+	}
+}
+
+func (s *service) RequestAccounting(ctx context.Context, input AccountingInput) (*Export, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	from, _ := time.Parse(dateLayout, input.From)
+	to, _ := time.Parse(dateLayout, input.To)
+	if to.Before(from) || to.Sub(from) >= MaxRange {
+		return nil, ErrInvalidInput
+	}
+
+	export := &Export{Kind: KindAccounting, From: from, To: to}
+	if err := s.repo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	payload := struct {
+		ExportID int64 `json:"export_id"`
+	}{ExportID: export.ID}
+	if err := s.queue.Enqueue(ctx, JobGenerate, payload); err != nil {
+		if failErr := s.repo.Fail(ctx, export.ID, "could not be queued"); failErr != nil {
+			return nil, errors.Join(err, failErr)
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+func (s *service) GetExport(ctx context.Context, id int64) (*Export, error) {
+	export, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	if export.Status == StatusCompleted {
+		export.DownloadURL = fmt.Sprintf("%s/admin/exports/%s/%d/download", s.publicURL, export.Kind, export.ID)
+	}
+	return export, nil
+}
+
+func (s *service) Download(ctx context.Context, id int64) (*blobstore.Object, error) {
+	export, err := s.GetExport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if export.Status != StatusCompleted {
+		return nil, ErrExportNotReady
+	}
+
+	object, err := s.blobs.Get(ctx, key(export))
+	if err != nil {
+		return nil, fmt.Errorf("error loading export: %w", err)
+	}
+	return object, nil
+}
+
+func (s *service) Generate(ctx context.Context, payload json.RawMessage) error {
+	var job struct {
+		ExportID int64 `json:"export_id"`
+	}
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("error decoding export job: %w", err)
+	}
+
+	export, err := s.repo.GetByID(ctx, job.ExportID)
+	if err != nil {
+		return err
+	}
+	if export.Status == StatusCompleted {
+		return nil
+	}
+
+	data, rows, err := s.render(ctx, export)
+	if err == nil {
+		err = s.blobs.Put(ctx, key(export), "text/csv; charset=utf-8", data)
+	}
+	if err != nil {
+		// The job is retried; the export reports the last failure meanwhile
+		if failErr := s.repo.Fail(ctx, export.ID, err.Error()); failErr != nil {
+			return errors.Join(err, failErr)
+		}
+		return err
+	}
+	return s.repo.Complete(ctx, export.ID, rows)
+}
+
+func (s *service) render(ctx context.Context, export *Export) ([]byte, int, error) {
+	if export.Kind != KindAccounting {
+		return nil, 0, fmt.Errorf("unknown export kind %q", export.Kind)
+	}
+
+	entries, err := s.repo.AccountingEntries(ctx, export.From, export.To.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(accountingHeader)
+	for _, e := range entries {
+		w.Write([]string{
+			e.Date.UTC().Format(dateLayout),
+			strconv.FormatFloat(e.Amount, 'f', 2, 64),
+			e.Description,
+			e.Reference,
+			e.Type,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, 0, fmt.Errorf("error writing export: %w", err)
+	}
+	return buf.Bytes(), len(entries), nil
+}
+
+func key(export *Export) string {
+	return fmt.Sprintf("exports/%s/%d.csv", export.Kind, export.ID)
+}
@@ -0,0 +1,113 @@
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	// Feeds are fetched by Merchant Center and Commerce Manager, which
+	// cannot send API keys
+	router.GET("/feeds/google-shopping.xml", h.serve(FormatGoogleShopping))
+	router.GET("/feeds/products.csv", h.serve(FormatCSV))
+
+	router.POST("/admin/feed-categories", h.SetMapping)
+	router.GET("/admin/feed-categories", h.ListMappings)
+	router.DELETE("/admin/feed-categories/:id", h.DeleteMapping)
+}
+
+// serve returns a handler writing the last generated feed in format.
+func (h *Handler) serve(format Format) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		feed, err := h.service.GetFeed(r.Context(), format)
+		if err != nil {
+			h.logger.Error("Failed to get feed", zap.String("format", string(format)), zap.Error(err))
+			if err == ErrFeedNotReady {
+				http.Error(w, err.Error(), http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", feed.ContentType)
+		if !feed.ModTime.IsZero() {
+			w.Header().Set("Last-Modified", feed.ModTime.UTC().Format(http.TimeFormat))
+		}
+		w.Write(feed.Data)
+	}
+}
+
+func (h *Handler) SetMapping(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input SetMappingInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode feed category mapping input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := h.service.SetMapping(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to set feed category mapping", zap.Error(err))
+		if err == ErrInvalidInput {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapping)
+}
+
+func (h *Handler) ListMappings(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	mappings, err := h.service.ListMappings(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list feed category mappings", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mappings)
+}
+
+func (h *Handler) DeleteMapping(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid feed category mapping ID", zap.Error(err))
+		http.Error(w, "Invalid feed category mapping ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteMapping(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to delete feed category mapping", zap.Error(err))
+		if err == ErrMappingNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
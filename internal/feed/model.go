@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Format is a generated catalog feed, named by the file it is served as
+type Format string
+
+const (
+	// FormatGoogleShopping is the Google Merchant Center RSS feed
+	FormatGoogleShopping Format = "google-shopping.xml"
+	// FormatCSV is the CSV feed in the Facebook catalog format
+	FormatCSV Format = "products.csv"
+)
+
+// CategoryMapping maps a catalog category to a Google product taxonomy
+// category, given as its ID ("187") or full path ("Apparel & Accessories >
+// Shoes").
+type CategoryMapping struct {
+	ID             int64     `db:"id" json:"id"`
+	StoreID        int64     `db:"store_id" json:"store_id"`
+	Category       string    `db:"category" json:"category"`
+	GoogleCategory string    `db:"google_category" json:"google_category"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type SetMappingInput struct {
+	Category       string `json:"category" validate:"required"`
+	GoogleCategory string `json:"google_category" validate:"required,max=255"`
+}
+
+// rss is the Google Shopping feed document
+type rss struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	G       string   `xml:"xmlns:g,attr"`
+	Channel channel  `xml:"channel"`
+}
+
+type channel struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Items       []*item `xml:"item"`
+}
+
+// item is a product in the Google Shopping feed. See
+// https://support.google.com/merchants/answer/7052112 for the attributes.
+type item struct {
+	ID                     string `xml:"g:id"`
+	Title                  string `xml:"g:title"`
+	Description            string `xml:"g:description"`
+	Link                   string `xml:"g:link"`
+	Price                  string `xml:"g:price"`
+	SalePrice              string `xml:"g:sale_price,omitempty"`
+	SalePriceEffectiveDate string `xml:"g:sale_price_effective_date,omitempty"`
+	Availability           string `xml:"g:availability"`
+	AvailabilityDate       string `xml:"g:availability_date,omitempty"`
+	Condition              string `xml:"g:condition"`
+	GoogleProductCategory  string `xml:"g:google_product_category,omitempty"`
+	ProductType            string `xml:"g:product_type,omitempty"`
+	Brand                  string `xml:"g:brand,omitempty"`
+	GTIN                   string `xml:"g:gtin,omitempty"`
+	MPN                    string `xml:"g:mpn,omitempty"`
+	IdentifierExists       string `xml:"g:identifier_exists,omitempty"`
+}
@@ -0,0 +1,207 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/store"
+)
+
+// Google truncates longer titles and descriptions
+const (
+	maxTitle       = 150
+	maxDescription = 5000
+)
+
+// entry is a product as it is listed in the feeds
+type entry struct {
+	product        *product.Product
+	googleCategory string
+	// bundleAvailable is the number of bundles component stock allows
+	bundleAvailable *int
+}
+
+// availability is the stock state of a product in the feeds
+type availability int
+
+const (
+	inStock availability = iota
+	outOfStock
+	backorder
+	preorder
+)
+
+// googleValues and facebookValues name each availability in the Google and
+// Facebook feed formats
+var (
+	googleValues   = map[availability]string{inStock: "in_stock", outOfStock: "out_of_stock", backorder: "backorder", preorder: "preorder"}
+	facebookValues = map[availability]string{inStock: "in stock", outOfStock: "out of stock", backorder: "available for order", preorder: "preorder"}
+)
+
+func (e *entry) availability() availability {
+	p := e.product
+	stock := p.StockQuantity
+	if e.bundleAvailable != nil {
+		stock = *e.bundleAvailable
+	}
+
+	switch {
+	case p.PreorderAvailableAt != nil:
+		return preorder
+	case stock > 0:
+		return inStock
+	case p.OversellPolicy != product.OversellStrict && e.bundleAvailable == nil:
+		return backorder
+	default:
+		return outOfStock
+	}
+}
+
+// sale returns the sale price and its effective date range while a sale is
+// running or upcoming
+func (s *service) sale(p *product.Product) (string, string) {
+	if p.SaleCountdown == nil {
+		return "", ""
+	}
+	return s.price(*p.SalePrice), p.SaleStartsAt.Format(time.RFC3339) + "/" + p.SaleEndsAt.Format(time.RFC3339)
+}
+
+func (s *service) price(amount float64) string {
+	return fmt.Sprintf("%.2f %s", amount, s.currency)
+}
+
+func (s *service) link(p *product.Product) string {
+	return s.storefrontURL + "/products/" + p.Slug
+}
+
+func (s *service) renderGoogleShopping(st *store.Store, entries []*entry) ([]byte, error) {
+	now := time.Now()
+	feed := rss{
+		Version: "2.0",
+		G:       "http://base.google.com/ns/1.0",
+		Channel: channel{
+			Title:       st.Name,
+			Link:        s.storefrontURL,
+			Description: "Product feed of " + st.Name,
+			Items:       make([]*item, 0, len(entries)),
+		},
+	}
+
+	for _, e := range entries {
+		p := e.product
+		p.ApplySale(now)
+		it := &item{
+			ID:                    strconv.FormatInt(p.ID, 10),
+			Title:                 truncate(p.Name, maxTitle),
+			Description:           truncate(p.Description, maxDescription),
+			Link:                  s.link(p),
+			Price:                 s.price(p.Price),
+			Availability:          googleValues[e.availability()],
+			Condition:             "new",
+			GoogleProductCategory: e.googleCategory,
+			Brand:                 brand(p),
+		}
+		it.SalePrice, it.SalePriceEffectiveDate = s.sale(p)
+		if p.PreorderAvailableAt != nil {
+			it.AvailabilityDate = p.PreorderAvailableAt.Format(time.RFC3339)
+		}
+		if len(p.Categories) > 0 {
+			it.ProductType = p.Categories[0]
+		}
+		if p.Barcode != nil {
+			it.GTIN = *p.Barcode
+		}
+		if p.SKU != nil {
+			it.MPN = *p.SKU
+		}
+		// Products without a GTIN, or a brand and MPN, must say so
+		if it.GTIN == "" && (it.Brand == "" || it.MPN == "") {
+			it.IdentifierExists = "no"
+		}
+		feed.Channel.Items = append(feed.Channel.Items, it)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return nil, fmt.Errorf("error encoding google shopping feed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvHeader lists the columns of the CSV feed, in the Facebook catalog format
+var csvHeader = []string{
+	"id", "title", "description", "availability", "condition", "price", "link", "brand",
+	"google_product_category", "product_type", "sale_price", "sale_price_effective_date", "gtin",
+}
+
+func (s *service) renderCSV(entries []*entry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, fmt.Errorf("error encoding csv feed: %w", err)
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		p := e.product
+		p.ApplySale(now)
+		salePrice, saleDates := s.sale(p)
+		productType := ""
+		if len(p.Categories) > 0 {
+			productType = p.Categories[0]
+		}
+		gtin := ""
+		if p.Barcode != nil {
+			gtin = *p.Barcode
+		}
+
+		record := []string{
+			strconv.FormatInt(p.ID, 10),
+			truncate(p.Name, maxTitle),
+			truncate(p.Description, maxDescription),
+			facebookValues[e.availability()],
+			"new",
+			s.price(p.Price),
+			s.link(p),
+			brand(p),
+			e.googleCategory,
+			productType,
+			salePrice,
+			saleDates,
+			gtin,
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("error encoding csv feed: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("error encoding csv feed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// brand is the product's brand attribute, if it has one
+func brand(p *product.Product) string {
+	if b, ok := p.Attributes["brand"].(string); ok {
+		return b
+	}
+	return ""
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for feed category mapping data operations
+type Repository interface {
+	// SetMapping creates or replaces the mapping of a category
+	SetMapping(ctx context.Context, mapping *CategoryMapping) error
+	ListMappings(ctx context.Context) ([]*CategoryMapping, error)
+	DeleteMapping(ctx context.Context, id int64) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// SetMapping upserts a mapping; categories are matched case-insensitively
+func (r *repository) SetMapping(ctx context.Context, mapping *CategoryMapping) error {
+	query := `
+		INSERT INTO feed_category_mappings (store_id, category, google_category)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (store_id, lower(category))
+		DO UPDATE SET category = EXCLUDED.category, google_category = EXCLUDED.google_category, updated_at = NOW()
+		RETURNING *`
+
+	err := r.db.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx), mapping.Category, mapping.GoogleCategory).
+		StructScan(mapping)
+	if err != nil {
+		return fmt.Errorf("error setting feed category mapping: %w", err)
+	}
+	return nil
+}
+
+// ListMappings retrieves the mappings of the current store by category
+func (r *repository) ListMappings(ctx context.Context) ([]*CategoryMapping, error) {
+	query := `
+		SELECT * FROM feed_category_mappings
+		WHERE store_id = $1
+		ORDER BY lower(category)`
+
+	mappings := []*CategoryMapping{}
+	if err := r.db.SelectContext(ctx, &mappings, query, tenant.StoreIDOrDefault(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing feed category mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// DeleteMapping removes a mapping
+func (r *repository) DeleteMapping(ctx context.Context, id int64) error {
+	query := `DELETE FROM feed_category_mappings WHERE id = $1 AND store_id = $2`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreIDOrDefault(ctx))
+	if err != nil {
+		return fmt.Errorf("error deleting feed category mapping: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("feed category mapping not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/store"
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrMappingNotFound = errors.New("feed category mapping not found")
+	ErrFeedNotReady    = errors.New("feed has not been generated yet")
+)
+
+// JobGenerate is the periodic job that regenerates the catalog feeds
+const JobGenerate = "catalog_feeds"
+
+// pageSize is the number of products loaded per page while generating feeds
+const pageSize = 100
+
+// Stores lists the stores feeds are generated for.
+type Stores interface {
+	ListStores(ctx context.Context) ([]*store.Store, error)
+}
+
+// Catalog reads the published products of a store.
+type Catalog interface {
+	ListProducts(ctx context.Context, filter product.ProductFilter, pagination product.PaginationParams) ([]*product.Product, int, error)
+	GetBundle(ctx context.Context, id int64) (*product.Bundle, error)
+}
+
+type Service interface {
+	SetMapping(ctx context.Context, input SetMappingInput) (*CategoryMapping, error)
+	ListMappings(ctx context.Context) ([]*CategoryMapping, error)
+	DeleteMapping(ctx context.Context, id int64) error
+	// GetFeed returns the last generated feed of the current store.
+	GetFeed(ctx context.Context, format Format) (*blobstore.Object, error)
+	// Generate is the JobGenerate job handler. It writes every feed of every
+	// store to the blob store.
+	Generate(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo          Repository
+	stores        Stores
+	catalog       Catalog
+	blobs         blobstore.Store
+	storefrontURL string
+	currency      string
+	validator     *validator.Validate
+}
+
+// NewService creates the feed service. Product links point at
+// storefrontURL and prices are given in currency.
+func NewService(repo Repository, stores Stores, catalog Catalog, blobs blobstore.Store, storefrontURL, currency string) Service {
+	return &service{
+		repo:          repo,
+		stores:        stores,
+		catalog:       catalog,
+		blobs:         blobs,
+		storefrontURL: strings.TrimSuffix(storefrontURL, "/"),
+		currency:      currency,
+		validator:     validator.New(),
+	}
+}
+
+func (s *service) SetMapping(ctx context.Context, input SetMappingInput) (*CategoryMapping, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	mapping := &CategoryMapping{
+		Category:       strings.TrimSpace(input.Category),
+		GoogleCategory: strings.TrimSpace(input.GoogleCategory),
+	}
+	if err := s.repo.SetMapping(ctx, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (s *service) ListMappings(ctx context.Context) ([]*CategoryMapping, error) {
+	return s.repo.ListMappings(ctx)
+}
+
+func (s *service) DeleteMapping(ctx context.Context, id int64) error {
+	err := s.repo.DeleteMapping(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMappingNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) GetFeed(ctx context.Context, format Format) (*blobstore.Object, error) {
+	object, err := s.blobs.Get(ctx, key(tenant.StoreIDOrDefault(ctx), format))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, ErrFeedNotReady
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s *service) Generate(ctx context.Context, _ json.RawMessage) error {
+	stores, err := s.stores.ListStores(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A failing store does not hold back the feeds of the others
+	var errs []error
+	for _, st := range stores {
+		if err := s.generateStore(tenant.WithStore(ctx, st.ID), st); err != nil {
+			errs = append(errs, fmt.Errorf("store %s: %w", st.Slug, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *service) generateStore(ctx context.Context, st *store.Store) error {
+	mappings, err := s.repo.ListMappings(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := s.entries(ctx, mappings)
+	if err != nil {
+		return err
+	}
+
+	xmlFeed, err := s.renderGoogleShopping(st, entries)
+	if err != nil {
+		return err
+	}
+	if err := s.blobs.Put(ctx, key(st.ID, FormatGoogleShopping), "application/xml", xmlFeed); err != nil {
+		return err
+	}
+
+	csvFeed, err := s.renderCSV(entries)
+	if err != nil {
+		return err
+	}
+	return s.blobs.Put(ctx, key(st.ID, FormatCSV), "text/csv", csvFeed)
+}
+
+// entries loads every published product of the current store
+func (s *service) entries(ctx context.Context, mappings []*CategoryMapping) ([]*entry, error) {
+	published := product.StatusPublished
+	filter := product.ProductFilter{Status: &published}
+
+	var entries []*entry
+	for page := 1; ; page++ {
+		products, total, err := s.catalog.ListProducts(ctx, filter, product.PaginationParams{Page: page, Limit: pageSize})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range products {
+			e := &entry{product: p, googleCategory: googleCategory(mappings, p)}
+			if p.IsBundle {
+				bundle, err := s.catalog.GetBundle(ctx, p.ID)
+				if err != nil {
+					return nil, err
+				}
+				e.bundleAvailable = &bundle.Available
+			}
+			entries = append(entries, e)
+		}
+
+		if page*pageSize >= total {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// googleCategory returns the mapped category of the first product category
+// that has a mapping
+func googleCategory(mappings []*CategoryMapping, p *product.Product) string {
+	for _, category := range p.Categories {
+		for _, mapping := range mappings {
+			if strings.EqualFold(mapping.Category, category) {
+				return mapping.GoogleCategory
+			}
+		}
+	}
+	return ""
+}
+
+// key is the blob key of a store's feed
+func key(storeID int64, format Format) string {
+	return fmt.Sprintf("feeds/%d/%s", storeID, format)
+}
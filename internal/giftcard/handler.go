@@ -0,0 +1,173 @@
+package giftcard
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+	keys    *apikey.Authenticator
+}
+
+// NewHandler creates the gift card handler. keys requires a key with the
+// giftcards:redeem scope to redeem, and a signed-in admin or admin key to
+// issue and inspect cards; nil leaves them open.
+func NewHandler(service Service, logger *zap.Logger, keys *apikey.Authenticator) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+		keys:    keys,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	// Codes are sent in the body so they stay out of access logs
+	router.POST("/gift-cards/balance", h.CheckBalance)
+	// Redemption spends money, so it needs a key even when keys are optional
+	router.POST("/gift-cards/redeem", h.keys.RequireKey(apikey.ScopeGiftCardsRedeem, h.Redeem))
+
+	admin := func(next httprouter.Handle) httprouter.Handle {
+		return h.keys.RequireKey(apikey.ScopeAdmin, next)
+	}
+	router.POST("/admin/gift-cards", admin(h.Issue))
+	router.GET("/admin/gift-cards", admin(h.ListCards))
+	router.GET("/admin/gift-cards/:id", admin(h.GetCard))
+	router.GET("/admin/gift-cards/:id/ledger", admin(h.GetLedger))
+}
+
+func (h *Handler) Issue(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input IssueInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode gift card input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	card, err := h.service.Issue(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to issue gift card", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(card)
+}
+
+func (h *Handler) ListCards(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	pagination := PaginationParams{Page: page, Limit: limit}
+
+	cards, totalCount, err := h.service.ListCards(r.Context(), pagination)
+	if err != nil {
+		h.logger.Error("Failed to list gift cards", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	response.List(w, r, cards, totalCount, pagination.Page, pagination.Limit)
+}
+
+func (h *Handler) GetCard(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid gift card ID", http.StatusBadRequest)
+		return
+	}
+
+	card, err := h.service.GetCard(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get gift card", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(card)
+}
+
+func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid gift card ID", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.service.GetLedger(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get gift card ledger", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (h *Handler) CheckBalance(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input BalanceInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode gift card balance input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	balance, err := h.service.CheckBalance(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to check gift card balance", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+func (h *Handler) Redeem(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input RedeemInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode gift card redemption", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.Redeem(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to redeem gift card", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrGiftCardNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrExpired, ErrInsufficientBalance:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+package giftcard
+
+import "time"
+
+// EntryKind is the kind of balance change recorded in the ledger
+type EntryKind string
+
+const (
+	EntryIssue  EntryKind = "issue"
+	EntryRedeem EntryKind = "redeem"
+	EntryExpire EntryKind = "expire"
+)
+
+type GiftCard struct {
+	ID             int64      `db:"id" json:"id"`
+	StoreID        int64      `db:"store_id" json:"store_id"`
+	CodeHash       string     `db:"code_hash" json:"-"`
+	LastFour       string     `db:"last_four" json:"last_four"`
+	InitialBalance float64    `db:"initial_balance" json:"initial_balance"`
+	Balance        float64    `db:"balance" json:"balance"`
+	Note           string     `db:"note" json:"note"`
+	ExpiresAt      *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Expired reports whether the card has expired at now.
+func (c *GiftCard) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// LedgerEntry is one change to a card's balance. Amount is negative for
+// redemptions and expiry.
+type LedgerEntry struct {
+	ID           int64     `db:"id" json:"id"`
+	GiftCardID   int64     `db:"gift_card_id" json:"gift_card_id"`
+	Kind         EntryKind `db:"kind" json:"kind"`
+	Amount       float64   `db:"amount" json:"amount"`
+	BalanceAfter float64   `db:"balance_after" json:"balance_after"`
+	Reference    string    `db:"reference" json:"reference"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+type IssueInput struct {
+	Amount    float64    `json:"amount" validate:"required,gt=0,max=10000"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Note      string     `json:"note" validate:"max=1000"`
+}
+
+// IssuedCard is returned once, when the card is issued.
+type IssuedCard struct {
+	*GiftCard
+	Code string `json:"code"`
+}
+
+type BalanceInput struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Balance is the public view of a card, looked up by code.
+type Balance struct {
+	Balance   float64    `json:"balance"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired"`
+}
+
+// RedeemInput takes amount from a card. Reference identifies the checkout
+// and makes retries safe: redeeming again with the same reference returns
+// the first redemption.
+type RedeemInput struct {
+	Code      string  `json:"code" validate:"required"`
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	Reference string  `json:"reference" validate:"required,max=255"`
+}
+
+type PaginationParams struct {
+	Page  int `json:"page" validate:"required,min=1"`
+	Limit int `json:"limit" validate:"required,min=1,max=100"`
+}
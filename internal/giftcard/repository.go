@@ -0,0 +1,217 @@
+package giftcard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for gift card data operations. Every
+// balance change is written to the ledger in the same transaction.
+type Repository interface {
+	Create(ctx context.Context, card *GiftCard) error
+	GetByID(ctx context.Context, id int64) (*GiftCard, error)
+	GetByCodeHash(ctx context.Context, codeHash string) (*GiftCard, error)
+	List(ctx context.Context, limit, offset int) ([]*GiftCard, int, error)
+	ListLedger(ctx context.Context, cardID int64) ([]*LedgerEntry, error)
+	Redeem(ctx context.Context, cardID int64, amount float64, reference string) (*LedgerEntry, error)
+	ExpireDue(ctx context.Context) (int, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository. Cards are
+// scoped to the store in the request context.
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new gift card and its issue entry
+func (r *repository) Create(ctx context.Context, card *GiftCard) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO gift_cards (store_id, code_hash, last_four, initial_balance, balance, note, expires_at)
+		VALUES ($1, $2, $3, $4, $4, $5, $6)
+		RETURNING *`
+	err = tx.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx), card.CodeHash, card.LastFour,
+		card.InitialBalance, card.Note, card.ExpiresAt).StructScan(card)
+	if err != nil {
+		return fmt.Errorf("error creating gift card: %w", err)
+	}
+
+	if _, err := insertEntry(ctx, tx, card.ID, EntryIssue, card.InitialBalance, card.Balance, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing gift card: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single gift card by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*GiftCard, error) {
+	var card GiftCard
+	query := `SELECT * FROM gift_cards WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.GetContext(ctx, &card, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("gift card not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting gift card: %w", err)
+	}
+	return &card, nil
+}
+
+// GetByCodeHash retrieves a single gift card by the hash of its code
+func (r *repository) GetByCodeHash(ctx context.Context, codeHash string) (*GiftCard, error) {
+	var card GiftCard
+	query := `SELECT * FROM gift_cards WHERE code_hash = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.GetContext(ctx, &card, query, codeHash, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("gift card not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting gift card: %w", err)
+	}
+	return &card, nil
+}
+
+// List retrieves gift cards, newest first
+func (r *repository) List(ctx context.Context, limit, offset int) ([]*GiftCard, int, error) {
+	cards := []*GiftCard{}
+	query := `
+		SELECT * FROM gift_cards
+		WHERE ($3::integer IS NULL OR store_id = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+	if err := r.db.SelectContext(ctx, &cards, query, limit, offset, tenant.StoreArg(ctx)); err != nil {
+		return nil, 0, fmt.Errorf("error listing gift cards: %w", err)
+	}
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM gift_cards WHERE ($1::integer IS NULL OR store_id = $1)`
+	if err := r.db.GetContext(ctx, &totalCount, countQuery, tenant.StoreArg(ctx)); err != nil {
+		return nil, 0, fmt.Errorf("error counting gift cards: %w", err)
+	}
+
+	return cards, totalCount, nil
+}
+
+// ListLedger retrieves the balance changes of a gift card, oldest first
+func (r *repository) ListLedger(ctx context.Context, cardID int64) ([]*LedgerEntry, error) {
+	entries := []*LedgerEntry{}
+	query := `SELECT * FROM gift_card_ledger WHERE gift_card_id = $1 ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &entries, query, cardID); err != nil {
+		return nil, fmt.Errorf("error listing gift card ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// Redeem takes amount from a gift card and records the redemption under
+// reference. The card is locked first, so concurrent redemptions cannot
+// overdraw it, and a reference already redeemed on the card returns its
+// existing entry. It returns ErrExpired or ErrInsufficientBalance when the
+// card cannot cover the amount.
+func (r *repository) Redeem(ctx context.Context, cardID int64, amount float64, reference string) (*LedgerEntry, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var card GiftCard
+	if err := tx.GetContext(ctx, &card, `SELECT * FROM gift_cards WHERE id = $1 FOR UPDATE`, cardID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("gift card not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting gift card: %w", err)
+	}
+
+	var existing LedgerEntry
+	err = tx.GetContext(ctx, &existing,
+		`SELECT * FROM gift_card_ledger WHERE gift_card_id = $1 AND reference = $2 AND kind = $3`,
+		cardID, reference, EntryRedeem)
+	if err == nil {
+		return &existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error checking gift card redemption: %w", err)
+	}
+
+	var balance float64
+	query := `
+		UPDATE gift_cards SET balance = balance - $1, updated_at = NOW()
+		WHERE id = $2 AND balance >= $1 AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING balance`
+	if err := tx.GetContext(ctx, &balance, query, amount, cardID); err != nil {
+		if err == sql.ErrNoRows {
+			if card.ExpiresAt != nil && card.Balance >= amount {
+				return nil, ErrExpired
+			}
+			return nil, ErrInsufficientBalance
+		}
+		return nil, fmt.Errorf("error redeeming gift card: %w", err)
+	}
+
+	entry, err := insertEntry(ctx, tx, cardID, EntryRedeem, -amount, balance, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing gift card redemption: %w", err)
+	}
+	return entry, nil
+}
+
+// ExpireDue zeroes the balance of expired gift cards and records an expire
+// entry for each. It returns the number of cards expired.
+func (r *repository) ExpireDue(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var due []*GiftCard
+	query := `SELECT * FROM gift_cards WHERE expires_at <= NOW() AND balance > 0 FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &due, query); err != nil {
+		return 0, fmt.Errorf("error listing expired gift cards: %w", err)
+	}
+
+	for _, card := range due {
+		if _, err := tx.ExecContext(ctx, `UPDATE gift_cards SET balance = 0, updated_at = NOW() WHERE id = $1`, card.ID); err != nil {
+			return 0, fmt.Errorf("error expiring gift card %d: %w", card.ID, err)
+		}
+		if _, err := insertEntry(ctx, tx, card.ID, EntryExpire, -card.Balance, 0, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing gift card expiry: %w", err)
+	}
+	return len(due), nil
+}
+
+func insertEntry(ctx context.Context, tx *sqlx.Tx, cardID int64, kind EntryKind, amount, balanceAfter float64, reference string) (*LedgerEntry, error) {
+	var entry LedgerEntry
+	query := `
+		INSERT INTO gift_card_ledger (gift_card_id, kind, amount, balance_after, reference)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING *`
+	if err := tx.QueryRowxContext(ctx, query, cardID, kind, amount, balanceAfter, reference).StructScan(&entry); err != nil {
+		return nil, fmt.Errorf("error recording gift card ledger entry: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,197 @@
+package giftcard
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrGiftCardNotFound    = errors.New("gift card not found")
+	ErrExpired             = errors.New("gift card has expired")
+	ErrInsufficientBalance = errors.New("insufficient gift card balance")
+	ErrInvalidInput        = errors.New("invalid input")
+)
+
+// JobExpire is the periodic job that zeroes the balance of expired cards
+const JobExpire = "gift_card_expiry"
+
+// codeAlphabet leaves out characters that are easily misread: 0, O, 1 and I
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// codeLength is the number of code characters, printed in groups of four
+const codeLength = 16
+
+type Service interface {
+	Issue(ctx context.Context, input IssueInput) (*IssuedCard, error)
+	GetCard(ctx context.Context, id int64) (*GiftCard, error)
+	ListCards(ctx context.Context, pagination PaginationParams) ([]*GiftCard, int, error)
+	GetLedger(ctx context.Context, id int64) ([]*LedgerEntry, error)
+	CheckBalance(ctx context.Context, input BalanceInput) (*Balance, error)
+	Redeem(ctx context.Context, input RedeemInput) (*LedgerEntry, error)
+	// ExpireCards is the JobExpire job handler.
+	ExpireCards(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	validator *validator.Validate
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo:      repo,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) Issue(ctx context.Context, input IssueInput) (*IssuedCard, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidInput
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return nil, err
+	}
+	normalized := normalizeCode(code)
+
+	card := &GiftCard{
+		CodeHash:       hashCode(normalized),
+		LastFour:       normalized[len(normalized)-4:],
+		InitialBalance: input.Amount,
+		Note:           input.Note,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, card); err != nil {
+		return nil, err
+	}
+	return &IssuedCard{GiftCard: card, Code: code}, nil
+}
+
+func (s *service) GetCard(ctx context.Context, id int64) (*GiftCard, error) {
+	card, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGiftCardNotFound
+		}
+		return nil, err
+	}
+	return card, nil
+}
+
+func (s *service) ListCards(ctx context.Context, pagination PaginationParams) ([]*GiftCard, int, error) {
+	if err := s.validator.Struct(pagination); err != nil {
+		return nil, 0, ErrInvalidInput
+	}
+	return s.repo.List(ctx, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+}
+
+func (s *service) GetLedger(ctx context.Context, id int64) ([]*LedgerEntry, error) {
+	if _, err := s.GetCard(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLedger(ctx, id)
+}
+
+func (s *service) CheckBalance(ctx context.Context, input BalanceInput) (*Balance, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	card, err := s.findByCode(ctx, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &Balance{
+		Balance:   card.Balance,
+		ExpiresAt: card.ExpiresAt,
+		Expired:   card.Expired(time.Now()),
+	}, nil
+}
+
+func (s *service) Redeem(ctx context.Context, input RedeemInput) (*LedgerEntry, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	card, err := s.findByCode(ctx, input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.Redeem(ctx, card.ID, input.Amount, input.Reference)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGiftCardNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *service) ExpireCards(ctx context.Context, _ json.RawMessage) error {
+	_, err := s.repo.ExpireDue(ctx)
+	return err
+}
+
+func (s *service) findByCode(ctx context.Context, code string) (*GiftCard, error) {
+	normalized := normalizeCode(code)
+	if len(normalized) != codeLength {
+		return nil, ErrGiftCardNotFound
+	}
+
+	card, err := s.repo.GetByCodeHash(ctx, hashCode(normalized))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGiftCardNotFound
+		}
+		return nil, err
+	}
+	return card, nil
+}
+
+// generateCode returns a random code such as ABCD-EFGH-JKLM-NPQR.
+func generateCode() (string, error) {
+	raw := make([]byte, codeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generating gift card code: %w", err)
+	}
+
+	var code strings.Builder
+	for i, b := range raw {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		// 256 is a multiple of the 32-character alphabet, so this is unbiased
+		code.WriteByte(codeAlphabet[int(b)%len(codeAlphabet)])
+	}
+	return code.String(), nil
+}
+
+// normalizeCode accepts codes typed with any case, spaces or dashes.
+func normalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+func hashCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
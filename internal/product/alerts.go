@@ -0,0 +1,49 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/events"
+	"github.com/dotslashbit/ecommerce-api/pkg/mailer"
+)
+
+// lowStockMailer emails ops when a product.low_stock event is published.
+// Webhook subscribers receive the same event through the outbox relay.
+type lowStockMailer struct {
+	mail mailer.Mailer
+	to   string
+}
+
+// NewLowStockMailer returns an events.Publisher that turns low-stock events
+// into an email to the given address and ignores every other event.
+func NewLowStockMailer(mail mailer.Mailer, to string) events.Publisher {
+	return &lowStockMailer{mail: mail, to: to}
+}
+
+func (m *lowStockMailer) Publish(ctx context.Context, envelope *events.Envelope) error {
+	if envelope.Type != EventLowStock {
+		return nil
+	}
+
+	var alert LowStockAlert
+	if err := json.Unmarshal(envelope.Data, &alert); err != nil {
+		return fmt.Errorf("error decoding low stock event: %w", err)
+	}
+
+	msg, err := mailer.Render(mailer.TemplateLowStock, m.to, mailer.LowStockData{
+		ProductID:     alert.ID,
+		ProductName:   alert.Name,
+		StockQuantity: alert.StockQuantity,
+		Threshold:     alert.LowStockThreshold,
+	})
+	if err != nil {
+		return err
+	}
+	return m.mail.Send(ctx, msg)
+}
+
+func (m *lowStockMailer) Close() error {
+	return nil
+}
@@ -1,46 +1,137 @@
 package product
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/botguard"
+	"github.com/dotslashbit/ecommerce-api/pkg/i18n"
+	"github.com/dotslashbit/ecommerce-api/pkg/idempotency"
+	"github.com/dotslashbit/ecommerce-api/pkg/locale"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
-	service Service
-	logger  *zap.Logger
+	service    Service
+	logger     *zap.Logger
+	idempotent *idempotency.Middleware
+	bots       *botguard.Guard
+	keys       *apikey.Authenticator
 }
 
-func NewHandler(service Service, logger *zap.Logger) *Handler {
+// NewHandler creates the product handler. idempotent may be nil to disable
+// Idempotency-Key support, and bots may be nil to disable bot detection on
+// the public catalog endpoints. keys enforces API key scopes; nil leaves
+// the routes open.
+func NewHandler(service Service, logger *zap.Logger, idempotent *idempotency.Middleware, bots *botguard.Guard, keys *apikey.Authenticator) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:    service,
+		logger:     logger,
+		idempotent: idempotent,
+		bots:       bots,
+		keys:       keys,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router *httprouter.Router) {
-	router.POST("/products", h.CreateProduct)
-	router.GET("/products/:id", h.GetProduct)
-	router.GET("/products", h.ListProducts)
-	router.PUT("/products/:id", h.UpdateProduct)
-	router.DELETE("/products/:id", h.DeleteProduct)
+	read := func(next httprouter.Handle) httprouter.Handle {
+		return h.keys.Require(apikey.ScopeCatalogRead, next)
+	}
+	write := func(next httprouter.Handle) httprouter.Handle {
+		return h.keys.Require(apikey.ScopeCatalogWrite, next)
+	}
+
+	router.POST("/products", write(request.Schema("product.create", h.idempotent.Wrap(h.CreateProduct))))
+	router.GET("/products/:id", read(h.bots.Wrap(h.GetProduct)))
+	router.GET("/products", read(h.bots.Wrap(h.ListProducts)))
+	router.GET("/products/:id/related", read(h.bots.Wrap(h.GetRelatedProducts)))
+	router.PUT("/products/:id", write(request.Schema("product.update", h.UpdateProduct)))
+	router.DELETE("/products/:id", write(h.DeleteProduct))
+	router.POST("/products/:id/status", write(request.Schema("product.status", h.ChangeStatus)))
+	router.POST("/products/:id/duplicate", write(h.DuplicateProduct))
+	router.PUT("/products/:id/sale", write(request.Schema("product.sale", h.SetSale)))
+	router.PUT("/products/:id/components", write(h.SetBundle))
+	router.GET("/products/:id/components", read(h.GetBundle))
+	router.DELETE("/products/:id/sale", write(h.EndSale))
+	router.POST("/products/:id/price-changes", write(request.Schema("product.price_change", h.SchedulePriceChange)))
+	router.GET("/products/:id/price-changes", read(h.ListPriceChanges))
+	router.DELETE("/products/:id/price-changes/:change_id", write(h.CancelPriceChange))
+	router.GET("/products/:id/translations", read(h.ListTranslations))
+	router.PUT("/products/:id/translations/:locale", write(request.Schema("product.translation", h.SetTranslation)))
+	router.DELETE("/products/:id/translations/:locale", write(h.DeleteTranslation))
+	// Price history names the staff behind each change, so it needs write access
+	router.GET("/products/:id/price-history", write(h.GetPriceHistory))
+	router.GET("/sync/products", read(h.SyncProducts))
+	// Links published before slugs moved under /products keep working
+	router.GET("/slugs/:slug", h.RedirectSlug)
+
+	// httprouter cannot register /products/by-sku/:sku next to
+	// /products/:id, so the lookups live on a second router that serves
+	// whatever the main one does not match
+	lookups := httprouter.New()
+	lookups.GET("/products/by-sku/:sku", read(h.bots.Wrap(h.GetProductBySKU)))
+	lookups.GET("/products/by-barcode/:barcode", read(h.bots.Wrap(h.GetProductByBarcode)))
+	lookups.GET("/products/slug/:slug", read(h.bots.Wrap(h.GetProductBySlug)))
+	lookups.NotFound = router.NotFound
+	router.NotFound = lookups
+
+	router.POST("/admin/inventory/:id/decrement", write(request.Schema("product.stock", h.DecrementStock)))
+	router.POST("/admin/inventory/:id/restock", write(request.Schema("product.stock", h.RestockProduct)))
+	router.GET("/admin/products", write(h.ListAllProducts))
+	router.GET("/admin/products/low-stock", read(h.ListLowStock))
+	router.POST("/admin/products/bulk-price", write(h.BulkUpdatePrices))
+	router.POST("/admin/products/import", write(request.SchemaSized("product.import", request.MaxImportSize, h.ImportProducts)))
+
+	// ERPs and warehouse systems push stock levels with their own keys
+	router.POST("/integrations/inventory-sync", h.keys.RequireKey(apikey.ScopeInventoryWrite,
+		request.SchemaSized("product.inventory_sync", request.MaxImportSize, h.idempotent.Wrap(h.SyncInventory))))
 }
+
 func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	var input CreateProductInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+	if err := request.Decode(r, &input); err != nil {
 		h.logger.Error("Failed to decode create product input", zap.Error(err))
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	product, err := h.service.CreateProduct(r.Context(), input)
+	// ?upsert=true updates the product with the same SKU instead of
+	// rejecting the duplicate
+	upsert, _ := strconv.ParseBool(r.URL.Query().Get("upsert"))
+	var product *Product
+	var err error
+	created := true
+	if upsert {
+		product, created, err = h.service.UpsertProduct(r.Context(), input)
+	} else {
+		product, err = h.service.CreateProduct(r.Context(), input)
+	}
 	if err != nil {
 		h.logger.Error("Failed to create product", zap.Error(err))
-		if err == ErrInvalidInput {
+		var violation *PolicyViolationError
+		var duplicate *DuplicateProductError
+		if errors.As(err, &violation) {
+			h.writePolicyViolation(w, r, violation)
+		} else if errors.As(err, &duplicate) {
+			h.writeDuplicate(w, r, duplicate)
+		} else if err == ErrInvalidInput || err == ErrUnknownBrand || err == ErrSKURequired {
 			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if err == ErrAdminOnly || err == ErrForbidden {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		} else if err == ErrDuplicateSKU || err == ErrDuplicateBarcode || err == ErrDuplicateSlug || err == ErrInvalidTransition {
+			http.Error(w, err.Error(), http.StatusConflict)
 		} else {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -48,10 +139,18 @@ func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request, _ httpro
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	if created {
+		w.Header().Set("Location", productPath(product.ID))
+		w.WriteHeader(http.StatusCreated)
+	}
 	json.NewEncoder(w).Encode(product)
 }
 
+// productPath is the API path of a product
+func productPath(id int64) string {
+	return "/products/" + strconv.FormatInt(id, 10)
+}
+
 func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
 	if err != nil {
@@ -61,6 +160,53 @@ func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request, ps httprout
 	}
 
 	product, err := h.service.GetProductByID(r.Context(), id)
+	h.serveProduct(w, r, product, err)
+}
+
+// GetProductBySKU looks a product up by SKU for warehouse and ERP systems.
+func (h *Handler) GetProductBySKU(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	product, err := h.service.GetProductBySKU(r.Context(), ps.ByName("sku"))
+	h.serveProduct(w, r, product, err)
+}
+
+// GetProductByBarcode looks a product up by its EAN or UPC barcode.
+func (h *Handler) GetProductByBarcode(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	product, err := h.service.GetProductByBarcode(r.Context(), ps.ByName("barcode"))
+	h.serveProduct(w, r, product, err)
+}
+
+// GetProductBySlug looks a product up by its storefront slug. Former slugs
+// are redirected permanently to the current one.
+func (h *Handler) GetProductBySlug(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	product, err := h.service.GetProductBySlug(r.Context(), ps.ByName("slug"))
+	if err == nil && product.Slug != ps.ByName("slug") && visible(r.Context(), product) {
+		http.Redirect(w, r, slugPath(product.Slug), http.StatusMovedPermanently)
+		return
+	}
+	h.serveProduct(w, r, product, err)
+}
+
+// RedirectSlug permanently redirects the former /slugs/:slug URLs to
+// /products/slug/:slug.
+func (h *Handler) RedirectSlug(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	target := slugPath(ps.ByName("slug"))
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+func slugPath(slug string) string {
+	return "/products/slug/" + url.PathEscape(slug)
+}
+
+// serveProduct writes a single product looked up with err, hiding products
+// the caller may not see.
+func (h *Handler) serveProduct(w http.ResponseWriter, r *http.Request, product *Product, err error) {
+	fields, include, ok := h.parseSelection(w, r)
+	if !ok {
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to get product", zap.Error(err))
 		if err == ErrProductNotFound {
@@ -70,12 +216,194 @@ func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request, ps httprout
 		}
 		return
 	}
+	if !visible(r.Context(), product) {
+		http.Error(w, ErrProductNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	applySales(w, product)
+	h.localize(w, r, product)
+	w.Header().Set("Content-Language", product.Locale)
+	if !h.include(w, r, include, product) {
+		return
+	}
+	// The reference price is informational; serve the product without it
+	// rather than fail
+	if fields.Has("lowest_price_30d") {
+		if product.LowestPrice30d, err = h.service.LowestPrice30d(r.Context(), product); err != nil {
+			h.logger.Warn("Failed to get lowest 30 day price", zap.Int64("product_id", product.ID), zap.Error(err))
+		}
+	}
 
+	body, err := sparse(fields, product)
+	if err != nil {
+		h.logger.Error("Failed to select product fields", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(body[0])
+}
+
+// parseSelection parses the sparse field set of ?fields= and the relations
+// of ?include=
+func (h *Handler) parseSelection(w http.ResponseWriter, r *http.Request) (Fields, Include, bool) {
+	fields, err := ParseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		h.logger.Error("Invalid field selection", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	include, err := ParseInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		h.logger.Error("Invalid include", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return fields, include, true
+}
+
+// include loads the relations asked for with ?include=. Unlike
+// translations they were asked for explicitly, so failing to load them
+// fails the request.
+func (h *Handler) include(w http.ResponseWriter, r *http.Request, include Include, products ...*Product) bool {
+	if len(include) == 0 {
+		return true
+	}
+	if err := h.service.Include(r.Context(), include, products...); err != nil {
+		h.logger.Error("Failed to include related resources", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// sparse trims the JSON encoding of products to fields and their included
+// relations. Fields that are empty and omitted from the full encoding stay
+// omitted.
+func sparse(fields Fields, products ...*Product) ([]interface{}, error) {
+	trimmed := make([]interface{}, len(products))
+	for i, product := range products {
+		if len(fields) == 0 {
+			trimmed[i] = product
+			continue
+		}
+		data, err := json.Marshal(product)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		if included, ok := all["included"]; ok {
+			selected["included"] = included
+		}
+		trimmed[i] = selected
+	}
+	return trimmed, nil
+}
+
+// saleCacheMaxAge caps how long clients may cache catalog responses that
+// carry sale countdowns
+const saleCacheMaxAge = time.Minute
+
+// applySales sets the current price and sale countdown of the served
+// products. While a sale runs or is upcoming, clients may cache the response
+// until the sale next starts or ends, for at most saleCacheMaxAge, so cached
+// countdowns and prices never outlive the sale.
+func applySales(w http.ResponseWriter, products ...*Product) {
+	now := time.Now()
+	maxAge := saleCacheMaxAge
+	cacheable := false
+	for _, product := range products {
+		product.ApplySale(now)
+		if change, ok := product.nextSaleChange(); ok {
+			cacheable = true
+			if until := change.Sub(now); until < maxAge {
+				maxAge = until
+			}
+		}
+	}
+	if cacheable {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	}
+}
+
+// localize serves products in the locale the client prefers. Untranslated
+// content is still usable, so a failed lookup serves the default locale
+// rather than an error.
+func (h *Handler) localize(w http.ResponseWriter, r *http.Request, products ...*Product) {
+	w.Header().Add("Vary", "Accept-Language")
+	if err := h.service.Localize(r.Context(), locale.Preferred(r), products...); err != nil {
+		h.logger.Warn("Failed to localize products", zap.Error(err))
+	}
+}
+
+// visible reports whether the caller may see product. Unpublished products
+// are only shown to their vendor, signed-in admins and admin clients with
+// write access.
+func visible(ctx context.Context, product *Product) bool {
+	if product.Status == StatusPublished || apikey.IsAdmin(ctx) {
+		return true
+	}
+	key := apikey.FromContext(ctx)
+	if key == nil {
+		return false
+	}
+	if key.VendorID != nil {
+		return product.VendorID != nil && *product.VendorID == *key.VendorID
+	}
+	return key.HasScope(apikey.ScopeCatalogWrite)
+}
+
+func (h *Handler) GetRelatedProducts(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > MaxRelated {
+		limit = 10
+	}
+
+	products, err := h.service.GetRelatedProducts(r.Context(), id, limit)
+	if err != nil {
+		h.logger.Error("Failed to get related products", zap.Error(err))
+		if err == ErrProductNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	applySales(w, products...)
+	h.localize(w, r, products...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
 }
 
+// ListProducts lists the published catalog.
 func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	h.listProducts(w, r, true)
+}
+
+// ListAllProducts lists products in every status for admins, optionally
+// filtered by status.
+func (h *Handler) ListAllProducts(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	h.listProducts(w, r, false)
+}
+
+func (h *Handler) listProducts(w http.ResponseWriter, r *http.Request, public bool) {
 	var filter ProductFilter
 	var pagination PaginationParams
 
@@ -87,6 +415,19 @@ func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request, _ httprou
 	}
 
 	// Parse filter parameters
+	if public {
+		published := StatusPublished
+		filter.Status = &published
+	} else if status := r.Form.Get("status"); status != "" {
+		s := Status(status)
+		filter.Status = &s
+	}
+	if vendorID := r.Form.Get("vendor_id"); vendorID != "" {
+		id, err := strconv.ParseInt(vendorID, 10, 64)
+		if err == nil {
+			filter.VendorID = &id
+		}
+	}
 	if categoryID := r.Form.Get("category_id"); categoryID != "" {
 		filter.CategoryID = &categoryID
 	}
@@ -105,6 +446,25 @@ func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request, _ httprou
 	if search := r.Form.Get("search"); search != "" {
 		filter.Search = &search
 	}
+	if brand := r.Form.Get("brand"); brand != "" {
+		filter.Brand = &brand
+	}
+	filter.OnSale, _ = strconv.ParseBool(r.Form.Get("on_sale"))
+	fields, include, ok := h.parseSelection(w, r)
+	if !ok {
+		return
+	}
+	filter.Fields, filter.Include = fields, include
+	for param, values := range r.Form {
+		key, ok := attributeParam(param)
+		if !ok {
+			continue
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = map[string][]string{}
+		}
+		filter.Attributes[key] = append(filter.Attributes[key], values...)
+	}
 
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(r.Form.Get("page"))
@@ -119,29 +479,47 @@ func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request, _ httprou
 		Page:  page,
 		Limit: limit,
 	}
+	// Infinite-scroll clients page forward without a total and can skip
+	// counting every match with count=false
+	if count, err := strconv.ParseBool(r.Form.Get("count")); err == nil && !count {
+		pagination.SkipCount = true
+	}
 
 	products, totalCount, err := h.service.ListProducts(r.Context(), filter, pagination)
 	if err != nil {
 		h.logger.Error("Failed to list products", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	applySales(w, products...)
+	h.localize(w, r, products...)
+	if !h.include(w, r, include, products...) {
 		return
 	}
 
-	response := struct {
-		Products   []*Product `json:"products"`
-		TotalCount int        `json:"total_count"`
-		Page       int        `json:"page"`
-		Limit      int        `json:"limit"`
-	}{
-		Products:   products,
-		TotalCount: totalCount,
-		Page:       pagination.Page,
-		Limit:      pagination.Limit,
+	body, err := sparse(filter.Fields, products...)
+	if err != nil {
+		h.logger.Error("Failed to select product fields", zap.Error(err))
+		response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if pagination.SkipCount {
+		response.ListUncounted(w, r, body, pagination.Page, pagination.Limit, pagination.More(totalCount))
+		return
 	}
+	response.List(w, r, body, totalCount, pagination.Page, pagination.Limit)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// attributeParam returns the attribute named by an attr[key] query
+// parameter.
+func attributeParam(param string) (string, bool) {
+	if !strings.HasPrefix(param, "attr[") || !strings.HasSuffix(param, "]") {
+		return "", false
+	}
+	key := param[len("attr[") : len(param)-1]
+	return key, key != ""
 }
+
 func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
 	if err != nil {
@@ -151,7 +529,7 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request, ps httpr
 	}
 
 	var input UpdateProductInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+	if err := request.Decode(r, &input); err != nil {
 		h.logger.Error("Failed to decode update product input", zap.Error(err))
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
@@ -160,11 +538,20 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request, ps httpr
 	err = h.service.UpdateProduct(r.Context(), id, input)
 	if err != nil {
 		h.logger.Error("Failed to update product", zap.Error(err))
+		var violation *PolicyViolationError
+		if errors.As(err, &violation) {
+			h.writePolicyViolation(w, r, violation)
+			return
+		}
 		switch err {
 		case ErrProductNotFound:
 			http.Error(w, err.Error(), http.StatusNotFound)
-		case ErrInvalidInput:
+		case ErrInvalidInput, ErrUnknownBrand:
 			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrInvalidTransition, ErrDuplicateSKU, ErrDuplicateBarcode, ErrDuplicateSlug:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case ErrForbidden, ErrAdminOnly:
+			http.Error(w, err.Error(), http.StatusForbidden)
 		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -174,6 +561,130 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request, ps httpr
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) BulkUpdatePrices(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input BulkPriceInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode bulk price input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.BulkUpdatePrices(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to update prices", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrAdminOnly:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ImportProducts creates the products of a JSON array. Rows that cannot be
+// created are listed in the response; the others are created.
+func (h *Handler) ImportProducts(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var inputs []CreateProductInput
+	if err := request.Decode(r, &inputs); err != nil {
+		h.logger.Error("Failed to decode import input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.ImportProducts(r.Context(), inputs)
+	if err != nil {
+		h.logger.Error("Failed to import products", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrAdminOnly:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// SyncInventory sets the stock of products by SKU. Items that cannot be set
+// are listed in the response; the others are set.
+func (h *Handler) SyncInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input InventorySyncInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode inventory sync input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.SyncInventory(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to sync inventory", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrAdminOnly:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) DuplicateProduct(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional; without one only the product details are copied
+	var input DuplicateInput
+	if err := request.Decode(r, &input); err != nil && err != io.EOF {
+		h.logger.Error("Failed to decode duplicate product input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.DuplicateProduct(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to duplicate product", zap.Error(err))
+		var violation *PolicyViolationError
+		if errors.As(err, &violation) {
+			h.writePolicyViolation(w, r, violation)
+			return
+		}
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
 func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
 	if err != nil {
@@ -185,9 +696,14 @@ func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request, ps httpr
 	err = h.service.DeleteProduct(r.Context(), id)
 	if err != nil {
 		h.logger.Error("Failed to delete product", zap.Error(err))
-		if err == ErrProductNotFound {
+		switch err {
+		case ErrProductNotFound:
 			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case ErrInBundle:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
 		return
@@ -195,3 +711,482 @@ func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request, ps httpr
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) ListLowStock(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	pagination := PaginationParams{
+		Page:  page,
+		Limit: limit,
+	}
+
+	products, totalCount, err := h.service.ListLowStock(r.Context(), pagination)
+	if err != nil {
+		h.logger.Error("Failed to list low stock products", zap.Error(err))
+		response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response.List(w, r, products, totalCount, pagination.Page, pagination.Limit)
+}
+
+func (h *Handler) SyncProducts(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 1000 {
+		limit = 500
+	}
+
+	changes, err := h.service.SyncProducts(r.Context(), r.URL.Query().Get("since"), limit)
+	if err != nil {
+		h.logger.Error("Failed to sync products", zap.Error(err))
+		if err == ErrInvalidInput {
+			http.Error(w, "Invalid sync cursor", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func (h *Handler) ChangeStatus(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input StatusChangeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode status change input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.ChangeStatus(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to change product status", zap.Error(err))
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrInvalidTransition:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case ErrForbidden, ErrAdminOnly:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *Handler) SetSale(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input SaleInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode sale input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.SetSale(r.Context(), id, input)
+	h.writeSale(w, product, err)
+}
+
+func (h *Handler) EndSale(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.EndSale(r.Context(), id)
+	h.writeSale(w, product, err)
+}
+
+func (h *Handler) writeSale(w http.ResponseWriter, product *Product, err error) {
+	if err != nil {
+		h.logger.Error("Failed to change product sale", zap.Error(err))
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	product.ApplySale(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *Handler) SetBundle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input BundleInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode bundle input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := h.service.SetBundle(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to set bundle components", zap.Error(err))
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
+func (h *Handler) GetBundle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := h.service.GetBundle(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get bundle", zap.Error(err))
+		if err == ErrProductNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
+func (h *Handler) GetPriceHistory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	history, err := h.service.GetPriceHistory(r.Context(), id, limit)
+	if err != nil {
+		h.logger.Error("Failed to get price history", zap.Error(err))
+		if err == ErrProductNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *Handler) SchedulePriceChange(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input PriceChangeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode price change input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.service.SchedulePriceChange(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to schedule price change", zap.Error(err))
+		var violation *PolicyViolationError
+		if errors.As(err, &violation) {
+			h.writePolicyViolation(w, r, violation)
+			return
+		}
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(change)
+}
+
+func (h *Handler) ListPriceChanges(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.service.ListPriceChanges(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to list price changes", zap.Error(err))
+		if err == ErrProductNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func (h *Handler) CancelPriceChange(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+	changeID, err := strconv.ParseInt(ps.ByName("change_id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid price change ID", zap.Error(err))
+		http.Error(w, "Invalid price change ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.CancelPriceChange(r.Context(), id, changeID)
+	if err != nil {
+		h.logger.Error("Failed to cancel price change", zap.Error(err))
+		switch err {
+		case ErrProductNotFound, ErrPriceChangeNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListTranslations(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	translations, err := h.service.ListTranslations(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to list translations", zap.Error(err))
+		if err == ErrProductNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translations)
+}
+
+func (h *Handler) SetTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input TranslationInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode translation input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	translation, err := h.service.SetTranslation(r.Context(), id, ps.ByName("locale"), input)
+	if err != nil {
+		h.logger.Error("Failed to set translation", zap.Error(err))
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translation)
+}
+
+func (h *Handler) DeleteTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteTranslation(r.Context(), id, ps.ByName("locale"))
+	if err != nil {
+		h.logger.Error("Failed to delete translation", zap.Error(err))
+		switch err {
+		case ErrProductNotFound, ErrTranslationNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DecrementStock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.changeStock(w, r, ps, h.service.DecrementStock)
+}
+
+func (h *Handler) RestockProduct(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.changeStock(w, r, ps, h.service.RestockProduct)
+}
+
+func (h *Handler) changeStock(w http.ResponseWriter, r *http.Request, ps httprouter.Params,
+	change func(ctx context.Context, id int64, input StockChangeInput) (*Product, error)) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var input StockChangeInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode stock change input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	product, err := change(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to change stock", zap.Error(err))
+		switch err {
+		case ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrInsufficientStock:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case ErrForbidden:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// writeDuplicate rejects a create that matches an existing product with a
+// link to it, so clients can fetch or update that product instead.
+func (h *Handler) writeDuplicate(w http.ResponseWriter, r *http.Request, duplicate *DuplicateProductError) {
+	conflict := struct {
+		Error     string `json:"error"`
+		Field     string `json:"field"`
+		ProductID int64  `json:"product_id"`
+		Href      string `json:"href"`
+	}{
+		Error:     i18n.Message(r.Context(), "product already exists"),
+		Field:     duplicate.Field,
+		ProductID: duplicate.Existing.ID,
+		Href:      productPath(duplicate.Existing.ID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", conflict.Href)
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(conflict)
+}
+
+func (h *Handler) writePolicyViolation(w http.ResponseWriter, r *http.Request, violation *PolicyViolationError) {
+	body := struct {
+		Error      string   `json:"error"`
+		Violations []string `json:"violations"`
+	}{
+		Error:      i18n.Message(r.Context(), "catalog policy violation"),
+		Violations: violation.Violations,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,204 @@
+package product_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/factory"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/golden"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// stubService serves fixed products; methods a test does not set panic
+// through the embedded nil interface.
+type stubService struct {
+	product.Service
+	products  map[int64]*product.Product
+	redirects map[string]int64
+	filter    product.ProductFilter
+}
+
+func (s *stubService) GetProductByID(_ context.Context, id int64) (*product.Product, error) {
+	p, ok := s.products[id]
+	if !ok {
+		return nil, product.ErrProductNotFound
+	}
+	return p, nil
+}
+
+func (s *stubService) GetProductBySlug(ctx context.Context, slug string) (*product.Product, error) {
+	for _, p := range s.products {
+		if p.Slug == slug {
+			return p, nil
+		}
+	}
+	return s.GetProductByID(ctx, s.redirects[slug])
+}
+
+func (s *stubService) GetProductBySKU(_ context.Context, sku string) (*product.Product, error) {
+	for _, p := range s.products {
+		if p.SKU != nil && *p.SKU == sku {
+			return p, nil
+		}
+	}
+	return nil, product.ErrProductNotFound
+}
+
+func (s *stubService) LowestPrice30d(_ context.Context, _ *product.Product) (*float64, error) {
+	return nil, nil
+}
+
+func (s *stubService) Localize(_ context.Context, _ []string, products ...*product.Product) error {
+	for _, p := range products {
+		p.Locale = "en"
+	}
+	return nil
+}
+
+func (s *stubService) ListProducts(_ context.Context, filter product.ProductFilter, pagination product.PaginationParams) ([]*product.Product, int, error) {
+	s.filter = filter
+	list := []*product.Product{}
+	offset := int64((pagination.Page - 1) * pagination.Limit)
+	for id := offset + 1; id <= int64(len(s.products)) && len(list) < pagination.Limit; id++ {
+		list = append(list, s.products[id])
+	}
+	return list, len(s.products), nil
+}
+
+func newRouter(t *testing.T, products ...*product.Product) (*httprouter.Router, *stubService) {
+	t.Helper()
+
+	service := &stubService{products: map[int64]*product.Product{}}
+	for _, p := range products {
+		service.products[p.ID] = p
+	}
+
+	router := httprouter.New()
+	handler := product.NewHandler(service, zap.NewNop(), nil, nil, nil)
+	handler.RegisterRoutes(router)
+	return router, service
+}
+
+func serve(router http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestGetProduct(t *testing.T) {
+	factory.Reset()
+	router, _ := newRouter(t,
+		factory.Product(func(p *product.Product) {
+			p.Name = "Trail Running Shoe"
+			p.Categories = []string{"footwear", "outdoor"}
+		}),
+		factory.Product(func(p *product.Product) {
+			p.Status = product.StatusDraft
+		}),
+	)
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"get_product", "/products/1"},
+		{"get_product_draft", "/products/2"},
+		{"get_product_not_found", "/products/3"},
+		{"get_product_invalid_id", "/products/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodGet, tt.target)
+			golden.AssertResponse(t, tt.name, rec, "Content-Type")
+		})
+	}
+}
+
+func TestListProducts(t *testing.T) {
+	factory.Reset()
+	router, service := newRouter(t, factory.Products(3)...)
+
+	rec := serve(router, http.MethodGet, "/products?page=1&limit=2&status=draft")
+	golden.AssertResponse(t, "list_products", rec, "Content-Type", "Link")
+
+	if service.filter.Status == nil || *service.filter.Status != product.StatusPublished {
+		t.Errorf("status filter = %v, want published", service.filter.Status)
+	}
+}
+
+func TestListProductsFields(t *testing.T) {
+	factory.Reset()
+	router, service := newRouter(t, factory.Products(2)...)
+
+	rec := serve(router, http.MethodGet, "/products?fields=id,name,current_price")
+	golden.AssertResponse(t, "list_products_fields", rec)
+
+	if len(service.filter.Fields) != 3 {
+		t.Errorf("fields filter = %v, want id, name and current_price", service.filter.Fields)
+	}
+
+	rec = serve(router, http.MethodGet, "/products?fields=id,colour")
+	golden.AssertResponse(t, "list_products_unknown_field", rec)
+}
+
+func TestListProductsCategoryID(t *testing.T) {
+	factory.Reset()
+	router, service := newRouter(t, factory.Product())
+
+	rec := serve(router, http.MethodGet, "/products?category_id=general")
+	golden.AssertResponse(t, "list_products_category_id", rec)
+
+	if service.filter.CategoryID == nil || *service.filter.CategoryID != "general" {
+		t.Errorf("category_id filter = %v, want general", service.filter.CategoryID)
+	}
+}
+
+func TestGetProductBySKU(t *testing.T) {
+	factory.Reset()
+	sku := "WID-001"
+	router, _ := newRouter(t, factory.Product(func(p *product.Product) {
+		p.SKU = &sku
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"get_product_by_sku", "/products/by-sku/WID-001"},
+		{"get_product_by_sku_not_found", "/products/by-sku/WID-404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodGet, tt.target)
+			golden.AssertResponse(t, tt.name, rec, "Content-Type")
+		})
+	}
+}
+
+func TestGetProductBySlug(t *testing.T) {
+	factory.Reset()
+	router, service := newRouter(t, factory.Product(func(p *product.Product) {
+		p.Slug = "trail-running-shoe"
+	}))
+	service.redirects = map[string]int64{"running-shoe": 1}
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"get_product_by_slug", "/products/slug/trail-running-shoe"},
+		{"get_product_by_old_slug", "/products/slug/running-shoe"},
+		{"get_product_by_slug_not_found", "/products/slug/sandal"},
+		{"get_product_by_legacy_slug_path", "/slugs/trail-running-shoe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodGet, tt.target)
+			golden.AssertResponse(t, tt.name, rec, "Content-Type", "Location")
+		})
+	}
+}
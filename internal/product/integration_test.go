@@ -0,0 +1,138 @@
+//go:build integration
+
+package product_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/pgtest"
+	"github.com/dotslashbit/ecommerce-api/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// newStack wires the handler, service and SQL repository to a fresh
+// database.
+func newStack(t *testing.T) (*httprouter.Router, *sqlx.DB) {
+	t.Helper()
+	db := pgtest.NewDB(t)
+	statements := database.NewStatements(db)
+	t.Cleanup(func() { statements.Close() })
+	service := product.NewService(product.NewRepository(db, statements), nil, 5, "en", 1000)
+	router := httprouter.New()
+	product.NewHandler(service, zap.NewNop(), nil, nil, nil).RegisterRoutes(router)
+	return router, db
+}
+
+func do(t *testing.T, router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func TestIntegrationProductLifecycle(t *testing.T) {
+	t.Parallel()
+	router, db := newStack(t)
+	const create = `{"sku": "SHOE-1", "name": "Trail Running Shoe", "price": 89.99, "categories": ["footwear"], "stock_quantity": 3}`
+
+	rec := do(t, router, http.MethodPost, "/products", create)
+	expectStatus(t, rec, http.StatusCreated)
+	created := decode[product.Product](t, rec)
+	if created.Slug != "trail-running-shoe" || created.Status != product.StatusPublished {
+		t.Fatalf("created slug %q status %s", created.Slug, created.Status)
+	}
+	if got := rec.Header().Get("Location"); got != "/products/1" {
+		t.Errorf("Location = %q", got)
+	}
+
+	var events int
+	if err := db.Get(&events, `SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND event_type = $2`, created.ID, product.EventProductCreated); err != nil || events != 1 {
+		t.Errorf("product.created events = %d (%v), want 1", events, err)
+	}
+
+	t.Run("duplicate create links the existing product", func(t *testing.T) {
+		rec := do(t, router, http.MethodPost, "/products", create)
+		expectStatus(t, rec, http.StatusConflict)
+		body := decode[struct {
+			Field string `json:"field"`
+			Href  string `json:"href"`
+		}](t, rec)
+		if body.Field != "sku" || body.Href != "/products/1" {
+			t.Errorf("duplicate = %+v", body)
+		}
+	})
+
+	t.Run("changed slug redirects", func(t *testing.T) {
+		expectStatus(t, do(t, router, http.MethodPut, "/products/1", `{"slug": "trail-shoe"}`), http.StatusNoContent)
+
+		rec := do(t, router, http.MethodGet, "/products/slug/trail-running-shoe", "")
+		expectStatus(t, rec, http.StatusMovedPermanently)
+		if got := rec.Header().Get("Location"); got != "/products/slug/trail-shoe" {
+			t.Errorf("Location = %q", got)
+		}
+		expectStatus(t, do(t, router, http.MethodGet, "/products/slug/trail-shoe", ""), http.StatusOK)
+	})
+
+	t.Run("upsert updates by sku", func(t *testing.T) {
+		rec := do(t, router, http.MethodPost, "/products?upsert=true", `{"sku": "shoe-1", "name": "Trail Running Shoe", "price": 79.99}`)
+		expectStatus(t, rec, http.StatusOK)
+		updated := decode[product.Product](t, rec)
+		if updated.ID != created.ID || updated.Price != 79.99 || updated.StockQuantity != 3 {
+			t.Errorf("upserted product %d price %v stock %d", updated.ID, updated.Price, updated.StockQuantity)
+		}
+	})
+
+	t.Run("renamed product keeps its slug", func(t *testing.T) {
+		expectStatus(t, do(t, router, http.MethodPut, "/products/1", `{"name": "Trail Runner"}`), http.StatusNoContent)
+		got := decode[product.Product](t, do(t, router, http.MethodGet, "/products/1", ""))
+		if got.Slug != "trail-shoe" {
+			t.Errorf("slug = %q, want trail-shoe", got.Slug)
+		}
+	})
+
+	t.Run("new product cannot take a redirected slug", func(t *testing.T) {
+		rec := do(t, router, http.MethodPost, "/products", `{"name": "Trail Running Shoe", "sku": "SHOE-2", "price": 99}`)
+		expectStatus(t, rec, http.StatusCreated)
+		if got := decode[product.Product](t, rec).Slug; got != "trail-running-shoe-2" {
+			t.Errorf("slug = %q, want trail-running-shoe-2", got)
+		}
+	})
+}
+
+func TestIntegrationStock(t *testing.T) {
+	t.Parallel()
+	router, _ := newStack(t)
+	expectStatus(t, do(t, router, http.MethodPost, "/products", `{"name": "Headlamp", "price": 25, "stock_quantity": 2}`), http.StatusCreated)
+
+	expectStatus(t, do(t, router, http.MethodPost, "/admin/inventory/1/decrement", `{"quantity": 2}`), http.StatusOK)
+	expectStatus(t, do(t, router, http.MethodPost, "/admin/inventory/1/decrement", `{"quantity": 1}`), http.StatusConflict)
+
+	rec := do(t, router, http.MethodPost, "/admin/inventory/1/restock", `{"quantity": 5}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[product.Product](t, rec).StockQuantity; got != 5 {
+		t.Errorf("stock = %d, want 5", got)
+	}
+}
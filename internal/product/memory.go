@@ -0,0 +1,1196 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+)
+
+// BrandLookup returns the ID of the brand of the current store with slug,
+// for the brand filter of the in-memory repository
+type BrandLookup func(ctx context.Context, slug string) (int64, error)
+
+// memoryRepository is an in-memory implementation of the Repository
+// interface. It keeps the rules the database enforces for the SQL
+// repository, such as unique codes and slugs, oversell policies and store
+// scoping, but records no outbox events or audit entries.
+type memoryRepository struct {
+	brands BrandLookup
+
+	mu           sync.Mutex
+	products     map[int64]*Product
+	redirects    map[redirectKey]int64
+	components   map[int64][]BundleComponentInput
+	history      []*PriceHistoryEntry
+	priceChanges []*PriceChange
+	translations map[int64]map[string]*Translation
+	related      map[int64][]int64
+	tombstones   []*Tombstone
+
+	lastID        int64
+	lastHistoryID int64
+	lastChangeID  int64
+	version       int64
+}
+
+// redirectKey is a former slug in a store
+type redirectKey struct {
+	storeID int64
+	slug    string
+}
+
+// NewMemoryRepository creates an empty in-memory repository, for tests and
+// the demo mode. brands resolves the brand filter of List; when it is nil,
+// filtering by brand matches no products.
+func NewMemoryRepository(brands BrandLookup) Repository {
+	return &memoryRepository{
+		brands:       brands,
+		products:     make(map[int64]*Product),
+		redirects:    make(map[redirectKey]int64),
+		components:   make(map[int64][]BundleComponentInput),
+		translations: make(map[int64]map[string]*Translation),
+		related:      make(map[int64][]int64),
+	}
+}
+
+// Create adds a new product to the current store
+func (r *memoryRepository) Create(ctx context.Context, product *Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.insert(ctx, tenant.StoreIDOrDefault(ctx), product)
+}
+
+// CreateBatch adds products to the current store. When one of them is
+// rejected none are kept, as in the SQL repository's transaction.
+func (r *memoryRepository) CreateBatch(ctx context.Context, products []*Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storeID := tenant.StoreIDOrDefault(ctx)
+	history := len(r.history)
+	for i, product := range products {
+		if err := r.insert(ctx, storeID, product); err != nil {
+			for _, created := range products[:i] {
+				delete(r.products, created.ID)
+			}
+			r.history = r.history[:history]
+			return err
+		}
+	}
+	return nil
+}
+
+// Duplicate adds product as a copy of the product sourceID, copying the
+// bundle components of the source when components is set
+func (r *memoryRepository) Duplicate(ctx context.Context, sourceID int64, product *Product, components bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.insert(ctx, product.StoreID, product); err != nil {
+		return err
+	}
+	if components {
+		r.components[product.ID] = append([]BundleComponentInput(nil), r.components[sourceID]...)
+	}
+	return nil
+}
+
+// insert adds product to storeID with its first price. r.mu must be held.
+func (r *memoryRepository) insert(ctx context.Context, storeID int64, product *Product) error {
+	if product.Slug == "" {
+		base := slug.Make(product.Name)
+		if base == "" {
+			base = AggregateType
+		}
+		product.Slug = slug.Pick(base, func(s string) bool { return r.slugTaken(storeID, s) })
+	}
+
+	stored := cloneProduct(product)
+	stored.StoreID = storeID
+	if err := r.checkUnique(stored); err != nil {
+		return err
+	}
+	if stored.Categories == nil {
+		stored.Categories = []string{}
+	}
+	if stored.Attributes == nil {
+		stored.Attributes = Attributes{}
+	}
+	if stored.Status == "" {
+		stored.Status = StatusDraft
+	}
+	if stored.OversellPolicy == "" {
+		stored.OversellPolicy = OversellStrict
+	}
+
+	r.lastID++
+	now := time.Now()
+	stored.ID = r.lastID
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	stored.SyncVersion = r.nextVersion()
+	stored.CreatedVersion = stored.SyncVersion
+	r.products[stored.ID] = stored
+	r.recordPrice(ctx, stored.ID, nil, stored.Price)
+
+	*product = *cloneProduct(stored)
+	return nil
+}
+
+// slugTaken reports whether a product of storeID uses s, now or before a
+// rename. r.mu must be held.
+func (r *memoryRepository) slugTaken(storeID int64, s string) bool {
+	if _, ok := r.redirects[redirectKey{storeID, s}]; ok {
+		return true
+	}
+	for _, p := range r.products {
+		if p.StoreID == storeID && p.Slug == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnique returns the error the SQL repository maps unique index
+// violations to when another product of the store shares a code or slug
+// with product. r.mu must be held.
+func (r *memoryRepository) checkUnique(product *Product) error {
+	for _, p := range r.products {
+		if p.ID == product.ID || p.StoreID != product.StoreID {
+			continue
+		}
+		switch {
+		case product.SKU != nil && p.SKU != nil && strings.EqualFold(*p.SKU, *product.SKU):
+			return ErrDuplicateSKU
+		case product.Barcode != nil && p.Barcode != nil && *p.Barcode == *product.Barcode:
+			return ErrDuplicateBarcode
+		case p.Slug == product.Slug:
+			return ErrDuplicateSlug
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves a single product by its ID
+func (r *memoryRepository) GetByID(ctx context.Context, id int64) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	product, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneProduct(product), nil
+}
+
+// GetBySKU retrieves a single product by its SKU
+func (r *memoryRepository) GetBySKU(ctx context.Context, sku string) (*Product, error) {
+	return r.find(ctx, func(p *Product) bool { return p.SKU != nil && strings.EqualFold(*p.SKU, sku) })
+}
+
+// GetByBarcode retrieves a single product by its barcode
+func (r *memoryRepository) GetByBarcode(ctx context.Context, barcode string) (*Product, error) {
+	return r.find(ctx, func(p *Product) bool { return p.Barcode != nil && *p.Barcode == barcode })
+}
+
+// GetBySlug retrieves a single product by its current slug
+func (r *memoryRepository) GetBySlug(ctx context.Context, s string) (*Product, error) {
+	return r.find(ctx, func(p *Product) bool { return p.Slug == s })
+}
+
+// FindByName matches vendorless products among themselves when vendorID is
+// nil
+func (r *memoryRepository) FindByName(ctx context.Context, name string, vendorID *int64, withSKU bool) (*Product, error) {
+	return r.find(ctx, func(p *Product) bool {
+		return strings.EqualFold(p.Name, name) && sameID(p.VendorID, vendorID) &&
+			p.Status != StatusArchived && (!withSKU || p.SKU == nil)
+	})
+}
+
+// find returns the product of the current store with the lowest ID that
+// matches
+func (r *memoryRepository) find(ctx context.Context, match func(*Product) bool) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	storeID := tenant.StoreIDOrDefault(ctx)
+	var found *Product
+	for _, p := range r.products {
+		if p.StoreID == storeID && match(p) && (found == nil || p.ID < found.ID) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("product not found: %w", sql.ErrNoRows)
+	}
+	return cloneProduct(found), nil
+}
+
+// ResolveSlug looks former slugs up in the redirects kept by Update
+func (r *memoryRepository) ResolveSlug(ctx context.Context, s string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.redirects[redirectKey{tenant.StoreIDOrDefault(ctx), s}]
+	if !ok {
+		return 0, fmt.Errorf("slug redirect not found: %w", sql.ErrNoRows)
+	}
+	return id, nil
+}
+
+// List retrieves a list of products, applying filters and pagination.
+// Search matches products whose name or description contains every word
+// of the query.
+func (r *memoryRepository) List(ctx context.Context, filter ProductFilter, pagination PaginationParams) ([]*Product, int, error) {
+	brandID := int64(-1)
+	if filter.Brand != nil && r.brands != nil {
+		if id, err := r.brands(ctx, *filter.Brand); err == nil {
+			brandID = id
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var matched []*Product
+	for _, p := range r.products {
+		if !inStore(ctx, p) {
+			continue
+		}
+		if filter.Status != nil && p.Status != *filter.Status {
+			continue
+		}
+		if filter.VendorID != nil && !sameID(p.VendorID, filter.VendorID) {
+			continue
+		}
+		if filter.CategoryID != nil && *filter.CategoryID != "" && !hasCategory(p, *filter.CategoryID, strings.Contains) {
+			continue
+		}
+		if filter.Brand != nil && (p.BrandID == nil || *p.BrandID != brandID) {
+			continue
+		}
+		if filter.OnSale && !saleRunning(p, now) {
+			continue
+		}
+		if !matchesAttributes(p, filter.Attributes) {
+			continue
+		}
+		if filter.MinPrice != nil && priceAt(p, now) < *filter.MinPrice {
+			continue
+		}
+		if filter.MaxPrice != nil && priceAt(p, now) > *filter.MaxPrice {
+			continue
+		}
+		if filter.Search != nil && *filter.Search != "" && !matchesSearch(p, *filter.Search) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	total := len(matched)
+	if pagination.SkipCount {
+		total = min(total, pagination.Page*pagination.Limit+1)
+	}
+	return page(matched, pagination), total, nil
+}
+
+// Update modifies an existing product, recording its price history and a
+// redirect from its old slug
+func (r *memoryRepository) Update(ctx context.Context, id int64, input UpdateProductInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	product := cloneProduct(before)
+	if input.SKU != nil {
+		product.SKU = nullIfEmpty(*input.SKU)
+	}
+	if input.Barcode != nil {
+		product.Barcode = nullIfEmpty(*input.Barcode)
+	}
+	if input.Slug != nil {
+		product.Slug = *input.Slug
+	}
+	if input.Name != nil {
+		product.Name = *input.Name
+	}
+	if input.Description != nil {
+		product.Description = *input.Description
+	}
+	if input.Price != nil {
+		product.Price = *input.Price
+	}
+	if input.Categories != nil {
+		product.Categories = append([]string{}, *input.Categories...)
+	}
+	if input.Attributes != nil {
+		product.Attributes = cloneAttributes(*input.Attributes)
+	}
+	if input.BrandID != nil {
+		product.BrandID = nil
+		if *input.BrandID != 0 {
+			brandID := *input.BrandID
+			product.BrandID = &brandID
+		}
+	}
+	if input.OversellPolicy != nil {
+		product.OversellPolicy = *input.OversellPolicy
+	}
+	if input.OversellLimit != nil {
+		product.OversellLimit = *input.OversellLimit
+	}
+	if input.LowStockThreshold != nil {
+		product.LowStockThreshold = *input.LowStockThreshold
+	}
+	if input.PublishAt != nil {
+		publishAt := *input.PublishAt
+		product.PublishAt = &publishAt
+	}
+	if input.PreorderAvailableAt != nil {
+		availableAt := *input.PreorderAvailableAt
+		product.PreorderAvailableAt = &availableAt
+	}
+	if err := r.checkUnique(product); err != nil {
+		return err
+	}
+
+	if product.Price != before.Price {
+		r.recordPrice(ctx, id, &before.Price, product.Price)
+	}
+	if product.Slug != before.Slug {
+		r.redirects[redirectKey{product.StoreID, before.Slug}] = id
+	}
+	r.save(product)
+	return nil
+}
+
+// Delete removes a product that is not a component of a bundle
+func (r *memoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, items := range r.components {
+		for _, item := range items {
+			if item.ProductID == id {
+				return ErrInBundle
+			}
+		}
+	}
+
+	delete(r.products, id)
+	delete(r.components, id)
+	delete(r.translations, id)
+	delete(r.related, id)
+	r.tombstones = append(r.tombstones, &Tombstone{
+		ProductID:   id,
+		StoreID:     product.StoreID,
+		SyncVersion: r.nextVersion(),
+		DeletedAt:   time.Now(),
+	})
+	return nil
+}
+
+// SetStatus moves a product from status from to status to
+func (r *memoryRepository) SetStatus(ctx context.Context, id int64, from, to Status) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != from {
+		return nil, fmt.Errorf("product not found in status %s: %w", from, sql.ErrNoRows)
+	}
+
+	product := cloneProduct(before)
+	product.Status = to
+	r.save(product)
+	return cloneProduct(product), nil
+}
+
+// SetSale starts or replaces a product's sale, or ends it when sale is nil
+func (r *memoryRepository) SetSale(ctx context.Context, id int64, sale *SaleInput) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product := cloneProduct(before)
+	product.SalePrice, product.SaleStartsAt, product.SaleEndsAt = nil, nil, nil
+	if sale != nil {
+		price, startsAt, endsAt := sale.SalePrice, sale.StartsAt, sale.EndsAt
+		product.SalePrice, product.SaleStartsAt, product.SaleEndsAt = &price, &startsAt, &endsAt
+	}
+	r.save(product)
+	return cloneProduct(product), nil
+}
+
+// SetComponents replaces the components of a bundle and marks the product
+// as a bundle, or as a regular product when components is empty
+func (r *memoryRepository) SetComponents(ctx context.Context, id int64, components []BundleComponentInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.scoped(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(components) > 0 {
+		for _, items := range r.components {
+			for _, item := range items {
+				if item.ProductID == id {
+					return ErrInvalidInput
+				}
+			}
+		}
+		for _, component := range components {
+			p, ok := r.products[component.ProductID]
+			if !ok || p.ID == id || p.StoreID != before.StoreID || p.IsBundle {
+				return ErrInvalidInput
+			}
+		}
+	}
+
+	if len(components) > 0 {
+		r.components[id] = append([]BundleComponentInput(nil), components...)
+	} else {
+		delete(r.components, id)
+	}
+
+	product := cloneProduct(before)
+	product.IsBundle = len(components) > 0
+	r.save(product)
+	return nil
+}
+
+// ListComponents retrieves the components of a bundle with their stock
+func (r *memoryRepository) ListComponents(ctx context.Context, id int64) ([]*BundleComponent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	components := []*BundleComponent{}
+	for _, item := range r.components[id] {
+		p, ok := r.products[item.ProductID]
+		if !ok {
+			continue
+		}
+		components = append(components, &BundleComponent{
+			ProductID:     p.ID,
+			Name:          p.Name,
+			Quantity:      item.Quantity,
+			StockQuantity: p.StockQuantity,
+		})
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].ProductID < components[j].ProductID })
+	return components, nil
+}
+
+// recordPrice adds a price history entry attributed to the actor in ctx.
+// r.mu must be held.
+func (r *memoryRepository) recordPrice(ctx context.Context, productID int64, oldPrice *float64, newPrice float64) {
+	r.lastHistoryID++
+	entry := &PriceHistoryEntry{
+		ID:        r.lastHistoryID,
+		ProductID: productID,
+		NewPrice:  newPrice,
+		Actor:     audit.ActorFrom(ctx).Name,
+		ChangedAt: time.Now(),
+	}
+	if oldPrice != nil {
+		price := *oldPrice
+		entry.OldPrice = &price
+	}
+	r.history = append(r.history, entry)
+}
+
+// ListPriceHistory retrieves up to limit price changes of a product, newest
+// first
+func (r *memoryRepository) ListPriceHistory(ctx context.Context, id int64, limit int) ([]*PriceHistoryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := []*PriceHistoryEntry{}
+	for i := len(r.history) - 1; i >= 0 && len(history) < limit; i-- {
+		if entry := r.history[i]; entry.ProductID == id {
+			copied := *entry
+			history = append(history, &copied)
+		}
+	}
+	return history, nil
+}
+
+// LowestPriceBefore returns the lowest price a product had during the 30
+// days before at, counting the price already in effect when that window
+// opened. It returns nil when no price was in effect before at.
+func (r *memoryRepository) LowestPriceBefore(ctx context.Context, id int64, at time.Time) (*float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var opened time.Time
+	for _, entry := range r.history {
+		if entry.ProductID == id && !entry.ChangedAt.After(at.AddDate(0, 0, -30)) && entry.ChangedAt.After(opened) {
+			opened = entry.ChangedAt
+		}
+	}
+
+	var lowest *float64
+	for _, entry := range r.history {
+		if entry.ProductID != id || !entry.ChangedAt.Before(at) || entry.ChangedAt.Before(opened) {
+			continue
+		}
+		if lowest == nil || entry.NewPrice < *lowest {
+			price := entry.NewPrice
+			lowest = &price
+		}
+	}
+	return lowest, nil
+}
+
+// SetTranslation creates or replaces a product's translation in a locale
+func (r *memoryRepository) SetTranslation(ctx context.Context, translation *Translation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[translation.ProductID]; !ok {
+		return fmt.Errorf("error setting translation: product %d does not exist", translation.ProductID)
+	}
+	locales := r.translations[translation.ProductID]
+	if locales == nil {
+		locales = make(map[string]*Translation)
+		r.translations[translation.ProductID] = locales
+	}
+
+	now := time.Now()
+	stored := *translation
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	if existing, ok := locales[translation.Locale]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	locales[translation.Locale] = &stored
+	*translation = stored
+	return nil
+}
+
+// ListTranslations retrieves the translations of a product ordered by locale
+func (r *memoryRepository) ListTranslations(ctx context.Context, productID int64) ([]*Translation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	translations := []*Translation{}
+	for _, translation := range r.translations[productID] {
+		copied := *translation
+		translations = append(translations, &copied)
+	}
+	sort.Slice(translations, func(i, j int) bool { return translations[i].Locale < translations[j].Locale })
+	return translations, nil
+}
+
+// DeleteTranslation removes a product's translation in a locale
+func (r *memoryRepository) DeleteTranslation(ctx context.Context, productID int64, locale string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.translations[productID][locale]; !ok {
+		return fmt.Errorf("translation not found: %w", sql.ErrNoRows)
+	}
+	delete(r.translations[productID], locale)
+	return nil
+}
+
+// TranslationsIn retrieves the translations of the given products in any of
+// the given locales
+func (r *memoryRepository) TranslationsIn(ctx context.Context, productIDs []int64, locales []string) ([]*Translation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	translations := []*Translation{}
+	for _, id := range productIDs {
+		for _, locale := range locales {
+			if translation, ok := r.translations[id][locale]; ok {
+				copied := *translation
+				translations = append(translations, &copied)
+			}
+		}
+	}
+	return translations, nil
+}
+
+// CategoriesNamed returns no categories: the in-memory repository does not
+// hold the category tree, so included categories are always empty
+func (r *memoryRepository) CategoriesNamed(ctx context.Context, names []string) ([]*CategoryRef, error) {
+	return []*CategoryRef{}, nil
+}
+
+// BrandsIn returns no brands, as brands are only looked up by slug
+func (r *memoryRepository) BrandsIn(ctx context.Context, ids []int64) ([]*BrandRef, error) {
+	return []*BrandRef{}, nil
+}
+
+// PublishDue publishes the unpublished products whose publish_at has
+// passed and returns the number of products published
+func (r *memoryRepository) PublishDue(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	published := 0
+	for _, p := range r.sorted() {
+		if p.PublishAt == nil || p.PublishAt.After(now) || (p.Status != StatusDraft && p.Status != StatusPendingReview) {
+			continue
+		}
+		product := cloneProduct(p)
+		product.Status = StatusPublished
+		product.PublishAt = nil
+		r.save(product)
+		published++
+	}
+	return published, nil
+}
+
+// CreatePriceChange schedules a price change for a product
+func (r *memoryRepository) CreatePriceChange(ctx context.Context, change *PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[change.ProductID]; !ok {
+		return fmt.Errorf("error creating price change: product %d does not exist", change.ProductID)
+	}
+	r.lastChangeID++
+	stored := *change
+	stored.ID = r.lastChangeID
+	stored.AppliedAt = nil
+	stored.CreatedAt = time.Now()
+	r.priceChanges = append(r.priceChanges, &stored)
+	*change = stored
+	return nil
+}
+
+// ListPriceChanges retrieves the scheduled and applied price changes of a
+// product, soonest first
+func (r *memoryRepository) ListPriceChanges(ctx context.Context, productID int64) ([]*PriceChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := []*PriceChange{}
+	for _, change := range r.duePriceChanges(func(c *PriceChange) bool { return c.ProductID == productID }) {
+		copied := *change
+		changes = append(changes, &copied)
+	}
+	return changes, nil
+}
+
+// DeletePriceChange cancels a price change that has not been applied yet
+func (r *memoryRepository) DeletePriceChange(ctx context.Context, productID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, change := range r.priceChanges {
+		if change.ID == id && change.ProductID == productID && change.AppliedAt == nil {
+			r.priceChanges = append(r.priceChanges[:i], r.priceChanges[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("price change not found: %w", sql.ErrNoRows)
+}
+
+// ApplyDuePriceChanges applies the price changes whose effective_at has
+// passed, oldest first, and returns the number of changes applied
+func (r *memoryRepository) ApplyDuePriceChanges(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := r.duePriceChanges(func(c *PriceChange) bool { return c.AppliedAt == nil && !c.EffectiveAt.After(now) })
+	for _, change := range due {
+		appliedAt := now
+		change.AppliedAt = &appliedAt
+
+		before, ok := r.products[change.ProductID]
+		if !ok {
+			continue
+		}
+		product := cloneProduct(before)
+		product.Price = change.Price
+		if product.Price != before.Price {
+			r.recordPrice(ctx, product.ID, &before.Price, product.Price)
+		}
+		r.save(product)
+	}
+	return len(due), nil
+}
+
+// duePriceChanges returns the matching price changes ordered by
+// effective_at and ID. r.mu must be held.
+func (r *memoryRepository) duePriceChanges(match func(*PriceChange) bool) []*PriceChange {
+	var changes []*PriceChange
+	for _, change := range r.priceChanges {
+		if match(change) {
+			changes = append(changes, change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].EffectiveAt.Equal(changes[j].EffectiveAt) {
+			return changes[i].EffectiveAt.Before(changes[j].EffectiveAt)
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// BulkUpdatePrices applies a price adjustment to every matching product,
+// or only counts them on a dry run
+func (r *memoryRepository) BulkUpdatePrices(ctx context.Context, input BulkPriceInput) (*BulkPriceResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &BulkPriceResult{DryRun: input.DryRun}
+	for _, p := range r.sorted() {
+		if !inStore(ctx, p) || (input.VendorID != nil && !sameID(p.VendorID, input.VendorID)) {
+			continue
+		}
+		if input.Category != nil && !hasCategory(p, *input.Category, func(category, name string) bool { return category == name }) {
+			continue
+		}
+
+		newPrice := p.Price + input.Value
+		if input.Adjustment == AdjustPercent {
+			newPrice = p.Price * (1 + input.Value/100.0)
+		}
+		newPrice = math.Round(newPrice*100) / 100
+		switch {
+		case newPrice <= 0:
+			result.Skipped++
+			continue
+		case newPrice == p.Price:
+			continue
+		}
+
+		result.Affected++
+		if input.DryRun {
+			continue
+		}
+		product := cloneProduct(p)
+		product.Price = newPrice
+		r.recordPrice(ctx, product.ID, &p.Price, newPrice)
+		r.save(product)
+	}
+	return result, nil
+}
+
+// ListChanges retrieves up to limit products and tombstones whose sync
+// version is greater than sinceVersion, in version order
+func (r *memoryRepository) ListChanges(ctx context.Context, sinceVersion int64, limit int) (*ChangeBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type change struct {
+		version   int64
+		product   *Product
+		tombstone *Tombstone
+	}
+	storeID, scoped := tenant.StoreID(ctx)
+	var changes []change
+	for _, p := range r.products {
+		if p.SyncVersion > sinceVersion && (!scoped || p.StoreID == storeID) {
+			changes = append(changes, change{version: p.SyncVersion, product: p})
+		}
+	}
+	for _, t := range r.tombstones {
+		if t.SyncVersion > sinceVersion && (!scoped || t.StoreID == storeID) {
+			changes = append(changes, change{version: t.SyncVersion, tombstone: t})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].version < changes[j].version })
+
+	batch := &ChangeBatch{
+		Products:    []*Product{},
+		Tombstones:  []*Tombstone{},
+		LastVersion: sinceVersion,
+	}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		batch.HasMore = true
+	}
+	for _, c := range changes {
+		batch.LastVersion = c.version
+		if c.product != nil {
+			batch.Products = append(batch.Products, cloneProduct(c.product))
+		} else {
+			copied := *c.tombstone
+			batch.Tombstones = append(batch.Tombstones, &copied)
+		}
+	}
+	return batch, nil
+}
+
+// DecrementStock removes quantity from a product's stock, enforcing its
+// oversell policy. Selling a bundle decrements its components instead; no
+// component changes unless all of them have enough stock.
+func (r *memoryRepository) DecrementStock(ctx context.Context, id int64, quantity int) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsBundle {
+		if !canDecrement(product, quantity) {
+			return nil, ErrInsufficientStock
+		}
+		return cloneProduct(r.changeStock(product, -quantity)), nil
+	}
+
+	for _, item := range r.components[id] {
+		component, ok := r.products[item.ProductID]
+		if !ok || !canDecrement(component, item.Quantity*quantity) {
+			return nil, ErrInsufficientStock
+		}
+	}
+	for _, item := range r.components[id] {
+		r.changeStock(r.products[item.ProductID], -item.Quantity*quantity)
+	}
+	return cloneProduct(product), nil
+}
+
+// IncrementStock adds quantity to a product's stock. A restock that brings
+// a pre-orderable product's stock above zero ends its pre-order.
+func (r *memoryRepository) IncrementStock(ctx context.Context, id int64, quantity int) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, err := r.scoped(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneProduct(r.changeStock(product, quantity)), nil
+}
+
+// SetStockLevels sets the stock of products of the current store by SKU.
+// Unknown SKUs and bundles are rejected.
+func (r *memoryRepository) SetStockLevels(ctx context.Context, levels []StockLevel) (*StockLevelResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &StockLevelResult{Rejected: make(map[string]error)}
+	storeID := tenant.StoreIDOrDefault(ctx)
+	for _, level := range levels {
+		sku := strings.ToLower(level.SKU)
+		var product *Product
+		for _, p := range r.products {
+			if p.StoreID == storeID && p.SKU != nil && strings.EqualFold(*p.SKU, sku) {
+				product = p
+				break
+			}
+		}
+		switch {
+		case product == nil:
+			result.Rejected[sku] = ErrProductNotFound
+		case product.IsBundle:
+			result.Rejected[sku] = ErrBundleStock
+		case product.StockQuantity == level.Quantity:
+			result.Unchanged++
+		default:
+			r.changeStock(product, level.Quantity-product.StockQuantity)
+			result.Changed++
+		}
+	}
+	return result, nil
+}
+
+// changeStock stores p with delta added to its stock and returns the
+// stored product. r.mu must be held.
+func (r *memoryRepository) changeStock(p *Product, delta int) *Product {
+	product := cloneProduct(p)
+	product.StockQuantity += delta
+	if product.StockQuantity > 0 {
+		product.PreorderAvailableAt = nil
+	}
+	r.save(product)
+	return product
+}
+
+// ListLowStock retrieves products at or below their low-stock threshold,
+// lowest stock first
+func (r *memoryRepository) ListLowStock(ctx context.Context, pagination PaginationParams) ([]*Product, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var low []*Product
+	for _, p := range r.sorted() {
+		if inStore(ctx, p) && p.StockQuantity <= p.LowStockThreshold {
+			low = append(low, p)
+		}
+	}
+	sort.SliceStable(low, func(i, j int) bool { return low[i].StockQuantity < low[j].StockQuantity })
+	return page(low, pagination), len(low), nil
+}
+
+// ListRelated retrieves the published products most related to a product,
+// best first
+func (r *memoryRepository) ListRelated(ctx context.Context, id int64, limit int) ([]*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	products := []*Product{}
+	for _, relatedID := range r.related[id] {
+		p, ok := r.products[relatedID]
+		if !ok || p.Status != StatusPublished || !inStore(ctx, p) {
+			continue
+		}
+		if products = append(products, cloneProduct(p)); len(products) == limit {
+			break
+		}
+	}
+	return products, nil
+}
+
+// RefreshRelated rebuilds the related products, keeping the perProduct
+// best matches of every product by the Jaccard similarity of their
+// category sets
+func (r *memoryRepository) RefreshRelated(ctx context.Context, perProduct int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type match struct {
+		id    int64
+		score float64
+	}
+	products := r.sorted()
+	related := make(map[int64][]int64, len(products))
+	for _, a := range products {
+		var matches []match
+		for _, b := range products {
+			if b.ID == a.ID || b.StoreID != a.StoreID {
+				continue
+			}
+			if score := jaccard(a.Categories, b.Categories); score > 0 {
+				matches = append(matches, match{b.ID, score})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+		for i := 0; i < len(matches) && i < perProduct; i++ {
+			related[a.ID] = append(related[a.ID], matches[i].id)
+		}
+	}
+	r.related = related
+	return nil
+}
+
+// scoped returns the stored product id when the current store may see it.
+// r.mu must be held.
+func (r *memoryRepository) scoped(ctx context.Context, id int64) (*Product, error) {
+	product, ok := r.products[id]
+	if !ok || !inStore(ctx, product) {
+		return nil, fmt.Errorf("product not found: %w", sql.ErrNoRows)
+	}
+	return product, nil
+}
+
+// save stores product as a new version. r.mu must be held.
+func (r *memoryRepository) save(product *Product) {
+	product.UpdatedAt = time.Now()
+	product.SyncVersion = r.nextVersion()
+	r.products[product.ID] = product
+}
+
+// nextVersion plays the part of catalog_sync_seq. r.mu must be held.
+func (r *memoryRepository) nextVersion() int64 {
+	r.version++
+	return r.version
+}
+
+// sorted returns the stored products in ID order. r.mu must be held.
+func (r *memoryRepository) sorted() []*Product {
+	products := make([]*Product, 0, len(r.products))
+	for _, p := range r.products {
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+// inStore reports whether p belongs to the store ctx is scoped to;
+// unscoped contexts see every store
+func inStore(ctx context.Context, p *Product) bool {
+	storeID, ok := tenant.StoreID(ctx)
+	return !ok || p.StoreID == storeID
+}
+
+// canDecrement reports whether p's oversell policy lets quantity units be
+// taken from its stock
+func canDecrement(p *Product, quantity int) bool {
+	switch {
+	case p.OversellPolicy == OversellBackorder, p.PreorderAvailableAt != nil:
+		return true
+	case p.OversellPolicy == OversellUpTo && p.StockQuantity-quantity >= -p.OversellLimit:
+		return true
+	}
+	return p.StockQuantity >= quantity
+}
+
+func saleRunning(p *Product, now time.Time) bool {
+	return p.SalePrice != nil && p.SaleStartsAt != nil && p.SaleEndsAt != nil &&
+		!p.SaleStartsAt.After(now) && p.SaleEndsAt.After(now)
+}
+
+// priceAt is the price p sells at at now
+func priceAt(p *Product, now time.Time) float64 {
+	if saleRunning(p, now) {
+		return *p.SalePrice
+	}
+	return p.Price
+}
+
+// hasCategory reports whether match holds for any category of p and name,
+// both lowercased
+func hasCategory(p *Product, name string, match func(category, name string) bool) bool {
+	name = strings.ToLower(name)
+	for _, category := range p.Categories {
+		if match(strings.ToLower(category), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAttributes compares attribute values as text, like the SQL
+// repository
+func matchesAttributes(p *Product, filter map[string][]string) bool {
+	for key, values := range filter {
+		value, ok := p.Attributes[key]
+		if !ok {
+			return false
+		}
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case float64:
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			text = strconv.FormatBool(v)
+		}
+		found := false
+		for _, want := range values {
+			if want == text {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesSearch(p *Product, search string) bool {
+	name, description := strings.ToLower(p.Name), strings.ToLower(p.Description)
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		if !strings.Contains(name, word) && !strings.Contains(description, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// jaccard is the size of the intersection of two category sets over the
+// size of their union
+func jaccard(a, b []string) float64 {
+	union := make(map[string]bool, len(a)+len(b))
+	inA := make(map[string]bool, len(a))
+	for _, category := range a {
+		inA[category] = true
+		union[category] = true
+	}
+	shared := make(map[string]bool)
+	for _, category := range b {
+		if inA[category] {
+			shared[category] = true
+		}
+		union[category] = true
+	}
+	if len(union) == 0 {
+		return 0
+	}
+	return float64(len(shared)) / float64(len(union))
+}
+
+// page returns the copies of the products on the requested page
+func page(products []*Product, pagination PaginationParams) []*Product {
+	start := (pagination.Page - 1) * pagination.Limit
+	if start > len(products) {
+		start = len(products)
+	}
+	end := start + pagination.Limit
+	if end > len(products) {
+		end = len(products)
+	}
+	result := make([]*Product, 0, end-start)
+	for _, p := range products[start:end] {
+		result = append(result, cloneProduct(p))
+	}
+	return result
+}
+
+// cloneProduct copies p deeply enough that callers cannot change stored
+// products
+func cloneProduct(p *Product) *Product {
+	copied := *p
+	if p.Categories != nil {
+		copied.Categories = append([]string{}, p.Categories...)
+	}
+	copied.Attributes = cloneAttributes(p.Attributes)
+	return &copied
+}
+
+func cloneAttributes(a Attributes) Attributes {
+	if a == nil {
+		return nil
+	}
+	copied := make(Attributes, len(a))
+	for key, value := range a {
+		copied[key] = value
+	}
+	return copied
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -1,6 +1,12 @@
 package product
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -8,36 +14,732 @@ import (
 
 type Product struct {
 	ID          int64          `db:"id" json:"id"`
+	StoreID     int64          `db:"store_id" json:"store_id"`
+	VendorID    *int64         `db:"vendor_id" json:"vendor_id,omitempty"`
+	BrandID     *int64         `db:"brand_id" json:"brand_id,omitempty"`
+	SKU         *string        `db:"sku" json:"sku,omitempty"`
+	Barcode     *string        `db:"barcode" json:"barcode,omitempty"`
+	Slug        string         `db:"slug" json:"slug"`
 	Name        string         `db:"name" json:"name"`
 	Description string         `db:"description" json:"description"`
 	Price       float64        `db:"price" json:"price"`
 	Categories  pq.StringArray `db:"categories" json:"categories"`
-	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	Attributes  Attributes     `db:"attributes" json:"attributes"`
+	Status      Status         `db:"status" json:"status"`
+	IsBundle    bool           `db:"is_bundle" json:"is_bundle"`
+	PublishAt   *time.Time     `db:"publish_at" json:"publish_at,omitempty"`
+
+	SalePrice    *float64   `db:"sale_price" json:"sale_price,omitempty"`
+	SaleStartsAt *time.Time `db:"sale_starts_at" json:"sale_starts_at,omitempty"`
+	SaleEndsAt   *time.Time `db:"sale_ends_at" json:"sale_ends_at,omitempty"`
+
+	// Locale is the locale of Name and Description. It is set when a
+	// product is served in a translation or the default locale.
+	Locale string `db:"-" json:"locale,omitempty"`
+
+	// LowestPrice30d is the lowest price in the 30 days before the current
+	// price or sale took effect, shown with price reductions as the EU
+	// Omnibus Directive requires. It is set when a single product is served.
+	LowestPrice30d *float64 `db:"-" json:"lowest_price_30d,omitempty"`
+
+	// CurrentPrice and SaleCountdown are set by ApplySale when the product
+	// is served
+	CurrentPrice  *float64       `db:"-" json:"current_price,omitempty"`
+	SaleCountdown *SaleCountdown `db:"-" json:"sale_countdown,omitempty"`
+
+	// Included holds the related resources asked for with ?include=
+	Included *Included `db:"-" json:"included,omitempty"`
+
+	StockQuantity  int            `db:"stock_quantity" json:"stock_quantity"`
+	OversellPolicy OversellPolicy `db:"oversell_policy" json:"oversell_policy"`
+	OversellLimit  int            `db:"oversell_limit" json:"oversell_limit"`
+
+	LowStockThreshold int `db:"low_stock_threshold" json:"low_stock_threshold"`
+
+	// PreorderAvailableAt is the expected availability date of a
+	// pre-orderable product. Pre-orders are accepted without stock, and the
+	// flag is cleared once restocking brings stock above zero.
+	PreorderAvailableAt *time.Time `db:"preorder_available_at" json:"preorder_available_at,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Versions from catalog_sync_seq, maintained by database triggers
+	SyncVersion    int64 `db:"sync_version" json:"-"`
+	CreatedVersion int64 `db:"created_version" json:"-"`
+}
+
+// SaleCountdown times a running or upcoming sale relative to when the
+// product was served, so storefronts can render timers without trusting
+// the shopper's clock.
+type SaleCountdown struct {
+	Active bool `json:"active"`
+	// StartsInSeconds is set while the sale is upcoming
+	StartsInSeconds int64 `json:"starts_in_seconds,omitempty"`
+	EndsInSeconds   int64 `json:"ends_in_seconds"`
+}
+
+// ApplySale sets CurrentPrice, the sale price while a sale runs and Price
+// otherwise, and SaleCountdown for a running or upcoming sale, as of now.
+func (p *Product) ApplySale(now time.Time) {
+	price := p.Price
+	p.CurrentPrice = &price
+	p.SaleCountdown = nil
+	if p.SalePrice == nil || p.SaleStartsAt == nil || p.SaleEndsAt == nil || !now.Before(*p.SaleEndsAt) {
+		return
+	}
+
+	p.SaleCountdown = &SaleCountdown{
+		Active:        !now.Before(*p.SaleStartsAt),
+		EndsInSeconds: int64(p.SaleEndsAt.Sub(now).Seconds()),
+	}
+	if p.SaleCountdown.Active {
+		p.CurrentPrice = p.SalePrice
+	} else {
+		p.SaleCountdown.StartsInSeconds = int64(p.SaleStartsAt.Sub(now).Seconds())
+	}
+}
+
+// nextSaleChange returns when the sale state of p next changes, or false
+// when it has no running or upcoming sale. ApplySale must have been called.
+func (p *Product) nextSaleChange() (time.Time, bool) {
+	if p.SaleCountdown == nil {
+		return time.Time{}, false
+	}
+	if p.SaleCountdown.Active {
+		return *p.SaleEndsAt, true
+	}
+	return *p.SaleStartsAt, true
+}
+
+var skuFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidSKU reports whether sku is 1 to 64 letters, digits, dots, dashes
+// and underscores, starting with a letter or digit.
+func ValidSKU(sku string) bool {
+	return skuFormat.MatchString(sku)
+}
+
+// ValidBarcode reports whether code is an EAN-8, UPC-A, EAN-13 or GTIN-14
+// barcode with a correct GS1 check digit.
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	// Digits are weighted 3 and 1 alternately, starting with 3 next to the
+	// check digit
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	check := int(code[len(code)-1] - '0')
+	return check >= 0 && check <= 9 && (10-sum%10)%10 == check
+}
+
+// MaxAttributes is the number of attributes a product may carry
+const MaxAttributes = 50
+
+var attributeKey = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// Attributes are the structured specs of a product, such as weight or
+// material, keyed by lowercase snake_case names. Values are strings,
+// numbers or booleans; per-category definitions are enforced by the
+// catalog policies.
+type Attributes map[string]interface{}
+
+// ValidAttributeKey reports whether key is a well formed attribute name
+func ValidAttributeKey(key string) bool {
+	return attributeKey.MatchString(key)
+}
+
+// Valid reports whether every key is well formed and every value a scalar.
+func (a Attributes) Valid() bool {
+	if len(a) > MaxAttributes {
+		return false
+	}
+	for key, value := range a {
+		if !ValidAttributeKey(key) {
+			return false
+		}
+		switch value.(type) {
+		case string, float64, bool:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements driver.Valuer, storing attributes as JSONB
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (a *Attributes) Scan(src interface{}) error {
+	*a = nil
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case nil:
+		*a = Attributes{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Attributes", src)
+	}
+}
+
+// OversellPolicy decides whether stock may be decremented below zero
+type OversellPolicy string
+
+const (
+	// OversellStrict never lets stock go below zero
+	OversellStrict OversellPolicy = "strict"
+	// OversellBackorder accepts any quantity and records the shortfall as negative stock
+	OversellBackorder OversellPolicy = "allow_backorder"
+	// OversellUpTo lets stock go down to -OversellLimit
+	OversellUpTo OversellPolicy = "allow_up_to"
+)
+
+// Status is where a product is in the publishing workflow. Only published
+// products are shown in the public catalog.
+type Status string
+
+const (
+	StatusDraft         Status = "draft"
+	StatusPendingReview Status = "pending_review"
+	StatusPublished     Status = "published"
+	StatusArchived      Status = "archived"
+)
+
+// transitions lists the statuses a product may move to from each status.
+var transitions = map[Status][]Status{
+	StatusDraft:         {StatusPendingReview, StatusPublished},
+	StatusPendingReview: {StatusDraft, StatusPublished},
+	StatusPublished:     {StatusDraft, StatusArchived},
+	StatusArchived:      {StatusDraft},
+}
+
+// vendorTransitions are the transitions a vendor may make on its own
+// products: submitting a draft for review and withdrawing it again. Every
+// other transition is made by admins.
+var vendorTransitions = map[Status][]Status{
+	StatusDraft:         {StatusPendingReview},
+	StatusPendingReview: {StatusDraft},
+}
+
+// CanTransition reports whether a product in status s may move to status to.
+func (s Status) CanTransition(to Status) bool {
+	return contains(transitions[s], to)
+}
+
+// VendorCanTransition reports whether a vendor may move its product from
+// status s to status to.
+func (s Status) VendorCanTransition(to Status) bool {
+	return contains(vendorTransitions[s], to)
+}
+
+func contains(statuses []Status, status Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
 }
 
 type CreateProductInput struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Price       float64  `json:"price"`
-	Categories  []string `json:"categories"`
+	SKU     *string `json:"sku"`
+	Barcode *string `json:"barcode"`
+	Name    string  `json:"name"`
+
+	// Slug addresses the product on the storefront. It is derived from
+	// the name when empty and kept when the product is renamed.
+	Slug string `json:"slug" validate:"omitempty,max=100"`
+
+	Description    string         `json:"description"`
+	Price          float64        `json:"price"`
+	Categories     []string       `json:"categories"`
+	Attributes     Attributes     `json:"attributes"`
+	BrandID        *int64         `json:"brand_id" validate:"omitempty,min=1"`
+	StockQuantity  int            `json:"stock_quantity" validate:"min=0"`
+	OversellPolicy OversellPolicy `json:"oversell_policy" validate:"omitempty,oneof=strict allow_backorder allow_up_to"`
+	OversellLimit  int            `json:"oversell_limit" validate:"min=0"`
+
+	// LowStockThreshold defaults to the configured low_stock_threshold
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,min=0"`
+
+	// PublishAt creates the product as a draft that is published at that time
+	PublishAt *time.Time `json:"publish_at"`
+
+	// PreorderAvailableAt makes the product pre-orderable
+	PreorderAvailableAt *time.Time `json:"preorder_available_at"`
 }
 
 type UpdateProductInput struct {
-	Name        *string   `json:"name"`
-	Description *string   `json:"description"`
-	Price       *float64  `json:"price"`
-	Categories  *[]string `json:"categories"`
+	// SKU and Barcode are cleared by empty strings
+	SKU            *string         `json:"sku"`
+	Barcode        *string         `json:"barcode"`
+	Name           *string         `json:"name"`
+	Description    *string         `json:"description"`
+	Price          *float64        `json:"price"`
+	Categories     *[]string       `json:"categories"`
+	OversellPolicy *OversellPolicy `json:"oversell_policy" validate:"omitempty,oneof=strict allow_backorder allow_up_to"`
+	OversellLimit  *int            `json:"oversell_limit" validate:"omitempty,min=0"`
+
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,min=0"`
+
+	// Attributes replaces every attribute of the product
+	Attributes *Attributes `json:"attributes"`
+
+	// Slug changes the product's storefront address; the old slug keeps
+	// redirecting to the product
+	Slug *string `json:"slug" validate:"omitempty,max=100"`
+
+	// BrandID moves the product to a brand of its store; 0 removes the brand
+	BrandID *int64 `json:"brand_id" validate:"omitempty,min=0"`
+
+	// PublishAt schedules an unpublished product to be published
+	PublishAt *time.Time `json:"publish_at"`
+
+	// PreorderAvailableAt makes the product pre-orderable or moves its
+	// expected availability date
+	PreorderAvailableAt *time.Time `json:"preorder_available_at"`
+}
+
+// Parts of a product that DuplicateInput may include in the copy
+const (
+	DuplicateAttributes = "attributes"
+	DuplicateComponents = "components"
+)
+
+// DuplicateInput names the copy of a product and picks the parts copied
+// along with its details. Stock, sales, schedules, SKUs and barcodes are
+// never copied.
+type DuplicateInput struct {
+	Name    *string  `json:"name" validate:"omitempty,min=1"`
+	Include []string `json:"include" validate:"dive,oneof=attributes components"`
+}
+
+type StatusChangeInput struct {
+	Status Status `json:"status" validate:"required,oneof=draft pending_review published archived"`
+}
+
+type SaleInput struct {
+	SalePrice float64   `json:"sale_price" validate:"required,gt=0"`
+	StartsAt  time.Time `json:"starts_at" validate:"required"`
+	EndsAt    time.Time `json:"ends_at" validate:"required"`
+}
+
+// BundleComponent is a product sold as part of a bundle. Quantity units of
+// it go into each bundle.
+type BundleComponent struct {
+	ProductID     int64  `db:"product_id" json:"product_id"`
+	Name          string `db:"name" json:"name"`
+	Quantity      int    `db:"quantity" json:"quantity"`
+	StockQuantity int    `db:"stock_quantity" json:"stock_quantity"`
+}
+
+// Bundle lists the components of a bundle product. Bundles have no stock
+// of their own; Available is how many can be assembled from component
+// stock.
+type Bundle struct {
+	ProductID  int64              `json:"product_id"`
+	Components []*BundleComponent `json:"components"`
+	Available  int                `json:"available"`
+}
+
+// BundleInput sets the components of a bundle; no components turns the
+// bundle back into a regular product.
+type BundleInput struct {
+	Components []BundleComponentInput `json:"components" validate:"max=20,dive"`
+}
+
+type BundleComponentInput struct {
+	ProductID int64 `json:"product_id" validate:"required"`
+	Quantity  int   `json:"quantity" validate:"required,min=1"`
+}
+
+// PriceHistoryEntry records one change to a product's price. OldPrice is
+// nil for the price a product was created with.
+type PriceHistoryEntry struct {
+	ID        int64     `db:"id" json:"id"`
+	ProductID int64     `db:"product_id" json:"product_id"`
+	OldPrice  *float64  `db:"old_price" json:"old_price"`
+	NewPrice  float64   `db:"new_price" json:"new_price"`
+	Actor     string    `db:"actor" json:"actor"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
+
+// PriceChange is a price that takes effect at EffectiveAt. AppliedAt is set
+// once the scheduler has applied it.
+type PriceChange struct {
+	ID          int64      `db:"id" json:"id"`
+	ProductID   int64      `db:"product_id" json:"product_id"`
+	Price       float64    `db:"price" json:"price"`
+	EffectiveAt time.Time  `db:"effective_at" json:"effective_at"`
+	AppliedAt   *time.Time `db:"applied_at" json:"applied_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+type PriceChangeInput struct {
+	Price       float64   `json:"price" validate:"required,gt=0"`
+	EffectiveAt time.Time `json:"effective_at" validate:"required"`
+}
+
+// Translation is a product's name and description in another locale than
+// the default locale
+type Translation struct {
+	ProductID   int64     `db:"product_id" json:"product_id"`
+	Locale      string    `db:"locale" json:"locale"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TranslationInput struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+// PriceAdjustment is how a bulk price update changes prices
+type PriceAdjustment string
+
+const (
+	// AdjustPercent changes prices by Value percent
+	AdjustPercent PriceAdjustment = "percent"
+	// AdjustFixed adds Value to prices
+	AdjustFixed PriceAdjustment = "fixed"
+)
+
+// BulkPriceInput adjusts the price of every product matching Category and
+// VendorID; at least one of them is required. New prices are rounded to
+// cents.
+type BulkPriceInput struct {
+	Category   *string         `json:"category"`
+	VendorID   *int64          `json:"vendor_id"`
+	Adjustment PriceAdjustment `json:"adjustment" validate:"required,oneof=percent fixed"`
+	Value      float64         `json:"value" validate:"required"`
+	DryRun     bool            `json:"dry_run"`
+}
+
+// BulkPriceResult counts the products a bulk price update changed, or would
+// change on a dry run. Skipped products matched but would have dropped to a
+// price of zero or less and are left unchanged.
+type BulkPriceResult struct {
+	Affected int  `json:"affected"`
+	Skipped  int  `json:"skipped"`
+	DryRun   bool `json:"dry_run"`
+}
+
+// MaxImportRows is the most products one import may create
+const MaxImportRows = 10000
+
+// ImportResult reports a product import. Failed lists the rows that were
+// not created, by their index in the import, with the reason.
+type ImportResult struct {
+	Created int             `json:"created"`
+	Failed  []ImportFailure `json:"failed"`
+}
+
+// ImportFailure is a row of an import that was not created
+type ImportFailure struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type StockChangeInput struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+// MaxStockLevels is the most SKUs one inventory sync may set
+const MaxStockLevels = 10000
+
+// InventorySyncInput sets the stock of products by SKU to the quantities on
+// hand in an external system, such as an ERP
+type InventorySyncInput struct {
+	Items []StockLevel `json:"items" validate:"required,min=1,max=10000,dive"`
+}
+
+// StockLevel is the stock on hand of the product with SKU
+type StockLevel struct {
+	SKU      string `json:"sku" validate:"required,max=64"`
+	Quantity int    `json:"quantity" validate:"min=0"`
+}
+
+// InventorySyncResult reports an inventory sync. Unchanged products were
+// already at their stock level. Failed lists the items that were not set,
+// by their index in the sync, with the reason.
+type InventorySyncResult struct {
+	Changed   int             `json:"changed"`
+	Unchanged int             `json:"unchanged"`
+	Failed    []ImportFailure `json:"failed"`
+}
+
+// StockLevelResult reports a batch of stock levels set by the repository.
+// Rejected maps the SKUs that were not set, lowercased, to the reason.
+type StockLevelResult struct {
+	Changed   int
+	Unchanged int
+	Rejected  map[string]error
 }
 
 type ProductFilter struct {
+	Status     *Status  `json:"status"`
+	OnSale     bool     `json:"on_sale"`
+	VendorID   *int64   `json:"vendor_id"`
 	CategoryID *string  `json:"category_id"`
 	MinPrice   *float64 `json:"min_price"`
 	MaxPrice   *float64 `json:"max_price"`
 	Search     *string  `json:"search"`
+	// Brand matches products of the brand with this slug
+	Brand *string `json:"brand"`
+
+	// Attributes matches products whose attribute equals any of the given
+	// values, for every attribute given
+	Attributes map[string][]string `json:"attributes"`
+
+	// Fields and Include limit the columns read to those the selected
+	// fields and included relations need
+	Fields  Fields  `json:"fields"`
+	Include Include `json:"include"`
 }
 
 type PaginationParams struct {
 	Page  int `json:"page" validate:"required,min=1"`
 	Limit int `json:"limit" validate:"required,min=1,max=100"`
+
+	// SkipCount spares List counting every match, for clients that only
+	// page forward. The total List returns is then a lower bound: it
+	// exceeds the products up to the end of the page only when there is a
+	// next page.
+	SkipCount bool `json:"-"`
+}
+
+// More reports whether a page of products listed with these parameters
+// is followed by another, given the total List returned
+func (p PaginationParams) More(total int) bool {
+	return total > p.Page*p.Limit
+}
+
+// Fields is a sparse field set, the JSON fields of Product a client asked
+// for with ?fields=id,name,price. An empty set means every field.
+type Fields []string
+
+// saleColumns are read to compute the current price of a product
+var saleColumns = []string{"price", "sale_price", "sale_starts_at", "sale_ends_at"}
+
+// fieldColumns maps the fields clients may select to the columns they are
+// read from. It is the whitelist for sparse field sets: only these column
+// names ever reach a query.
+var fieldColumns = map[string][]string{
+	"id":                    {"id"},
+	"store_id":              {"store_id"},
+	"vendor_id":             {"vendor_id"},
+	"brand_id":              {"brand_id"},
+	"sku":                   {"sku"},
+	"barcode":               {"barcode"},
+	"slug":                  {"slug"},
+	"name":                  {"name"},
+	"description":           {"description"},
+	"price":                 {"price"},
+	"categories":            {"categories"},
+	"attributes":            {"attributes"},
+	"status":                {"status"},
+	"is_bundle":             {"is_bundle"},
+	"publish_at":            {"publish_at"},
+	"sale_price":            {"sale_price"},
+	"sale_starts_at":        {"sale_starts_at"},
+	"sale_ends_at":          {"sale_ends_at"},
+	"locale":                {},
+	"lowest_price_30d":      saleColumns,
+	"current_price":         saleColumns,
+	"sale_countdown":        saleColumns,
+	"stock_quantity":        {"stock_quantity"},
+	"oversell_policy":       {"oversell_policy"},
+	"oversell_limit":        {"oversell_limit"},
+	"low_stock_threshold":   {"low_stock_threshold"},
+	"preorder_available_at": {"preorder_available_at"},
+	"created_at":            {"created_at"},
+	"updated_at":            {"updated_at"},
+}
+
+// servedColumns are read whatever fields are selected: serving a product
+// checks its visibility and applies its sale.
+var servedColumns = append([]string{"id", "store_id", "vendor_id", "status"}, saleColumns...)
+
+// ParseFields parses a comma-separated field list. Unknown fields are an
+// error wrapping ErrUnknownField.
+func ParseFields(raw string) (Fields, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var fields Fields
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := fieldColumns[field]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownField, field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Has reports whether field is selected
+func (f Fields) Has(field string) bool {
+	return len(f) == 0 || slices.Contains(f, field)
+}
+
+// columns lists the columns to read for f and the relations in include, or
+// nil for every column
+func (f Fields) columns(include Include) []string {
+	if len(f) == 0 {
+		return nil
+	}
+	columns := append([]string(nil), servedColumns...)
+	add := func(names []string) {
+		for _, column := range names {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	for _, field := range f {
+		add(fieldColumns[field])
+	}
+	for _, relation := range include {
+		add(includeColumns[relation])
+	}
+	return columns
+}
+
+// Include names the related resources a client asked for with
+// ?include=categories,brand
+type Include []string
+
+// Relations that can be included
+const (
+	IncludeCategories = "categories"
+	IncludeBrand      = "brand"
+)
+
+// includeColumns are the product columns each relation is looked up by
+var includeColumns = map[string][]string{
+	IncludeCategories: {"categories"},
+	IncludeBrand:      {"brand_id"},
+}
+
+// ParseInclude parses a comma-separated relation list. Unknown relations
+// are an error wrapping ErrUnknownInclude.
+func ParseInclude(raw string) (Include, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var include Include
+	for _, relation := range strings.Split(raw, ",") {
+		relation = strings.TrimSpace(relation)
+		if _, ok := includeColumns[relation]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownInclude, relation)
+		}
+		include = append(include, relation)
+	}
+	return include, nil
+}
+
+// Has reports whether relation is included
+func (i Include) Has(relation string) bool {
+	return slices.Contains(i, relation)
+}
+
+// Included are the related resources of a product. Each relation is
+// loaded for all served products at once.
+type Included struct {
+	Categories []*CategoryRef `json:"categories,omitempty"`
+	Brand      *BrandRef      `json:"brand,omitempty"`
+}
+
+// CategoryRef is a category a product is filed under
+type CategoryRef struct {
+	ID       int64  `db:"id" json:"id"`
+	StoreID  int64  `db:"store_id" json:"-"`
+	ParentID *int64 `db:"parent_id" json:"parent_id,omitempty"`
+	Name     string `db:"name" json:"name"`
+	Slug     string `db:"slug" json:"slug"`
+}
+
+// BrandRef is the brand of a product
+type BrandRef struct {
+	ID   int64  `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+	Slug string `db:"slug" json:"slug"`
+}
+
+// Tombstone records the deletion of a product for sync clients
+type Tombstone struct {
+	ProductID   int64     `db:"product_id" json:"id"`
+	StoreID     int64     `db:"store_id" json:"-"`
+	SyncVersion int64     `db:"sync_version" json:"-"`
+	DeletedAt   time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+// ChangeBatch is a page of raw changes read from the repository
+type ChangeBatch struct {
+	Products    []*Product
+	Tombstones  []*Tombstone
+	LastVersion int64
+	HasMore     bool
+}
+
+// ProductChanges is one page of the differential sync feed
+type ProductChanges struct {
+	Created    []*Product   `json:"created"`
+	Updated    []*Product   `json:"updated"`
+	Deleted    []*Tombstone `json:"deleted"`
+	NextCursor string       `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
+
+// LowStockAlert is the payload of a product.low_stock event
+type LowStockAlert struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	StockQuantity     int    `json:"stock_quantity"`
+	LowStockThreshold int    `json:"low_stock_threshold"`
+}
+
+// PreorderReleased is the payload of a product.preorder_released event.
+// Backordered is the number of pre-ordered units now ready to fulfil.
+type PreorderReleased struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	StockQuantity int    `json:"stock_quantity"`
+	Backordered   int    `json:"backordered"`
+}
+
+// BackInStock is the payload of a product.back_in_stock event
+type BackInStock struct {
+	ID            int64  `json:"id"`
+	Slug          string `json:"slug"`
+	Name          string `json:"name"`
+	StockQuantity int    `json:"stock_quantity"`
 }
@@ -0,0 +1,136 @@
+package producttest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+)
+
+// CreateInput builds a valid create request for a simple product; pass
+// overrides to change only what a test cares about.
+func CreateInput(overrides ...func(*product.CreateProductInput)) product.CreateProductInput {
+	input := product.CreateProductInput{
+		Name:          "Trail Running Shoe",
+		Description:   "A product for tests",
+		Price:         19.99,
+		Categories:    []string{"footwear"},
+		Attributes:    product.Attributes{},
+		StockQuantity: 10,
+	}
+	for _, override := range overrides {
+		override(&input)
+	}
+	return input
+}
+
+// Catalog is an in-memory product table behind a RepositoryMock. Lookups
+// and creates are served from it; other repository methods panic until a
+// test sets them on Repository.
+type Catalog struct {
+	Repository *RepositoryMock
+
+	mu        sync.Mutex
+	products  map[int64]*product.Product
+	redirects map[string]int64
+	nextID    int64
+}
+
+// NewCatalog returns a Catalog holding products.
+func NewCatalog(products ...*product.Product) *Catalog {
+	c := &Catalog{
+		products:  make(map[int64]*product.Product),
+		redirects: make(map[string]int64),
+	}
+	for _, p := range products {
+		c.Add(p)
+	}
+
+	c.Repository = &RepositoryMock{
+		CreateFunc: func(_ context.Context, p *product.Product) error {
+			c.Add(p)
+			return nil
+		},
+		GetByIDFunc: func(_ context.Context, id int64) (*product.Product, error) {
+			return c.find(func(p *product.Product) bool { return p.ID == id })
+		},
+		GetBySKUFunc: func(_ context.Context, sku string) (*product.Product, error) {
+			return c.find(func(p *product.Product) bool { return p.SKU != nil && strings.EqualFold(*p.SKU, sku) })
+		},
+		GetByBarcodeFunc: func(_ context.Context, barcode string) (*product.Product, error) {
+			return c.find(func(p *product.Product) bool { return p.Barcode != nil && *p.Barcode == barcode })
+		},
+		GetBySlugFunc: func(_ context.Context, slug string) (*product.Product, error) {
+			return c.find(func(p *product.Product) bool { return p.Slug == slug })
+		},
+		FindByNameFunc: func(_ context.Context, name string, vendorID *int64, withSKU bool) (*product.Product, error) {
+			return c.find(func(p *product.Product) bool {
+				return strings.EqualFold(p.Name, name) && sameVendor(p.VendorID, vendorID) &&
+					p.Status != product.StatusArchived && (!withSKU || p.SKU == nil)
+			})
+		},
+		ResolveSlugFunc: func(_ context.Context, slug string) (int64, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if id, ok := c.redirects[slug]; ok {
+				return id, nil
+			}
+			return 0, fmt.Errorf("slug redirect not found: %w", sql.ErrNoRows)
+		},
+	}
+	return c
+}
+
+// Add stores p, giving it the next free ID when it has none.
+func (c *Catalog) Add(p *product.Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = c.nextID + 1
+	}
+	if p.ID > c.nextID {
+		c.nextID = p.ID
+	}
+	c.products[p.ID] = p
+}
+
+// Redirect makes the former slug old lead to the product id.
+func (c *Catalog) Redirect(old string, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redirects[old] = id
+}
+
+// Get returns the stored product id, or nil.
+func (c *Catalog) Get(id int64) *product.Product {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.products[id]
+}
+
+// find returns the matching product with the lowest ID, or an error
+// wrapping sql.ErrNoRows like the SQL repository.
+func (c *Catalog) find(match func(*product.Product) bool) (*product.Product, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var found *product.Product
+	for _, p := range c.products {
+		if match(p) && (found == nil || p.ID < found.ID) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("product not found: %w", sql.ErrNoRows)
+	}
+	return found, nil
+}
+
+func sameVendor(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,6 @@
+// Package producttest provides test doubles and fixtures for code built on
+// the product package. The mocks are generated with moq; rerun go generate
+// after changing the Repository, Service or PolicyChecker interfaces.
+package producttest
+
+//go:generate moq -out mocks.go -pkg producttest .. Repository Service PolicyChecker
@@ -3,54 +3,396 @@ package product
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/database"
+	"github.com/dotslashbit/ecommerce-api/pkg/outbox"
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// AggregateType identifies products in the outbox
+const AggregateType = "product"
+
+// Domain events recorded in the outbox on product writes
+const (
+	EventProductCreated   = "product.created"
+	EventProductUpdated   = "product.updated"
+	EventProductDeleted   = "product.deleted"
+	EventStockChanged     = "product.stock_changed"
+	EventLowStock         = "product.low_stock"
+	EventBackInStock      = "product.back_in_stock"
+	EventStatusChanged    = "product.status_changed"
+	EventPreorderReleased = "product.preorder_released"
+)
+
+// onSale matches products whose sale is running
+const onSale = `(sale_price IS NOT NULL AND sale_starts_at <= NOW() AND sale_ends_at > NOW())`
+
+// currentPrice is the price a product sells at right now
+const currentPrice = `(CASE WHEN ` + onSale + ` THEN sale_price ELSE price END)`
+
 // Repository defines the interface for product data operations
 type Repository interface {
 	Create(ctx context.Context, product *Product) error
+	// CreateBatch creates products together in one transaction
+	CreateBatch(ctx context.Context, products []*Product) error
+	Duplicate(ctx context.Context, sourceID int64, product *Product, components bool) error
 	GetByID(ctx context.Context, id int64) (*Product, error)
+	// GetBySKU matches SKUs case-insensitively
+	GetBySKU(ctx context.Context, sku string) (*Product, error)
+	GetByBarcode(ctx context.Context, barcode string) (*Product, error)
+	GetBySlug(ctx context.Context, slug string) (*Product, error)
+	// FindByName returns the oldest unarchived product of vendorID with
+	// name, ignoring case. withSKU skips products that have a SKU.
+	FindByName(ctx context.Context, name string, vendorID *int64, withSKU bool) (*Product, error)
+	// ResolveSlug returns the ID of the product a former slug redirects to
+	ResolveSlug(ctx context.Context, slug string) (int64, error)
 	List(ctx context.Context, filter ProductFilter, pagination PaginationParams) ([]*Product, int, error)
 	Update(ctx context.Context, id int64, input UpdateProductInput) error
 	Delete(ctx context.Context, id int64) error
+	SetStatus(ctx context.Context, id int64, from, to Status) (*Product, error)
+	SetSale(ctx context.Context, id int64, sale *SaleInput) (*Product, error)
+	SetComponents(ctx context.Context, id int64, components []BundleComponentInput) error
+	ListComponents(ctx context.Context, id int64) ([]*BundleComponent, error)
+	ListPriceHistory(ctx context.Context, id int64, limit int) ([]*PriceHistoryEntry, error)
+	LowestPriceBefore(ctx context.Context, id int64, at time.Time) (*float64, error)
+	SetTranslation(ctx context.Context, translation *Translation) error
+	ListTranslations(ctx context.Context, productID int64) ([]*Translation, error)
+	DeleteTranslation(ctx context.Context, productID int64, locale string) error
+	// TranslationsIn returns the translations of the given products in any
+	// of the given locales
+	TranslationsIn(ctx context.Context, productIDs []int64, locales []string) ([]*Translation, error)
+	// CategoriesNamed returns the categories with any of the given names,
+	// ignoring case
+	CategoriesNamed(ctx context.Context, names []string) ([]*CategoryRef, error)
+	// BrandsIn returns the brands with the given IDs
+	BrandsIn(ctx context.Context, ids []int64) ([]*BrandRef, error)
+	PublishDue(ctx context.Context) (int, error)
+	CreatePriceChange(ctx context.Context, change *PriceChange) error
+	ListPriceChanges(ctx context.Context, productID int64) ([]*PriceChange, error)
+	DeletePriceChange(ctx context.Context, productID, id int64) error
+	ApplyDuePriceChanges(ctx context.Context) (int, error)
+	// BulkUpdatePrices applies a price adjustment to every matching product
+	// in a single statement, or only counts them on a dry run
+	BulkUpdatePrices(ctx context.Context, input BulkPriceInput) (*BulkPriceResult, error)
+	ListChanges(ctx context.Context, sinceVersion int64, limit int) (*ChangeBatch, error)
+	DecrementStock(ctx context.Context, id int64, quantity int) (*Product, error)
+	IncrementStock(ctx context.Context, id int64, quantity int) (*Product, error)
+	// SetStockLevels sets the stock of products of the current store by SKU
+	// in one transaction, recording a stock change for each that moved
+	SetStockLevels(ctx context.Context, levels []StockLevel) (*StockLevelResult, error)
+	ListLowStock(ctx context.Context, pagination PaginationParams) ([]*Product, int, error)
+	ListRelated(ctx context.Context, id int64, limit int) ([]*Product, error)
+	RefreshRelated(ctx context.Context, perProduct int) error
 }
 
 // repository is the SQL implementation of the Repository interface
 type repository struct {
-	db *sqlx.DB
+	db         *sqlx.DB
+	statements *database.Statements
+}
+
+// NewRepository creates a new instance of the SQL repository. Queries are
+// scoped to the store in the request context; unscoped contexts, such as
+// background jobs, see every store. The lookups run on every catalog
+// request are prepared once in statements.
+func NewRepository(db *sqlx.DB, statements *database.Statements) Repository {
+	return &repository{db: db, statements: statements}
+}
+
+// getPrepared is GetContext with query run as a cached prepared statement
+func (r *repository) getPrepared(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, err := r.statements.Get(ctx, query)
+	if err != nil {
+		return err
+	}
+	return stmt.GetContext(ctx, dest, args...)
 }
 
-// NewRepository creates a new instance of the SQL repository
-func NewRepository(db *sqlx.DB) Repository {
-	return &repository{db: db}
+// selectPrepared is SelectContext with query run as a cached prepared
+// statement
+func (r *repository) selectPrepared(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, err := r.statements.Get(ctx, query)
+	if err != nil {
+		return err
+	}
+	return stmt.SelectContext(ctx, dest, args...)
 }
 
-// Create adds a new product to the database
+// Create adds a new product to the database and records a product.created
+// event and an audit entry
 func (r *repository) Create(ctx context.Context, product *Product) error {
-	query := `
-		INSERT INTO products (name, description, price, categories)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at`
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insert(ctx, tx, tenant.StoreIDOrDefault(ctx), product); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing product: %w", err)
+	}
+
+	return nil
+}
+
+// Duplicate adds product as a copy of the product sourceID inside one
+// transaction, copying the bundle components of the source when components
+// is set
+func (r *repository) Duplicate(ctx context.Context, sourceID int64, product *Product, components bool) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insert(ctx, tx, product.StoreID, product); err != nil {
+		return err
+	}
+
+	if components {
+		query := `
+			INSERT INTO product_bundle_items (bundle_id, component_id, quantity)
+			SELECT $1, component_id, quantity FROM product_bundle_items WHERE bundle_id = $2`
+		if _, err := tx.ExecContext(ctx, query, product.ID, sourceID); err != nil {
+			return fmt.Errorf("error copying bundle components: %w", err)
+		}
+	}
 
-	err := r.db.QueryRowxContext(ctx, query,
-		product.Name, product.Description, product.Price, product.Categories).
-		StructScan(product)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing product: %w", err)
+	}
+
+	return nil
+}
+
+// insert adds product to storeID inside tx with its first price, a
+// product.created event and an audit entry
+func insert(ctx context.Context, tx *sqlx.Tx, storeID int64, product *Product) error {
+	if product.Slug == "" {
+		unique, err := slug.Unique(ctx, tx, "products", AggregateType, storeID, slug.Make(product.Name), AggregateType)
+		if err != nil {
+			return err
+		}
+		product.Slug = unique
+	}
+
+	query := `INSERT INTO products (` + strings.Join(insertColumns, ", ") + `) VALUES (` + placeholders(1, len(insertColumns)) + `) RETURNING *`
+	if err := tx.QueryRowxContext(ctx, query, insertValues(storeID, product)...).StructScan(product); err != nil {
+		return insertError(err)
+	}
+
+	if err := recordPrice(ctx, tx, product.ID, nil, product.Price); err != nil {
+		return err
+	}
+
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventProductCreated, product); err != nil {
+		return err
+	}
+	return audit.Record(ctx, tx, AggregateType, product.ID, audit.ActionCreate, nil, product)
+}
+
+// insertColumns are the columns set when a product is created
+var insertColumns = []string{
+	"store_id", "vendor_id", "brand_id", "sku", "barcode", "slug", "name", "description", "price", "categories",
+	"attributes", "status", "is_bundle", "publish_at", "stock_quantity", "oversell_policy", "oversell_limit",
+	"low_stock_threshold", "preorder_available_at",
+}
 
+// insertValues are the values of insertColumns for product in storeID
+func insertValues(storeID int64, product *Product) []interface{} {
+	return []interface{}{
+		storeID, product.VendorID, product.BrandID, product.SKU, product.Barcode, product.Slug, product.Name,
+		product.Description, product.Price, product.Categories, product.Attributes, product.Status, product.IsBundle,
+		product.PublishAt, product.StockQuantity, product.OversellPolicy, product.OversellLimit,
+		product.LowStockThreshold, product.PreorderAvailableAt,
+	}
+}
+
+// placeholders lists n query parameters starting at $first
+func placeholders(first, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", first+i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// insertError maps the constraint violations of a product insert to the
+// service's errors
+func insertError(err error) error {
+	if dup := duplicateCode(err); dup != nil {
+		return dup
+	}
+	if isUnknownBrand(err) {
+		return ErrUnknownBrand
+	}
+	return fmt.Errorf("error creating product: %w", err)
+}
+
+// maxInsertRows is the most products one INSERT can hold, as Postgres
+// takes at most 65535 parameters per statement
+var maxInsertRows = 65535 / len(insertColumns)
+
+// CreateBatch adds products to the current store in one transaction. The
+// products, their first prices, their product.created events and their
+// audit entries are each written with multi-row inserts rather than one
+// statement per product.
+func (r *repository) CreateBatch(ctx context.Context, products []*Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	storeID := tenant.StoreIDOrDefault(ctx)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error creating product: %w", err)
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Products without a slug get one from their name that avoids the
+	// slugs the rest of the batch was given
+	var bases, reserved []string
+	var unnamed []*Product
+	for _, product := range products {
+		if product.Slug == "" {
+			bases = append(bases, slug.Make(product.Name))
+			unnamed = append(unnamed, product)
+		} else {
+			reserved = append(reserved, product.Slug)
+		}
 	}
+	if len(unnamed) > 0 {
+		slugs, err := slug.UniqueAll(ctx, tx, "products", AggregateType, storeID, bases, AggregateType, reserved)
+		if err != nil {
+			return err
+		}
+		for i, product := range unnamed {
+			product.Slug = slugs[i]
+		}
+	}
+
+	for start := 0; start < len(products); start += maxInsertRows {
+		if err := insertRows(ctx, tx, storeID, products[start:min(start+maxInsertRows, len(products))]); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]int64, len(products))
+	created := make([]interface{}, len(products))
+	for i, product := range products {
+		ids[i], created[i] = product.ID, product
+	}
+	query := `
+		INSERT INTO price_history (product_id, old_price, new_price, actor)
+		SELECT id, NULL, price, $2 FROM products WHERE id = ANY($1)`
+	if _, err := tx.ExecContext(ctx, query, pq.Array(ids), audit.ActorFrom(ctx).Name); err != nil {
+		return fmt.Errorf("error recording price history: %w", err)
+	}
+	if err := outbox.RecordBatch(ctx, tx, storeID, AggregateType, ids, EventProductCreated, created); err != nil {
+		return err
+	}
+	if err := audit.RecordCreates(ctx, tx, AggregateType, ids, created); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing products: %w", err)
+	}
+	return nil
+}
 
+// insertRows adds products to storeID with a single INSERT and fills in
+// the columns the database set
+func insertRows(ctx context.Context, tx *sqlx.Tx, storeID int64, products []*Product) error {
+	rows := make([]string, len(products))
+	args := make([]interface{}, 0, len(products)*len(insertColumns))
+	bySlug := make(map[string]*Product, len(products))
+	for i, product := range products {
+		rows[i] = "(" + placeholders(len(args)+1, len(insertColumns)) + ")"
+		args = append(args, insertValues(storeID, product)...)
+		bySlug[product.Slug] = product
+	}
+
+	// RETURNING does not promise the order of VALUES, so created rows are
+	// matched back by their slug, which is unique within the store
+	query := `INSERT INTO products (` + strings.Join(insertColumns, ", ") + `) VALUES ` + strings.Join(rows, ", ") + ` RETURNING *`
+	result, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return insertError(err)
+	}
+	defer result.Close()
+	for result.Next() {
+		var created Product
+		if err := result.StructScan(&created); err != nil {
+			return fmt.Errorf("error reading created product: %w", err)
+		}
+		*bySlug[created.Slug] = created
+	}
+	if err := result.Err(); err != nil {
+		return insertError(err)
+	}
 	return nil
 }
 
 // GetByID retrieves a single product by its ID
 func (r *repository) GetByID(ctx context.Context, id int64) (*Product, error) {
 	var product Product
-	query := `SELECT * FROM products WHERE id = $1`
-	err := r.db.GetContext(ctx, &product, query, id)
+	query := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	err := r.getPrepared(ctx, &product, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &product, nil
+}
+
+// GetBySKU retrieves a single product by its SKU
+func (r *repository) GetBySKU(ctx context.Context, sku string) (*Product, error) {
+	var product Product
+	query := `SELECT * FROM products WHERE lower(sku) = lower($1) AND store_id = $2`
+	err := r.getPrepared(ctx, &product, query, sku, tenant.StoreIDOrDefault(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &product, nil
+}
+
+// GetByBarcode retrieves a single product by its barcode
+func (r *repository) GetByBarcode(ctx context.Context, barcode string) (*Product, error) {
+	var product Product
+	query := `SELECT * FROM products WHERE barcode = $1 AND store_id = $2`
+	err := r.getPrepared(ctx, &product, query, barcode, tenant.StoreIDOrDefault(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	return &product, nil
+}
+
+// GetBySlug retrieves a single product by its current slug
+func (r *repository) GetBySlug(ctx context.Context, s string) (*Product, error) {
+	var product Product
+	query := `SELECT * FROM products WHERE slug = $1 AND store_id = $2`
+	err := r.getPrepared(ctx, &product, query, s, tenant.StoreIDOrDefault(ctx))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("product not found: %w", err)
@@ -60,26 +402,112 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*Product, error) {
 	return &product, nil
 }
 
+// FindByName matches vendorless products among themselves when vendorID is
+// nil
+func (r *repository) FindByName(ctx context.Context, name string, vendorID *int64, withSKU bool) (*Product, error) {
+	var product Product
+	query := `
+		SELECT * FROM products
+		WHERE store_id = $1 AND lower(name) = lower($2) AND vendor_id IS NOT DISTINCT FROM $3
+			AND status <> 'archived' AND (NOT $4 OR sku IS NULL)
+		ORDER BY id
+		LIMIT 1`
+	err := r.db.GetContext(ctx, &product, query, tenant.StoreIDOrDefault(ctx), name, vendorID, withSKU)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error finding product: %w", err)
+	}
+	return &product, nil
+}
+
+// ResolveSlug looks former slugs up in the redirect table
+func (r *repository) ResolveSlug(ctx context.Context, s string) (int64, error) {
+	return slug.Resolve(ctx, r.db, tenant.StoreIDOrDefault(ctx), AggregateType, s)
+}
+
+// duplicateCode maps unique violations of the SKU, barcode and slug
+// indexes to ErrDuplicateSKU, ErrDuplicateBarcode and ErrDuplicateSlug,
+// and returns nil for other errors
+func duplicateCode(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23505" {
+		return nil
+	}
+	switch pqErr.Constraint {
+	case "idx_products_sku":
+		return ErrDuplicateSKU
+	case "idx_products_barcode":
+		return ErrDuplicateBarcode
+	case "idx_products_slug":
+		return ErrDuplicateSlug
+	}
+	return nil
+}
+
+// isUnknownBrand reports whether err is a violation of the products' brand
+// foreign key, which also rejects brands of another store
+func isUnknownBrand(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503" && pqErr.Constraint == "fk_products_brand"
+}
+
 // List retrieves a list of products, applying filters and pagination
 func (r *repository) List(ctx context.Context, filter ProductFilter, pagination PaginationParams) ([]*Product, int, error) {
-	query := `SELECT * FROM products`
+	query := `SELECT ` + selectList(filter.Fields, filter.Include) + ` FROM products`
 	countQuery := `SELECT COUNT(*) FROM products`
 	whereClause := []string{}
 	args := []interface{}{}
 	argID := 1
 
+	if storeID, ok := tenant.StoreID(ctx); ok {
+		whereClause = append(whereClause, fmt.Sprintf("store_id = $%d", argID))
+		args = append(args, storeID)
+		argID++
+	}
+	if filter.Status != nil {
+		whereClause = append(whereClause, fmt.Sprintf("status = $%d", argID))
+		args = append(args, *filter.Status)
+		argID++
+	}
+	if filter.VendorID != nil {
+		whereClause = append(whereClause, fmt.Sprintf("vendor_id = $%d", argID))
+		args = append(args, *filter.VendorID)
+		argID++
+	}
 	if filter.CategoryID != nil && *filter.CategoryID != "" {
 		whereClause = append(whereClause, fmt.Sprintf(`EXISTS (SELECT 1 FROM unnest(categories) category WHERE category ILIKE $%d)`, argID))
 		args = append(args, "%"+*filter.CategoryID+"%")
 		argID++
 	}
+	if filter.Brand != nil {
+		whereClause = append(whereClause, fmt.Sprintf(`brand_id = (SELECT id FROM brands WHERE slug = $%d AND store_id = products.store_id)`, argID))
+		args = append(args, *filter.Brand)
+		argID++
+	}
+	if filter.OnSale {
+		whereClause = append(whereClause, onSale)
+	}
+	// Keys are sorted so equal filters build the same query. Values are
+	// compared as text, so attr[weight_kg]=1.5 matches the number 1.5.
+	attributeKeys := make([]string, 0, len(filter.Attributes))
+	for key := range filter.Attributes {
+		attributeKeys = append(attributeKeys, key)
+	}
+	sort.Strings(attributeKeys)
+	for _, key := range attributeKeys {
+		whereClause = append(whereClause, fmt.Sprintf("attributes ? $%d AND attributes ->> $%d = ANY($%d)", argID, argID, argID+1))
+		args = append(args, key, pq.StringArray(filter.Attributes[key]))
+		argID += 2
+	}
 	if filter.MinPrice != nil {
-		whereClause = append(whereClause, fmt.Sprintf("price >= $%d", argID))
+		whereClause = append(whereClause, fmt.Sprintf(currentPrice+" >= $%d", argID))
 		args = append(args, *filter.MinPrice)
 		argID++
 	}
 	if filter.MaxPrice != nil {
-		whereClause = append(whereClause, fmt.Sprintf("price <= $%d", argID))
+		whereClause = append(whereClause, fmt.Sprintf(currentPrice+" <= $%d", argID))
 		args = append(args, *filter.MaxPrice)
 		argID++
 	}
@@ -94,14 +522,28 @@ func (r *repository) List(ctx context.Context, filter ProductFilter, pagination
 		countQuery += " WHERE " + strings.Join(whereClause, " AND ")
 	}
 
+	// Without a count, one product past the page tells whether another
+	// page follows
+	offset := (pagination.Page - 1) * pagination.Limit
+	limit := pagination.Limit
+	if pagination.SkipCount {
+		limit++
+	}
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argID, argID+1)
-	args = append(args, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+	args = append(args, limit, offset)
 
 	var products []*Product
 	err := r.db.SelectContext(ctx, &products, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error listing products: %w", err)
 	}
+	if pagination.SkipCount {
+		total := offset + len(products)
+		if len(products) > pagination.Limit {
+			products = products[:pagination.Limit]
+		}
+		return products, total, nil
+	}
 
 	var totalCount int
 	err = r.db.GetContext(ctx, &totalCount, countQuery, args[:len(args)-2]...)
@@ -112,12 +554,39 @@ func (r *repository) List(ctx context.Context, filter ProductFilter, pagination
 	return products, totalCount, nil
 }
 
-// Update modifies an existing product
+// selectList is the select list reading the columns fields and include
+// need. Column names come from the fieldColumns and includeColumns
+// whitelists, never from the request.
+func selectList(fields Fields, include Include) string {
+	columns := fields.columns(include)
+	if columns == nil {
+		return "*"
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Update modifies an existing product and records a product.updated event
+// and an audit entry
 func (r *repository) Update(ctx context.Context, id int64, input UpdateProductInput) error {
 	query := `UPDATE products SET `
 	args := []interface{}{}
 	argID := 1
 
+	if input.SKU != nil {
+		query += fmt.Sprintf("sku = NULLIF($%d, ''), ", argID)
+		args = append(args, *input.SKU)
+		argID++
+	}
+	if input.Barcode != nil {
+		query += fmt.Sprintf("barcode = NULLIF($%d, ''), ", argID)
+		args = append(args, *input.Barcode)
+		argID++
+	}
+	if input.Slug != nil {
+		query += fmt.Sprintf("slug = $%d, ", argID)
+		args = append(args, *input.Slug)
+		argID++
+	}
 	if input.Name != nil {
 		query += fmt.Sprintf("name = $%d, ", argID)
 		args = append(args, *input.Name)
@@ -135,47 +604,1114 @@ func (r *repository) Update(ctx context.Context, id int64, input UpdateProductIn
 	}
 	if input.Categories != nil {
 		query += fmt.Sprintf("categories = $%d, ", argID)
-		args = append(args, *input.Categories)
+		args = append(args, pq.StringArray(*input.Categories))
+		argID++
+	}
+	if input.Attributes != nil {
+		query += fmt.Sprintf("attributes = $%d, ", argID)
+		args = append(args, *input.Attributes)
+		argID++
+	}
+	if input.BrandID != nil {
+		query += fmt.Sprintf("brand_id = NULLIF($%d, 0), ", argID)
+		args = append(args, *input.BrandID)
+		argID++
+	}
+	if input.OversellPolicy != nil {
+		query += fmt.Sprintf("oversell_policy = $%d, ", argID)
+		args = append(args, *input.OversellPolicy)
+		argID++
+	}
+	if input.OversellLimit != nil {
+		query += fmt.Sprintf("oversell_limit = $%d, ", argID)
+		args = append(args, *input.OversellLimit)
+		argID++
+	}
+	if input.LowStockThreshold != nil {
+		query += fmt.Sprintf("low_stock_threshold = $%d, ", argID)
+		args = append(args, *input.LowStockThreshold)
+		argID++
+	}
+	if input.PublishAt != nil {
+		query += fmt.Sprintf("publish_at = $%d, ", argID)
+		args = append(args, *input.PublishAt)
+		argID++
+	}
+	if input.PreorderAvailableAt != nil {
+		query += fmt.Sprintf("preorder_available_at = $%d, ", argID)
+		args = append(args, *input.PreorderAvailableAt)
 		argID++
 	}
 
-	query = strings.TrimSuffix(query, ", ")
-	query += fmt.Sprintf(", updated_at = NOW() WHERE id = $%d", argID)
+	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d RETURNING *", argID)
 	args = append(args, id)
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Product
+	scoped := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product not found: %w", err)
+		}
+		return fmt.Errorf("error getting product: %w", err)
+	}
+
+	var product Product
+	if err := tx.GetContext(ctx, &product, query, args...); err != nil {
+		if dup := duplicateCode(err); dup != nil {
+			return dup
+		}
+		if isUnknownBrand(err) {
+			return ErrUnknownBrand
+		}
 		return fmt.Errorf("error updating product: %w", err)
 	}
+	if product.Price != before.Price {
+		if err := recordPrice(ctx, tx, id, &before.Price, product.Price); err != nil {
+			return err
+		}
+	}
+	if product.Slug != before.Slug {
+		if err := slug.Redirect(ctx, tx, product.StoreID, AggregateType, id, before.Slug); err != nil {
+			return err
+		}
+	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, id, EventProductUpdated, &product); err != nil {
+		return err
+	}
+	if err := audit.Record(ctx, tx, AggregateType, id, audit.ActionUpdate, &before, &product); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("product not found")
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing product update: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes a product from the database
+// Delete removes a product from the database and records a product.deleted
+// event and an audit entry
 func (r *repository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM products WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Product
+	query := `DELETE FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) RETURNING *`
+	if err := tx.GetContext(ctx, &before, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product not found: %w", err)
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return ErrInBundle
+		}
 		return fmt.Errorf("error deleting product: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	payload := struct {
+		ID int64 `json:"id"`
+	}{ID: id}
+	if err := outbox.Record(ctx, tx, before.StoreID, AggregateType, id, EventProductDeleted, payload); err != nil {
+		return err
+	}
+	if err := audit.Record(ctx, tx, AggregateType, id, audit.ActionDelete, &before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing product deletion: %w", err)
+	}
+
+	return nil
+}
+
+// SetStatus moves a product from status from to status to and records a
+// product.status_changed event and an audit entry. The update only succeeds
+// while the product is still in status from, so concurrent transitions
+// cannot both win.
+func (r *repository) SetStatus(ctx context.Context, id int64, from, to Status) (*Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+		return nil, fmt.Errorf("error starting transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("product not found")
+	var before Product
+	scoped := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+
+	var product Product
+	query := `UPDATE products SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING *`
+	if err := tx.GetContext(ctx, &product, query, to, id, from); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found in status %s: %w", from, err)
+		}
+		return nil, fmt.Errorf("error updating product status: %w", err)
+	}
+
+	payload := struct {
+		ID   int64  `json:"id"`
+		From Status `json:"from"`
+		To   Status `json:"to"`
+	}{ID: id, From: from, To: to}
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, id, EventStatusChanged, payload); err != nil {
+		return nil, err
+	}
+	if err := audit.Record(ctx, tx, AggregateType, id, audit.ActionUpdate, &before, &product); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing product status: %w", err)
+	}
+
+	return &product, nil
+}
+
+// SetSale starts or replaces a product's sale, or ends it when sale is nil,
+// and records a product.updated event and an audit entry
+func (r *repository) SetSale(ctx context.Context, id int64, sale *SaleInput) (*Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Product
+	scoped := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+
+	var price *float64
+	var startsAt, endsAt *time.Time
+	if sale != nil {
+		price, startsAt, endsAt = &sale.SalePrice, &sale.StartsAt, &sale.EndsAt
+	}
+
+	var product Product
+	query := `
+		UPDATE products SET sale_price = $1, sale_starts_at = $2, sale_ends_at = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING *`
+	if err := tx.GetContext(ctx, &product, query, price, startsAt, endsAt, id); err != nil {
+		return nil, fmt.Errorf("error updating product sale: %w", err)
+	}
+
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, id, EventProductUpdated, &product); err != nil {
+		return nil, err
+	}
+	if err := audit.Record(ctx, tx, AggregateType, id, audit.ActionUpdate, &before, &product); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing product sale: %w", err)
+	}
+
+	return &product, nil
+}
+
+// PublishDue publishes the unpublished products whose publish_at has
+// passed, recording a product.status_changed event and an audit entry for
+// each. It returns the number of products published.
+func (r *repository) PublishDue(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var due []*Product
+	query := `
+		SELECT * FROM products
+		WHERE publish_at <= NOW() AND status IN ('draft', 'pending_review')
+		ORDER BY publish_at
+		FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &due, query); err != nil {
+		return 0, fmt.Errorf("error listing products due for publishing: %w", err)
 	}
 
+	for _, before := range due {
+		var product Product
+		update := `UPDATE products SET status = 'published', publish_at = NULL, updated_at = NOW() WHERE id = $1 RETURNING *`
+		if err := tx.GetContext(ctx, &product, update, before.ID); err != nil {
+			return 0, fmt.Errorf("error publishing product %d: %w", before.ID, err)
+		}
+
+		payload := struct {
+			ID   int64  `json:"id"`
+			From Status `json:"from"`
+			To   Status `json:"to"`
+		}{ID: product.ID, From: before.Status, To: product.Status}
+		if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventStatusChanged, payload); err != nil {
+			return 0, err
+		}
+		if err := audit.Record(ctx, tx, AggregateType, product.ID, audit.ActionUpdate, before, &product); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing scheduled publishing: %w", err)
+	}
+	return len(due), nil
+}
+
+// CreatePriceChange schedules a price change for a product
+func (r *repository) CreatePriceChange(ctx context.Context, change *PriceChange) error {
+	query := `
+		INSERT INTO product_price_changes (product_id, price, effective_at)
+		VALUES ($1, $2, $3)
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, change.ProductID, change.Price, change.EffectiveAt).StructScan(change)
+	if err != nil {
+		return fmt.Errorf("error creating price change: %w", err)
+	}
+	return nil
+}
+
+// ListPriceChanges retrieves the scheduled and applied price changes of a
+// product, soonest first
+func (r *repository) ListPriceChanges(ctx context.Context, productID int64) ([]*PriceChange, error) {
+	changes := []*PriceChange{}
+	query := `SELECT * FROM product_price_changes WHERE product_id = $1 ORDER BY effective_at, id`
+	if err := r.db.SelectContext(ctx, &changes, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing price changes: %w", err)
+	}
+	return changes, nil
+}
+
+// DeletePriceChange cancels a price change that has not been applied yet
+func (r *repository) DeletePriceChange(ctx context.Context, productID, id int64) error {
+	query := `DELETE FROM product_price_changes WHERE id = $1 AND product_id = $2 AND applied_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id, productID)
+	if err != nil {
+		return fmt.Errorf("error deleting price change: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("price change not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ApplyDuePriceChanges applies the price changes whose effective_at has
+// passed, oldest first, recording a product.updated event and an audit
+// entry for each. It returns the number of changes applied.
+func (r *repository) ApplyDuePriceChanges(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var due []*PriceChange
+	query := `
+		SELECT * FROM product_price_changes
+		WHERE applied_at IS NULL AND effective_at <= NOW()
+		ORDER BY effective_at, id
+		FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &due, query); err != nil {
+		return 0, fmt.Errorf("error listing due price changes: %w", err)
+	}
+
+	for _, change := range due {
+		var before Product
+		if err := tx.GetContext(ctx, &before, `SELECT * FROM products WHERE id = $1 FOR UPDATE`, change.ProductID); err != nil {
+			return 0, fmt.Errorf("error getting product %d: %w", change.ProductID, err)
+		}
+
+		var product Product
+		update := `UPDATE products SET price = $1, updated_at = NOW() WHERE id = $2 RETURNING *`
+		if err := tx.GetContext(ctx, &product, update, change.Price, change.ProductID); err != nil {
+			return 0, fmt.Errorf("error applying price change %d: %w", change.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE product_price_changes SET applied_at = NOW() WHERE id = $1`, change.ID); err != nil {
+			return 0, fmt.Errorf("error marking price change %d applied: %w", change.ID, err)
+		}
+		if product.Price != before.Price {
+			if err := recordPrice(ctx, tx, product.ID, &before.Price, product.Price); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventProductUpdated, &product); err != nil {
+			return 0, err
+		}
+		if err := audit.Record(ctx, tx, AggregateType, product.ID, audit.ActionUpdate, &before, &product); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing price changes: %w", err)
+	}
+	return len(due), nil
+}
+
+// bulkPriceRow is a product changed by a bulk price update with the values
+// it replaced
+type bulkPriceRow struct {
+	Product
+	OldPrice     float64   `db:"old_price"`
+	OldUpdatedAt time.Time `db:"old_updated_at"`
+}
+
+// BulkUpdatePrices changes the matching prices and records their price
+// history in one statement, then records a product.updated event and an
+// audit entry for every changed product in the same transaction
+func (r *repository) BulkUpdatePrices(ctx context.Context, input BulkPriceInput) (*BulkPriceResult, error) {
+	newPrice := "ROUND(price + $1, 2)"
+	if input.Adjustment == AdjustPercent {
+		newPrice = "ROUND(price * (1 + $1 / 100.0), 2)"
+	}
+	matches := `($2::integer IS NULL OR store_id = $2)
+		AND ($3::text IS NULL OR EXISTS (SELECT 1 FROM unnest(categories) category WHERE lower(category) = lower($3)))
+		AND ($4::integer IS NULL OR vendor_id = $4)`
+	args := []interface{}{input.Value, tenant.StoreArg(ctx), input.Category, input.VendorID}
+
+	result := &BulkPriceResult{DryRun: input.DryRun}
+	if input.DryRun {
+		query := fmt.Sprintf(`
+			SELECT COUNT(*) FILTER (WHERE %[1]s > 0 AND %[1]s <> price), COUNT(*) FILTER (WHERE %[1]s <= 0)
+			FROM products WHERE %[2]s`, newPrice, matches)
+		if err := r.db.QueryRowxContext(ctx, query, args...).Scan(&result.Affected, &result.Skipped); err != nil {
+			return nil, fmt.Errorf("error counting bulk price update: %w", err)
+		}
+		return result, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	skipped := fmt.Sprintf(`SELECT COUNT(*) FROM products WHERE %s AND %s <= 0`, matches, newPrice)
+	if err := tx.GetContext(ctx, &result.Skipped, skipped, args...); err != nil {
+		return nil, fmt.Errorf("error counting skipped products: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT id, price, updated_at, %[1]s AS new_price FROM products
+			WHERE %[2]s
+			FOR UPDATE
+		), updated AS (
+			UPDATE products p SET price = m.new_price, updated_at = NOW()
+			FROM matched m
+			WHERE p.id = m.id AND m.new_price > 0 AND m.new_price <> m.price
+			RETURNING p.*, m.price AS old_price, m.updated_at AS old_updated_at
+		), history AS (
+			INSERT INTO price_history (product_id, old_price, new_price, actor)
+			SELECT id, old_price, price, $5 FROM updated
+		)
+		SELECT * FROM updated`, newPrice, matches)
+
+	var rows []*bulkPriceRow
+	if err := tx.SelectContext(ctx, &rows, query, append(args, audit.ActorFrom(ctx).Name)...); err != nil {
+		return nil, fmt.Errorf("error updating prices: %w", err)
+	}
+
+	for _, row := range rows {
+		product := row.Product
+		before := row.Product
+		before.Price = row.OldPrice
+		before.UpdatedAt = row.OldUpdatedAt
+
+		if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventProductUpdated, &product); err != nil {
+			return nil, err
+		}
+		if err := audit.Record(ctx, tx, AggregateType, product.ID, audit.ActionUpdate, &before, &product); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing bulk price update: %w", err)
+	}
+
+	result.Affected = len(rows)
+	return result, nil
+}
+
+// ListChanges retrieves up to limit products and tombstones whose sync
+// version is greater than sinceVersion, in version order
+func (r *repository) ListChanges(ctx context.Context, sinceVersion int64, limit int) (*ChangeBatch, error) {
+	changesQuery := `
+		SELECT id, sync_version, FALSE AS deleted FROM products
+		WHERE sync_version > $1 AND ($3::integer IS NULL OR store_id = $3)
+		UNION ALL
+		SELECT product_id, sync_version, TRUE AS deleted FROM product_tombstones
+		WHERE sync_version > $1 AND ($3::integer IS NULL OR store_id = $3)
+		ORDER BY sync_version
+		LIMIT $2`
+
+	var changes []struct {
+		ID          int64 `db:"id"`
+		SyncVersion int64 `db:"sync_version"`
+		Deleted     bool  `db:"deleted"`
+	}
+	// Fetch one extra row to find out whether another page follows
+	if err := r.db.SelectContext(ctx, &changes, changesQuery, sinceVersion, limit+1, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing product changes: %w", err)
+	}
+
+	batch := &ChangeBatch{
+		Products:    []*Product{},
+		Tombstones:  []*Tombstone{},
+		LastVersion: sinceVersion,
+	}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		batch.HasMore = true
+	}
+	if len(changes) == 0 {
+		return batch, nil
+	}
+	batch.LastVersion = changes[len(changes)-1].SyncVersion
+
+	var productIDs, deletedIDs []int64
+	for _, change := range changes {
+		if change.Deleted {
+			deletedIDs = append(deletedIDs, change.ID)
+		} else {
+			productIDs = append(productIDs, change.ID)
+		}
+	}
+
+	// Rows may have changed again since the first query; those are left
+	// for the next page, where their new version falls
+	if len(productIDs) > 0 {
+		query := `SELECT * FROM products WHERE id = ANY($1) AND sync_version > $2 AND sync_version <= $3 ORDER BY sync_version`
+		err := r.db.SelectContext(ctx, &batch.Products, query, pq.Array(productIDs), sinceVersion, batch.LastVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error loading changed products: %w", err)
+		}
+	}
+
+	if len(deletedIDs) > 0 {
+		query := `SELECT * FROM product_tombstones WHERE product_id = ANY($1) AND sync_version > $2 AND sync_version <= $3 ORDER BY sync_version`
+		err := r.db.SelectContext(ctx, &batch.Tombstones, query, pq.Array(deletedIDs), sinceVersion, batch.LastVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error loading product tombstones: %w", err)
+		}
+	}
+
+	return batch, nil
+}
+
+// DecrementStock removes quantity from a product's stock in a single
+// statement that also enforces the product's oversell policy, so concurrent
+// checkouts cannot oversell. Pre-orderable products accept any quantity. It returns ErrInsufficientStock when the
+// policy rejects the decrement. Selling a bundle decrements its components
+// instead.
+func (r *repository) DecrementStock(ctx context.Context, id int64, quantity int) (*Product, error) {
+	var isBundle bool
+	err := r.db.GetContext(ctx, &isBundle, `SELECT is_bundle FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`, id, tenant.StoreArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+	if isBundle {
+		return r.decrementBundle(ctx, id, quantity)
+	}
+
+	query := `
+		UPDATE products SET stock_quantity = stock_quantity - $1, updated_at = NOW()
+		WHERE id = $2 AND ($3::integer IS NULL OR store_id = $3) AND (
+			oversell_policy = 'allow_backorder'
+			OR preorder_available_at IS NOT NULL
+			OR (oversell_policy = 'allow_up_to' AND stock_quantity - $1 >= -oversell_limit)
+			OR stock_quantity >= $1
+		)
+		RETURNING *`
+
+	product, err := r.changeStock(ctx, query, id, quantity, -quantity)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2))`, id, tenant.StoreArg(ctx)); err != nil {
+			return nil, fmt.Errorf("error checking product: %w", err)
+		}
+		if exists {
+			return nil, ErrInsufficientStock
+		}
+	}
+	return product, err
+}
+
+// IncrementStock adds quantity to a product's stock. When the restock
+// brings a pre-orderable product's stock above zero, the product stops
+// being pre-orderable and a product.preorder_released event is recorded so
+// the backordered units can be fulfilled.
+func (r *repository) IncrementStock(ctx context.Context, id int64, quantity int) (*Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Product
+	scoped := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+
+	var product Product
+	query := `
+		UPDATE products SET
+			stock_quantity = stock_quantity + $1,
+			preorder_available_at = CASE WHEN stock_quantity + $1 > 0 THEN NULL ELSE preorder_available_at END,
+			updated_at = NOW()
+		WHERE id = $2
+		RETURNING *`
+	if err := tx.GetContext(ctx, &product, query, quantity, id); err != nil {
+		return nil, fmt.Errorf("error changing stock: %w", err)
+	}
+
+	if err := recordStockChange(ctx, tx, &product, quantity); err != nil {
+		return nil, err
+	}
+	if err := recordPreorderRelease(ctx, tx, &before, &product); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing stock change: %w", err)
+	}
+
+	return &product, nil
+}
+
+// SetStockLevels sets absolute stock levels, so a sync that is sent twice
+// changes nothing the second time. Products are locked in ID order, so
+// overlapping syncs and sales cannot deadlock. Unknown SKUs and bundles,
+// which have no stock of their own, are rejected.
+func (r *repository) SetStockLevels(ctx context.Context, levels []StockLevel) (*StockLevelResult, error) {
+	result := &StockLevelResult{Rejected: make(map[string]error)}
+	if len(levels) == 0 {
+		return result, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	skus := make([]string, len(levels))
+	for i, level := range levels {
+		skus[i] = strings.ToLower(level.SKU)
+	}
+	var locked []Product
+	query := `SELECT * FROM products WHERE store_id = $1 AND lower(sku) = ANY($2) ORDER BY id FOR UPDATE`
+	if err := tx.SelectContext(ctx, &locked, query, tenant.StoreIDOrDefault(ctx), pq.Array(skus)); err != nil {
+		return nil, fmt.Errorf("error locking products: %w", err)
+	}
+	bySKU := make(map[string]*Product, len(locked))
+	for i := range locked {
+		bySKU[strings.ToLower(*locked[i].SKU)] = &locked[i]
+	}
+
+	for i, level := range levels {
+		before, ok := bySKU[skus[i]]
+		switch {
+		case !ok:
+			result.Rejected[skus[i]] = ErrProductNotFound
+			continue
+		case before.IsBundle:
+			result.Rejected[skus[i]] = ErrBundleStock
+			continue
+		case before.StockQuantity == level.Quantity:
+			result.Unchanged++
+			continue
+		}
+
+		var product Product
+		query := `
+			UPDATE products SET
+				stock_quantity = $1,
+				preorder_available_at = CASE WHEN $1 > 0 THEN NULL ELSE preorder_available_at END,
+				updated_at = NOW()
+			WHERE id = $2
+			RETURNING *`
+		if err := tx.GetContext(ctx, &product, query, level.Quantity, before.ID); err != nil {
+			return nil, fmt.Errorf("error setting stock: %w", err)
+		}
+		if err := recordStockChange(ctx, tx, &product, level.Quantity-before.StockQuantity); err != nil {
+			return nil, err
+		}
+		if err := recordPreorderRelease(ctx, tx, before, &product); err != nil {
+			return nil, err
+		}
+		result.Changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing stock levels: %w", err)
+	}
+	return result, nil
+}
+
+// recordPreorderRelease records a product.preorder_released event when a
+// stock change ended the pre-order of product
+func recordPreorderRelease(ctx context.Context, tx *sqlx.Tx, before, product *Product) error {
+	if before.PreorderAvailableAt == nil || product.PreorderAvailableAt != nil {
+		return nil
+	}
+	released := PreorderReleased{
+		ID:            product.ID,
+		Name:          product.Name,
+		StockQuantity: product.StockQuantity,
+	}
+	if before.StockQuantity < 0 {
+		released.Backordered = -before.StockQuantity
+	}
+	return outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventPreorderReleased, released)
+}
+
+// changeStock runs a stock update query and records its events in the same
+// transaction
+func (r *repository) changeStock(ctx context.Context, query string, id int64, quantity, delta int) (*Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var product Product
+	if err := tx.GetContext(ctx, &product, query, quantity, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error changing stock: %w", err)
+	}
+
+	if err := recordStockChange(ctx, tx, &product, delta); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing stock change: %w", err)
+	}
+
+	return &product, nil
+}
+
+// recordStockChange records a product.stock_changed event for a change of
+// delta units, plus a product.low_stock event when the change takes stock
+// from above the product's threshold to at or below it and a
+// product.back_in_stock event when it takes stock from none to some
+func recordStockChange(ctx context.Context, tx *sqlx.Tx, product *Product, delta int) error {
+	payload := struct {
+		ID            int64 `json:"id"`
+		StockQuantity int   `json:"stock_quantity"`
+		Delta         int   `json:"delta"`
+	}{
+		ID:            product.ID,
+		StockQuantity: product.StockQuantity,
+		Delta:         delta,
+	}
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventStockChanged, payload); err != nil {
+		return err
+	}
+
+	previous := product.StockQuantity - delta
+	if previous > product.LowStockThreshold && product.StockQuantity <= product.LowStockThreshold {
+		alert := LowStockAlert{
+			ID:                product.ID,
+			Name:              product.Name,
+			StockQuantity:     product.StockQuantity,
+			LowStockThreshold: product.LowStockThreshold,
+		}
+		if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventLowStock, alert); err != nil {
+			return err
+		}
+	}
+	if previous <= 0 && product.StockQuantity > 0 {
+		restock := BackInStock{
+			ID:            product.ID,
+			Slug:          product.Slug,
+			Name:          product.Name,
+			StockQuantity: product.StockQuantity,
+		}
+		if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, product.ID, EventBackInStock, restock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decrementBundle sells quantity bundles by decrementing every component in
+// one transaction. Components are locked in ID order, so concurrent sales
+// of overlapping bundles cannot deadlock, and each component's oversell
+// policy is enforced. It returns ErrInsufficientStock when any component
+// falls short.
+func (r *repository) decrementBundle(ctx context.Context, id int64, quantity int) (*Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var bundle Product
+	if err := tx.GetContext(ctx, &bundle, `SELECT * FROM products WHERE id = $1 FOR UPDATE`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting bundle: %w", err)
+	}
+
+	var items []struct {
+		ComponentID int64 `db:"component_id"`
+		Quantity    int   `db:"quantity"`
+	}
+	query := `SELECT component_id, quantity FROM product_bundle_items WHERE bundle_id = $1 ORDER BY component_id`
+	if err := tx.SelectContext(ctx, &items, query, id); err != nil {
+		return nil, fmt.Errorf("error listing bundle components: %w", err)
+	}
+
+	decrement := `
+		UPDATE products SET stock_quantity = stock_quantity - $1, updated_at = NOW()
+		WHERE id = $2 AND (
+			oversell_policy = 'allow_backorder'
+			OR preorder_available_at IS NOT NULL
+			OR (oversell_policy = 'allow_up_to' AND stock_quantity - $1 >= -oversell_limit)
+			OR stock_quantity >= $1
+		)
+		RETURNING *`
+	for _, item := range items {
+		units := item.Quantity * quantity
+
+		var component Product
+		if err := tx.GetContext(ctx, &component, decrement, units, item.ComponentID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrInsufficientStock
+			}
+			return nil, fmt.Errorf("error decrementing bundle component %d: %w", item.ComponentID, err)
+		}
+		if err := recordStockChange(ctx, tx, &component, -units); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing bundle sale: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+// SetComponents replaces the components of a bundle and marks the product
+// as a bundle, or as a regular product when components is empty. Components
+// must be regular products of the bundle's store, and a product used in
+// another bundle cannot become one; ErrInvalidInput is returned otherwise.
+func (r *repository) SetComponents(ctx context.Context, id int64, components []BundleComponentInput) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Product
+	scoped := `SELECT * FROM products WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, scoped, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product not found: %w", err)
+		}
+		return fmt.Errorf("error getting product: %w", err)
+	}
+
+	if len(components) > 0 {
+		var inBundle bool
+		if err := tx.GetContext(ctx, &inBundle, `SELECT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1)`, id); err != nil {
+			return fmt.Errorf("error checking bundle components: %w", err)
+		}
+		if inBundle {
+			return ErrInvalidInput
+		}
+
+		ids := make([]int64, len(components))
+		for i, component := range components {
+			ids[i] = component.ProductID
+		}
+		var valid int
+		query := `SELECT COUNT(*) FROM products WHERE id = ANY($1) AND id <> $2 AND store_id = $3 AND NOT is_bundle`
+		if err := tx.GetContext(ctx, &valid, query, pq.Array(ids), id, before.StoreID); err != nil {
+			return fmt.Errorf("error checking bundle components: %w", err)
+		}
+		if valid != len(components) {
+			return ErrInvalidInput
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_bundle_items WHERE bundle_id = $1`, id); err != nil {
+		return fmt.Errorf("error clearing bundle components: %w", err)
+	}
+	for _, component := range components {
+		query := `INSERT INTO product_bundle_items (bundle_id, component_id, quantity) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, id, component.ProductID, component.Quantity); err != nil {
+			return fmt.Errorf("error adding bundle component: %w", err)
+		}
+	}
+
+	var product Product
+	query := `UPDATE products SET is_bundle = $1, updated_at = NOW() WHERE id = $2 RETURNING *`
+	if err := tx.GetContext(ctx, &product, query, len(components) > 0, id); err != nil {
+		return fmt.Errorf("error updating bundle: %w", err)
+	}
+
+	if err := outbox.Record(ctx, tx, product.StoreID, AggregateType, id, EventProductUpdated, &product); err != nil {
+		return err
+	}
+	if err := audit.Record(ctx, tx, AggregateType, id, audit.ActionUpdate, &before, &product); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing bundle: %w", err)
+	}
+	return nil
+}
+
+// ListComponents retrieves the components of a bundle with their stock
+func (r *repository) ListComponents(ctx context.Context, id int64) ([]*BundleComponent, error) {
+	components := []*BundleComponent{}
+	query := `
+		SELECT p.id AS product_id, p.name, i.quantity, p.stock_quantity
+		FROM product_bundle_items i
+		JOIN products p ON p.id = i.component_id
+		WHERE i.bundle_id = $1
+		ORDER BY p.id`
+	if err := r.db.SelectContext(ctx, &components, query, id); err != nil {
+		return nil, fmt.Errorf("error listing bundle components: %w", err)
+	}
+	return components, nil
+}
+
+// recordPrice adds a price_history entry attributed to the actor in ctx
+func recordPrice(ctx context.Context, tx *sqlx.Tx, productID int64, oldPrice *float64, newPrice float64) error {
+	query := `INSERT INTO price_history (product_id, old_price, new_price, actor) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, query, productID, oldPrice, newPrice, audit.ActorFrom(ctx).Name); err != nil {
+		return fmt.Errorf("error recording price history: %w", err)
+	}
+	return nil
+}
+
+// ListPriceHistory retrieves up to limit price changes of a product, newest
+// first
+func (r *repository) ListPriceHistory(ctx context.Context, id int64, limit int) ([]*PriceHistoryEntry, error) {
+	history := []*PriceHistoryEntry{}
+	query := `SELECT * FROM price_history WHERE product_id = $1 ORDER BY changed_at DESC, id DESC LIMIT $2`
+	if err := r.selectPrepared(ctx, &history, query, id, limit); err != nil {
+		return nil, fmt.Errorf("error listing price history: %w", err)
+	}
+	return history, nil
+}
+
+// LowestPriceBefore returns the lowest price a product had during the 30
+// days before at, counting the price already in effect when that window
+// opened. It returns nil when no price was in effect before at.
+func (r *repository) LowestPriceBefore(ctx context.Context, id int64, at time.Time) (*float64, error) {
+	var lowest *float64
+	query := `
+		SELECT MIN(new_price) FROM price_history
+		WHERE product_id = $1 AND changed_at < $2 AND changed_at >= (
+			SELECT COALESCE(MAX(changed_at), '-infinity') FROM price_history
+			WHERE product_id = $1 AND changed_at <= $2 - INTERVAL '30 days'
+		)`
+	if err := r.getPrepared(ctx, &lowest, query, id, at); err != nil {
+		return nil, fmt.Errorf("error getting lowest price: %w", err)
+	}
+	return lowest, nil
+}
+
+// SetTranslation creates or replaces a product's translation in a locale
+func (r *repository) SetTranslation(ctx context.Context, translation *Translation) error {
+	query := `
+		INSERT INTO product_translations (product_id, locale, name, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, locale) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = NOW()
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, translation.ProductID, translation.Locale, translation.Name, translation.Description).StructScan(translation)
+	if err != nil {
+		return fmt.Errorf("error setting translation: %w", err)
+	}
+	return nil
+}
+
+// ListTranslations retrieves the translations of a product ordered by locale
+func (r *repository) ListTranslations(ctx context.Context, productID int64) ([]*Translation, error) {
+	translations := []*Translation{}
+	query := `SELECT * FROM product_translations WHERE product_id = $1 ORDER BY locale`
+	if err := r.db.SelectContext(ctx, &translations, query, productID); err != nil {
+		return nil, fmt.Errorf("error listing translations: %w", err)
+	}
+	return translations, nil
+}
+
+// DeleteTranslation removes a product's translation in a locale
+func (r *repository) DeleteTranslation(ctx context.Context, productID int64, locale string) error {
+	query := `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`
+	result, err := r.db.ExecContext(ctx, query, productID, locale)
+	if err != nil {
+		return fmt.Errorf("error deleting translation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("translation not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// TranslationsIn retrieves the translations of the given products in any of
+// the given locales
+func (r *repository) TranslationsIn(ctx context.Context, productIDs []int64, locales []string) ([]*Translation, error) {
+	translations := []*Translation{}
+	query := `SELECT * FROM product_translations WHERE product_id = ANY($1) AND locale = ANY($2)`
+	if err := r.selectPrepared(ctx, &translations, query, pq.Array(productIDs), pq.Array(locales)); err != nil {
+		return nil, fmt.Errorf("error getting translations: %w", err)
+	}
+	return translations, nil
+}
+
+// CategoriesNamed retrieves the categories with any of the given names,
+// ignoring case
+func (r *repository) CategoriesNamed(ctx context.Context, names []string) ([]*CategoryRef, error) {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	categories := []*CategoryRef{}
+	query := `
+		SELECT id, store_id, parent_id, name, slug FROM categories
+		WHERE lower(name) = ANY($1) AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.SelectContext(ctx, &categories, query, pq.Array(lowered), tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error getting categories: %w", err)
+	}
+	return categories, nil
+}
+
+// BrandsIn retrieves the brands with the given IDs
+func (r *repository) BrandsIn(ctx context.Context, ids []int64) ([]*BrandRef, error) {
+	brands := []*BrandRef{}
+	query := `SELECT id, name, slug FROM brands WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &brands, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("error getting brands: %w", err)
+	}
+	return brands, nil
+}
+
+// ListLowStock retrieves products at or below their low-stock threshold,
+// lowest stock first
+func (r *repository) ListLowStock(ctx context.Context, pagination PaginationParams) ([]*Product, int, error) {
+	var products []*Product
+	query := `
+		SELECT * FROM products
+		WHERE stock_quantity <= low_stock_threshold AND ($3::integer IS NULL OR store_id = $3)
+		ORDER BY stock_quantity, id
+		LIMIT $1 OFFSET $2`
+	err := r.db.SelectContext(ctx, &products, query, pagination.Limit, (pagination.Page-1)*pagination.Limit, tenant.StoreArg(ctx))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing low stock products: %w", err)
+	}
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM products WHERE stock_quantity <= low_stock_threshold AND ($1::integer IS NULL OR store_id = $1)`
+	if err := r.db.GetContext(ctx, &totalCount, countQuery, tenant.StoreArg(ctx)); err != nil {
+		return nil, 0, fmt.Errorf("error counting low stock products: %w", err)
+	}
+
+	return products, totalCount, nil
+}
+
+// ListRelated retrieves the published products most related to a product,
+// best first
+func (r *repository) ListRelated(ctx context.Context, id int64, limit int) ([]*Product, error) {
+	products := []*Product{}
+	query := `
+		SELECT p.* FROM related_products r
+		JOIN products p ON p.id = r.related_id
+		WHERE r.product_id = $1 AND p.status = 'published' AND ($3::integer IS NULL OR p.store_id = $3)
+		ORDER BY r.score DESC, p.id
+		LIMIT $2`
+	if err := r.selectPrepared(ctx, &products, query, id, limit, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing related products: %w", err)
+	}
+	return products, nil
+}
+
+// RefreshRelated rebuilds the related_products table, keeping the
+// perProduct best matches of every product. Products are scored by the
+// Jaccard similarity of their category sets; only products of the same
+// store are related.
+func (r *repository) RefreshRelated(ctx context.Context, perProduct int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM related_products`); err != nil {
+		return fmt.Errorf("error clearing related products: %w", err)
+	}
+
+	query := `
+		INSERT INTO related_products (product_id, related_id, score)
+		SELECT product_id, related_id, score FROM (
+			SELECT product_id, related_id, score,
+				ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY score DESC, related_id) AS rank
+			FROM (
+				SELECT a.id AS product_id, b.id AS related_id,
+					(SELECT COUNT(*) FROM (SELECT unnest(a.categories) INTERSECT SELECT unnest(b.categories)) shared)::float
+					/ (SELECT COUNT(*) FROM (SELECT unnest(a.categories) UNION SELECT unnest(b.categories)) combined) AS score
+				FROM products a
+				JOIN products b ON b.id <> a.id AND b.store_id = a.store_id AND b.categories && a.categories
+			) scored
+		) ranked
+		WHERE rank <= $1`
+
+	if _, err := tx.ExecContext(ctx, query, perProduct); err != nil {
+		return fmt.Errorf("error computing related products: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing related products: %w", err)
+	}
 	return nil
 }
@@ -3,53 +3,414 @@ package product
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/locale"
+	"github.com/dotslashbit/ecommerce-api/pkg/slug"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
 	"github.com/go-playground/validator"
 )
 
 var (
-	ErrProductNotFound = errors.New("product not found")
-	ErrInvalidInput    = errors.New("invalid input")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrForbidden         = errors.New("product belongs to another vendor")
+	ErrInvalidTransition = errors.New("invalid product status transition")
+	ErrAdminOnly         = errors.New("only admins can make this change")
+	ErrInBundle          = errors.New("product is a component of a bundle")
+	ErrBundleStock       = errors.New("bundles have no stock of their own")
+	ErrDuplicateSKU      = errors.New("sku already in use")
+	ErrDuplicateBarcode  = errors.New("barcode already in use")
+	ErrDuplicateSlug     = errors.New("slug already in use")
+	ErrUnknownBrand      = errors.New("brand not found in this store")
+	ErrSKURequired       = errors.New("upsert requires a sku")
+	ErrUnknownField      = errors.New("unknown field")
+	ErrUnknownInclude    = errors.New("unknown relation")
+
+	ErrPriceChangeNotFound = errors.New("price change not found")
+	ErrTranslationNotFound = errors.New("translation not found")
 )
 
+// JobRefreshRelated is the periodic job that rebuilds related products
+const JobRefreshRelated = "related_products_refresh"
+
+// JobApplySchedules is the periodic job that applies scheduled publishing
+// and price changes
+const JobApplySchedules = "catalog_schedules"
+
+// schedulerActor is recorded in the audit log for scheduled changes
+const schedulerActor = "scheduler"
+
+// MaxRelated is the number of related products kept per product
+const MaxRelated = 20
+
+// oversellsPrevented counts stock decrements rejected by an oversell policy
+var oversellsPrevented = expvar.NewInt("inventory_oversells_prevented")
+
+// PolicyViolationError is returned when a product breaks one or more
+// catalog policies.
+type PolicyViolationError struct {
+	Violations []string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return "catalog policy violation: " + strings.Join(e.Violations, "; ")
+}
+
+// DuplicateProductError is returned when a new product matches an existing
+// one by SKU, or by name and vendor.
+type DuplicateProductError struct {
+	Existing *Product
+	// Field is "sku" or "name"
+	Field string
+}
+
+func (e *DuplicateProductError) Error() string {
+	return fmt.Sprintf("product %d already has this %s", e.Existing.ID, e.Field)
+}
+
+// PolicyChecker validates a product against the catalog policies configured
+// by admins and returns a message for every violated policy.
+type PolicyChecker interface {
+	Check(ctx context.Context, product *Product) ([]string, error)
+}
+
 type Service interface {
+	// CreateProduct adds a product. It returns a *DuplicateProductError
+	// instead when the product already exists.
 	CreateProduct(ctx context.Context, input CreateProductInput) (*Product, error)
+	// UpsertProduct updates the product with the SKU of input, or creates
+	// it when there is none. created reports which happened.
+	UpsertProduct(ctx context.Context, input CreateProductInput) (product *Product, created bool, err error)
 	GetProductByID(ctx context.Context, id int64) (*Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*Product, error)
+	GetProductByBarcode(ctx context.Context, barcode string) (*Product, error)
+	// GetProductBySlug finds a product by its current slug or, through
+	// the redirect table, by one it had before. Callers compare the slugs
+	// to redirect to the current one.
+	GetProductBySlug(ctx context.Context, slug string) (*Product, error)
 	ListProducts(ctx context.Context, filter ProductFilter, pagination PaginationParams) ([]*Product, int, error)
 	UpdateProduct(ctx context.Context, id int64, input UpdateProductInput) error
 	DeleteProduct(ctx context.Context, id int64) error
+	// DuplicateProduct copies a product into a new draft
+	DuplicateProduct(ctx context.Context, id int64, input DuplicateInput) (*Product, error)
+	// ChangeStatus moves a product through the publishing workflow. Vendors
+	// may only submit their drafts for review and withdraw them.
+	ChangeStatus(ctx context.Context, id int64, input StatusChangeInput) (*Product, error)
+	SetSale(ctx context.Context, id int64, input SaleInput) (*Product, error)
+	SetBundle(ctx context.Context, id int64, input BundleInput) (*Bundle, error)
+	GetBundle(ctx context.Context, id int64) (*Bundle, error)
+	GetPriceHistory(ctx context.Context, id int64, limit int) ([]*PriceHistoryEntry, error)
+	// LowestPrice30d returns the reference price for a running sale or a
+	// price cut made in the last 30 days, and nil when there is neither.
+	LowestPrice30d(ctx context.Context, product *Product) (*float64, error)
+	EndSale(ctx context.Context, id int64) (*Product, error)
+	SyncProducts(ctx context.Context, cursor string, limit int) (*ProductChanges, error)
+	DecrementStock(ctx context.Context, id int64, input StockChangeInput) (*Product, error)
+	RestockProduct(ctx context.Context, id int64, input StockChangeInput) (*Product, error)
+	// SyncInventory sets the stock of products by SKU in batches, each in
+	// one transaction. Unknown SKUs and bundles fail on their own. Only
+	// admins may sync.
+	SyncInventory(ctx context.Context, input InventorySyncInput) (*InventorySyncResult, error)
+	SchedulePriceChange(ctx context.Context, id int64, input PriceChangeInput) (*PriceChange, error)
+	ListPriceChanges(ctx context.Context, id int64) ([]*PriceChange, error)
+	CancelPriceChange(ctx context.Context, id, changeID int64) error
+	// SetTranslation creates or replaces a product's translation. The
+	// default locale is not translated; it is the product's own content.
+	SetTranslation(ctx context.Context, id int64, locale string, input TranslationInput) (*Translation, error)
+	ListTranslations(ctx context.Context, id int64) ([]*Translation, error)
+	DeleteTranslation(ctx context.Context, id int64, locale string) error
+	// Localize replaces the name and description of products with their
+	// translation in the first of locales that has one. Products fall back
+	// to the default locale, which also ends the search when it is listed.
+	Localize(ctx context.Context, locales []string, products ...*Product) error
+	// Include loads the relations in include for products, with one query
+	// per relation.
+	Include(ctx context.Context, include Include, products ...*Product) error
+	// ImportProducts creates products in batches, each in one
+	// transaction. Rows that are invalid or duplicate an existing product
+	// fail on their own; a batch the database rejects fails as a whole.
+	// Only admins may import.
+	ImportProducts(ctx context.Context, inputs []CreateProductInput) (*ImportResult, error)
+	// BulkUpdatePrices adjusts the prices of the products matching a
+	// category or vendor. Only admins may make bulk changes.
+	BulkUpdatePrices(ctx context.Context, input BulkPriceInput) (*BulkPriceResult, error)
+	// ApplySchedules is the JobApplySchedules job handler.
+	ApplySchedules(ctx context.Context, payload json.RawMessage) error
+	ListLowStock(ctx context.Context, pagination PaginationParams) ([]*Product, int, error)
+	GetRelatedProducts(ctx context.Context, id int64, limit int) ([]*Product, error)
+	// RefreshRelated is the JobRefreshRelated job handler.
+	RefreshRelated(ctx context.Context, payload json.RawMessage) error
 }
 
 type service struct {
-	repo      Repository
-	validator *validator.Validate
+	repo              Repository
+	policies          PolicyChecker
+	lowStockThreshold int
+	defaultLocale     string
+	importBatchSize   int
+	validator         *validator.Validate
 }
 
-func NewService(repo Repository) Service {
+// NewService creates the product service. policies may be nil to skip
+// catalog policy enforcement. lowStockThreshold is the alert threshold given
+// to products created without one, and defaultLocale is the locale product
+// content is written in. Imports create up to importBatchSize products per
+// transaction.
+func NewService(repo Repository, policies PolicyChecker, lowStockThreshold int, defaultLocale string, importBatchSize int) Service {
+	defaultLocale, _ = locale.Normalize(defaultLocale)
+	if importBatchSize < 1 {
+		importBatchSize = 1
+	}
 	return &service{
-		repo:      repo,
-		validator: validator.New(),
+		repo:              repo,
+		policies:          policies,
+		lowStockThreshold: lowStockThreshold,
+		defaultLocale:     defaultLocale,
+		importBatchSize:   importBatchSize,
+		validator:         validator.New(),
 	}
 }
 
 func (s *service) CreateProduct(ctx context.Context, input CreateProductInput) (*Product, error) {
-	if err := s.validator.Struct(input); err != nil {
+	product, err := s.newProduct(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicate(ctx, product); err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicies(ctx, product); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// newProduct validates input and builds the product it creates
+func (s *service) newProduct(ctx context.Context, input CreateProductInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil || !input.Attributes.Valid() {
+		return nil, ErrInvalidInput
+	}
+	if !validCodes(input.SKU, input.Barcode) {
+		return nil, ErrInvalidInput
+	}
+	if input.Slug != "" && !slug.Valid(input.Slug) {
 		return nil, ErrInvalidInput
 	}
 
 	product := &Product{
+		StoreID:     tenant.StoreIDOrDefault(ctx),
+		SKU:         nonEmpty(input.SKU),
+		Barcode:     nonEmpty(input.Barcode),
+		Slug:        input.Slug,
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
 		Categories:  input.Categories,
+		Attributes:  input.Attributes,
+		BrandID:     input.BrandID,
+
+		StockQuantity:  input.StockQuantity,
+		OversellPolicy: input.OversellPolicy,
+		OversellLimit:  input.OversellLimit,
+
+		PreorderAvailableAt: input.PreorderAvailableAt,
+	}
+	// Vendor products start as drafts and are published after review
+	product.Status = StatusPublished
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil {
+		product.VendorID = key.VendorID
+		product.Status = StatusDraft
 	}
+	if input.PublishAt != nil {
+		if err := s.checkPublishAt(ctx, *input.PublishAt); err != nil {
+			return nil, err
+		}
+		product.Status = StatusDraft
+		product.PublishAt = input.PublishAt
+	}
+	if product.OversellPolicy == "" {
+		product.OversellPolicy = OversellStrict
+	}
+	product.LowStockThreshold = s.lowStockThreshold
+	if input.LowStockThreshold != nil {
+		product.LowStockThreshold = *input.LowStockThreshold
+	}
+	return product, nil
+}
 
-	if err := s.repo.Create(ctx, product); err != nil {
-		return nil, err
+func (s *service) ImportProducts(ctx context.Context, inputs []CreateProductInput) (*ImportResult, error) {
+	if len(inputs) == 0 || len(inputs) > MaxImportRows {
+		return nil, ErrInvalidInput
+	}
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil {
+		return nil, ErrAdminOnly
 	}
 
-	return product, nil
+	result := &ImportResult{Failed: []ImportFailure{}}
+	fail := func(index int, err error) {
+		result.Failed = append(result.Failed, ImportFailure{Index: index, Error: err.Error()})
+	}
+
+	// Codes and slugs repeated within the import would fail a whole batch
+	// on the unique indexes, so the later rows fail on their own instead
+	seen := make(map[string]int)
+	repeated := func(index int, field, value string) bool {
+		key := field + ":" + strings.ToLower(value)
+		if first, ok := seen[key]; ok {
+			fail(index, fmt.Errorf("%s already used at index %d", field, first))
+			return true
+		}
+		seen[key] = index
+		return false
+	}
+
+	var products []*Product
+	var indexes []int
+	for i, input := range inputs {
+		product, err := s.newProduct(ctx, input)
+		if err == nil {
+			err = s.checkDuplicate(ctx, product)
+		}
+		if err == nil {
+			err = s.checkPolicies(ctx, product)
+		}
+		if err != nil {
+			if !importFailure(err) {
+				return nil, err
+			}
+			fail(i, err)
+			continue
+		}
+		if (product.SKU != nil && repeated(i, "sku", *product.SKU)) ||
+			(product.Barcode != nil && repeated(i, "barcode", *product.Barcode)) ||
+			(product.Slug != "" && repeated(i, "slug", product.Slug)) {
+			continue
+		}
+		products = append(products, product)
+		indexes = append(indexes, i)
+	}
+
+	for start := 0; start < len(products); start += s.importBatchSize {
+		end := min(start+s.importBatchSize, len(products))
+		if err := s.repo.CreateBatch(ctx, products[start:end]); err != nil {
+			if !importFailure(err) {
+				return nil, err
+			}
+			for _, index := range indexes[start:end] {
+				fail(index, fmt.Errorf("batch rejected: %w", err))
+			}
+			continue
+		}
+		result.Created += end - start
+	}
+
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+	return result, nil
+}
+
+// importFailure reports whether err rejects a row or batch of an import,
+// rather than stopping it
+func importFailure(err error) bool {
+	var violation *PolicyViolationError
+	var duplicate *DuplicateProductError
+	switch {
+	case errors.As(err, &violation), errors.As(err, &duplicate):
+		return true
+	}
+	switch err {
+	case ErrInvalidInput, ErrUnknownBrand, ErrDuplicateSKU, ErrDuplicateBarcode, ErrDuplicateSlug:
+		return true
+	}
+	return false
+}
+
+// checkDuplicate looks for an existing product with the SKU of product, or
+// with its name from the same vendor. Products that both carry SKUs are
+// told apart by them, so only a product without one is matched by name.
+// The name check is not locked against concurrent creates.
+func (s *service) checkDuplicate(ctx context.Context, product *Product) error {
+	if product.SKU != nil {
+		existing, err := s.repo.GetBySKU(ctx, *product.SKU)
+		if err == nil {
+			return &DuplicateProductError{Existing: existing, Field: "sku"}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	existing, err := s.repo.FindByName(ctx, product.Name, product.VendorID, product.SKU != nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return &DuplicateProductError{Existing: existing, Field: "name"}
+}
+
+func (s *service) UpsertProduct(ctx context.Context, input CreateProductInput) (*Product, bool, error) {
+	if input.SKU == nil || *input.SKU == "" {
+		return nil, false, ErrSKURequired
+	}
+	existing, err := s.GetProductBySKU(ctx, *input.SKU)
+	if err == ErrProductNotFound {
+		product, err := s.CreateProduct(ctx, input)
+		return product, err == nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Stock is left alone; it moves through the inventory endpoints
+	update := UpdateProductInput{
+		Barcode:             input.Barcode,
+		Name:                &input.Name,
+		Description:         &input.Description,
+		Price:               &input.Price,
+		Categories:          &input.Categories,
+		Attributes:          &input.Attributes,
+		BrandID:             input.BrandID,
+		LowStockThreshold:   input.LowStockThreshold,
+		PublishAt:           input.PublishAt,
+		PreorderAvailableAt: input.PreorderAvailableAt,
+	}
+	if input.Categories == nil {
+		update.Categories = &[]string{}
+	}
+	if input.Attributes == nil {
+		update.Attributes = &Attributes{}
+	}
+	if input.Slug != "" {
+		update.Slug = &input.Slug
+	}
+	if input.OversellPolicy != "" {
+		update.OversellPolicy = &input.OversellPolicy
+		update.OversellLimit = &input.OversellLimit
+	}
+
+	if err := s.UpdateProduct(ctx, existing.ID, update); err != nil {
+		return nil, false, err
+	}
+	product, err := s.GetProductByID(ctx, existing.ID)
+	return product, false, err
 }
 
 func (s *service) GetProductByID(ctx context.Context, id int64) (*Product, error) {
@@ -63,6 +424,64 @@ func (s *service) GetProductByID(ctx context.Context, id int64) (*Product, error
 	return product, nil
 }
 
+func (s *service) GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	product, err := s.repo.GetBySKU(ctx, sku)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *service) GetProductByBarcode(ctx context.Context, barcode string) (*Product, error) {
+	product, err := s.repo.GetByBarcode(ctx, barcode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *service) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	product, err := s.repo.GetBySlug(ctx, slug)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return product, err
+	}
+
+	id, err := s.repo.ResolveSlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return s.GetProductByID(ctx, id)
+}
+
+// validCodes reports whether the SKU and barcode given on a write are well
+// formed. Empty values are accepted; they leave a new product without one
+// and clear it on update.
+func validCodes(sku, barcode *string) bool {
+	if sku != nil && *sku != "" && !ValidSKU(*sku) {
+		return false
+	}
+	if barcode != nil && *barcode != "" && !ValidBarcode(*barcode) {
+		return false
+	}
+	return true
+}
+
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
 func (s *service) ListProducts(ctx context.Context, filter ProductFilter, pagination PaginationParams) ([]*Product, int, error) {
 	if err := s.validator.Struct(pagination); err != nil {
 		return nil, 0, ErrInvalidInput
@@ -75,6 +494,41 @@ func (s *service) UpdateProduct(ctx context.Context, id int64, input UpdateProdu
 	if err := s.validator.Struct(input); err != nil {
 		return ErrInvalidInput
 	}
+	if input.Attributes != nil && !input.Attributes.Valid() {
+		return ErrInvalidInput
+	}
+	if !validCodes(input.SKU, input.Barcode) {
+		return ErrInvalidInput
+	}
+	if input.Slug != nil && !slug.Valid(*input.Slug) {
+		return ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return err
+	}
+	if input.PublishAt != nil {
+		if err := s.checkPublishAt(ctx, *input.PublishAt); err != nil {
+			return err
+		}
+		product, err := s.GetProductByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product.Status == StatusPublished || product.Status == StatusArchived {
+			return ErrInvalidTransition
+		}
+	}
+
+	if s.policies != nil {
+		product, err := s.GetProductByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		applyUpdate(product, input)
+		if err := s.checkPolicies(ctx, product); err != nil {
+			return err
+		}
+	}
 
 	err := s.repo.Update(ctx, id, input)
 	if err != nil {
@@ -88,6 +542,10 @@ func (s *service) UpdateProduct(ctx context.Context, id int64, input UpdateProdu
 }
 
 func (s *service) DeleteProduct(ctx context.Context, id int64) error {
+	if err := s.authorize(ctx, id); err != nil {
+		return err
+	}
+
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -98,3 +556,731 @@ func (s *service) DeleteProduct(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+func (s *service) DuplicateProduct(ctx context.Context, id int64, input DuplicateInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+	source, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &Product{
+		StoreID:           source.StoreID,
+		VendorID:          source.VendorID,
+		BrandID:           source.BrandID,
+		Name:              source.Name + " (copy)",
+		Description:       source.Description,
+		Price:             source.Price,
+		Categories:        source.Categories,
+		Attributes:        Attributes{},
+		Status:            StatusDraft,
+		OversellPolicy:    source.OversellPolicy,
+		OversellLimit:     source.OversellLimit,
+		LowStockThreshold: source.LowStockThreshold,
+	}
+	if input.Name != nil {
+		product.Name = *input.Name
+	}
+	components := false
+	for _, part := range input.Include {
+		switch part {
+		case DuplicateAttributes:
+			product.Attributes = source.Attributes
+		case DuplicateComponents:
+			components = source.IsBundle
+			product.IsBundle = source.IsBundle
+		}
+	}
+
+	if err := s.checkPolicies(ctx, product); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Duplicate(ctx, source.ID, product, components); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *service) ChangeStatus(ctx context.Context, id int64, input StatusChangeInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Status.CanTransition(input.Status) {
+		return nil, ErrInvalidTransition
+	}
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil && !product.Status.VendorCanTransition(input.Status) {
+		return nil, ErrAdminOnly
+	}
+
+	updated, err := s.repo.SetStatus(ctx, id, product.Status, input.Status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another request changed the status first
+			return nil, ErrInvalidTransition
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetSale starts or replaces a product's sale. The sale price must be below
+// the regular price and the sale must end in the future.
+func (s *service) SetSale(ctx context.Context, id int64, input SaleInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if !input.EndsAt.After(input.StartsAt) || !input.EndsAt.After(time.Now()) {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.SalePrice >= product.Price {
+		return nil, ErrInvalidInput
+	}
+
+	return s.setSale(ctx, id, &input)
+}
+
+func (s *service) EndSale(ctx context.Context, id int64) (*Product, error) {
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.setSale(ctx, id, nil)
+}
+
+func (s *service) setSale(ctx context.Context, id int64, input *SaleInput) (*Product, error) {
+	product, err := s.repo.SetSale(ctx, id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *service) SetBundle(ctx context.Context, id int64, input BundleInput) (*Bundle, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	seen := make(map[int64]bool, len(input.Components))
+	for _, component := range input.Components {
+		if seen[component.ProductID] {
+			return nil, ErrInvalidInput
+		}
+		seen[component.ProductID] = true
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetComponents(ctx, id, input.Components); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return s.GetBundle(ctx, id)
+}
+
+func (s *service) GetBundle(ctx context.Context, id int64) (*Bundle, error) {
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	components, err := s.repo.ListComponents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{ProductID: id, Components: components}
+	for i, component := range components {
+		available := component.StockQuantity / component.Quantity
+		if i == 0 || available < bundle.Available {
+			bundle.Available = available
+		}
+	}
+	if bundle.Available < 0 {
+		bundle.Available = 0
+	}
+	return bundle, nil
+}
+
+func (s *service) GetPriceHistory(ctx context.Context, id int64, limit int) ([]*PriceHistoryEntry, error) {
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPriceHistory(ctx, id, limit)
+}
+
+func (s *service) LowestPrice30d(ctx context.Context, product *Product) (*float64, error) {
+	now := time.Now()
+	if product.SalePrice != nil && product.SaleStartsAt != nil && product.SaleEndsAt != nil &&
+		!now.Before(*product.SaleStartsAt) && now.Before(*product.SaleEndsAt) {
+		return s.repo.LowestPriceBefore(ctx, product.ID, *product.SaleStartsAt)
+	}
+
+	latest, err := s.repo.ListPriceHistory(ctx, product.ID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	change := latest[0]
+	if change.OldPrice == nil || change.NewPrice >= *change.OldPrice || change.ChangedAt.Before(now.AddDate(0, 0, -30)) {
+		return nil, nil
+	}
+	return s.repo.LowestPriceBefore(ctx, product.ID, change.ChangedAt)
+}
+
+func (s *service) SchedulePriceChange(ctx context.Context, id int64, input PriceChangeInput) (*PriceChange, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if !input.EffectiveAt.After(time.Now()) {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	product.Price = input.Price
+	if err := s.checkPolicies(ctx, product); err != nil {
+		return nil, err
+	}
+
+	change := &PriceChange{
+		ProductID:   id,
+		Price:       input.Price,
+		EffectiveAt: input.EffectiveAt,
+	}
+	if err := s.repo.CreatePriceChange(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *service) ListPriceChanges(ctx context.Context, id int64) ([]*PriceChange, error) {
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPriceChanges(ctx, id)
+}
+
+func (s *service) CancelPriceChange(ctx context.Context, id, changeID int64) error {
+	if err := s.authorize(ctx, id); err != nil {
+		return err
+	}
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePriceChange(ctx, id, changeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPriceChangeNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) SetTranslation(ctx context.Context, id int64, tag string, input TranslationInput) (*Translation, error) {
+	tag, ok := locale.Normalize(tag)
+	if !ok || tag == s.defaultLocale {
+		return nil, ErrInvalidInput
+	}
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	translation := &Translation{
+		ProductID:   id,
+		Locale:      tag,
+		Name:        input.Name,
+		Description: input.Description,
+	}
+	if err := s.repo.SetTranslation(ctx, translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+func (s *service) ListTranslations(ctx context.Context, id int64) ([]*Translation, error) {
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTranslations(ctx, id)
+}
+
+func (s *service) DeleteTranslation(ctx context.Context, id int64, tag string) error {
+	tag, ok := locale.Normalize(tag)
+	if !ok {
+		return ErrTranslationNotFound
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return err
+	}
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTranslation(ctx, id, tag); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTranslationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) Localize(ctx context.Context, locales []string, products ...*Product) error {
+	for _, product := range products {
+		product.Locale = s.defaultLocale
+	}
+
+	// Only the locales preferred over the default locale are looked up
+	var wanted []string
+	for _, tag := range locales {
+		if tag == s.defaultLocale {
+			break
+		}
+		wanted = append(wanted, tag)
+	}
+	if len(wanted) == 0 || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	translations, err := s.repo.TranslationsIn(ctx, ids, wanted)
+	if err != nil {
+		return err
+	}
+
+	byProduct := make(map[int64]map[string]*Translation)
+	for _, t := range translations {
+		if byProduct[t.ProductID] == nil {
+			byProduct[t.ProductID] = make(map[string]*Translation)
+		}
+		byProduct[t.ProductID][t.Locale] = t
+	}
+	for _, product := range products {
+		for _, tag := range wanted {
+			if t, ok := byProduct[product.ID][tag]; ok {
+				product.Name = t.Name
+				product.Description = t.Description
+				product.Locale = t.Locale
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) Include(ctx context.Context, include Include, products ...*Product) error {
+	if len(include) == 0 || len(products) == 0 {
+		return nil
+	}
+	for _, product := range products {
+		product.Included = &Included{}
+	}
+
+	if include.Has(IncludeCategories) {
+		var names []string
+		for _, product := range products {
+			names = append(names, product.Categories...)
+		}
+		categories, err := s.repo.CategoriesNamed(ctx, names)
+		if err != nil {
+			return err
+		}
+
+		type key struct {
+			storeID int64
+			name    string
+		}
+		byName := make(map[key]*CategoryRef, len(categories))
+		for _, c := range categories {
+			byName[key{c.StoreID, strings.ToLower(c.Name)}] = c
+		}
+		for _, product := range products {
+			for _, name := range product.Categories {
+				if c, ok := byName[key{product.StoreID, strings.ToLower(name)}]; ok {
+					product.Included.Categories = append(product.Included.Categories, c)
+				}
+			}
+		}
+	}
+
+	if include.Has(IncludeBrand) {
+		var ids []int64
+		for _, product := range products {
+			if product.BrandID != nil {
+				ids = append(ids, *product.BrandID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		brands, err := s.repo.BrandsIn(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int64]*BrandRef, len(brands))
+		for _, b := range brands {
+			byID[b.ID] = b
+		}
+		for _, product := range products {
+			if product.BrandID != nil {
+				product.Included.Brand = byID[*product.BrandID]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) BulkUpdatePrices(ctx context.Context, input BulkPriceInput) (*BulkPriceResult, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if (input.Category == nil || *input.Category == "") && input.VendorID == nil {
+		return nil, ErrInvalidInput
+	}
+	if input.Adjustment == AdjustPercent && input.Value <= -100 {
+		return nil, ErrInvalidInput
+	}
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil {
+		return nil, ErrAdminOnly
+	}
+	if input.Category != nil && *input.Category == "" {
+		input.Category = nil
+	}
+
+	return s.repo.BulkUpdatePrices(ctx, input)
+}
+
+func (s *service) ApplySchedules(ctx context.Context, _ json.RawMessage) error {
+	ctx = audit.WithActor(ctx, audit.Actor{Name: schedulerActor})
+
+	if _, err := s.repo.PublishDue(ctx); err != nil {
+		return err
+	}
+	_, err := s.repo.ApplyDuePriceChanges(ctx)
+	return err
+}
+
+// checkPublishAt allows only admins to schedule publishing, and only in the
+// future. Vendors submit their products for review instead.
+func (s *service) checkPublishAt(ctx context.Context, publishAt time.Time) error {
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil {
+		return ErrAdminOnly
+	}
+	if !publishAt.After(time.Now()) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// DecrementStock is the single path through which sales remove stock.
+func (s *service) DecrementStock(ctx context.Context, id int64, input StockChangeInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.DecrementStock(ctx, id, input.Quantity)
+	if err != nil {
+		if errors.Is(err, ErrInsufficientStock) {
+			oversellsPrevented.Add(1)
+			return nil, ErrInsufficientStock
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *service) RestockProduct(ctx context.Context, id int64, input StockChangeInput) (*Product, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := s.authorize(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// Bundles have no stock of their own; restock their components
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsBundle {
+		return nil, ErrInvalidInput
+	}
+
+	product, err = s.repo.IncrementStock(ctx, id, input.Quantity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *service) SyncInventory(ctx context.Context, input InventorySyncInput) (*InventorySyncResult, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if key := apikey.FromContext(ctx); key != nil && key.VendorID != nil {
+		return nil, ErrAdminOnly
+	}
+
+	result := &InventorySyncResult{Failed: []ImportFailure{}}
+
+	// A SKU repeated within the sync keeps its first level
+	seen := make(map[string]int)
+	var levels []StockLevel
+	var indexes []int
+	for i, level := range input.Items {
+		sku := strings.ToLower(level.SKU)
+		if first, ok := seen[sku]; ok {
+			result.Failed = append(result.Failed, ImportFailure{Index: i, Error: fmt.Sprintf("sku already used at index %d", first)})
+			continue
+		}
+		seen[sku] = i
+		levels = append(levels, level)
+		indexes = append(indexes, i)
+	}
+
+	for start := 0; start < len(levels); start += s.importBatchSize {
+		end := min(start+s.importBatchSize, len(levels))
+		batch, err := s.repo.SetStockLevels(ctx, levels[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result.Changed += batch.Changed
+		result.Unchanged += batch.Unchanged
+		for j, level := range levels[start:end] {
+			if err, ok := batch.Rejected[strings.ToLower(level.SKU)]; ok {
+				result.Failed = append(result.Failed, ImportFailure{Index: indexes[start+j], Error: err.Error()})
+			}
+		}
+	}
+
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+	return result, nil
+}
+
+// authorize lets a vendor API key change only its own vendor's products.
+// Requests not made with a vendor key are not restricted here.
+func (s *service) authorize(ctx context.Context, id int64) error {
+	key := apikey.FromContext(ctx)
+	if key == nil || key.VendorID == nil {
+		return nil
+	}
+
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if product.VendorID == nil || *product.VendorID != *key.VendorID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *service) ListLowStock(ctx context.Context, pagination PaginationParams) ([]*Product, int, error) {
+	if err := s.validator.Struct(pagination); err != nil {
+		return nil, 0, ErrInvalidInput
+	}
+
+	return s.repo.ListLowStock(ctx, pagination)
+}
+
+func (s *service) GetRelatedProducts(ctx context.Context, id int64, limit int) ([]*Product, error) {
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > MaxRelated {
+		return nil, ErrInvalidInput
+	}
+
+	return s.repo.ListRelated(ctx, id, limit)
+}
+
+func (s *service) RefreshRelated(ctx context.Context, _ json.RawMessage) error {
+	return s.repo.RefreshRelated(ctx, MaxRelated)
+}
+
+// SyncProducts returns the catalog changes after cursor. An empty cursor
+// starts a full sync from the beginning of the catalog. Products the
+// caller may not see are sent as deleted.
+func (s *service) SyncProducts(ctx context.Context, cursor string, limit int) (*ProductChanges, error) {
+	since, err := decodeSyncCursor(cursor)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	batch, err := s.repo.ListChanges(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &ProductChanges{
+		Created:    []*Product{},
+		Updated:    []*Product{},
+		Deleted:    batch.Tombstones,
+		NextCursor: encodeSyncCursor(batch.LastVersion),
+		HasMore:    batch.HasMore,
+	}
+	for _, product := range batch.Products {
+		// Unpublished products leave the feed of callers who may not see
+		// them, as if deleted, so a product unpublished after a client
+		// copied it is removed there too
+		if !visible(ctx, product) {
+			changes.Deleted = append(changes.Deleted, &Tombstone{
+				ProductID:   product.ID,
+				StoreID:     product.StoreID,
+				SyncVersion: product.SyncVersion,
+				DeletedAt:   product.UpdatedAt,
+			})
+			continue
+		}
+		if product.CreatedVersion > since {
+			changes.Created = append(changes.Created, product)
+		} else {
+			changes.Updated = append(changes.Updated, product)
+		}
+	}
+
+	return changes, nil
+}
+
+const syncCursorPrefix = "v1:"
+
+func encodeSyncCursor(version int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncCursorPrefix + strconv.FormatInt(version, 10)))
+}
+
+func decodeSyncCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(string(raw), syncCursorPrefix) {
+		return 0, errors.New("unknown sync cursor version")
+	}
+
+	version, err := strconv.ParseInt(strings.TrimPrefix(string(raw), syncCursorPrefix), 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.New("malformed sync cursor")
+	}
+	return version, nil
+}
+
+func (s *service) checkPolicies(ctx context.Context, product *Product) error {
+	if s.policies == nil {
+		return nil
+	}
+
+	violations, err := s.policies.Check(ctx, product)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// applyUpdate copies the fields set in input onto product.
+func applyUpdate(product *Product, input UpdateProductInput) {
+	if input.SKU != nil {
+		product.SKU = nonEmpty(input.SKU)
+	}
+	if input.Barcode != nil {
+		product.Barcode = nonEmpty(input.Barcode)
+	}
+	if input.Slug != nil {
+		product.Slug = *input.Slug
+	}
+	if input.Name != nil {
+		product.Name = *input.Name
+	}
+	if input.Description != nil {
+		product.Description = *input.Description
+	}
+	if input.Price != nil {
+		product.Price = *input.Price
+	}
+	if input.Categories != nil {
+		product.Categories = *input.Categories
+	}
+	if input.Attributes != nil {
+		product.Attributes = *input.Attributes
+	}
+	if input.BrandID != nil {
+		product.BrandID = nil
+		if *input.BrandID != 0 {
+			product.BrandID = input.BrandID
+		}
+	}
+	if input.OversellPolicy != nil {
+		product.OversellPolicy = *input.OversellPolicy
+	}
+	if input.OversellLimit != nil {
+		product.OversellLimit = *input.OversellLimit
+	}
+	if input.LowStockThreshold != nil {
+		product.LowStockThreshold = *input.LowStockThreshold
+	}
+	if input.PublishAt != nil {
+		product.PublishAt = input.PublishAt
+	}
+	if input.PreorderAvailableAt != nil {
+		product.PreorderAvailableAt = input.PreorderAvailableAt
+	}
+}
@@ -0,0 +1,527 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/product/producttest"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/factory"
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// vendorContext authenticates as an API key of vendorID.
+func vendorContext(vendorID int64) context.Context {
+	return apikey.WithKey(context.Background(), &apikey.Key{
+		Scopes:   []string{apikey.ScopeCatalogWrite},
+		VendorID: &vendorID,
+	})
+}
+
+func TestCreateProduct(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		existing   []*product.Product
+		violations []string
+		input      product.CreateProductInput
+		wantErr    error
+		wantDup    string
+		wantStatus product.Status
+		wantVendor *int64
+	}{
+		{
+			name:       "creates published product",
+			input:      producttest.CreateInput(),
+			wantStatus: product.StatusPublished,
+		},
+		{
+			name:       "vendor products start as drafts",
+			ctx:        vendorContext(7),
+			input:      producttest.CreateInput(),
+			wantStatus: product.StatusDraft,
+			wantVendor: ptr(int64(7)),
+		},
+		{
+			name:    "rejects negative stock",
+			input:   producttest.CreateInput(func(in *product.CreateProductInput) { in.StockQuantity = -1 }),
+			wantErr: product.ErrInvalidInput,
+		},
+		{
+			name:    "rejects malformed sku",
+			input:   producttest.CreateInput(func(in *product.CreateProductInput) { in.SKU = ptr("no spaces") }),
+			wantErr: product.ErrInvalidInput,
+		},
+		{
+			name:    "rejects malformed slug",
+			input:   producttest.CreateInput(func(in *product.CreateProductInput) { in.Slug = "Trail Shoe" }),
+			wantErr: product.ErrInvalidInput,
+		},
+		{
+			name:     "rejects duplicate sku",
+			existing: []*product.Product{factory.Product(func(p *product.Product) { p.SKU = ptr("SHOE-1") })},
+			input:    producttest.CreateInput(func(in *product.CreateProductInput) { in.SKU = ptr("shoe-1") }),
+			wantDup:  "sku",
+		},
+		{
+			name:     "rejects duplicate name from the same vendor",
+			existing: []*product.Product{factory.Product(func(p *product.Product) { p.Name = "Trail Running Shoe" })},
+			input:    producttest.CreateInput(func(in *product.CreateProductInput) { in.Name = "trail running shoe" }),
+			wantDup:  "name",
+		},
+		{
+			name: "allows the name of another vendor's product",
+			ctx:  vendorContext(7),
+			existing: []*product.Product{factory.Product(func(p *product.Product) {
+				p.Name = "Trail Running Shoe"
+				p.VendorID = ptr(int64(8))
+			})},
+			input:      producttest.CreateInput(),
+			wantStatus: product.StatusDraft,
+			wantVendor: ptr(int64(7)),
+		},
+		{
+			name: "allows the same name with different skus",
+			existing: []*product.Product{factory.Product(func(p *product.Product) {
+				p.Name = "Trail Running Shoe"
+				p.SKU = ptr("SHOE-1")
+			})},
+			input:      producttest.CreateInput(func(in *product.CreateProductInput) { in.SKU = ptr("SHOE-2") }),
+			wantStatus: product.StatusPublished,
+		},
+		{
+			name:       "enforces catalog policies",
+			violations: []string{"description must be at least 50 characters"},
+			input:      producttest.CreateInput(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory.Reset()
+			catalog := producttest.NewCatalog(tt.existing...)
+			policies := &producttest.PolicyCheckerMock{
+				CheckFunc: func(context.Context, *product.Product) ([]string, error) {
+					return tt.violations, nil
+				},
+			}
+			service := product.NewService(catalog.Repository, policies, 5, "en", 1000)
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			created, err := service.CreateProduct(ctx, tt.input)
+
+			var violation *product.PolicyViolationError
+			var duplicate *product.DuplicateProductError
+			switch {
+			case tt.violations != nil:
+				if !errors.As(err, &violation) {
+					t.Fatalf("err = %v, want policy violation", err)
+				}
+			case tt.wantDup != "":
+				if !errors.As(err, &duplicate) {
+					t.Fatalf("err = %v, want duplicate", err)
+				}
+				if duplicate.Field != tt.wantDup || duplicate.Existing.ID != tt.existing[0].ID {
+					t.Errorf("duplicate = %s of %d, want %s of %d", duplicate.Field, duplicate.Existing.ID, tt.wantDup, tt.existing[0].ID)
+				}
+			case tt.wantErr != nil:
+				if err != tt.wantErr {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("CreateProduct: %v", err)
+				}
+				if created.Status != tt.wantStatus {
+					t.Errorf("status = %s, want %s", created.Status, tt.wantStatus)
+				}
+				if (created.VendorID == nil) != (tt.wantVendor == nil) || (tt.wantVendor != nil && *created.VendorID != *tt.wantVendor) {
+					t.Errorf("vendor = %v, want %v", created.VendorID, tt.wantVendor)
+				}
+				if created.OversellPolicy != product.OversellStrict || created.LowStockThreshold != 5 {
+					t.Errorf("defaults = %s/%d, want strict/5", created.OversellPolicy, created.LowStockThreshold)
+				}
+			}
+
+			wantCreates := 0
+			if err == nil {
+				wantCreates = 1
+			}
+			if got := len(catalog.Repository.CreateCalls()); got != wantCreates {
+				t.Errorf("Create called %d times, want %d", got, wantCreates)
+			}
+		})
+	}
+}
+
+func TestUpsertProduct(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       product.CreateProductInput
+		wantErr     error
+		wantCreated bool
+	}{
+		{
+			name:    "requires a sku",
+			input:   producttest.CreateInput(),
+			wantErr: product.ErrSKURequired,
+		},
+		{
+			name:        "creates unknown sku",
+			input:       producttest.CreateInput(func(in *product.CreateProductInput) { in.SKU = ptr("SHOE-2") }),
+			wantCreated: true,
+		},
+		{
+			name: "updates known sku",
+			input: producttest.CreateInput(func(in *product.CreateProductInput) {
+				in.SKU = ptr("SHOE-1")
+				in.Price = 24.99
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory.Reset()
+			existing := factory.Product(func(p *product.Product) { p.SKU = ptr("SHOE-1") })
+			catalog := producttest.NewCatalog(existing)
+			catalog.Repository.UpdateFunc = func(_ context.Context, id int64, input product.UpdateProductInput) error {
+				p := catalog.Get(id)
+				p.Name, p.Price = *input.Name, *input.Price
+				return nil
+			}
+			service := product.NewService(catalog.Repository, nil, 5, "en", 1000)
+
+			got, created, err := service.UpsertProduct(context.Background(), tt.input)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if got.Price != tt.input.Price {
+				t.Errorf("price = %v, want %v", got.Price, tt.input.Price)
+			}
+			if !created && (got.ID != existing.ID || len(catalog.Repository.UpdateCalls()) != 1) {
+				t.Errorf("upsert of known sku did not update product %d", existing.ID)
+			}
+		})
+	}
+}
+
+func TestGetProductBySlugFollowsRedirects(t *testing.T) {
+	factory.Reset()
+	catalog := producttest.NewCatalog(factory.Product(func(p *product.Product) { p.Slug = "trail-running-shoe" }))
+	catalog.Redirect("running-shoe", 1)
+	service := product.NewService(catalog.Repository, nil, 5, "en", 1000)
+
+	tests := []struct {
+		slug    string
+		wantErr error
+	}{
+		{slug: "trail-running-shoe"},
+		{slug: "running-shoe"},
+		{slug: "sandal", wantErr: product.ErrProductNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			got, err := service.GetProductBySlug(context.Background(), tt.slug)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Slug != "trail-running-shoe" {
+				t.Errorf("slug = %s, want the current slug", got.Slug)
+			}
+		})
+	}
+}
+
+func TestUpdateProductAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"admin", context.Background(), nil},
+		{"owning vendor", vendorContext(7), nil},
+		{"other vendor", vendorContext(8), product.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory.Reset()
+			catalog := producttest.NewCatalog(factory.Product(func(p *product.Product) { p.VendorID = ptr(int64(7)) }))
+			catalog.Repository.UpdateFunc = func(context.Context, int64, product.UpdateProductInput) error {
+				return nil
+			}
+			service := product.NewService(catalog.Repository, nil, 5, "en", 1000)
+
+			err := service.UpdateProduct(tt.ctx, 1, product.UpdateProductInput{Name: ptr("Trail Shoe")})
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			wantUpdates := 1
+			if tt.wantErr != nil {
+				wantUpdates = 0
+			}
+			if got := len(catalog.Repository.UpdateCalls()); got != wantUpdates {
+				t.Errorf("Update called %d times, want %d", got, wantUpdates)
+			}
+		})
+	}
+}
+
+func TestDecrementStockOversellPolicies(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    product.OversellPolicy
+		limit     int
+		quantity  int
+		wantErr   error
+		wantStock int
+	}{
+		{name: "strict in stock", policy: product.OversellStrict, quantity: 3, wantStock: 0},
+		{name: "strict short", policy: product.OversellStrict, quantity: 4, wantErr: product.ErrInsufficientStock},
+		{name: "backorder", policy: product.OversellBackorder, quantity: 10, wantStock: -7},
+		{name: "up to limit", policy: product.OversellUpTo, limit: 2, quantity: 5, wantStock: -2},
+		{name: "past limit", policy: product.OversellUpTo, limit: 2, quantity: 6, wantErr: product.ErrInsufficientStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := product.NewService(product.NewMemoryRepository(nil), nil, 5, "en", 1000)
+			created, err := service.CreateProduct(context.Background(), producttest.CreateInput(func(in *product.CreateProductInput) {
+				in.StockQuantity = 3
+				in.OversellPolicy = tt.policy
+				in.OversellLimit = tt.limit
+			}))
+			if err != nil {
+				t.Fatalf("CreateProduct: %v", err)
+			}
+
+			got, err := service.DecrementStock(context.Background(), created.ID, product.StockChangeInput{Quantity: tt.quantity})
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.StockQuantity != tt.wantStock {
+				t.Errorf("stock = %d, want %d", got.StockQuantity, tt.wantStock)
+			}
+		})
+	}
+}
+
+func TestUpdateProductSlugRedirects(t *testing.T) {
+	ctx := context.Background()
+	service := product.NewService(product.NewMemoryRepository(nil), nil, 5, "en", 1000)
+	created, err := service.CreateProduct(ctx, producttest.CreateInput())
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if err := service.UpdateProduct(ctx, created.ID, product.UpdateProductInput{Slug: ptr("trail-shoe")}); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+
+	got, err := service.GetProductBySlug(ctx, created.Slug)
+	if err != nil {
+		t.Fatalf("GetProductBySlug(%q): %v", created.Slug, err)
+	}
+	if got.ID != created.ID || got.Slug != "trail-shoe" {
+		t.Errorf("got product %d with slug %s, want %d with slug trail-shoe", got.ID, got.Slug, created.ID)
+	}
+
+	// Generated slugs skip the old slug, which keeps leading to the renamed
+	// product
+	second, err := service.CreateProduct(ctx, producttest.CreateInput(func(in *product.CreateProductInput) { in.Name = "Trail-Running Shoe" }))
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if second.Slug != created.Slug+"-2" {
+		t.Errorf("slug = %s, want %s-2", second.Slug, created.Slug)
+	}
+}
+
+func TestIncludeLoadsEachRelationOnce(t *testing.T) {
+	repo := &producttest.RepositoryMock{
+		CategoriesNamedFunc: func(_ context.Context, names []string) ([]*product.CategoryRef, error) {
+			return []*product.CategoryRef{{ID: 3, StoreID: 1, Name: "Footwear", Slug: "footwear"}}, nil
+		},
+		BrandsInFunc: func(_ context.Context, ids []int64) ([]*product.BrandRef, error) {
+			return []*product.BrandRef{{ID: 9, Name: "Summit", Slug: "summit"}}, nil
+		},
+	}
+	service := product.NewService(repo, nil, 5, "en", 1000)
+
+	products := factory.Products(3)
+	products[0].BrandID = ptr(int64(9))
+	for _, p := range products {
+		p.Categories = []string{"footwear", "outdoor"}
+	}
+	include := product.Include{product.IncludeCategories, product.IncludeBrand}
+	if err := service.Include(context.Background(), include, products...); err != nil {
+		t.Fatalf("Include: %v", err)
+	}
+
+	if calls := len(repo.CategoriesNamedCalls()); calls != 1 {
+		t.Errorf("CategoriesNamed called %d times, want 1", calls)
+	}
+	if calls := len(repo.BrandsInCalls()); calls != 1 {
+		t.Errorf("BrandsIn called %d times, want 1", calls)
+	}
+	for _, p := range products {
+		if len(p.Included.Categories) != 1 || p.Included.Categories[0].ID != 3 {
+			t.Errorf("product %d categories = %v, want footwear only", p.ID, p.Included.Categories)
+		}
+	}
+	if products[0].Included.Brand == nil || products[0].Included.Brand.ID != 9 {
+		t.Errorf("product 1 brand = %v, want summit", products[0].Included.Brand)
+	}
+	if products[1].Included.Brand != nil {
+		t.Errorf("product 2 brand = %v, want none", products[1].Included.Brand)
+	}
+}
+
+func TestImportProductsInBatches(t *testing.T) {
+	memory := product.NewMemoryRepository(nil)
+	repo := &producttest.RepositoryMock{
+		CreateBatchFunc: memory.CreateBatch,
+		GetBySKUFunc:    memory.GetBySKU,
+		FindByNameFunc:  memory.FindByName,
+	}
+	service := product.NewService(repo, nil, 5, "en", 2)
+
+	var inputs []product.CreateProductInput
+	for i, sku := range []string{"TRAIL-1", "TRAIL-2", "TRAIL-3", "trail-1", "TRAIL-5"} {
+		inputs = append(inputs, producttest.CreateInput(func(in *product.CreateProductInput) {
+			in.SKU = ptr(sku)
+			in.Name = fmt.Sprintf("Trail Shoe %d", i)
+		}))
+	}
+	inputs[4].StockQuantity = -1
+
+	result, err := service.ImportProducts(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("ImportProducts: %v", err)
+	}
+	if result.Created != 3 {
+		t.Errorf("created = %d, want 3", result.Created)
+	}
+	var failed []int
+	for _, failure := range result.Failed {
+		failed = append(failed, failure.Index)
+	}
+	if fmt.Sprint(failed) != "[3 4]" {
+		t.Errorf("failed rows = %v, want [3 4]", failed)
+	}
+	if calls := len(repo.CreateBatchCalls()); calls != 2 {
+		t.Errorf("CreateBatch called %d times, want 2", calls)
+	}
+}
+
+func TestSyncInventorySetsStockLevels(t *testing.T) {
+	memory := product.NewMemoryRepository(nil)
+	ctx := context.Background()
+	for _, p := range []*product.Product{
+		factory.Product(func(p *product.Product) { p.SKU = ptr("TRAIL-1"); p.StockQuantity = 3 }),
+		factory.Product(func(p *product.Product) { p.SKU = ptr("TRAIL-2"); p.StockQuantity = 5 }),
+		factory.Product(func(p *product.Product) { p.SKU = ptr("TRAIL-KIT"); p.IsBundle = true }),
+	} {
+		if err := memory.Create(ctx, p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	repo := &producttest.RepositoryMock{
+		SetStockLevelsFunc: memory.SetStockLevels,
+	}
+	service := product.NewService(repo, nil, 5, "en", 2)
+
+	result, err := service.SyncInventory(ctx, product.InventorySyncInput{Items: []product.StockLevel{
+		{SKU: "trail-1", Quantity: 10},
+		{SKU: "TRAIL-2", Quantity: 5},
+		{SKU: "TRAIL-1", Quantity: 1},
+		{SKU: "TRAIL-KIT", Quantity: 4},
+		{SKU: "ROAD-1", Quantity: 1},
+	}})
+	if err != nil {
+		t.Fatalf("SyncInventory: %v", err)
+	}
+	if result.Changed != 1 || result.Unchanged != 1 {
+		t.Errorf("changed, unchanged = %d, %d, want 1, 1", result.Changed, result.Unchanged)
+	}
+	var failed []int
+	for _, failure := range result.Failed {
+		failed = append(failed, failure.Index)
+	}
+	if fmt.Sprint(failed) != "[2 3 4]" {
+		t.Errorf("failed items = %v, want [2 3 4]", failed)
+	}
+	if calls := len(repo.SetStockLevelsCalls()); calls != 2 {
+		t.Errorf("SetStockLevels called %d times, want 2", calls)
+	}
+
+	got, err := memory.GetBySKU(ctx, "TRAIL-1")
+	if err != nil {
+		t.Fatalf("GetBySKU: %v", err)
+	}
+	if got.StockQuantity != 10 {
+		t.Errorf("stock = %d, want 10", got.StockQuantity)
+	}
+}
+
+func TestSyncProductsHidesUnpublished(t *testing.T) {
+	published := factory.Product()
+	draft := factory.Product(func(p *product.Product) {
+		p.Status = product.StatusDraft
+		p.VendorID = ptr(int64(7))
+	})
+	repo := &producttest.RepositoryMock{
+		ListChangesFunc: func(ctx context.Context, sinceVersion int64, limit int) (*product.ChangeBatch, error) {
+			return &product.ChangeBatch{Products: []*product.Product{published, draft}}, nil
+		},
+	}
+	service := product.NewService(repo, nil, 5, "en", 2)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		wantDeleted bool
+	}{
+		{"anonymous", context.Background(), true},
+		{"read key", apikey.WithKey(context.Background(), &apikey.Key{Scopes: []string{apikey.ScopeCatalogRead}}), true},
+		{"write key", apikey.WithKey(context.Background(), &apikey.Key{Scopes: []string{apikey.ScopeCatalogWrite}}), false},
+		{"owning vendor", vendorContext(7), false},
+		{"other vendor", vendorContext(8), true},
+		{"admin", apikey.WithAdmin(context.Background()), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := service.SyncProducts(tt.ctx, "", 10)
+			if err != nil {
+				t.Fatalf("SyncProducts: %v", err)
+			}
+
+			var deleted []int64
+			for _, tombstone := range changes.Deleted {
+				deleted = append(deleted, tombstone.ProductID)
+			}
+			listed := len(changes.Created) + len(changes.Updated)
+			if tt.wantDeleted {
+				if listed != 1 || fmt.Sprint(deleted) != fmt.Sprint([]int64{draft.ID}) {
+					t.Errorf("listed %d, deleted %v, want 1 listed and the draft deleted", listed, deleted)
+				}
+			} else if listed != 2 || len(deleted) != 0 {
+				t.Errorf("listed %d, deleted %v, want both listed", listed, deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package question
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+	keys    *apikey.Authenticator
+}
+
+// NewHandler creates the product Q&A handler. keys guards answering with
+// the catalog:write scope, always with a key so anonymous clients cannot
+// post answers that read as the store's own; nil leaves it open.
+func NewHandler(service Service, logger *zap.Logger, keys *apikey.Authenticator) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+		keys:    keys,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/products/:id/questions", h.Ask)
+	router.GET("/products/:id/questions", h.ListProductQuestions)
+	router.POST("/products/:id/questions/:question_id/answers", h.keys.RequireKey(apikey.ScopeCatalogWrite, h.Answer))
+
+	router.GET("/admin/questions", h.ListQuestions)
+	router.PUT("/admin/questions/:id/status", h.Moderate)
+}
+
+func (h *Handler) Ask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	productID, ok := h.parseProductID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input AskInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode question input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.service.Ask(r.Context(), productID, input)
+	if err != nil {
+		h.logger.Error("Failed to ask question", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(question)
+}
+
+func (h *Handler) ListProductQuestions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	productID, ok := h.parseProductID(w, ps)
+	if !ok {
+		return
+	}
+
+	pagination := paginationParams(r)
+	questions, totalCount, err := h.service.ListApproved(r.Context(), productID, pagination)
+	if err != nil {
+		h.logger.Error("Failed to list product questions", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+	response.List(w, r, questions, totalCount, pagination.Page, pagination.Limit)
+}
+
+func (h *Handler) Answer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	productID, ok := h.parseProductID(w, ps)
+	if !ok {
+		return
+	}
+	questionID, err := strconv.ParseInt(ps.ByName("question_id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid question ID", zap.Error(err))
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	var input AnswerInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode answer input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	answer, err := h.service.Answer(r.Context(), productID, questionID, input)
+	if err != nil {
+		h.logger.Error("Failed to answer question", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(answer)
+}
+
+// ListQuestions is the moderation queue, filtered by ?status and
+// ?product_id.
+func (h *Handler) ListQuestions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var filter QuestionFilter
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := Status(status)
+		filter.Status = &s
+	}
+	if productID := r.URL.Query().Get("product_id"); productID != "" {
+		id, err := strconv.ParseInt(productID, 10, 64)
+		if err == nil {
+			filter.ProductID = &id
+		}
+	}
+
+	pagination := paginationParams(r)
+	questions, totalCount, err := h.service.ListQuestions(r.Context(), filter, pagination)
+	if err != nil {
+		h.logger.Error("Failed to list questions", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+	response.List(w, r, questions, totalCount, pagination.Page, pagination.Limit)
+}
+
+func (h *Handler) Moderate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid question ID", zap.Error(err))
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	var input ModerateInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode moderation input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.service.Moderate(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to moderate question", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(question)
+}
+
+func (h *Handler) parseProductID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid product ID", zap.Error(err))
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func paginationParams(r *http.Request) PaginationParams {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	return PaginationParams{Page: page, Limit: limit}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case product.ErrProductNotFound, ErrQuestionNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrForbidden:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case ErrNotApproved:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
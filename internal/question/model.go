@@ -0,0 +1,63 @@
+package question
+
+import (
+	"time"
+)
+
+// Status is the moderation status of a question. Only approved questions
+// are shown on the storefront.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+type Question struct {
+	ID         int64     `db:"id" json:"id"`
+	StoreID    int64     `db:"store_id" json:"-"`
+	ProductID  int64     `db:"product_id" json:"product_id"`
+	AuthorName string    `db:"author_name" json:"author_name"`
+	Body       string    `db:"body" json:"body"`
+	Status     Status    `db:"status" json:"status"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+
+	Answers []*Answer `db:"-" json:"answers"`
+}
+
+// Answer is a reply from store staff or, when VendorID is set, from the
+// vendor selling the product. Actor records who wrote it and is not shown
+// publicly.
+type Answer struct {
+	ID         int64     `db:"id" json:"id"`
+	QuestionID int64     `db:"question_id" json:"question_id"`
+	VendorID   *int64    `db:"vendor_id" json:"vendor_id,omitempty"`
+	Body       string    `db:"body" json:"body"`
+	Actor      string    `db:"actor" json:"-"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type AskInput struct {
+	AuthorName string `json:"author_name" validate:"required,max=100"`
+	Body       string `json:"body" validate:"required,min=10,max=2000"`
+}
+
+type AnswerInput struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type ModerateInput struct {
+	Status Status `json:"status" validate:"required,oneof=approved rejected"`
+}
+
+type QuestionFilter struct {
+	ProductID *int64
+	Status    *Status
+}
+
+type PaginationParams struct {
+	Page  int `json:"page" validate:"required,min=1"`
+	Limit int `json:"limit" validate:"required,min=1,max=100"`
+}
@@ -0,0 +1,161 @@
+package question
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// entityType identifies questions in the audit log
+const entityType = "product_question"
+
+// Repository defines the interface for product question data operations
+type Repository interface {
+	Create(ctx context.Context, question *Question) error
+	GetByID(ctx context.Context, id int64) (*Question, error)
+	List(ctx context.Context, filter QuestionFilter, pagination PaginationParams) ([]*Question, int, error)
+	SetStatus(ctx context.Context, id int64, status Status) (*Question, error)
+	CreateAnswer(ctx context.Context, answer *Answer) error
+	// ListAnswers retrieves the answers to the given questions, oldest first
+	ListAnswers(ctx context.Context, questionIDs []int64) ([]*Answer, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository. Questions are
+// scoped to the store in the request context.
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new question in the store of its product
+func (r *repository) Create(ctx context.Context, question *Question) error {
+	query := `
+		INSERT INTO product_questions (store_id, product_id, author_name, body, status)
+		SELECT store_id, id, $2, $3, $4 FROM products WHERE id = $1
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, question.ProductID, question.AuthorName, question.Body, question.Status).StructScan(question)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product not found: %w", err)
+		}
+		return fmt.Errorf("error creating question: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single question by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*Question, error) {
+	var question Question
+	query := `SELECT * FROM product_questions WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	if err := r.db.GetContext(ctx, &question, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("question not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting question: %w", err)
+	}
+	return &question, nil
+}
+
+// List retrieves questions matching filter, newest first
+func (r *repository) List(ctx context.Context, filter QuestionFilter, pagination PaginationParams) ([]*Question, int, error) {
+	whereClause := []string{"($1::integer IS NULL OR store_id = $1)"}
+	args := []interface{}{tenant.StoreArg(ctx)}
+	argID := 2
+
+	if filter.ProductID != nil {
+		whereClause = append(whereClause, fmt.Sprintf("product_id = $%d", argID))
+		args = append(args, *filter.ProductID)
+		argID++
+	}
+	if filter.Status != nil {
+		whereClause = append(whereClause, fmt.Sprintf("status = $%d", argID))
+		args = append(args, *filter.Status)
+		argID++
+	}
+	where := strings.Join(whereClause, " AND ")
+
+	questions := []*Question{}
+	query := fmt.Sprintf(`
+		SELECT * FROM product_questions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, where, argID, argID+1)
+	listArgs := append(append([]interface{}{}, args...), pagination.Limit, (pagination.Page-1)*pagination.Limit)
+	if err := r.db.SelectContext(ctx, &questions, query, listArgs...); err != nil {
+		return nil, 0, fmt.Errorf("error listing questions: %w", err)
+	}
+
+	var totalCount int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM product_questions WHERE %s`, where)
+	if err := r.db.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("error counting questions: %w", err)
+	}
+
+	return questions, totalCount, nil
+}
+
+// SetStatus moderates a question and records the decision in the audit log
+func (r *repository) SetStatus(ctx context.Context, id int64, status Status) (*Question, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before Question
+	query := `SELECT * FROM product_questions WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2) FOR UPDATE`
+	if err := tx.GetContext(ctx, &before, query, id, tenant.StoreArg(ctx)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("question not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting question: %w", err)
+	}
+
+	var after Question
+	query = `UPDATE product_questions SET status = $2, updated_at = NOW() WHERE id = $1 RETURNING *`
+	if err := tx.GetContext(ctx, &after, query, id, status); err != nil {
+		return nil, fmt.Errorf("error moderating question: %w", err)
+	}
+
+	if err := audit.Record(ctx, tx, entityType, id, audit.ActionUpdate, &before, &after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing question: %w", err)
+	}
+	return &after, nil
+}
+
+// CreateAnswer adds an answer to a question
+func (r *repository) CreateAnswer(ctx context.Context, answer *Answer) error {
+	query := `
+		INSERT INTO product_answers (question_id, vendor_id, body, actor)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, answer.QuestionID, answer.VendorID, answer.Body, answer.Actor).StructScan(answer)
+	if err != nil {
+		return fmt.Errorf("error creating answer: %w", err)
+	}
+	return nil
+}
+
+// ListAnswers retrieves the answers to the given questions, oldest first
+func (r *repository) ListAnswers(ctx context.Context, questionIDs []int64) ([]*Answer, error) {
+	answers := []*Answer{}
+	query := `SELECT * FROM product_answers WHERE question_id = ANY($1) ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &answers, query, pq.Array(questionIDs)); err != nil {
+		return nil, fmt.Errorf("error listing answers: %w", err)
+	}
+	return answers, nil
+}
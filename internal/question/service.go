@@ -0,0 +1,218 @@
+package question
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrForbidden        = errors.New("product belongs to another vendor")
+	ErrNotApproved      = errors.New("question has not been approved")
+)
+
+// Catalog looks up the products questions are asked about.
+type Catalog interface {
+	GetProductByID(ctx context.Context, id int64) (*product.Product, error)
+}
+
+type Service interface {
+	// Ask records a customer's question about a published product. It is
+	// held for moderation before it is shown.
+	Ask(ctx context.Context, productID int64, input AskInput) (*Question, error)
+	// ListApproved returns the approved questions of a product with their
+	// answers, newest first.
+	ListApproved(ctx context.Context, productID int64, pagination PaginationParams) ([]*Question, int, error)
+	// Answer replies to an approved question. Vendor API keys may only
+	// answer questions about their own products.
+	Answer(ctx context.Context, productID, questionID int64, input AnswerInput) (*Answer, error)
+	// ListQuestions returns questions in every status for moderators.
+	ListQuestions(ctx context.Context, filter QuestionFilter, pagination PaginationParams) ([]*Question, int, error)
+	Moderate(ctx context.Context, id int64, input ModerateInput) (*Question, error)
+}
+
+type service struct {
+	repo      Repository
+	catalog   Catalog
+	validator *validator.Validate
+}
+
+func NewService(repo Repository, catalog Catalog) Service {
+	return &service{
+		repo:      repo,
+		catalog:   catalog,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) Ask(ctx context.Context, productID int64, input AskInput) (*Question, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.publishedProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	question := &Question{
+		ProductID:  productID,
+		AuthorName: input.AuthorName,
+		Body:       input.Body,
+		Status:     StatusPending,
+	}
+	if err := s.repo.Create(ctx, question); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrProductNotFound
+		}
+		return nil, err
+	}
+	question.Answers = []*Answer{}
+	return question, nil
+}
+
+func (s *service) ListApproved(ctx context.Context, productID int64, pagination PaginationParams) ([]*Question, int, error) {
+	if err := s.validator.Struct(pagination); err != nil {
+		return nil, 0, ErrInvalidInput
+	}
+	if _, err := s.publishedProduct(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+
+	approved := StatusApproved
+	filter := QuestionFilter{ProductID: &productID, Status: &approved}
+	return s.list(ctx, filter, pagination)
+}
+
+func (s *service) Answer(ctx context.Context, productID, questionID int64, input AnswerInput) (*Answer, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	p, err := s.catalog.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	key := apikey.FromContext(ctx)
+	var vendorID *int64
+	if key != nil && key.VendorID != nil {
+		if p.VendorID == nil || *p.VendorID != *key.VendorID {
+			return nil, ErrForbidden
+		}
+		vendorID = key.VendorID
+	}
+
+	question, err := s.getQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.ProductID != productID {
+		return nil, ErrQuestionNotFound
+	}
+	if question.Status != StatusApproved {
+		return nil, ErrNotApproved
+	}
+
+	answer := &Answer{
+		QuestionID: questionID,
+		VendorID:   vendorID,
+		Body:       input.Body,
+		Actor:      audit.ActorFrom(ctx).Name,
+	}
+	if err := s.repo.CreateAnswer(ctx, answer); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+func (s *service) ListQuestions(ctx context.Context, filter QuestionFilter, pagination PaginationParams) ([]*Question, int, error) {
+	if err := s.validator.Struct(pagination); err != nil {
+		return nil, 0, ErrInvalidInput
+	}
+	if filter.Status != nil && *filter.Status != StatusPending && *filter.Status != StatusApproved && *filter.Status != StatusRejected {
+		return nil, 0, ErrInvalidInput
+	}
+	return s.list(ctx, filter, pagination)
+}
+
+func (s *service) Moderate(ctx context.Context, id int64, input ModerateInput) (*Question, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	question, err := s.repo.SetStatus(ctx, id, input.Status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	if err := s.attachAnswers(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+func (s *service) getQuestion(ctx context.Context, id int64) (*Question, error) {
+	question, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return question, nil
+}
+
+// publishedProduct looks up a product customers can see; questions about
+// unpublished products are treated as not found.
+func (s *service) publishedProduct(ctx context.Context, id int64) (*product.Product, error) {
+	p, err := s.catalog.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != product.StatusPublished {
+		return nil, product.ErrProductNotFound
+	}
+	return p, nil
+}
+
+func (s *service) list(ctx context.Context, filter QuestionFilter, pagination PaginationParams) ([]*Question, int, error) {
+	questions, totalCount, err := s.repo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachAnswers(ctx, questions...); err != nil {
+		return nil, 0, err
+	}
+	return questions, totalCount, nil
+}
+
+// attachAnswers loads the answers of questions in one query
+func (s *service) attachAnswers(ctx context.Context, questions ...*Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(questions))
+	byID := make(map[int64]*Question, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+		byID[question.ID] = question
+		question.Answers = []*Answer{}
+	}
+
+	answers, err := s.repo.ListAnswers(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, answer := range answers {
+		byID[answer.QuestionID].Answers = append(byID[answer.QuestionID].Answers, answer)
+	}
+	return nil
+}
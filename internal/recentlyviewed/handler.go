@@ -0,0 +1,97 @@
+package recentlyviewed
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// HeaderSessionID identifies the storefront session. There are no user
+// accounts yet, so views are tracked per session.
+const HeaderSessionID = "X-Session-ID"
+
+// maxSessionIDLength matches the session_id column
+const maxSessionIDLength = 128
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/me/recently-viewed", h.RecordView)
+	router.GET("/me/recently-viewed", h.ListRecentlyViewed)
+}
+
+func (h *Handler) RecordView(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	sessionID, ok := h.sessionID(w, r)
+	if !ok {
+		return
+	}
+
+	var input RecordViewInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode record view input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.RecordView(r.Context(), sessionID, input)
+	if err != nil {
+		h.logger.Error("Failed to record product view", zap.Error(err))
+		switch err {
+		case product.ErrProductNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListRecentlyViewed(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	sessionID, ok := h.sessionID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > MaxViews {
+		limit = 10
+	}
+
+	views, err := h.service.ListRecentlyViewed(r.Context(), sessionID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list recently viewed products", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+func (h *Handler) sessionID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	sessionID := r.Header.Get(HeaderSessionID)
+	if sessionID == "" || len(sessionID) > maxSessionIDLength {
+		http.Error(w, "Missing or invalid "+HeaderSessionID+" header", http.StatusBadRequest)
+		return "", false
+	}
+	return sessionID, true
+}
@@ -0,0 +1,17 @@
+package recentlyviewed
+
+import (
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+)
+
+// View is a product in a session's recently viewed list.
+type View struct {
+	*product.Product
+	ViewedAt time.Time `db:"viewed_at" json:"viewed_at"`
+}
+
+type RecordViewInput struct {
+	ProductID int64 `json:"product_id" validate:"required,min=1"`
+}
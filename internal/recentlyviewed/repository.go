@@ -0,0 +1,76 @@
+package recentlyviewed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for recently viewed data operations
+type Repository interface {
+	Record(ctx context.Context, sessionID string, productID int64, keep int) error
+	List(ctx context.Context, sessionID string, limit int) ([]*View, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Record stores a product view, moving an earlier view of the same product
+// to the front, and trims the session's history to its keep newest views
+func (r *repository) Record(ctx context.Context, sessionID string, productID int64, keep int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO recently_viewed (session_id, product_id, viewed_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (session_id, product_id) DO UPDATE SET viewed_at = EXCLUDED.viewed_at`
+	if _, err := tx.ExecContext(ctx, query, sessionID, productID); err != nil {
+		return fmt.Errorf("error recording product view: %w", err)
+	}
+
+	trim := `
+		DELETE FROM recently_viewed
+		WHERE session_id = $1 AND product_id NOT IN (
+			SELECT product_id FROM recently_viewed
+			WHERE session_id = $1
+			ORDER BY viewed_at DESC
+			LIMIT $2
+		)`
+	if _, err := tx.ExecContext(ctx, trim, sessionID, keep); err != nil {
+		return fmt.Errorf("error trimming recently viewed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing product view: %w", err)
+	}
+	return nil
+}
+
+// List retrieves a session's recently viewed products of the current store
+// that are still published, newest first
+func (r *repository) List(ctx context.Context, sessionID string, limit int) ([]*View, error) {
+	views := []*View{}
+	query := `
+		SELECT p.*, v.viewed_at FROM recently_viewed v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.session_id = $1 AND p.status = 'published' AND ($3::integer IS NULL OR p.store_id = $3)
+		ORDER BY v.viewed_at DESC
+		LIMIT $2`
+	if err := r.db.SelectContext(ctx, &views, query, sessionID, limit, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing recently viewed: %w", err)
+	}
+	return views, nil
+}
@@ -0,0 +1,76 @@
+package recentlyviewed
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/go-playground/validator"
+)
+
+// MaxViews is the number of products remembered per session
+const MaxViews = 20
+
+var (
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrMissingSession = errors.New("missing session")
+)
+
+// Catalog looks up viewed products.
+type Catalog interface {
+	GetProductByID(ctx context.Context, id int64) (*product.Product, error)
+}
+
+type Service interface {
+	RecordView(ctx context.Context, sessionID string, input RecordViewInput) error
+	ListRecentlyViewed(ctx context.Context, sessionID string, limit int) ([]*View, error)
+}
+
+type service struct {
+	repo      Repository
+	catalog   Catalog
+	validator *validator.Validate
+}
+
+func NewService(repo Repository, catalog Catalog) Service {
+	return &service{
+		repo:      repo,
+		catalog:   catalog,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) RecordView(ctx context.Context, sessionID string, input RecordViewInput) error {
+	if sessionID == "" {
+		return ErrMissingSession
+	}
+	if err := s.validator.Struct(input); err != nil {
+		return ErrInvalidInput
+	}
+
+	if _, err := s.catalog.GetProductByID(ctx, input.ProductID); err != nil {
+		return err
+	}
+
+	return s.repo.Record(ctx, sessionID, input.ProductID, MaxViews)
+}
+
+func (s *service) ListRecentlyViewed(ctx context.Context, sessionID string, limit int) ([]*View, error) {
+	if sessionID == "" {
+		return nil, ErrMissingSession
+	}
+	if limit < 1 || limit > MaxViews {
+		return nil, ErrInvalidInput
+	}
+
+	views, err := s.repo.List(ctx, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, view := range views {
+		view.ApplySale(now)
+	}
+	return views, nil
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/reports", h.CreateDefinition)
+	router.GET("/admin/reports", h.ListDefinitions)
+	router.GET("/admin/reports/:id", h.GetDefinition)
+	router.PUT("/admin/reports/:id", h.UpdateDefinition)
+	router.DELETE("/admin/reports/:id", h.DeleteDefinition)
+	router.POST("/admin/reports/:id/run", h.Run)
+}
+
+func (h *Handler) CreateDefinition(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateDefinitionInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create report input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	def, err := h.service.CreateDefinition(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create report", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(def)
+}
+
+func (h *Handler) ListDefinitions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	defs, err := h.service.ListDefinitions(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list reports", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(defs)
+}
+
+func (h *Handler) GetDefinition(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	def, err := h.service.GetDefinition(r.Context(), id)
+	if err != nil {
+		if err != ErrReportNotFound {
+			h.logger.Error("Failed to get report", zap.Int64("id", id), zap.Error(err))
+		}
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(def)
+}
+
+func (h *Handler) UpdateDefinition(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input UpdateDefinitionInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update report input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	def, err := h.service.UpdateDefinition(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update report", zap.Int64("id", id), zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(def)
+}
+
+func (h *Handler) DeleteDefinition(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDefinition(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete report", zap.Int64("id", id), zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Run delivers the report immediately, for instance to check a new
+// definition without waiting for its schedule
+func (h *Handler) Run(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	run, err := h.service.Run(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to run report", zap.Int64("id", id), zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+func (h *Handler) parseID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrReportNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
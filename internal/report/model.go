@@ -0,0 +1,105 @@
+package report
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Kind is what a report covers
+type Kind string
+
+const (
+	// KindSales is units sold and revenue per product over the period
+	KindSales Kind = "sales"
+	// KindInventory is the stock on hand when the report runs
+	KindInventory Kind = "inventory"
+)
+
+// Frequency is how often a report is delivered. Daily reports cover the
+// previous UTC day; weekly reports run on Monday and cover the week before.
+type Frequency string
+
+const (
+	FrequencyDaily  Frequency = "daily"
+	FrequencyWeekly Frequency = "weekly"
+)
+
+// Delivery is where a generated report goes
+type Delivery string
+
+const (
+	// DeliveryEmail mails the report to each recipient
+	DeliveryEmail Delivery = "email"
+	// DeliveryStorage uploads the report as CSV to the blob store, which is
+	// S3 when blob_driver is s3
+	DeliveryStorage Delivery = "storage"
+)
+
+// Definition is a report delivered on a schedule
+type Definition struct {
+	ID         int64          `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Kind       Kind           `db:"kind" json:"kind"`
+	Frequency  Frequency      `db:"frequency" json:"frequency"`
+	Delivery   Delivery       `db:"delivery" json:"delivery"`
+	Recipients pq.StringArray `db:"recipients" json:"recipients"`
+	Enabled    bool           `db:"enabled" json:"enabled"`
+	NextRunAt  time.Time      `db:"next_run_at" json:"next_run_at"`
+	LastRunAt  *time.Time     `db:"last_run_at" json:"last_run_at,omitempty"`
+	LastError  *string        `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// CreateDefinitionInput creates a report definition. Email delivery needs
+// at least one recipient.
+type CreateDefinitionInput struct {
+	Name       string   `json:"name" validate:"required,max=255"`
+	Kind       string   `json:"kind" validate:"required,oneof=sales inventory"`
+	Frequency  string   `json:"frequency" validate:"required,oneof=daily weekly"`
+	Delivery   string   `json:"delivery" validate:"required,oneof=email storage"`
+	Recipients []string `json:"recipients" validate:"max=20,dive,email"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// UpdateDefinitionInput changes the fields that are set. Changing the
+// frequency reschedules the next run.
+type UpdateDefinitionInput struct {
+	Name       *string   `json:"name" validate:"omitempty,min=1,max=255"`
+	Kind       *string   `json:"kind" validate:"omitempty,oneof=sales inventory"`
+	Frequency  *string   `json:"frequency" validate:"omitempty,oneof=daily weekly"`
+	Delivery   *string   `json:"delivery" validate:"omitempty,oneof=email storage"`
+	Recipients *[]string `json:"recipients" validate:"omitempty,max=20,dive,email"`
+	Enabled    *bool     `json:"enabled"`
+}
+
+// Run is the outcome of delivering a report once
+type Run struct {
+	DefinitionID int64     `json:"definition_id"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Rows         int       `json:"rows"`
+	// Key is the blob store key of the uploaded CSV, for storage delivery
+	Key string `json:"key,omitempty"`
+}
+
+// SalesLine is a product's sales over a report period. Revenue is priced
+// at the product's current price.
+type SalesLine struct {
+	ProductID int64   `db:"product_id"`
+	SKU       string  `db:"sku"`
+	Name      string  `db:"name"`
+	Units     int     `db:"units"`
+	Revenue   float64 `db:"revenue"`
+}
+
+// InventoryLine is a product's stock when the report runs
+type InventoryLine struct {
+	ProductID     int64   `db:"product_id"`
+	SKU           string  `db:"sku"`
+	Name          string  `db:"name"`
+	StockQuantity int     `db:"stock_quantity"`
+	Threshold     int     `db:"low_stock_threshold"`
+	Value         float64 `db:"value"`
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the interface for report data operations
+type Repository interface {
+	Create(ctx context.Context, def *Definition) error
+	GetByID(ctx context.Context, id int64) (*Definition, error)
+	List(ctx context.Context) ([]*Definition, error)
+	Update(ctx context.Context, def *Definition) error
+	Delete(ctx context.Context, id int64) error
+	// ListDue returns the enabled definitions whose next run is at or
+	// before now
+	ListDue(ctx context.Context, now time.Time) ([]*Definition, error)
+	// RecordRun stores the outcome of a run; reason is nil when it succeeded
+	RecordRun(ctx context.Context, id int64, ranAt, nextRunAt time.Time, reason *string) error
+	// SalesLines sums the purchases from from up to, but not including, to
+	// per product, best sellers first
+	SalesLines(ctx context.Context, from, to time.Time) ([]*SalesLine, error)
+	// InventoryLines lists the stock of every product that is not archived,
+	// lowest stock first
+	InventoryLines(ctx context.Context) ([]*InventoryLine, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, def *Definition) error {
+	query := `
+		INSERT INTO report_definitions (name, kind, frequency, delivery, recipients, enabled, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, def.Name, def.Kind, def.Frequency, def.Delivery,
+		def.Recipients, def.Enabled, def.NextRunAt).StructScan(def)
+	if err != nil {
+		return fmt.Errorf("error creating report definition: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*Definition, error) {
+	var def Definition
+	if err := r.db.GetContext(ctx, &def, `SELECT * FROM report_definitions WHERE id = $1`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("report definition not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting report definition: %w", err)
+	}
+	return &def, nil
+}
+
+func (r *repository) List(ctx context.Context) ([]*Definition, error) {
+	defs := []*Definition{}
+	if err := r.db.SelectContext(ctx, &defs, `SELECT * FROM report_definitions ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("error listing report definitions: %w", err)
+	}
+	return defs, nil
+}
+
+// Update writes the editable fields of def
+func (r *repository) Update(ctx context.Context, def *Definition) error {
+	query := `
+		UPDATE report_definitions
+		SET name = $1, kind = $2, frequency = $3, delivery = $4, recipients = $5,
+			enabled = $6, next_run_at = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at`
+	err := r.db.QueryRowxContext(ctx, query, def.Name, def.Kind, def.Frequency, def.Delivery,
+		def.Recipients, def.Enabled, def.NextRunAt, def.ID).Scan(&def.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("report definition not found: %w", err)
+		}
+		return fmt.Errorf("error updating report definition: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM report_definitions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting report definition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("report definition not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *repository) ListDue(ctx context.Context, now time.Time) ([]*Definition, error) {
+	defs := []*Definition{}
+	query := `
+		SELECT * FROM report_definitions
+		WHERE enabled AND next_run_at <= $1
+		ORDER BY next_run_at, id`
+	if err := r.db.SelectContext(ctx, &defs, query, now); err != nil {
+		return nil, fmt.Errorf("error listing due reports: %w", err)
+	}
+	return defs, nil
+}
+
+func (r *repository) RecordRun(ctx context.Context, id int64, ranAt, nextRunAt time.Time, reason *string) error {
+	query := `
+		UPDATE report_definitions
+		SET last_run_at = $1, next_run_at = GREATEST(next_run_at, $2), last_error = $3
+		WHERE id = $4`
+	if _, err := r.db.ExecContext(ctx, query, ranAt, nextRunAt, reason, id); err != nil {
+		return fmt.Errorf("error recording report run: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) SalesLines(ctx context.Context, from, to time.Time) ([]*SalesLine, error) {
+	// Orders live outside this module; purchases are reported to the
+	// analytics endpoint by the checkout
+	lines := []*SalesLine{}
+	query := `
+		SELECT p.id AS product_id, COALESCE(p.sku, '') AS sku, p.name,
+			SUM(e.quantity) AS units,
+			SUM(e.quantity) * p.price AS revenue
+		FROM analytics_events e
+		JOIN products p ON p.id = e.product_id
+		WHERE e.type = 'purchase' AND e.occurred_at >= $1 AND e.occurred_at < $2
+		GROUP BY p.id
+		ORDER BY units DESC, p.id`
+	if err := r.db.SelectContext(ctx, &lines, query, from, to); err != nil {
+		return nil, fmt.Errorf("error summing sales: %w", err)
+	}
+	return lines, nil
+}
+
+func (r *repository) InventoryLines(ctx context.Context) ([]*InventoryLine, error) {
+	lines := []*InventoryLine{}
+	query := `
+		SELECT id AS product_id, COALESCE(sku, '') AS sku, name, stock_quantity, low_stock_threshold,
+			GREATEST(stock_quantity, 0) * price AS value
+		FROM products
+		WHERE status <> 'archived' AND NOT is_bundle
+		ORDER BY stock_quantity, id`
+	if err := r.db.SelectContext(ctx, &lines, query); err != nil {
+		return nil, fmt.Errorf("error listing inventory: %w", err)
+	}
+	return lines, nil
+}
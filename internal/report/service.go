@@ -0,0 +1,357 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/mailer"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrInvalidInput   = errors.New("invalid input")
+)
+
+// JobDeliver is the periodic job type that delivers the reports that are due
+const JobDeliver = "report_delivery"
+
+// maxEmailRows caps the lines of a report shown in an email; storage
+// delivery always has every line
+const maxEmailRows = 100
+
+// dateLayout formats the dates of report periods and file names
+const dateLayout = "2006-01-02"
+
+type Service interface {
+	CreateDefinition(ctx context.Context, input CreateDefinitionInput) (*Definition, error)
+	GetDefinition(ctx context.Context, id int64) (*Definition, error)
+	ListDefinitions(ctx context.Context) ([]*Definition, error)
+	UpdateDefinition(ctx context.Context, id int64, input UpdateDefinitionInput) (*Definition, error)
+	DeleteDefinition(ctx context.Context, id int64) error
+	// Run delivers a report now for its last complete period, leaving its
+	// schedule unchanged.
+	Run(ctx context.Context, id int64) (*Run, error)
+	// DeliverDue is the JobDeliver job handler.
+	DeliverDue(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	mail      mailer.Mailer
+	blobs     blobstore.Store
+	validator *validator.Validate
+	now       func() time.Time
+}
+
+// NewService creates the report service. Emailed reports are sent through
+// mail; stored ones are uploaded to blobs under reports/<definition id>/.
+func NewService(repo Repository, mail mailer.Mailer, blobs blobstore.Store) Service {
+	return &service{
+		repo:      repo,
+		mail:      mail,
+		blobs:     blobs,
+		validator: validator.New(),
+		now:       time.Now,
+	}
+}
+
+func (s *service) CreateDefinition(ctx context.Context, input CreateDefinitionInput) (*Definition, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	def := &Definition{
+		Name:       input.Name,
+		Kind:       Kind(input.Kind),
+		Frequency:  Frequency(input.Frequency),
+		Delivery:   Delivery(input.Delivery),
+		Recipients: input.Recipients,
+		Enabled:    true,
+	}
+	if def.Recipients == nil {
+		def.Recipients = []string{}
+	}
+	if input.Enabled != nil {
+		def.Enabled = *input.Enabled
+	}
+	if def.Delivery == DeliveryEmail && len(def.Recipients) == 0 {
+		return nil, ErrInvalidInput
+	}
+	def.NextRunAt = nextRun(def.Frequency, s.now())
+
+	if err := s.repo.Create(ctx, def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (s *service) GetDefinition(ctx context.Context, id int64) (*Definition, error) {
+	def, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return def, nil
+}
+
+func (s *service) ListDefinitions(ctx context.Context) ([]*Definition, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) UpdateDefinition(ctx context.Context, id int64, input UpdateDefinitionInput) (*Definition, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	def, err := s.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		def.Name = *input.Name
+	}
+	if input.Kind != nil {
+		def.Kind = Kind(*input.Kind)
+	}
+	if input.Frequency != nil && Frequency(*input.Frequency) != def.Frequency {
+		def.Frequency = Frequency(*input.Frequency)
+		def.NextRunAt = nextRun(def.Frequency, s.now())
+	}
+	if input.Delivery != nil {
+		def.Delivery = Delivery(*input.Delivery)
+	}
+	if input.Recipients != nil {
+		def.Recipients = *input.Recipients
+	}
+	if input.Enabled != nil {
+		def.Enabled = *input.Enabled
+	}
+	if def.Delivery == DeliveryEmail && len(def.Recipients) == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.Update(ctx, def); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return def, nil
+}
+
+func (s *service) DeleteDefinition(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrReportNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) Run(ctx context.Context, id int64) (*Run, error) {
+	def, err := s.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	run, err := s.deliver(ctx, def, periodEnd(def.Frequency, now))
+	if recordErr := s.recordRun(ctx, def, now, def.NextRunAt, err); recordErr != nil {
+		return nil, errors.Join(err, recordErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *service) DeliverDue(ctx context.Context, _ json.RawMessage) error {
+	now := s.now()
+	defs, err := s.repo.ListDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range defs {
+		_, err := s.deliver(ctx, def, periodEnd(def.Frequency, now))
+
+		// A failed report stays due, so the next tick tries it again
+		next := nextRun(def.Frequency, now)
+		if err != nil {
+			next = def.NextRunAt
+		}
+		if err := s.recordRun(ctx, def, now, next, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) recordRun(ctx context.Context, def *Definition, ranAt, next time.Time, runErr error) error {
+	var reason *string
+	if runErr != nil {
+		msg := runErr.Error()
+		reason = &msg
+	}
+	return s.repo.RecordRun(ctx, def.ID, ranAt, next, reason)
+}
+
+// deliver generates the report for the period ending at end and sends it
+// where the definition says
+func (s *service) deliver(ctx context.Context, def *Definition, end time.Time) (*Run, error) {
+	start := periodStart(def.Frequency, end)
+	columns, rows, err := s.generate(ctx, def.Kind, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &Run{DefinitionID: def.ID, From: start, To: end, Rows: len(rows)}
+	switch def.Delivery {
+	case DeliveryEmail:
+		err = s.email(ctx, def, start, end, columns, rows)
+	case DeliveryStorage:
+		run.Key = key(def, start)
+		err = s.upload(ctx, run.Key, columns, rows)
+	default:
+		err = fmt.Errorf("unknown report delivery %q", def.Delivery)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// generate returns the header and lines of a report over [from, to)
+func (s *service) generate(ctx context.Context, kind Kind, from, to time.Time) ([]string, [][]string, error) {
+	switch kind {
+	case KindSales:
+		lines, err := s.repo.SalesLines(ctx, from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := make([][]string, 0, len(lines))
+		for _, l := range lines {
+			rows = append(rows, []string{
+				strconv.FormatInt(l.ProductID, 10),
+				l.SKU,
+				l.Name,
+				strconv.Itoa(l.Units),
+				strconv.FormatFloat(l.Revenue, 'f', 2, 64),
+			})
+		}
+		return []string{"Product ID", "SKU", "Name", "Units", "Revenue"}, rows, nil
+
+	case KindInventory:
+		lines, err := s.repo.InventoryLines(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := make([][]string, 0, len(lines))
+		for _, l := range lines {
+			rows = append(rows, []string{
+				strconv.FormatInt(l.ProductID, 10),
+				l.SKU,
+				l.Name,
+				strconv.Itoa(l.StockQuantity),
+				strconv.Itoa(l.Threshold),
+				strconv.FormatFloat(l.Value, 'f', 2, 64),
+			})
+		}
+		return []string{"Product ID", "SKU", "Name", "Stock", "Low Stock Threshold", "Value"}, rows, nil
+	}
+	return nil, nil, fmt.Errorf("unknown report kind %q", kind)
+}
+
+func (s *service) email(ctx context.Context, def *Definition, from, to time.Time, columns []string, rows [][]string) error {
+	data := mailer.ReportData{
+		Name:      def.Name,
+		Period:    periodLabel(from, to),
+		Columns:   columns,
+		Rows:      rows,
+		TotalRows: len(rows),
+	}
+	if len(data.Rows) > maxEmailRows {
+		data.Rows = data.Rows[:maxEmailRows]
+	}
+
+	for _, recipient := range def.Recipients {
+		msg, err := mailer.Render(mailer.TemplateReport, recipient, data)
+		if err != nil {
+			return err
+		}
+		if err := s.mail.Send(ctx, msg); err != nil {
+			return fmt.Errorf("error sending report to %s: %w", recipient, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) upload(ctx context.Context, key string, columns []string, rows [][]string) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(columns)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+
+	if err := s.blobs.Put(ctx, key, "text/csv; charset=utf-8", buf.Bytes()); err != nil {
+		return fmt.Errorf("error storing report: %w", err)
+	}
+	return nil
+}
+
+// periodEnd returns the period boundary at or before t: midnight UTC for
+// daily reports and Monday midnight UTC for weekly ones
+func periodEnd(freq Frequency, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if freq == FrequencyWeekly {
+		// Weekday counts from Sunday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+// periodStart returns the start of the period ending at end
+func periodStart(freq Frequency, end time.Time) time.Time {
+	if freq == FrequencyWeekly {
+		return end.AddDate(0, 0, -7)
+	}
+	return end.AddDate(0, 0, -1)
+}
+
+// nextRun returns the first period boundary after t
+func nextRun(freq Frequency, t time.Time) time.Time {
+	end := periodEnd(freq, t)
+	if freq == FrequencyWeekly {
+		return end.AddDate(0, 0, 7)
+	}
+	return end.AddDate(0, 0, 1)
+}
+
+// periodLabel describes [from, to) by its first and last day
+func periodLabel(from, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	if last.Equal(from) {
+		return from.Format(dateLayout)
+	}
+	return from.Format(dateLayout) + " to " + last.Format(dateLayout)
+}
+
+func key(def *Definition, from time.Time) string {
+	return fmt.Sprintf("reports/%d/%s-%s.csv", def.ID, def.Kind, from.Format(dateLayout))
+}
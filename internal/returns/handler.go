@@ -0,0 +1,352 @@
+package returns
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/inbound"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// ProviderCarrier names the carrier in webhook receipts
+const ProviderCarrier = "carrier"
+
+type Handler struct {
+	service  Service
+	logger   *zap.Logger
+	webhooks *inbound.Verifier
+	carrier  inbound.Provider
+}
+
+// NewHandler creates the returns handler. Carrier tracking callbacks must
+// be signed with carrierSecret and are rejected while it is empty.
+func NewHandler(service Service, logger *zap.Logger, webhooks *inbound.Verifier, carrierSecret string) *Handler {
+	return &Handler{
+		service:  service,
+		logger:   logger,
+		webhooks: webhooks,
+		carrier:  inbound.Provider{Name: ProviderCarrier, Secret: carrierSecret},
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	// Customers look their return up with the access token issued when they
+	// requested it; sequential IDs are for admins only
+	router.POST("/returns", h.CreateReturn)
+	router.GET("/returns/:token", h.GetReturnByToken)
+	router.GET("/returns/:token/history", h.GetReturnHistoryByToken)
+	router.POST("/returns/tracking-events", h.webhooks.Wrap(h.carrier, h.RecordTrackingEvent))
+
+	router.GET("/admin/returns", h.ListReturns)
+	router.GET("/admin/returns/:id", h.GetReturn)
+	router.GET("/admin/returns/:id/history", h.GetReturnHistory)
+	router.POST("/admin/returns/:id/approve", h.ApproveReturn)
+	router.POST("/admin/returns/:id/reject", h.RejectReturn)
+	router.POST("/admin/returns/:id/label", h.IssueLabel)
+	router.POST("/admin/returns/:id/receive", h.ReceiveReturn)
+	router.POST("/admin/returns/:id/refund", h.RefundReturn)
+}
+
+func (h *Handler) CreateReturn(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateReturnInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create return input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.service.RequestReturn(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) GetReturn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	ret, err := h.service.GetReturnByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) GetReturnHistory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.GetReturnHistory(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get return history", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetReturnByToken returns the return whose access token is in the path.
+// Unknown tokens answer 404 Not Found, like unknown IDs.
+func (h *Handler) GetReturnByToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ret, err := h.service.GetReturnByToken(r.Context(), ps.ByName("token"))
+	if err != nil {
+		if err != ErrReturnNotFound {
+			h.logger.Error("Failed to get return by token", zap.Error(err))
+		}
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) GetReturnHistoryByToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ret, err := h.service.GetReturnByToken(r.Context(), ps.ByName("token"))
+	if err != nil {
+		if err != ErrReturnNotFound {
+			h.logger.Error("Failed to get return by token", zap.Error(err))
+		}
+		h.writeError(w, err)
+		return
+	}
+
+	history, err := h.service.GetReturnHistory(r.Context(), ret.ID)
+	if err != nil {
+		h.logger.Error("Failed to get return history", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *Handler) ListReturns(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var filter ReturnFilter
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := Status(status)
+		filter.Status = &s
+	}
+	if orderID := r.URL.Query().Get("order_id"); orderID != "" {
+		id, err := strconv.ParseInt(orderID, 10, 64)
+		if err == nil {
+			filter.OrderID = &id
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	pagination := PaginationParams{
+		Page:  page,
+		Limit: limit,
+	}
+
+	returns, totalCount, err := h.service.ListReturns(r.Context(), filter, pagination)
+	if err != nil {
+		h.logger.Error("Failed to list returns", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	response.List(w, r, returns, totalCount, pagination.Page, pagination.Limit)
+}
+
+func (h *Handler) ApproveReturn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input DecisionInput
+	if !h.decodeOptional(w, r, &input) {
+		return
+	}
+
+	ret, err := h.service.ApproveReturn(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to approve return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) RejectReturn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input DecisionInput
+	if !h.decodeOptional(w, r, &input) {
+		return
+	}
+
+	ret, err := h.service.RejectReturn(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to reject return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) IssueLabel(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input IssueLabelInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode issue label input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.service.IssueLabel(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to issue return label", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) ReceiveReturn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input ReceiveInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode receive return input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.service.ReceiveReturn(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to receive return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) RefundReturn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	ret, err := h.service.RefundReturn(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to refund return", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// RecordTrackingEvent handles carrier tracking callbacks, which are
+// authenticated by the webhook verifier. Unlike our own endpoints it
+// ignores unknown fields, since the carrier may add fields at any time.
+func (h *Handler) RecordTrackingEvent(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input TrackingEventInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.logger.Error("Failed to decode tracking event input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.service.RecordTrackingEvent(r.Context(), input)
+	if err != nil {
+		if err == ErrNotConfigured {
+			http.Error(w, "Automatic refunds are not configured", http.StatusNotImplemented)
+			return
+		}
+		h.logger.Error("Failed to record tracking event", zap.Error(err))
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+func (h *Handler) parseID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid return ID", zap.Error(err))
+		http.Error(w, "Invalid return ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeOptional decodes a JSON body if one was sent; decision endpoints
+// accept an empty body.
+func (h *Handler) decodeOptional(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := request.Decode(r, v); err != nil {
+		h.logger.Error("Failed to decode return input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrReturnNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case ErrInvalidInput:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case ErrInvalidTransition:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,71 @@
+package returns_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/internal/returns"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/factory"
+	"github.com/dotslashbit/ecommerce-api/internal/testutil/golden"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// stubService serves fixed returns; methods a test does not set panic
+// through the embedded nil interface.
+type stubService struct {
+	returns.Service
+	returns map[int64]*returns.Return
+	tokens  map[string]int64
+}
+
+func (s *stubService) GetReturnByID(_ context.Context, id int64) (*returns.Return, error) {
+	ret, ok := s.returns[id]
+	if !ok {
+		return nil, returns.ErrReturnNotFound
+	}
+	return ret, nil
+}
+
+func (s *stubService) GetReturnByToken(ctx context.Context, token string) (*returns.Return, error) {
+	id, ok := s.tokens[token]
+	if !ok {
+		return nil, returns.ErrReturnNotFound
+	}
+	return s.GetReturnByID(ctx, id)
+}
+
+func TestGetReturn(t *testing.T) {
+	factory.Reset()
+	labelURL := "https://labels.example.com/2.pdf"
+	service := &stubService{returns: map[int64]*returns.Return{
+		1: factory.Return(),
+		2: factory.Return(func(r *returns.Return) {
+			r.Status = returns.StatusApproved
+			r.LabelURL = &labelURL
+		}),
+	}, tokens: map[string]int64{"rt_customer1": 1}}
+
+	router := httprouter.New()
+	returns.NewHandler(service, zap.NewNop(), nil, "").RegisterRoutes(router)
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"get_return", "/admin/returns/1"},
+		{"get_return_with_label", "/admin/returns/2"},
+		{"get_return_not_found", "/admin/returns/3"},
+		{"get_return_by_token", "/returns/rt_customer1"},
+		{"get_return_by_id_without_token", "/returns/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			golden.AssertResponse(t, tt.name, rec, "Content-Type")
+		})
+	}
+}
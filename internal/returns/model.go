@@ -0,0 +1,145 @@
+package returns
+
+import (
+	"time"
+)
+
+type Status string
+
+const (
+	StatusRequested   Status = "requested"
+	StatusApproved    Status = "approved"
+	StatusRejected    Status = "rejected"
+	StatusLabelIssued Status = "label_issued"
+	StatusReceived    Status = "received"
+	StatusRefunded    Status = "refunded"
+)
+
+// transitions lists the statuses a return may move to from each status.
+var transitions = map[Status][]Status{
+	StatusRequested:   {StatusApproved, StatusRejected},
+	StatusApproved:    {StatusLabelIssued},
+	StatusLabelIssued: {StatusReceived},
+	StatusReceived:    {StatusRefunded},
+}
+
+// CanTransition reports whether a return in status s may move to status to.
+func (s Status) CanTransition(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type Return struct {
+	ID             int64     `db:"id" json:"id"`
+	OrderID        int64     `db:"order_id" json:"order_id"`
+	OrderLineID    int64     `db:"order_line_id" json:"order_line_id"`
+	Quantity       int       `db:"quantity" json:"quantity"`
+	Reason         string    `db:"reason" json:"reason"`
+	CustomerEmail  string    `db:"customer_email" json:"customer_email"`
+	Status         Status    `db:"status" json:"status"`
+	LabelURL       *string   `db:"label_url" json:"label_url,omitempty"`
+	TrackingNumber *string   `db:"tracking_number" json:"tracking_number,omitempty"`
+	RefundAmount   *float64  `db:"refund_amount" json:"refund_amount,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+
+	Carrier             *string         `db:"carrier" json:"carrier,omitempty"`
+	DropOffInstructions *string         `db:"drop_off_instructions" json:"drop_off_instructions,omitempty"`
+	ShipmentStatus      *ShipmentStatus `db:"shipment_status" json:"shipment_status,omitempty"`
+
+	// AccessTokenHash is the SHA-256 hash of the token the customer looks the
+	// return up with
+	AccessTokenHash *string `db:"access_token_hash" json:"-"`
+}
+
+// CreatedReturn is returned once, when the return is requested; it is the
+// only response that includes the access token.
+type CreatedReturn struct {
+	*Return
+	Token string `json:"token"`
+}
+
+// ShipmentStatus is the carrier status of the package sent back by the customer
+type ShipmentStatus string
+
+const (
+	ShipmentInTransit ShipmentStatus = "in_transit"
+	ShipmentDelivered ShipmentStatus = "delivered"
+	ShipmentException ShipmentStatus = "exception"
+)
+
+// Label is a return shipping label purchased from a label provider
+type Label struct {
+	URL                 string
+	TrackingNumber      string
+	Carrier             string
+	DropOffInstructions string
+}
+
+type HistoryEntry struct {
+	ID         int64     `db:"id" json:"id"`
+	ReturnID   int64     `db:"return_id" json:"return_id"`
+	FromStatus *Status   `db:"from_status" json:"from_status"`
+	ToStatus   Status    `db:"to_status" json:"to_status"`
+	Note       string    `db:"note" json:"note"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type CreateReturnInput struct {
+	OrderID       int64  `json:"order_id" validate:"required,min=1"`
+	OrderLineID   int64  `json:"order_line_id" validate:"required,min=1"`
+	Quantity      int    `json:"quantity" validate:"required,min=1"`
+	Reason        string `json:"reason" validate:"required"`
+	CustomerEmail string `json:"customer_email" validate:"required,email"`
+}
+
+type DecisionInput struct {
+	Note string `json:"note"`
+}
+
+type IssueLabelInput struct {
+	LabelURL            string `json:"label_url" validate:"required,url"`
+	TrackingNumber      string `json:"tracking_number" validate:"required"`
+	Carrier             string `json:"carrier"`
+	DropOffInstructions string `json:"drop_off_instructions"`
+}
+
+// ReceiveInput marks a return received. When RefundAmount is omitted the
+// Refunder decides the amount from the order line.
+type ReceiveInput struct {
+	RefundAmount *float64 `json:"refund_amount" validate:"omitempty,min=0"`
+	Note         string   `json:"note"`
+}
+
+type TrackingEventInput struct {
+	TrackingNumber string         `json:"tracking_number" validate:"required"`
+	Status         ShipmentStatus `json:"status" validate:"required,oneof=in_transit delivered exception"`
+}
+
+// StatusChange describes a transition applied by the repository together
+// with the fields that change alongside it.
+type StatusChange struct {
+	From           Status
+	To             Status
+	Note           string
+	LabelURL       *string
+	TrackingNumber *string
+	RefundAmount   *float64
+
+	Carrier             *string
+	DropOffInstructions *string
+}
+
+type ReturnFilter struct {
+	Status  *Status `json:"status"`
+	OrderID *int64  `json:"order_id"`
+}
+
+type PaginationParams struct {
+	Page  int `json:"page" validate:"required,min=1"`
+	Limit int `json:"limit" validate:"required,min=1,max=100"`
+}
@@ -0,0 +1,295 @@
+package returns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/crypto"
+	"github.com/jmoiron/sqlx"
+)
+
+// entityType identifies returns in the audit log
+const entityType = "return"
+
+// EncryptedFields lists the columns sealed with the PII keyring
+var EncryptedFields = []crypto.Field{
+	{Table: "return_requests", Column: "customer_email"},
+}
+
+// Repository defines the interface for return data operations
+type Repository interface {
+	Create(ctx context.Context, ret *Return) error
+	GetByID(ctx context.Context, id int64) (*Return, error)
+	GetByAccessTokenHash(ctx context.Context, hash string) (*Return, error)
+	List(ctx context.Context, filter ReturnFilter, pagination PaginationParams) ([]*Return, int, error)
+	ApplyStatusChange(ctx context.Context, id int64, change StatusChange) (*Return, error)
+	ListHistory(ctx context.Context, returnID int64) ([]*HistoryEntry, error)
+	GetByTrackingNumber(ctx context.Context, trackingNumber string) (*Return, error)
+	UpdateShipmentStatus(ctx context.Context, id int64, status ShipmentStatus) error
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db  *sqlx.DB
+	pii *crypto.Keyring
+}
+
+// NewRepository creates a new instance of the SQL repository. Customer
+// emails are encrypted with pii; nil stores them in plaintext.
+func NewRepository(db *sqlx.DB, pii *crypto.Keyring) Repository {
+	return &repository{db: db, pii: pii}
+}
+
+// Create adds a new return request, its initial history entry and an audit entry
+func (r *repository) Create(ctx context.Context, ret *Return) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	email := ret.CustomerEmail
+	ret.CustomerEmail, err = r.pii.Encrypt(email)
+	if err != nil {
+		return fmt.Errorf("error encrypting customer email: %w", err)
+	}
+	// The audit entry keeps the stored, encrypted email
+	defer func() { ret.CustomerEmail = email }()
+
+	query := `
+		INSERT INTO return_requests (order_id, order_line_id, quantity, reason, customer_email, status, access_token_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRowxContext(ctx, query,
+		ret.OrderID, ret.OrderLineID, ret.Quantity, ret.Reason, ret.CustomerEmail, ret.Status, ret.AccessTokenHash).
+		StructScan(ret)
+	if err != nil {
+		return fmt.Errorf("error creating return: %w", err)
+	}
+
+	if err := insertHistory(ctx, tx, ret.ID, nil, ret.Status, ""); err != nil {
+		return err
+	}
+	if err := audit.Record(ctx, tx, entityType, ret.ID, audit.ActionCreate, nil, ret); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing return: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a single return request by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*Return, error) {
+	var ret Return
+	query := `SELECT * FROM return_requests WHERE id = $1`
+	err := r.db.GetContext(ctx, &ret, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("return not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting return: %w", err)
+	}
+	if err := r.decrypt(&ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetByAccessTokenHash retrieves the return request whose access token
+// hashes to hash
+func (r *repository) GetByAccessTokenHash(ctx context.Context, hash string) (*Return, error) {
+	var ret Return
+	query := `SELECT * FROM return_requests WHERE access_token_hash = $1`
+	err := r.db.GetContext(ctx, &ret, query, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("return not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting return by access token: %w", err)
+	}
+	if err := r.decrypt(&ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// List retrieves return requests, applying filters and pagination
+func (r *repository) List(ctx context.Context, filter ReturnFilter, pagination PaginationParams) ([]*Return, int, error) {
+	query := `SELECT * FROM return_requests`
+	countQuery := `SELECT COUNT(*) FROM return_requests`
+	whereClause := []string{}
+	args := []interface{}{}
+	argID := 1
+
+	if filter.Status != nil {
+		whereClause = append(whereClause, fmt.Sprintf("status = $%d", argID))
+		args = append(args, *filter.Status)
+		argID++
+	}
+	if filter.OrderID != nil {
+		whereClause = append(whereClause, fmt.Sprintf("order_id = $%d", argID))
+		args = append(args, *filter.OrderID)
+		argID++
+	}
+
+	if len(whereClause) > 0 {
+		query += " WHERE " + strings.Join(whereClause, " AND ")
+		countQuery += " WHERE " + strings.Join(whereClause, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argID, argID+1)
+	args = append(args, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+
+	var returns []*Return
+	err := r.db.SelectContext(ctx, &returns, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing returns: %w", err)
+	}
+	for _, ret := range returns {
+		if err := r.decrypt(ret); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var totalCount int
+	err = r.db.GetContext(ctx, &totalCount, countQuery, args[:len(args)-2]...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting returns: %w", err)
+	}
+
+	return returns, totalCount, nil
+}
+
+// ApplyStatusChange moves a return from change.From to change.To and records
+// the transition in the history table and the audit log. The update only
+// succeeds while the return is still in change.From, so concurrent
+// transitions cannot both win.
+func (r *repository) ApplyStatusChange(ctx context.Context, id int64, change StatusChange) (*Return, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE return_requests SET
+			status = $1,
+			label_url = COALESCE($2, label_url),
+			tracking_number = COALESCE($3, tracking_number),
+			refund_amount = COALESCE($4, refund_amount),
+			carrier = COALESCE($5, carrier),
+			drop_off_instructions = COALESCE($6, drop_off_instructions),
+			updated_at = NOW()
+		WHERE id = $7 AND status = $8
+		RETURNING *`
+
+	var before Return
+	if err := tx.GetContext(ctx, &before, `SELECT * FROM return_requests WHERE id = $1 FOR UPDATE`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("return not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting return: %w", err)
+	}
+
+	var ret Return
+	err = tx.GetContext(ctx, &ret, query,
+		change.To, change.LabelURL, change.TrackingNumber, change.RefundAmount,
+		change.Carrier, change.DropOffInstructions, id, change.From)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("return not found in status %s: %w", change.From, err)
+		}
+		return nil, fmt.Errorf("error updating return status: %w", err)
+	}
+
+	if err := insertHistory(ctx, tx, id, &change.From, change.To, change.Note); err != nil {
+		return nil, err
+	}
+	if err := audit.Record(ctx, tx, entityType, id, audit.ActionUpdate, &before, &ret); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing return status: %w", err)
+	}
+
+	if err := r.decrypt(&ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ListHistory retrieves the status history of a return, oldest first
+func (r *repository) ListHistory(ctx context.Context, returnID int64) ([]*HistoryEntry, error) {
+	var history []*HistoryEntry
+	query := `SELECT * FROM return_status_history WHERE return_id = $1 ORDER BY created_at, id`
+	err := r.db.SelectContext(ctx, &history, query, returnID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing return history: %w", err)
+	}
+	return history, nil
+}
+
+// GetByTrackingNumber retrieves the most recent return shipped with a tracking number
+func (r *repository) GetByTrackingNumber(ctx context.Context, trackingNumber string) (*Return, error) {
+	var ret Return
+	query := `SELECT * FROM return_requests WHERE tracking_number = $1 ORDER BY created_at DESC LIMIT 1`
+	err := r.db.GetContext(ctx, &ret, query, trackingNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("return not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting return by tracking number: %w", err)
+	}
+	if err := r.decrypt(&ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// UpdateShipmentStatus records the latest carrier status of a return shipment
+func (r *repository) UpdateShipmentStatus(ctx context.Context, id int64, status ShipmentStatus) error {
+	query := `UPDATE return_requests SET shipment_status = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, status, id)
+	if err != nil {
+		return fmt.Errorf("error updating return shipment status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("return not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func insertHistory(ctx context.Context, tx *sqlx.Tx, returnID int64, from *Status, to Status, note string) error {
+	query := `
+		INSERT INTO return_status_history (return_id, from_status, to_status, note)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := tx.ExecContext(ctx, query, returnID, from, to, note); err != nil {
+		return fmt.Errorf("error recording return history: %w", err)
+	}
+	return nil
+}
+
+// decrypt replaces the stored customer email with its plaintext
+func (r *repository) decrypt(ret *Return) error {
+	email, err := r.pii.Decrypt(ret.CustomerEmail)
+	if err != nil {
+		return fmt.Errorf("error decrypting customer email of return %d: %w", ret.ID, err)
+	}
+	ret.CustomerEmail = email
+	return nil
+}
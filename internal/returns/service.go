@@ -0,0 +1,361 @@
+package returns
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/jobs"
+	"github.com/dotslashbit/ecommerce-api/pkg/mailer"
+	"github.com/go-playground/validator"
+)
+
+// JobGenerateLabel is the job type that buys the label for an approved return
+const JobGenerateLabel = "return_label"
+
+// tokenPrefix marks return access tokens so they are recognisable in logs
+// and secret scanners
+const tokenPrefix = "rt_"
+
+var (
+	ErrReturnNotFound    = errors.New("return not found")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInvalidTransition = errors.New("invalid return status transition")
+	// ErrNotConfigured is returned by the automated paths whose refunder or
+	// label provider was not supplied
+	ErrNotConfigured = errors.New("not configured on this server")
+)
+
+// Refunder issues the refund for a received return. Payments live outside
+// this module, so the implementation is supplied by the caller.
+type Refunder interface {
+	Refund(ctx context.Context, ret *Return) error
+}
+
+// LabelProvider purchases return shipping labels from a carrier.
+type LabelProvider interface {
+	CreateLabel(ctx context.Context, ret *Return) (*Label, error)
+}
+
+type Service interface {
+	// RequestReturn creates a return and issues the access token the
+	// customer looks it up with.
+	RequestReturn(ctx context.Context, input CreateReturnInput) (*CreatedReturn, error)
+	GetReturnByID(ctx context.Context, id int64) (*Return, error)
+	GetReturnByToken(ctx context.Context, token string) (*Return, error)
+	ListReturns(ctx context.Context, filter ReturnFilter, pagination PaginationParams) ([]*Return, int, error)
+	GetReturnHistory(ctx context.Context, id int64) ([]*HistoryEntry, error)
+	ApproveReturn(ctx context.Context, id int64, input DecisionInput) (*Return, error)
+	RejectReturn(ctx context.Context, id int64, input DecisionInput) (*Return, error)
+	IssueLabel(ctx context.Context, id int64, input IssueLabelInput) (*Return, error)
+	ReceiveReturn(ctx context.Context, id int64, input ReceiveInput) (*Return, error)
+	RefundReturn(ctx context.Context, id int64) (*Return, error)
+	RecordTrackingEvent(ctx context.Context, input TrackingEventInput) (*Return, error)
+	// GenerateLabel is the JobGenerateLabel job handler.
+	GenerateLabel(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	refunder  Refunder
+	labels    LabelProvider
+	mail      mailer.Mailer
+	queue     jobs.Enqueuer
+	validator *validator.Validate
+}
+
+// NewService creates the returns service. refunder may be nil, in which case
+// received returns wait for an admin to confirm the refund manually and
+// carrier tracking events are refused with ErrNotConfigured, as a delivery
+// scan could not trigger the refund. labels may be nil, in which case
+// admins upload labels themselves.
+func NewService(repo Repository, refunder Refunder, labels LabelProvider, mail mailer.Mailer, queue jobs.Enqueuer) Service {
+	return &service{
+		repo:      repo,
+		refunder:  refunder,
+		labels:    labels,
+		mail:      mail,
+		queue:     queue,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) RequestReturn(ctx context.Context, input CreateReturnInput) (*CreatedReturn, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(token)
+
+	ret := &Return{
+		OrderID:         input.OrderID,
+		OrderLineID:     input.OrderLineID,
+		Quantity:        input.Quantity,
+		Reason:          input.Reason,
+		CustomerEmail:   input.CustomerEmail,
+		Status:          StatusRequested,
+		AccessTokenHash: &hash,
+	}
+
+	if err := s.repo.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return &CreatedReturn{Return: ret, Token: token}, nil
+}
+
+func (s *service) GetReturnByID(ctx context.Context, id int64) (*Return, error) {
+	ret, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (s *service) GetReturnByToken(ctx context.Context, token string) (*Return, error) {
+	if token == "" {
+		return nil, ErrReturnNotFound
+	}
+	ret, err := s.repo.GetByAccessTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (s *service) ListReturns(ctx context.Context, filter ReturnFilter, pagination PaginationParams) ([]*Return, int, error) {
+	if err := s.validator.Struct(pagination); err != nil {
+		return nil, 0, ErrInvalidInput
+	}
+
+	return s.repo.List(ctx, filter, pagination)
+}
+
+func (s *service) GetReturnHistory(ctx context.Context, id int64) ([]*HistoryEntry, error) {
+	if _, err := s.GetReturnByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListHistory(ctx, id)
+}
+
+// ApproveReturn approves a return and, when a label provider is configured,
+// queues the purchase of its shipping label.
+func (s *service) ApproveReturn(ctx context.Context, id int64, input DecisionInput) (*Return, error) {
+	ret, err := s.transition(ctx, id, StatusChange{To: StatusApproved, Note: input.Note})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.labels != nil {
+		payload := struct {
+			ReturnID int64 `json:"return_id"`
+		}{ReturnID: id}
+		if err := s.queue.Enqueue(ctx, JobGenerateLabel, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func (s *service) RejectReturn(ctx context.Context, id int64, input DecisionInput) (*Return, error) {
+	return s.transition(ctx, id, StatusChange{To: StatusRejected, Note: input.Note})
+}
+
+func (s *service) IssueLabel(ctx context.Context, id int64, input IssueLabelInput) (*Return, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	change := StatusChange{
+		To:             StatusLabelIssued,
+		LabelURL:       &input.LabelURL,
+		TrackingNumber: &input.TrackingNumber,
+	}
+	if input.Carrier != "" {
+		change.Carrier = &input.Carrier
+	}
+	if input.DropOffInstructions != "" {
+		change.DropOffInstructions = &input.DropOffInstructions
+	}
+
+	ret, err := s.transition(ctx, id, change)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := mailer.Render(mailer.TemplateReturnLabel, ret.CustomerEmail, mailer.ReturnLabelData{
+		ReturnID:            ret.ID,
+		LabelURL:            input.LabelURL,
+		TrackingNumber:      input.TrackingNumber,
+		Carrier:             input.Carrier,
+		DropOffInstructions: input.DropOffInstructions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mail.Send(ctx, msg); err != nil {
+		return nil, fmt.Errorf("error sending return label email: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (s *service) GenerateLabel(ctx context.Context, payload json.RawMessage) error {
+	var job struct {
+		ReturnID int64 `json:"return_id"`
+	}
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("error decoding return label job: %w", err)
+	}
+	if s.labels == nil {
+		return fmt.Errorf("error creating return label: label provider %w", ErrNotConfigured)
+	}
+
+	ret, err := s.GetReturnByID(ctx, job.ReturnID)
+	if err != nil {
+		return err
+	}
+	// A label may have been uploaded manually while the job was queued
+	if ret.Status != StatusApproved {
+		return nil
+	}
+
+	label, err := s.labels.CreateLabel(ctx, ret)
+	if err != nil {
+		return fmt.Errorf("error creating return label: %w", err)
+	}
+
+	_, err = s.IssueLabel(ctx, ret.ID, IssueLabelInput{
+		LabelURL:            label.URL,
+		TrackingNumber:      label.TrackingNumber,
+		Carrier:             label.Carrier,
+		DropOffInstructions: label.DropOffInstructions,
+	})
+	if err == ErrInvalidTransition {
+		return nil
+	}
+	return err
+}
+
+// RecordTrackingEvent stores a carrier status update for a return shipment.
+// A delivery scan marks the return received, which triggers the refund.
+func (s *service) RecordTrackingEvent(ctx context.Context, input TrackingEventInput) (*Return, error) {
+	if s.refunder == nil {
+		return nil, ErrNotConfigured
+	}
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	ret, err := s.repo.GetByTrackingNumber(ctx, input.TrackingNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.repo.UpdateShipmentStatus(ctx, ret.ID, input.Status); err != nil {
+		return nil, err
+	}
+
+	if input.Status == ShipmentDelivered && ret.Status == StatusLabelIssued {
+		return s.ReceiveReturn(ctx, ret.ID, ReceiveInput{Note: "Delivered to warehouse (carrier scan)"})
+	}
+
+	return s.GetReturnByID(ctx, ret.ID)
+}
+
+func (s *service) ReceiveReturn(ctx context.Context, id int64, input ReceiveInput) (*Return, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	ret, err := s.transition(ctx, id, StatusChange{
+		To:           StatusReceived,
+		Note:         input.Note,
+		RefundAmount: input.RefundAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.refunder == nil {
+		return ret, nil
+	}
+
+	return s.RefundReturn(ctx, id)
+}
+
+// RefundReturn issues the refund for a received return and marks it refunded.
+// It is called automatically on receipt and can be retried by an admin if
+// the refund failed or is handled outside the system.
+func (s *service) RefundReturn(ctx context.Context, id int64) (*Return, error) {
+	ret, err := s.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.Status.CanTransition(StatusRefunded) {
+		return nil, ErrInvalidTransition
+	}
+
+	if s.refunder != nil {
+		if err := s.refunder.Refund(ctx, ret); err != nil {
+			return nil, fmt.Errorf("error refunding return: %w", err)
+		}
+	}
+
+	return s.transition(ctx, id, StatusChange{To: StatusRefunded})
+}
+
+func (s *service) transition(ctx context.Context, id int64, change StatusChange) (*Return, error) {
+	ret, err := s.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.Status.CanTransition(change.To) {
+		return nil, ErrInvalidTransition
+	}
+
+	change.From = ret.Status
+	updated, err := s.repo.ApplyStatusChange(ctx, id, change)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another request moved the return out of change.From first
+			return nil, ErrInvalidTransition
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating return access token: %w", err)
+	}
+	return tokenPrefix + hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
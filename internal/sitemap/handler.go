@@ -0,0 +1,52 @@
+package sitemap
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/sitemap.xml", h.GetIndex)
+	router.GET("/sitemaps/:name", h.GetSitemap)
+}
+
+func (h *Handler) GetIndex(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	h.serve(w, r, IndexName)
+}
+
+func (h *Handler) GetSitemap(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.serve(w, r, ps.ByName("name"))
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, name string) {
+	sitemap, err := h.service.Get(r.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get sitemap", zap.String("name", name), zap.Error(err))
+		if err == ErrSitemapNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	if !sitemap.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", sitemap.ModTime.UTC().Format(http.TimeFormat))
+	}
+	w.Write(sitemap.Data)
+}
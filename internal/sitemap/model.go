@@ -0,0 +1,40 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Namespace is the XML namespace of sitemaps and sitemap indexes
+const Namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// Entry is a page listed in a sitemap
+type Entry struct {
+	Path    string    `db:"path"`
+	LastMod time.Time `db:"last_mod"`
+}
+
+// ProductEntry is a product page; ID pages through the product sitemaps
+type ProductEntry struct {
+	ID int64 `db:"id"`
+	Entry
+}
+
+// urlSet is a sitemap document
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []*url   `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// index is a sitemap index document listing the sub-sitemaps
+type index struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []*url   `xml:"sitemap"`
+}
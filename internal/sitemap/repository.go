@@ -0,0 +1,73 @@
+package sitemap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the catalog queries behind the sitemaps
+type Repository interface {
+	// CatalogVersion returns the latest catalog sync version of a store,
+	// which changes whenever one of its products is written or deleted
+	CatalogVersion(ctx context.Context, storeID int64) (int64, error)
+	// ListProducts retrieves up to limit published products of a store
+	// with an ID above afterID, in ID order
+	ListProducts(ctx context.Context, storeID, afterID int64, limit int) ([]*ProductEntry, error)
+	// ListCategories retrieves the categories of a store's published
+	// products with the time a product in each last changed
+	ListCategories(ctx context.Context, storeID int64) ([]*Entry, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CatalogVersion(ctx context.Context, storeID int64) (int64, error) {
+	query := `
+		SELECT GREATEST(
+			(SELECT COALESCE(MAX(sync_version), 0) FROM products WHERE store_id = $1),
+			(SELECT COALESCE(MAX(sync_version), 0) FROM product_tombstones WHERE store_id = $1))`
+
+	var version int64
+	if err := r.db.GetContext(ctx, &version, query, storeID); err != nil {
+		return 0, fmt.Errorf("error getting catalog version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *repository) ListProducts(ctx context.Context, storeID, afterID int64, limit int) ([]*ProductEntry, error) {
+	query := `
+		SELECT id, '/products/' || slug AS path, updated_at AS last_mod FROM products
+		WHERE store_id = $1 AND status = 'published' AND id > $2
+		ORDER BY id
+		LIMIT $3`
+
+	var products []*ProductEntry
+	if err := r.db.SelectContext(ctx, &products, query, storeID, afterID, limit); err != nil {
+		return nil, fmt.Errorf("error listing sitemap products: %w", err)
+	}
+	return products, nil
+}
+
+func (r *repository) ListCategories(ctx context.Context, storeID int64) ([]*Entry, error) {
+	query := `
+		SELECT category AS path, MAX(updated_at) AS last_mod
+		FROM products, unnest(categories) category
+		WHERE store_id = $1 AND status = 'published'
+		GROUP BY category
+		ORDER BY category`
+
+	var categories []*Entry
+	if err := r.db.SelectContext(ctx, &categories, query, storeID); err != nil {
+		return nil, fmt.Errorf("error listing sitemap categories: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,212 @@
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/store"
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+)
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+// JobRebuild is the periodic job that rebuilds the sitemaps of stores whose
+// catalog changed since the last build
+const JobRebuild = "sitemap_rebuild"
+
+// IndexName is the sitemap index listing every sub-sitemap of a store
+const IndexName = "sitemap.xml"
+
+// MaxURLs is the number of URLs the sitemap protocol allows per sitemap
+const MaxURLs = 50000
+
+var sitemapName = regexp.MustCompile(`^(sitemap|categories|products-[1-9][0-9]*)\.xml$`)
+
+// Stores lists the stores sitemaps are built for.
+type Stores interface {
+	ListStores(ctx context.Context) ([]*store.Store, error)
+}
+
+type Service interface {
+	// Get returns a built sitemap of the current store by file name.
+	Get(ctx context.Context, name string) (*blobstore.Object, error)
+	// Rebuild is the JobRebuild job handler.
+	Rebuild(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo          Repository
+	stores        Stores
+	blobs         blobstore.Store
+	storefrontURL string
+
+	// built is the catalog version each store's sitemaps were last built
+	// from
+	mu    sync.Mutex
+	built map[int64]int64
+}
+
+// NewService creates the sitemap service. Page URLs, and the sub-sitemaps
+// listed in the index, are on storefrontURL; the storefront is expected to
+// proxy /sitemap.xml and /sitemaps/ to the API.
+func NewService(repo Repository, stores Stores, blobs blobstore.Store, storefrontURL string) Service {
+	return &service{
+		repo:          repo,
+		stores:        stores,
+		blobs:         blobs,
+		storefrontURL: strings.TrimSuffix(storefrontURL, "/"),
+		built:         make(map[int64]int64),
+	}
+}
+
+func (s *service) Get(ctx context.Context, name string) (*blobstore.Object, error) {
+	if !sitemapName.MatchString(name) {
+		return nil, ErrSitemapNotFound
+	}
+
+	object, err := s.blobs.Get(ctx, key(tenant.StoreIDOrDefault(ctx), name))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, ErrSitemapNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s *service) Rebuild(ctx context.Context, _ json.RawMessage) error {
+	stores, err := s.stores.ListStores(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, st := range stores {
+		if err := s.rebuildStore(ctx, st.ID); err != nil {
+			errs = append(errs, fmt.Errorf("store %s: %w", st.Slug, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rebuildStore builds the sitemaps of a store unless its catalog is
+// unchanged since the last build. Sub-sitemaps are written before the index
+// so the index never lists a missing file.
+func (s *service) rebuildStore(ctx context.Context, storeID int64) error {
+	version, err := s.repo.CatalogVersion(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	built, ok := s.built[storeID]
+	s.mu.Unlock()
+	if ok && built == version {
+		return nil
+	}
+
+	var sitemaps []*url
+	var afterID int64
+	for page := 1; ; page++ {
+		products, err := s.repo.ListProducts(ctx, storeID, afterID, MaxURLs)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 && page > 1 {
+			break
+		}
+
+		entries := make([]*Entry, len(products))
+		for i, p := range products {
+			entries[i] = &p.Entry
+			afterID = p.ID
+		}
+		name := "products-" + strconv.Itoa(page) + ".xml"
+		lastMod, err := s.write(ctx, storeID, name, entries)
+		if err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, s.location(name, lastMod))
+
+		if len(products) < MaxURLs {
+			break
+		}
+	}
+
+	categories, err := s.repo.ListCategories(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		category.Path = "/categories/" + neturl.PathEscape(category.Path)
+	}
+	lastMod, err := s.write(ctx, storeID, "categories.xml", categories)
+	if err != nil {
+		return err
+	}
+	sitemaps = append(sitemaps, s.location("categories.xml", lastMod))
+
+	data, err := encode(&index{Xmlns: Namespace, Sitemaps: sitemaps})
+	if err != nil {
+		return err
+	}
+	if err := s.blobs.Put(ctx, key(storeID, IndexName), "application/xml", data); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.built[storeID] = version
+	s.mu.Unlock()
+	return nil
+}
+
+// write stores a sitemap of entries and returns its latest modification
+func (s *service) write(ctx context.Context, storeID int64, name string, entries []*Entry) (time.Time, error) {
+	var lastMod time.Time
+	set := &urlSet{Xmlns: Namespace, URLs: make([]*url, len(entries))}
+	for i, entry := range entries {
+		set.URLs[i] = &url{Loc: s.storefrontURL + entry.Path, LastMod: entry.LastMod.UTC().Format(time.RFC3339)}
+		if entry.LastMod.After(lastMod) {
+			lastMod = entry.LastMod
+		}
+	}
+
+	data, err := encode(set)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return lastMod, s.blobs.Put(ctx, key(storeID, name), "application/xml", data)
+}
+
+// location lists a sub-sitemap in the index
+func (s *service) location(name string, lastMod time.Time) *url {
+	loc := &url{Loc: s.storefrontURL + "/sitemaps/" + name}
+	if !lastMod.IsZero() {
+		loc.LastMod = lastMod.UTC().Format(time.RFC3339)
+	}
+	return loc
+}
+
+func encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("error encoding sitemap: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// key is the blob key of a store's sitemap
+func key(storeID int64, name string) string {
+	return fmt.Sprintf("sitemaps/%d/%s", storeID, name)
+}
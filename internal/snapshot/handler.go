@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/catalog-snapshots", h.CreateSnapshot)
+	router.POST("/admin/catalog-snapshots/:name/restore", h.RestoreSnapshot)
+}
+
+func (h *Handler) CreateSnapshot(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	info, err := h.service.Create(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to create catalog snapshot", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Created catalog snapshot", zap.String("name", info.Name))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+func (h *Handler) RestoreSnapshot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	name := ps.ByName("name")
+
+	info, err := h.service.Restore(r.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to restore catalog snapshot", zap.String("name", name), zap.Error(err))
+		switch err {
+		case ErrSnapshotNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidSnapshot:
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case ErrSchemaMismatch:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.logger.Info("Restored catalog snapshot", zap.String("name", name))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
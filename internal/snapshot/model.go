@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FormatVersion is bumped when the layout of snapshot files changes
+const FormatVersion = 1
+
+// table is a catalog table copied by snapshots. serial tables have an id
+// sequence that is moved past the restored rows.
+type table struct {
+	name   string
+	serial bool
+}
+
+// tables are the catalog tables in a snapshot, parents before children.
+// The catalog has no variants table; SKUs and barcodes live on products.
+var tables = []table{
+	{name: "brands", serial: true},
+	{name: "categories", serial: true},
+	{name: "products", serial: true},
+	{name: "product_bundle_items"},
+	{name: "product_translations"},
+	{name: "related_products"},
+	{name: "product_price_changes", serial: true},
+	{name: "price_history", serial: true},
+	{name: "attribute_definitions", serial: true},
+	{name: "feed_category_mappings", serial: true},
+	{name: "slug_redirects"},
+}
+
+// Snapshot is the content of a snapshot file. Rows are kept as the JSON
+// Postgres renders them in, so they load back with json_populate_recordset
+// whatever columns the tables have.
+type Snapshot struct {
+	FormatVersion int                        `json:"format_version"`
+	SchemaVersion int                        `json:"schema_version"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	Stores        json.RawMessage            `json:"stores"`
+	Tables        map[string]json.RawMessage `json:"tables"`
+	Rows          map[string]int             `json:"rows"`
+}
+
+// Info describes a stored snapshot.
+type Info struct {
+	Name          string         `json:"name"`
+	SchemaVersion int            `json:"schema_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Rows          map[string]int `json:"rows"`
+}
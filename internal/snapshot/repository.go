@@ -0,0 +1,186 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// entityType is the audit log entity of catalog restores
+const entityType = "catalog_snapshot"
+
+// Repository defines the interface for catalog snapshot data operations
+type Repository interface {
+	// Export reads every catalog table as of a single point in time
+	Export(ctx context.Context) (*Snapshot, error)
+	// Restore replaces the catalog with snapshot in one transaction
+	Restore(ctx context.Context, snapshot *Snapshot, info *Info) error
+	// SchemaVersion returns the latest migration applied to the database
+	SchemaVersion(ctx context.Context) (int, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository. Snapshots
+// cover the catalog of every store.
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Export(ctx context.Context) (*Snapshot, error) {
+	// A repeatable read transaction sees every table as of its first query,
+	// so writes during the export cannot tear the snapshot
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := &Snapshot{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Tables:        make(map[string]json.RawMessage, len(tables)),
+		Rows:          make(map[string]int, len(tables)),
+	}
+	if snapshot.SchemaVersion, err = schemaVersion(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Stores, _, err = exportTable(ctx, tx, "stores"); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		rows, count, err := exportTable(ctx, tx, t.name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Tables[t.name] = rows
+		snapshot.Rows[t.name] = count
+	}
+	return snapshot, nil
+}
+
+func exportTable(ctx context.Context, tx *sqlx.Tx, name string) (json.RawMessage, int, error) {
+	var rows []byte
+	var count int
+	query := fmt.Sprintf(`SELECT COALESCE(json_agg(t), '[]'), COUNT(*) FROM %s t`, pq.QuoteIdentifier(name))
+	if err := tx.QueryRowxContext(ctx, query).Scan(&rows, &count); err != nil {
+		return nil, 0, fmt.Errorf("error exporting %s: %w", name, err)
+	}
+	return rows, count, nil
+}
+
+func (r *repository) Restore(ctx context.Context, snapshot *Snapshot, info *Info) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Stores are not replaced, only created when the target lacks them
+	query := `INSERT INTO stores SELECT * FROM json_populate_recordset(NULL::stores, $1) ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, []byte(snapshot.Stores)); err != nil {
+		return fmt.Errorf("error restoring stores: %w", err)
+	}
+
+	// Children go first; deleting products also cascades to the customer
+	// data hanging off them, such as questions and stock subscriptions
+	for i := len(tables) - 1; i >= 0; i-- {
+		name := pq.QuoteIdentifier(tables[i].name)
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+name); err != nil {
+			return fmt.Errorf("error clearing %s: %w", tables[i].name, err)
+		}
+	}
+
+	for _, t := range tables {
+		if err := restoreTable(ctx, tx, t, snapshot.Tables[t.name]); err != nil {
+			return err
+		}
+	}
+
+	if err := audit.Record(ctx, tx, entityType, 0, audit.ActionRestore, nil, info); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func restoreTable(ctx context.Context, tx *sqlx.Tx, t table, rows json.RawMessage) error {
+	if len(rows) == 0 {
+		rows = json.RawMessage("[]")
+	}
+	name := pq.QuoteIdentifier(t.name)
+
+	if t.name == "products" {
+		if err := restoreProducts(ctx, tx, rows); err != nil {
+			return err
+		}
+	} else {
+		query := fmt.Sprintf(`INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1)`, name, name)
+		if _, err := tx.ExecContext(ctx, query, []byte(rows)); err != nil {
+			return fmt.Errorf("error restoring %s: %w", t.name, err)
+		}
+	}
+
+	if t.serial {
+		query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence($1, 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)`, name)
+		if _, err := tx.ExecContext(ctx, query, t.name); err != nil {
+			return fmt.Errorf("error resetting %s sequence: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// restoreProducts inserts products through a staging table. Vendors are not
+// part of the catalog, so products of vendors the target lacks are restored
+// as their store's own.
+func restoreProducts(ctx context.Context, tx *sqlx.Tx, rows json.RawMessage) error {
+	if _, err := tx.ExecContext(ctx, `CREATE TEMP TABLE snapshot_products (LIKE products) ON COMMIT DROP`); err != nil {
+		return fmt.Errorf("error staging products: %w", err)
+	}
+	query := `INSERT INTO snapshot_products SELECT * FROM json_populate_recordset(NULL::products, $1)`
+	if _, err := tx.ExecContext(ctx, query, []byte(rows)); err != nil {
+		return fmt.Errorf("error staging products: %w", err)
+	}
+	query = `
+		UPDATE snapshot_products SET vendor_id = NULL
+		WHERE vendor_id IS NOT NULL AND vendor_id NOT IN (SELECT id FROM vendors)`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error detaching unknown vendors: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO products SELECT * FROM snapshot_products`); err != nil {
+		return fmt.Errorf("error restoring products: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) SchemaVersion(ctx context.Context) (int, error) {
+	return schemaVersion(ctx, r.db)
+}
+
+// schemaVersion returns the latest applied migration, or 0 for databases
+// never migrated by the migrate command.
+func schemaVersion(ctx context.Context, q sqlx.QueryerContext) (int, error) {
+	// Checked first, as querying a missing table would abort the caller's
+	// transaction
+	var migrated bool
+	if err := sqlx.GetContext(ctx, q, &migrated, `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+		return 0, fmt.Errorf("error reading schema version: %w", err)
+	}
+	if !migrated {
+		return 0, nil
+	}
+
+	var version int
+	if err := sqlx.GetContext(ctx, q, &version, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+		return 0, fmt.Errorf("error reading schema version: %w", err)
+	}
+	return version, nil
+}
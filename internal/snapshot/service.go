@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/blobstore"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrInvalidSnapshot  = errors.New("snapshot file is not readable by this version of the API")
+	ErrSchemaMismatch   = errors.New("snapshot was taken at a different schema version; migrate both databases to the same version first")
+)
+
+// keyPrefix is where snapshots are kept in the blob store
+const keyPrefix = "snapshots/catalog/"
+
+// nameLayout names snapshots after the time they were taken
+const nameLayout = "catalog-20060102T150405Z"
+
+var snapshotName = regexp.MustCompile(`^catalog-[0-9]{8}T[0-9]{6}Z$`)
+
+type Service interface {
+	// Create exports the catalog to the blob store.
+	Create(ctx context.Context) (*Info, error)
+	// Restore replaces the catalog with the named snapshot. Rows hanging
+	// off replaced products, such as customer questions, are deleted.
+	Restore(ctx context.Context, name string) (*Info, error)
+}
+
+type service struct {
+	repo  Repository
+	blobs blobstore.Store
+}
+
+// NewService creates the snapshot service. A snapshot is a consistent
+// export of the catalog of every store; restoring it replaces the catalog
+// of the database it runs against, for instance to refresh staging from
+// production. Environments exchange snapshots through a shared bucket.
+func NewService(repo Repository, blobs blobstore.Store) Service {
+	return &service{
+		repo:  repo,
+		blobs: blobs,
+	}
+}
+
+func (s *service) Create(ctx context.Context) (*Info, error) {
+	snapshot, err := s.repo.Export(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("error encoding snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing snapshot: %w", err)
+	}
+
+	info := infoOf(snapshot)
+	if err := s.blobs.Put(ctx, key(info.Name), "application/gzip", buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("error storing snapshot: %w", err)
+	}
+	return info, nil
+}
+
+func (s *service) Restore(ctx context.Context, name string) (*Info, error) {
+	if !snapshotName.MatchString(name) {
+		return nil, ErrSnapshotNotFound
+	}
+
+	object, err := s.blobs.Get(ctx, key(name))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("error loading snapshot: %w", err)
+	}
+
+	snapshot, err := decode(object.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.repo.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.SchemaVersion != version {
+		return nil, ErrSchemaMismatch
+	}
+
+	info := infoOf(snapshot)
+	if err := s.repo.Restore(ctx, snapshot, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func decode(data []byte) (*Snapshot, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil || snapshot.FormatVersion != FormatVersion {
+		return nil, ErrInvalidSnapshot
+	}
+	return &snapshot, nil
+}
+
+func infoOf(snapshot *Snapshot) *Info {
+	return &Info{
+		Name:          snapshot.CreatedAt.Format(nameLayout),
+		SchemaVersion: snapshot.SchemaVersion,
+		CreatedAt:     snapshot.CreatedAt,
+		Rows:          snapshot.Rows,
+	}
+}
+
+func key(name string) string {
+	return keyPrefix + name + ".json.gz"
+}
@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/admin/stats", h.GetStats)
+}
+
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	stats, err := h.service.GetStats(r.Context(), refresh)
+	if err != nil {
+		h.logger.Error("Failed to compute admin stats", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"time"
+)
+
+// Stats is the admin dashboard summary.
+type Stats struct {
+	Catalog         CatalogStats     `json:"catalog"`
+	ReturnsByStatus map[string]int   `json:"returns_by_status"`
+	LowStock        []*LowStockAlert `json:"low_stock"`
+	ComputedAt      time.Time        `json:"computed_at"`
+	Stale           bool             `json:"stale"`
+}
+
+type CatalogStats struct {
+	TotalProducts  int     `db:"total_products" json:"total_products"`
+	OutOfStock     int     `db:"out_of_stock" json:"out_of_stock"`
+	LowStock       int     `db:"low_stock" json:"low_stock"`
+	TotalUnits     int64   `db:"total_units" json:"total_units"`
+	InventoryValue float64 `db:"inventory_value" json:"inventory_value"`
+}
+
+// LowStockAlert is a product at or below its low-stock threshold.
+type LowStockAlert struct {
+	ProductID     int64  `db:"id" json:"product_id"`
+	Name          string `db:"name" json:"name"`
+	StockQuantity int    `db:"stock_quantity" json:"stock_quantity"`
+}
+
+type statusCount struct {
+	Status string `db:"status"`
+	Count  int    `db:"count"`
+}
@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository defines the aggregate queries behind the dashboard
+type Repository interface {
+	CatalogStats(ctx context.Context) (*CatalogStats, error)
+	ReturnsByStatus(ctx context.Context) (map[string]int, error)
+	LowStock(ctx context.Context, limit int) ([]*LowStockAlert, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// CatalogStats aggregates product and stock counts in a single scan
+func (r *repository) CatalogStats(ctx context.Context) (*CatalogStats, error) {
+	query := `
+		SELECT
+			COUNT(*) AS total_products,
+			COUNT(*) FILTER (WHERE stock_quantity <= 0) AS out_of_stock,
+			COUNT(*) FILTER (WHERE stock_quantity > 0 AND stock_quantity <= low_stock_threshold) AS low_stock,
+			COALESCE(SUM(GREATEST(stock_quantity, 0)), 0) AS total_units,
+			COALESCE(SUM(GREATEST(stock_quantity, 0) * price), 0) AS inventory_value
+		FROM products`
+
+	var stats CatalogStats
+	if err := r.db.GetContext(ctx, &stats, query); err != nil {
+		return nil, fmt.Errorf("error getting catalog stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// ReturnsByStatus counts return requests per status
+func (r *repository) ReturnsByStatus(ctx context.Context) (map[string]int, error) {
+	var counts []statusCount
+	query := `SELECT status, COUNT(*) AS count FROM return_requests GROUP BY status`
+	if err := r.db.SelectContext(ctx, &counts, query); err != nil {
+		return nil, fmt.Errorf("error counting returns by status: %w", err)
+	}
+
+	byStatus := make(map[string]int, len(counts))
+	for _, c := range counts {
+		byStatus[c.Status] = c.Count
+	}
+	return byStatus, nil
+}
+
+// LowStock lists products at or below their threshold, lowest stock first
+func (r *repository) LowStock(ctx context.Context, limit int) ([]*LowStockAlert, error) {
+	alerts := []*LowStockAlert{}
+	query := `
+		SELECT id, name, stock_quantity FROM products
+		WHERE stock_quantity <= low_stock_threshold
+		ORDER BY stock_quantity, id
+		LIMIT $1`
+	if err := r.db.SelectContext(ctx, &alerts, query, limit); err != nil {
+		return nil, fmt.Errorf("error listing low stock products: %w", err)
+	}
+	return alerts, nil
+}
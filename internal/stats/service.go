@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"context"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/reportcache"
+)
+
+// lowStockAlertLimit caps the number of low-stock products in the dashboard
+const lowStockAlertLimit = 20
+
+// ReportName is the reportcache key of the dashboard statistics
+const ReportName = "admin_stats"
+
+type Service interface {
+	// GetStats returns the cached statistics, recomputing them when refresh
+	// is set.
+	GetStats(ctx context.Context, refresh bool) (*Stats, error)
+}
+
+type service struct {
+	repo  Repository
+	cache *reportcache.Cache
+}
+
+// NewService creates the stats service and registers the dashboard with
+// cache for background refresh.
+func NewService(repo Repository, cache *reportcache.Cache) Service {
+	s := &service{
+		repo:  repo,
+		cache: cache,
+	}
+	cache.Register(ReportName, s.compute)
+	return s
+}
+
+func (s *service) GetStats(ctx context.Context, refresh bool) (*Stats, error) {
+	result, err := s.cache.Get(ctx, ReportName, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy so the cached value is never modified
+	stats := *result.Data.(*Stats)
+	stats.ComputedAt = result.ComputedAt
+	stats.Stale = result.Stale
+	return &stats, nil
+}
+
+func (s *service) compute(ctx context.Context) (interface{}, error) {
+	catalog, err := s.repo.CatalogStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	returnsByStatus, err := s.repo.ReturnsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lowStock, err := s.repo.LowStock(ctx, lowStockAlertLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		Catalog:         *catalog,
+		ReturnsByStatus: returnsByStatus,
+		LowStock:        lowStock,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/stores", h.CreateStore)
+	router.GET("/admin/stores", h.ListStores)
+}
+
+func (h *Handler) CreateStore(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateStoreInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create store input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	store, err := h.service.CreateStore(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create store", zap.Error(err))
+		switch err {
+		case ErrInvalidInput:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case ErrSlugTaken:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(store)
+}
+
+func (h *Handler) ListStores(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	stores, err := h.service.ListStores(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list stores", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stores)
+}
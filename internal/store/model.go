@@ -0,0 +1,16 @@
+package store
+
+import "time"
+
+type Store struct {
+	ID        int64     `db:"id" json:"id"`
+	Slug      string    `db:"slug" json:"slug"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type CreateStoreInput struct {
+	// Slug is the subdomain and X-Store header value of the store
+	Slug string `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123,excludesall=."`
+	Name string `json:"name" validate:"required,max=255"`
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Repository defines the interface for store data operations
+type Repository interface {
+	Create(ctx context.Context, store *Store) error
+	List(ctx context.Context) ([]*Store, error)
+	GetBySlug(ctx context.Context, slug string) (*Store, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new store. It returns ErrSlugTaken when the slug is in use.
+func (r *repository) Create(ctx context.Context, store *Store) error {
+	query := `INSERT INTO stores (slug, name) VALUES ($1, $2) RETURNING *`
+	err := r.db.QueryRowxContext(ctx, query, store.Slug, store.Name).StructScan(store)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrSlugTaken
+		}
+		return fmt.Errorf("error creating store: %w", err)
+	}
+	return nil
+}
+
+// List retrieves all stores, oldest first
+func (r *repository) List(ctx context.Context) ([]*Store, error) {
+	stores := []*Store{}
+	if err := r.db.SelectContext(ctx, &stores, `SELECT * FROM stores ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("error listing stores: %w", err)
+	}
+	return stores, nil
+}
+
+// GetBySlug retrieves a single store by its slug
+func (r *repository) GetBySlug(ctx context.Context, slug string) (*Store, error) {
+	var store Store
+	err := r.db.GetContext(ctx, &store, `SELECT * FROM stores WHERE slug = $1`, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("store not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting store: %w", err)
+	}
+	return &store, nil
+}
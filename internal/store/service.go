@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrSlugTaken    = errors.New("store slug already in use")
+)
+
+type Service interface {
+	CreateStore(ctx context.Context, input CreateStoreInput) (*Store, error)
+	ListStores(ctx context.Context) ([]*Store, error)
+	// ResolveStore implements tenant.Resolver.
+	ResolveStore(ctx context.Context, slug string) (int64, error)
+}
+
+type service struct {
+	repo      Repository
+	validator *validator.Validate
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo:      repo,
+		validator: validator.New(),
+	}
+}
+
+func (s *service) CreateStore(ctx context.Context, input CreateStoreInput) (*Store, error) {
+	input.Slug = strings.ToLower(input.Slug)
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	store := &Store{Slug: input.Slug, Name: input.Name}
+	if err := s.repo.Create(ctx, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *service) ListStores(ctx context.Context) ([]*Store, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) ResolveStore(ctx context.Context, slug string) (int64, error) {
+	store, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, tenant.ErrStoreNotFound
+		}
+		return 0, err
+	}
+	return store.ID, nil
+}
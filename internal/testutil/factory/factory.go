@@ -0,0 +1,86 @@
+// Package factory builds domain values for tests. Every value has sensible
+// defaults, a unique ID and fixed timestamps so golden files stay stable;
+// pass override functions to change only what a test cares about.
+//
+// The API has no user or order model yet. Returns carry the order and line
+// IDs they refer to, which is what tests need for now.
+package factory
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/internal/product"
+	"github.com/dotslashbit/ecommerce-api/internal/returns"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/lib/pq"
+)
+
+// Epoch is the CreatedAt and UpdatedAt of every built value.
+var Epoch = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+var nextID atomic.Int64
+
+// Reset restarts ID numbering, so each golden test sees IDs from 1.
+func Reset() {
+	nextID.Store(0)
+}
+
+func id() int64 {
+	return nextID.Add(1)
+}
+
+// Product builds a published, in-stock product with strict overselling.
+func Product(overrides ...func(*product.Product)) *product.Product {
+	id := id()
+	p := &product.Product{
+		ID:                id,
+		StoreID:           tenant.DefaultStoreID,
+		Slug:              fmt.Sprintf("product-%d", id),
+		Name:              fmt.Sprintf("Product %d", id),
+		Description:       "A product for tests",
+		Price:             19.99,
+		Categories:        pq.StringArray{"general"},
+		Attributes:        product.Attributes{},
+		Status:            product.StatusPublished,
+		StockQuantity:     10,
+		OversellPolicy:    product.OversellStrict,
+		LowStockThreshold: 5,
+		CreatedAt:         Epoch,
+		UpdatedAt:         Epoch,
+	}
+	for _, override := range overrides {
+		override(p)
+	}
+	return p
+}
+
+// Products builds n products with the same overrides.
+func Products(n int, overrides ...func(*product.Product)) []*product.Product {
+	products := make([]*product.Product, n)
+	for i := range products {
+		products[i] = Product(overrides...)
+	}
+	return products
+}
+
+// Return builds a requested return of one item.
+func Return(overrides ...func(*returns.Return)) *returns.Return {
+	id := id()
+	r := &returns.Return{
+		ID:            id,
+		OrderID:       1000 + id,
+		OrderLineID:   2000 + id,
+		Quantity:      1,
+		Reason:        "Item arrived damaged",
+		CustomerEmail: fmt.Sprintf("customer%d@example.com", id),
+		Status:        returns.StatusRequested,
+		CreatedAt:     Epoch,
+		UpdatedAt:     Epoch,
+	}
+	for _, override := range overrides {
+		override(r)
+	}
+	return r
+}
@@ -0,0 +1,75 @@
+// Package golden compares HTTP responses in tests with snapshots stored
+// under the test package's testdata directory. Run the tests with -update
+// to rewrite the snapshots after an intended change, then review the diff.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// AssertResponse snapshots the status, the headers in headers and the body
+// of rec as testdata/<name>.golden. JSON bodies are indented so diffs are
+// readable.
+func AssertResponse(t *testing.T, name string, rec *httptest.ResponseRecorder, headers ...string) {
+	t.Helper()
+	Assert(t, name, Format(rec.Result(), rec.Body.Bytes(), headers...))
+}
+
+// Assert compares got with testdata/<name>.golden.
+func Assert(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("response does not match %s (run with -update to accept it)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// Format renders a response as the status line, the selected headers in
+// sorted order, a blank line and the body.
+func Format(resp *http.Response, body []byte, headers ...string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+
+	sort.Strings(headers)
+	for _, name := range headers {
+		for _, value := range resp.Header.Values(name) {
+			fmt.Fprintf(&buf, "%s: %s\n", http.CanonicalHeaderKey(name), value)
+		}
+	}
+	buf.WriteString("\n")
+
+	var indented bytes.Buffer
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	buf.Write(bytes.TrimRight(body, "\n"))
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
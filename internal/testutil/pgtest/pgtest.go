@@ -0,0 +1,147 @@
+//go:build integration
+
+// Package pgtest gives integration tests a real PostgreSQL database. The
+// first call starts a container with testcontainers and applies every
+// migration to a template database; each test then gets its own copy of
+// the template, so tests can run in parallel without cleaning up after
+// each other. Docker must be available.
+//
+// Integration tests are built with the integration tag:
+//
+//	go test -tags=integration ./...
+package pgtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// Image is the PostgreSQL image tests run against
+const Image = "postgres:16-alpine"
+
+// templateDB holds the migrated schema every test database is copied from
+const templateDB = "ecommerce_template"
+
+var (
+	once     sync.Once
+	server   *postgres.PostgresContainer
+	startErr error
+	sequence atomic.Int64
+)
+
+// NewDB returns a connection to a new, fully migrated database that is
+// dropped when t ends.
+func NewDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	once.Do(func() { startErr = start(context.Background()) })
+	if startErr != nil {
+		t.Fatalf("starting postgres: %v", startErr)
+	}
+
+	ctx := context.Background()
+	admin, err := sqlx.Connect("postgres", dsn(ctx, t, "postgres"))
+	if err != nil {
+		t.Fatalf("connecting to postgres: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("test_%d", sequence.Add(1))
+	if _, err := admin.ExecContext(ctx, fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s`, name, templateDB)); err != nil {
+		t.Fatalf("creating test database: %v", err)
+	}
+
+	db, err := sqlx.Connect("postgres", dsn(ctx, t, name))
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin, err := sqlx.Connect("postgres", dsn(ctx, t, "postgres"))
+		if err != nil {
+			t.Logf("dropping test database: %v", err)
+			return
+		}
+		defer admin.Close()
+		if _, err := admin.ExecContext(ctx, fmt.Sprintf(`DROP DATABASE %s`, name)); err != nil {
+			t.Logf("dropping test database: %v", err)
+		}
+	})
+	return db
+}
+
+// start runs the container and migrates the template database. The
+// container is removed by the testcontainers reaper when the test binary
+// exits.
+func start(ctx context.Context) error {
+	var err error
+	server, err = postgres.Run(ctx, Image,
+		postgres.WithDatabase(templateDB),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies())
+	if err != nil {
+		return err
+	}
+
+	connection, err := server.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return err
+	}
+	db, err := sqlx.Connect("postgres", connection)
+	if err != nil {
+		return err
+	}
+	// The template must have no open connections when it is copied
+	defer db.Close()
+	return migrate(ctx, db)
+}
+
+// migrate applies the up migrations in order, like the deployment does
+func migrate(ctx context.Context, db *sqlx.DB) error {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations")
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migrations found in %s", dir)
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, string(migration)); err != nil {
+			return fmt.Errorf("applying %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+func dsn(ctx context.Context, t *testing.T, database string) string {
+	t.Helper()
+	host, err := server.Host(ctx)
+	if err != nil {
+		t.Fatalf("getting postgres host: %v", err)
+	}
+	port, err := server.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		t.Fatalf("getting postgres port: %v", err)
+	}
+	return fmt.Sprintf("host=%s port=%s user=postgres password=postgres dbname=%s sslmode=disable", host, port.Port(), database)
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/webhooks", h.CreateSubscription)
+	router.GET("/admin/webhooks", h.ListSubscriptions)
+	router.GET("/admin/webhooks/:id", h.GetSubscription)
+	router.PUT("/admin/webhooks/:id", h.UpdateSubscription)
+	router.DELETE("/admin/webhooks/:id", h.DeleteSubscription)
+	router.GET("/admin/webhooks/:id/deliveries", h.ListDeliveries)
+}
+
+func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateSubscriptionInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create webhook input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.service.CreateSubscription(r.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create webhook subscription", zap.Error(err))
+		if err == ErrInvalidInput || err == ErrPrivateEndpoint {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	subs, err := h.service.ListSubscriptions(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list webhook subscriptions", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+func (h *Handler) GetSubscription(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.GetSubscription(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get webhook subscription", zap.Error(err))
+		if err == ErrSubscriptionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	var input UpdateSubscriptionInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode update webhook input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.UpdateSubscription(r.Context(), id, input)
+	if err != nil {
+		h.logger.Error("Failed to update webhook subscription", zap.Error(err))
+		switch err {
+		case ErrSubscriptionNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case ErrInvalidInput, ErrPrivateEndpoint:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteSubscription(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to delete webhook subscription", zap.Error(err))
+		if err == ErrSubscriptionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := h.parseID(w, ps)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	deliveries, totalCount, err := h.service.ListDeliveries(r.Context(), id, page, limit)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", zap.Error(err))
+		if err == ErrSubscriptionNotFound {
+			response.Errorf(w, r, http.StatusNotFound, "%s", err)
+		} else {
+			response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	response.List(w, r, deliveries, totalCount, page, limit)
+}
+
+func (h *Handler) parseID(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid webhook subscription ID", zap.Error(err))
+		http.Error(w, "Invalid webhook subscription ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
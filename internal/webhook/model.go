@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/events"
+	"github.com/lib/pq"
+)
+
+// AllEvents subscribes to every event type
+const AllEvents = "*"
+
+type Subscription struct {
+	ID         int64          `db:"id" json:"id"`
+	StoreID    int64          `db:"store_id" json:"store_id"`
+	URL        string         `db:"url" json:"url"`
+	EventTypes pq.StringArray `db:"event_types" json:"event_types"`
+	Secret     string         `db:"secret" json:"-"`
+	Active     bool           `db:"active" json:"active"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// CreatedSubscription is returned once on registration; it is the only
+// response that includes the signing secret.
+type CreatedSubscription struct {
+	*Subscription
+	Secret string `json:"secret"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type Delivery struct {
+	ID             int64          `db:"id" json:"id"`
+	SubscriptionID int64          `db:"subscription_id" json:"subscription_id"`
+	EventID        string         `db:"event_id" json:"event_id"`
+	EventType      string         `db:"event_type" json:"event_type"`
+	Attempt        int            `db:"attempt" json:"attempt"`
+	Status         DeliveryStatus `db:"status" json:"status"`
+	ResponseCode   *int           `db:"response_code" json:"response_code,omitempty"`
+	Error          *string        `db:"error" json:"error,omitempty"`
+	DurationMS     int            `db:"duration_ms" json:"duration_ms"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+}
+
+type CreateSubscriptionInput struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,required"`
+}
+
+type UpdateSubscriptionInput struct {
+	URL        *string   `json:"url" validate:"omitempty,url"`
+	EventTypes *[]string `json:"event_types" validate:"omitempty,min=1,dive,required"`
+	Active     *bool     `json:"active"`
+}
+
+// deliveryJob is the payload of a JobDeliver job
+type deliveryJob struct {
+	SubscriptionID int64            `json:"subscription_id"`
+	Event          *events.Envelope `json:"event"`
+}
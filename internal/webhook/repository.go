@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Repository defines the interface for webhook data operations
+type Repository interface {
+	Create(ctx context.Context, sub *Subscription) error
+	GetByID(ctx context.Context, id int64) (*Subscription, error)
+	List(ctx context.Context) ([]*Subscription, error)
+	// ListForEvent retrieves the active subscriptions of storeID for an
+	// event type
+	ListForEvent(ctx context.Context, storeID int64, eventType string) ([]*Subscription, error)
+	Update(ctx context.Context, id int64, input UpdateSubscriptionInput) error
+	Delete(ctx context.Context, id int64) error
+	RecordDelivery(ctx context.Context, delivery *Delivery) error
+	CountDeliveries(ctx context.Context, subscriptionID int64, eventID string) (int, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]*Delivery, int, error)
+}
+
+// repository is the SQL implementation of the Repository interface
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository creates a new instance of the SQL repository
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create adds a new subscription to the database
+func (r *repository) Create(ctx context.Context, sub *Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (store_id, url, event_types, secret, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, store_id, created_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query, tenant.StoreIDOrDefault(ctx), sub.URL, sub.EventTypes, sub.Secret, sub.Active).
+		StructScan(sub)
+	if err != nil {
+		return fmt.Errorf("error creating webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single subscription by its ID
+func (r *repository) GetByID(ctx context.Context, id int64) (*Subscription, error) {
+	var sub Subscription
+	query := `SELECT * FROM webhook_subscriptions WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	err := r.db.GetContext(ctx, &sub, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook subscription not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// List retrieves all subscriptions
+func (r *repository) List(ctx context.Context) ([]*Subscription, error) {
+	var subs []*Subscription
+	query := `SELECT * FROM webhook_subscriptions WHERE ($1::integer IS NULL OR store_id = $1) ORDER BY id`
+	if err := r.db.SelectContext(ctx, &subs, query, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// ListForEvent retrieves the active subscriptions of a store for an event type
+func (r *repository) ListForEvent(ctx context.Context, storeID int64, eventType string) ([]*Subscription, error) {
+	var subs []*Subscription
+	query := `SELECT * FROM webhook_subscriptions WHERE active AND store_id = $1 AND event_types && $2`
+	err := r.db.SelectContext(ctx, &subs, query, storeID, pq.StringArray{eventType, AllEvents})
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook subscriptions for event: %w", err)
+	}
+	return subs, nil
+}
+
+// Update modifies an existing subscription
+func (r *repository) Update(ctx context.Context, id int64, input UpdateSubscriptionInput) error {
+	query := `UPDATE webhook_subscriptions SET `
+	args := []interface{}{}
+	argID := 1
+
+	if input.URL != nil {
+		query += fmt.Sprintf("url = $%d, ", argID)
+		args = append(args, *input.URL)
+		argID++
+	}
+	if input.EventTypes != nil {
+		query += fmt.Sprintf("event_types = $%d, ", argID)
+		args = append(args, pq.StringArray(*input.EventTypes))
+		argID++
+	}
+	if input.Active != nil {
+		query += fmt.Sprintf("active = $%d, ", argID)
+		args = append(args, *input.Active)
+		argID++
+	}
+
+	query = strings.TrimSuffix(query, ", ")
+	if len(args) > 0 {
+		query += ", "
+	}
+	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d AND ($%d::integer IS NULL OR store_id = $%d)", argID, argID+1, argID+1)
+	args = append(args, id, tenant.StoreArg(ctx))
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// Delete removes a subscription and its delivery log
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+	result, err := r.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		return fmt.Errorf("error deleting webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RecordDelivery adds an entry to the delivery log
+func (r *repository) RecordDelivery(ctx context.Context, delivery *Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries
+			(subscription_id, event_id, event_type, attempt, status, response_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		delivery.SubscriptionID, delivery.EventID, delivery.EventType, delivery.Attempt,
+		delivery.Status, delivery.ResponseCode, delivery.Error, delivery.DurationMS).
+		StructScan(delivery)
+	if err != nil {
+		return fmt.Errorf("error recording webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// CountDeliveries returns how many attempts were made to deliver an event
+func (r *repository) CountDeliveries(ctx context.Context, subscriptionID int64, eventID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1 AND event_id = $2`
+	if err := r.db.GetContext(ctx, &count, query, subscriptionID, eventID); err != nil {
+		return 0, fmt.Errorf("error counting webhook deliveries: %w", err)
+	}
+	return count, nil
+}
+
+// ListDeliveries retrieves the delivery log of a subscription, newest first
+func (r *repository) ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]*Delivery, int, error) {
+	var deliveries []*Delivery
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	if err := r.db.SelectContext(ctx, &deliveries, query, subscriptionID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("error listing webhook deliveries: %w", err)
+	}
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1`
+	if err := r.db.GetContext(ctx, &totalCount, countQuery, subscriptionID); err != nil {
+		return nil, 0, fmt.Errorf("error counting webhook deliveries: %w", err)
+	}
+
+	return deliveries, totalCount, nil
+}
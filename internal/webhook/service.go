@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/events"
+	"github.com/dotslashbit/ecommerce-api/pkg/jobs"
+	"github.com/go-playground/validator"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrPrivateEndpoint      = errors.New("webhook URL must resolve to a public address")
+)
+
+// JobDeliver is the job type that POSTs one event to one subscription
+const JobDeliver = "webhook_delivery"
+
+// Request headers sent with every delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-ID"
+)
+
+const deliveryTimeout = 10 * time.Second
+
+type Service interface {
+	CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (*CreatedSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, id int64, input UpdateSubscriptionInput) error
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, id int64, page, limit int) ([]*Delivery, int, error)
+	// Dispatch enqueues a delivery job for every subscription to the event.
+	Dispatch(ctx context.Context, envelope *events.Envelope) error
+	// Deliver is the JobDeliver job handler.
+	Deliver(ctx context.Context, payload json.RawMessage) error
+}
+
+type service struct {
+	repo      Repository
+	queue     jobs.Enqueuer
+	client    *http.Client
+	validator *validator.Validate
+}
+
+func NewService(repo Repository, queue jobs.Enqueuer) Service {
+	return &service{
+		repo:      repo,
+		queue:     queue,
+		client:    newClient(),
+		validator: validator.New(),
+	}
+}
+
+func (s *service) CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (*CreatedSubscription, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if err := checkEndpoint(ctx, input.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := s.repo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return &CreatedSubscription{Subscription: sub, Secret: secret}, nil
+}
+
+func (s *service) GetSubscription(ctx context.Context, id int64) (*Subscription, error) {
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *service) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) UpdateSubscription(ctx context.Context, id int64, input UpdateSubscriptionInput) error {
+	if err := s.validator.Struct(input); err != nil {
+		return ErrInvalidInput
+	}
+	if input.URL != nil {
+		if err := checkEndpoint(ctx, *input.URL); err != nil {
+			return err
+		}
+	}
+
+	err := s.repo.Update(ctx, id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, id int64) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) ListDeliveries(ctx context.Context, id int64, page, limit int) ([]*Delivery, int, error) {
+	if _, err := s.GetSubscription(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListDeliveries(ctx, id, limit, (page-1)*limit)
+}
+
+func (s *service) Dispatch(ctx context.Context, envelope *events.Envelope) error {
+	subs, err := s.repo.ListForEvent(ctx, envelope.StoreID, envelope.Type)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		job := deliveryJob{SubscriptionID: sub.ID, Event: envelope}
+		if err := s.queue.Enqueue(ctx, JobDeliver, job, jobs.MaxAttempts(8)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Deliver(ctx context.Context, payload json.RawMessage) error {
+	var job deliveryJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("error decoding webhook delivery job: %w", err)
+	}
+
+	sub, err := s.repo.GetByID(ctx, job.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Subscription was deleted after the event was dispatched
+			return nil
+		}
+		return err
+	}
+	if !sub.Active {
+		return nil
+	}
+
+	attempts, err := s.repo.CountDeliveries(ctx, sub.ID, job.Event.ID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(job.Event)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook body: %w", err)
+	}
+
+	started := time.Now()
+	statusCode, deliveryErr := s.post(ctx, sub, job.Event, body)
+
+	delivery := &Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        job.Event.ID,
+		EventType:      job.Event.Type,
+		Attempt:        attempts + 1,
+		Status:         DeliverySucceeded,
+		DurationMS:     int(time.Since(started).Milliseconds()),
+	}
+	if statusCode != 0 {
+		delivery.ResponseCode = &statusCode
+	}
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		delivery.Status = DeliveryFailed
+		delivery.Error = &msg
+	}
+
+	if err := s.repo.RecordDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	// Returning the error makes the job queue retry with backoff
+	return deliveryErr
+}
+
+func (s *service) post(ctx context.Context, sub *Subscription, envelope *events.Envelope, body []byte) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, envelope.Type)
+	req.Header.Set(HeaderEventID, envelope.ID)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Webhook-Signature value for body: "t=<unix>,v1=<hex>",
+// where v1 is the HMAC-SHA256 of "<unix>.<body>" keyed with the secret.
+// Receivers should recompute it and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// publisher feeds published domain events into webhook dispatch
+type publisher struct {
+	service Service
+}
+
+// NewPublisher adapts the webhook service to events.Publisher so the outbox
+// relay can fan events out to subscribers
+func NewPublisher(service Service) events.Publisher {
+	return &publisher{service: service}
+}
+
+func (p *publisher) Publish(ctx context.Context, envelope *events.Envelope) error {
+	return p.service.Dispatch(ctx, envelope)
+}
+
+func (p *publisher) Close() error {
+	return nil
+}
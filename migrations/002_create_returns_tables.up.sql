@@ -0,0 +1,33 @@
+-- Create return requests table
+CREATE TABLE IF NOT EXISTS return_requests (
+    id SERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    order_line_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason TEXT NOT NULL,
+    customer_email VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'requested',
+    label_url TEXT,
+    tracking_number VARCHAR(255),
+    refund_amount DECIMAL(10, 2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on order lines for duplicate return lookups
+CREATE INDEX idx_return_requests_order_line ON return_requests (order_id, order_line_id);
+
+-- Create index on status for the admin queue
+CREATE INDEX idx_return_requests_status ON return_requests (status);
+
+-- Create return status history table
+CREATE TABLE IF NOT EXISTS return_status_history (
+    id SERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES return_requests (id) ON DELETE CASCADE,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_return_status_history_return_id ON return_status_history (return_id);
@@ -0,0 +1,15 @@
+-- Create catalog policies table
+CREATE TABLE IF NOT EXISTS catalog_policies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rule VARCHAR(64) NOT NULL,
+    category TEXT,
+    min_value INTEGER,
+    words TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on enabled policies, which are loaded on every product write
+CREATE INDEX idx_catalog_policies_enabled ON catalog_policies (enabled);
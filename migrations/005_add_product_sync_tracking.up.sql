@@ -0,0 +1,46 @@
+-- Create sequence shared by product changes and deletions
+CREATE SEQUENCE IF NOT EXISTS catalog_sync_seq;
+
+-- Track the version at which each product was created and last changed
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT nextval('catalog_sync_seq');
+ALTER TABLE products ADD COLUMN IF NOT EXISTS created_version BIGINT NOT NULL DEFAULT 0;
+UPDATE products SET created_version = sync_version WHERE created_version = 0;
+
+CREATE INDEX idx_products_sync_version ON products (sync_version);
+
+-- Create tombstones for deleted products
+CREATE TABLE IF NOT EXISTS product_tombstones (
+    product_id BIGINT PRIMARY KEY,
+    sync_version BIGINT NOT NULL DEFAULT nextval('catalog_sync_seq'),
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_tombstones_sync_version ON product_tombstones (sync_version);
+
+-- Bump the version on every insert and update
+CREATE OR REPLACE FUNCTION products_bump_sync_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sync_version := nextval('catalog_sync_seq');
+    IF TG_OP = 'INSERT' THEN
+        NEW.created_version := NEW.sync_version;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_sync_version
+    BEFORE INSERT OR UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION products_bump_sync_version();
+
+-- Record a tombstone whenever a product is deleted
+CREATE OR REPLACE FUNCTION products_record_tombstone() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO product_tombstones (product_id) VALUES (OLD.id)
+    ON CONFLICT (product_id) DO UPDATE SET sync_version = nextval('catalog_sync_seq'), deleted_at = CURRENT_TIMESTAMP;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_tombstone
+    AFTER DELETE ON products
+    FOR EACH ROW EXECUTE FUNCTION products_record_tombstone();
@@ -0,0 +1,4 @@
+-- Add stock tracking and oversell policy to products
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock_quantity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS oversell_policy VARCHAR(32) NOT NULL DEFAULT 'strict';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS oversell_limit INTEGER NOT NULL DEFAULT 0 CHECK (oversell_limit >= 0);
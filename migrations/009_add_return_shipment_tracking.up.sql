@@ -0,0 +1,7 @@
+-- Add label details and return shipment tracking to return requests
+ALTER TABLE return_requests ADD COLUMN IF NOT EXISTS carrier VARCHAR(64);
+ALTER TABLE return_requests ADD COLUMN IF NOT EXISTS drop_off_instructions TEXT;
+ALTER TABLE return_requests ADD COLUMN IF NOT EXISTS shipment_status VARCHAR(32);
+
+-- Create index on tracking numbers for carrier status updates
+CREATE INDEX idx_return_requests_tracking_number ON return_requests (tracking_number);
@@ -0,0 +1,18 @@
+-- Create audit log table
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    entity_type VARCHAR(64) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    before JSONB,
+    after JSONB,
+    diff JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(64),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for the admin audit log filters
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor, created_at DESC);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
@@ -0,0 +1,5 @@
+-- Add per-product low-stock alert threshold
+ALTER TABLE products ADD COLUMN IF NOT EXISTS low_stock_threshold INTEGER NOT NULL DEFAULT 5 CHECK (low_stock_threshold >= 0);
+
+-- Create index to list low-stock products
+CREATE INDEX idx_products_low_stock ON products (stock_quantity) WHERE stock_quantity <= low_stock_threshold;
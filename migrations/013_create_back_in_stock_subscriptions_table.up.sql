@@ -0,0 +1,12 @@
+-- Create back-in-stock subscriptions table
+CREATE TABLE IF NOT EXISTS back_in_stock_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One pending subscription per product and email
+CREATE UNIQUE INDEX idx_back_in_stock_pending ON back_in_stock_subscriptions (product_id, email) WHERE notified_at IS NULL;
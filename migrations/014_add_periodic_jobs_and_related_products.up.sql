@@ -0,0 +1,14 @@
+-- Add periodic jobs; at most one run of each periodic job type is queued
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS periodic BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX idx_jobs_periodic_next ON jobs (type) WHERE periodic AND status IN ('pending', 'running');
+
+-- Create related products table, rebuilt by the related_products_refresh job
+CREATE TABLE IF NOT EXISTS related_products (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    related_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (product_id, related_id)
+);
+
+CREATE INDEX idx_related_products_score ON related_products (product_id, score DESC);
@@ -0,0 +1,9 @@
+-- Create recently viewed products table, one row per session and product
+CREATE TABLE IF NOT EXISTS recently_viewed (
+    session_id VARCHAR(128) NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, product_id)
+);
+
+CREATE INDEX idx_recently_viewed_session ON recently_viewed (session_id, viewed_at DESC);
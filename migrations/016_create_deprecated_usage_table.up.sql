@@ -0,0 +1,9 @@
+-- Create deprecated API usage table, one row per deprecation and client
+CREATE TABLE IF NOT EXISTS deprecated_usage (
+    notice_id VARCHAR(128) NOT NULL,
+    client VARCHAR(128) NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (notice_id, client)
+);
@@ -0,0 +1,17 @@
+-- Create analytics events table, partitioned by month. The analytics
+-- partition job creates upcoming monthly partitions; the default partition
+-- only catches rows if that job falls behind.
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id BIGSERIAL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('view', 'add_to_cart', 'purchase')),
+    product_id INTEGER NOT NULL,
+    session_id VARCHAR(128) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+
+CREATE TABLE IF NOT EXISTS analytics_events_default PARTITION OF analytics_events DEFAULT;
+
+CREATE INDEX idx_analytics_events_product ON analytics_events (product_id, occurred_at);
+CREATE INDEX idx_analytics_events_occurred ON analytics_events (occurred_at);
@@ -0,0 +1,10 @@
+-- Widen customer email columns to hold encrypted values
+ALTER TABLE return_requests ALTER COLUMN customer_email TYPE TEXT;
+ALTER TABLE back_in_stock_subscriptions ALTER COLUMN email TYPE TEXT;
+
+-- Add blind index so encrypted subscriber emails stay unique per product;
+-- the re-encryption job fills it for existing rows
+ALTER TABLE back_in_stock_subscriptions ADD COLUMN email_hash CHAR(64);
+
+DROP INDEX IF EXISTS idx_back_in_stock_pending;
+CREATE UNIQUE INDEX idx_back_in_stock_pending ON back_in_stock_subscriptions (product_id, email_hash) WHERE notified_at IS NULL;
@@ -0,0 +1,25 @@
+-- Create stores table; store 1 owns everything written before stores existed
+CREATE TABLE IF NOT EXISTS stores (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO stores (id, slug, name) VALUES (1, 'default', 'Default store') ON CONFLICT (id) DO NOTHING;
+SELECT setval('stores_id_seq', GREATEST((SELECT MAX(id) FROM stores), 1));
+
+-- Scope products to a store
+ALTER TABLE products ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id);
+CREATE INDEX idx_products_store ON products (store_id, created_at DESC);
+
+-- Keep the store of deleted products so sync feeds stay per store
+ALTER TABLE product_tombstones ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION products_record_tombstone() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO product_tombstones (product_id, store_id) VALUES (OLD.id, OLD.store_id)
+    ON CONFLICT (product_id) DO UPDATE SET sync_version = nextval('catalog_sync_seq'), deleted_at = CURRENT_TIMESTAMP;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,20 @@
+-- Create marketplace vendors table
+CREATE TABLE IF NOT EXISTS vendors (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    name VARCHAR(255) NOT NULL,
+    contact_email TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_vendors_store_status ON vendors (store_id, status);
+
+-- Products and API keys may belong to a vendor
+ALTER TABLE products ADD COLUMN vendor_id INTEGER REFERENCES vendors (id);
+CREATE INDEX idx_products_vendor ON products (vendor_id) WHERE vendor_id IS NOT NULL;
+
+ALTER TABLE api_keys ADD COLUMN vendor_id INTEGER REFERENCES vendors (id);
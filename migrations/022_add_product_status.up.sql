@@ -0,0 +1,4 @@
+-- Add a publishing status to products; existing products stay published
+ALTER TABLE products ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'pending_review', 'published', 'archived'));
+CREATE INDEX idx_products_status ON products (store_id, status, created_at DESC);
@@ -0,0 +1,16 @@
+-- Schedule products to be published at a set time
+ALTER TABLE products ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_products_publish_at ON products (publish_at) WHERE publish_at IS NOT NULL;
+
+-- Create scheduled price changes table
+CREATE TABLE IF NOT EXISTS product_price_changes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price DECIMAL(10, 2) NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_price_changes_product ON product_price_changes (product_id, effective_at);
+CREATE INDEX idx_product_price_changes_due ON product_price_changes (effective_at) WHERE applied_at IS NULL;
@@ -0,0 +1,11 @@
+-- Add time-boxed sale prices to products
+ALTER TABLE products
+    ADD COLUMN sale_price DECIMAL(10, 2),
+    ADD COLUMN sale_starts_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN sale_ends_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT products_sale_window CHECK (
+        (sale_price IS NULL AND sale_starts_at IS NULL AND sale_ends_at IS NULL)
+        OR (sale_price IS NOT NULL AND sale_starts_at IS NOT NULL AND sale_ends_at > sale_starts_at)
+    );
+
+CREATE INDEX idx_products_sale_window ON products (sale_starts_at, sale_ends_at) WHERE sale_price IS NOT NULL;
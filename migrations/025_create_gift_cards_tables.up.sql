@@ -0,0 +1,31 @@
+-- Create gift cards table; codes are stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    last_four VARCHAR(4) NOT NULL,
+    initial_balance DECIMAL(10, 2) NOT NULL CHECK (initial_balance > 0),
+    balance DECIMAL(10, 2) NOT NULL CHECK (balance >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_gift_cards_store ON gift_cards (store_id, created_at DESC);
+CREATE INDEX idx_gift_cards_expiry ON gift_cards (expires_at) WHERE balance > 0;
+
+-- Create gift card ledger; every balance change is recorded here
+CREATE TABLE IF NOT EXISTS gift_card_ledger (
+    id SERIAL PRIMARY KEY,
+    gift_card_id INTEGER NOT NULL REFERENCES gift_cards (id),
+    kind VARCHAR(20) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    balance_after DECIMAL(10, 2) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_gift_card_ledger_card ON gift_card_ledger (gift_card_id, created_at);
+-- A checkout reference redeems a card at most once
+CREATE UNIQUE INDEX idx_gift_card_ledger_redemption ON gift_card_ledger (gift_card_id, reference) WHERE kind = 'redeem';
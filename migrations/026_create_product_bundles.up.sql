@@ -0,0 +1,13 @@
+-- Bundles are products sold as a set of component products
+ALTER TABLE products ADD COLUMN is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create bundle components table; a product in a bundle cannot be deleted
+CREATE TABLE IF NOT EXISTS product_bundle_items (
+    bundle_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    component_id INTEGER NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX idx_product_bundle_items_component ON product_bundle_items (component_id);
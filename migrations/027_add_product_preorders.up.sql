@@ -0,0 +1,2 @@
+-- Pre-orderable products sell without stock until inventory arrives
+ALTER TABLE products ADD COLUMN preorder_available_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,15 @@
+-- Create price history table; every change to a product's price is recorded
+CREATE TABLE IF NOT EXISTS price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    old_price DECIMAL(10, 2),
+    new_price DECIMAL(10, 2) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_history_product ON price_history (product_id, changed_at DESC);
+
+-- Start the history of existing products at their current price
+INSERT INTO price_history (product_id, new_price, actor, changed_at)
+SELECT id, price, 'migration', created_at FROM products;
@@ -0,0 +1,21 @@
+-- Create product attributes; specs are stored on the product as a flat
+-- JSON object of strings, numbers and booleans
+ALTER TABLE products ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX idx_products_attributes ON products USING GIN (attributes);
+
+-- Attribute definitions describe the specs expected of products in a category
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    category TEXT NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('text', 'number', 'boolean')),
+    unit VARCHAR(32),
+    allowed_values TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_attribute_definitions_key ON attribute_definitions (store_id, lower(category), key);
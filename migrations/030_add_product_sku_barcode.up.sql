@@ -0,0 +1,7 @@
+-- Add SKUs and barcodes to products; both are unique within a store and
+-- SKUs are matched case-insensitively
+ALTER TABLE products ADD COLUMN sku VARCHAR(64);
+ALTER TABLE products ADD COLUMN barcode VARCHAR(14);
+
+CREATE UNIQUE INDEX idx_products_sku ON products (store_id, lower(sku));
+CREATE UNIQUE INDEX idx_products_barcode ON products (store_id, barcode);
@@ -0,0 +1,12 @@
+-- Create feed category mappings; catalog categories are mapped to the Google
+-- product taxonomy in shopping feeds
+CREATE TABLE IF NOT EXISTS feed_category_mappings (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    category TEXT NOT NULL,
+    google_category VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_feed_category_mappings_category ON feed_category_mappings (store_id, lower(category));
@@ -0,0 +1,11 @@
+-- Create product translations; products store their content in the default
+-- locale and translations hold the name and description in other locales
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    locale VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, locale)
+);
@@ -0,0 +1,10 @@
+-- Create webhook receipts table; records the events received from each
+-- webhook provider so replayed deliveries are processed only once
+CREATE TABLE IF NOT EXISTS webhook_receipts (
+    provider VARCHAR(64) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
+
+CREATE INDEX idx_webhook_receipts_received_at ON webhook_receipts (received_at);
@@ -0,0 +1,26 @@
+-- Create product questions and answers; customers ask questions on a
+-- product and store staff or the product's vendor answer them
+CREATE TABLE IF NOT EXISTS product_questions (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    author_name VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_questions_product ON product_questions (product_id, status, created_at DESC);
+CREATE INDEX idx_product_questions_status ON product_questions (store_id, status, created_at);
+
+CREATE TABLE IF NOT EXISTS product_answers (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES product_questions (id) ON DELETE CASCADE,
+    vendor_id INTEGER REFERENCES vendors (id),
+    body TEXT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_answers_question ON product_answers (question_id, created_at);
@@ -0,0 +1,14 @@
+-- Create categories table; arranges the category names products are
+-- tagged with into a tree
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    parent_id INTEGER REFERENCES categories (id),
+    name VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_categories_name ON categories (store_id, lower(name));
+CREATE INDEX idx_categories_parent ON categories (parent_id);
@@ -0,0 +1,21 @@
+-- Create brands table; products may belong to a brand of their store
+CREATE TABLE IF NOT EXISTS brands (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    logo_content_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (id, store_id)
+);
+
+CREATE UNIQUE INDEX idx_brands_slug ON brands (store_id, slug);
+
+-- The brand must belong to the product's store
+ALTER TABLE products ADD COLUMN IF NOT EXISTS brand_id INTEGER;
+ALTER TABLE products ADD CONSTRAINT fk_products_brand
+    FOREIGN KEY (brand_id, store_id) REFERENCES brands (id, store_id);
+
+CREATE INDEX idx_products_brand ON products (brand_id);
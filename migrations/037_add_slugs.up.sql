@@ -0,0 +1,28 @@
+-- Create slugs for products and categories, and the redirects that keep
+-- old slugs working after they change
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+
+-- Existing rows get a slug from their name; the older row keeps a shared
+-- slug and later ones are told apart by their ID
+UPDATE products SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(left(name, 80)), '[^a-z0-9]+', '-', 'g')), ''), 'product');
+UPDATE products p SET slug = p.slug || '-' || p.id
+WHERE EXISTS (SELECT 1 FROM products q WHERE q.store_id = p.store_id AND q.slug = p.slug AND q.id < p.id);
+
+UPDATE categories SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(left(name, 80)), '[^a-z0-9]+', '-', 'g')), ''), 'category');
+UPDATE categories c SET slug = c.slug || '-' || c.id
+WHERE EXISTS (SELECT 1 FROM categories d WHERE d.store_id = c.store_id AND d.slug = c.slug AND d.id < c.id);
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX idx_products_slug ON products (store_id, slug);
+CREATE UNIQUE INDEX idx_categories_slug ON categories (store_id, slug);
+
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    store_id INTEGER NOT NULL REFERENCES stores (id),
+    entity_type VARCHAR(50) NOT NULL,
+    old_slug VARCHAR(100) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (store_id, entity_type, old_slug)
+);
@@ -0,0 +1,16 @@
+-- Create exports table; files such as accounting exports are generated by
+-- a job and kept in object storage until downloaded
+CREATE TABLE IF NOT EXISTS exports (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CHECK (from_date <= to_date)
+);
+
+CREATE INDEX idx_exports_kind ON exports (kind, created_at DESC);
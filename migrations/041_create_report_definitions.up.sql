@@ -0,0 +1,18 @@
+-- Create report definitions; the report delivery job generates each enabled
+-- report once per day or week and emails it or uploads it to object storage
+CREATE TABLE IF NOT EXISTS report_definitions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('sales', 'inventory')),
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    delivery VARCHAR(20) NOT NULL CHECK (delivery IN ('email', 'storage')),
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_definitions_due ON report_definitions (next_run_at) WHERE enabled;
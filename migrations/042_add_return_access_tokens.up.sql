@@ -0,0 +1,7 @@
+-- Add access tokens to return requests. Customers look up their return with
+-- the token issued at creation instead of its sequential ID; only a SHA-256
+-- hash is stored. Returns created before this have no token and are only
+-- visible to admins.
+ALTER TABLE return_requests ADD COLUMN IF NOT EXISTS access_token_hash CHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_return_requests_access_token_hash ON return_requests (access_token_hash);
@@ -0,0 +1,6 @@
+-- Scope idempotency keys to the store and client that sent them, so two
+-- clients choosing the same key never get each other's responses
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS scope VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (scope, key);
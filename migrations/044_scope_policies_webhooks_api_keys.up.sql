@@ -0,0 +1,13 @@
+-- Scope catalog policies, webhook subscriptions and API keys to a store;
+-- existing rows belong to the default store
+ALTER TABLE catalog_policies ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id);
+CREATE INDEX idx_catalog_policies_store ON catalog_policies (store_id);
+
+ALTER TABLE webhook_subscriptions ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id);
+CREATE INDEX idx_webhook_subscriptions_store ON webhook_subscriptions (store_id);
+
+ALTER TABLE api_keys ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores (id);
+CREATE INDEX idx_api_keys_store ON api_keys (store_id);
+
+-- Keep the store of every event so webhooks only receive their own store's
+ALTER TABLE outbox ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1;
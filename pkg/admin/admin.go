@@ -0,0 +1,181 @@
+// Package admin keeps the accounts of the people who run the store. The
+// first account is provisioned from the command line or, on first run,
+// from the admin_email and admin_password settings, as there is nobody yet
+// to create it through the API. Passwords are stored as bcrypt hashes.
+//
+// Admins sign in with POST /admin/login for a bearer session token, which
+// Guard requires on every other admin route. Failed sign-ins are throttled
+// per account and per client IP, first with growing delays and then with a
+// temporary lockout, and recorded in the audit log.
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Roles an admin user can hold
+const (
+	// RoleAdmin may do everything, including managing other admins
+	RoleAdmin = "admin"
+)
+
+// MinPasswordLength is the shortest password accepted
+const MinPasswordLength = 12
+
+const entityType = "admin_user"
+
+var (
+	ErrUserNotFound       = errors.New("admin user not found")
+	ErrEmailTaken         = errors.New("an admin user with this email already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+)
+
+type User struct {
+	ID           int64     `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         string    `db:"role" json:"role"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Store persists admin users.
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// Create adds an admin user with the given password.
+func (s *Store) Create(ctx context.Context, email, password, role string) (*User, error) {
+	if len(password) < MinPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user := &User{Email: strings.TrimSpace(email), PasswordHash: string(hash), Role: role}
+	query := `
+		INSERT INTO admin_users (email, password_hash, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`
+	err = tx.QueryRowxContext(ctx, query, user.Email, user.PasswordHash, user.Role).
+		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("error creating admin user: %w", err)
+	}
+
+	if err := audit.Record(ctx, tx, entityType, user.ID, audit.ActionCreate, nil, user); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return user, nil
+}
+
+// Count returns how many admin users exist.
+func (s *Store) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := s.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM admin_users`); err != nil {
+		return 0, fmt.Errorf("error counting admin users: %w", err)
+	}
+	return count, nil
+}
+
+// FindByEmail retrieves the admin user with the given email, ignoring case.
+func (s *Store) FindByEmail(ctx context.Context, email string) (*User, error) {
+	var user User
+	query := `SELECT * FROM admin_users WHERE lower(email) = lower($1)`
+	if err := s.db.GetContext(ctx, &user, query, strings.TrimSpace(email)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error finding admin user: %w", err)
+	}
+	return &user, nil
+}
+
+// dummyHash is compared against for unknown emails, so they take as long
+// to refuse as wrong passwords
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// Authenticate returns the admin user with email if password matches. It
+// returns ErrInvalidCredentials for unknown emails and wrong passwords
+// alike, in about the same time, so responses do not tell which emails
+// have accounts.
+func (s *Store) Authenticate(ctx context.Context, email, password string) (*User, error) {
+	user, err := s.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Bootstrap creates the first admin user with email and password, unless
+// an admin user exists already. It returns nil when nothing was created.
+func (s *Store) Bootstrap(ctx context.Context, email, password string) (*User, error) {
+	count, err := s.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, nil
+	}
+	return s.Create(ctx, email, password, RoleAdmin)
+}
+
+// RecordEvent records a security event of the admin user with userID in
+// the audit log; userID is 0 when the event names no known user.
+func (s *Store) RecordEvent(ctx context.Context, userID int64, action string, details interface{}) error {
+	return audit.RecordEvent(ctx, s.db, entityType, userID, action, details)
+}
+
+// GeneratePassword returns a random 24 character password.
+func GeneratePassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
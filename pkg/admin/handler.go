@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/go-playground/validator"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Handler signs admins in.
+type Handler struct {
+	store      *Store
+	throttle   *Throttle
+	logger     *zap.Logger
+	sessionTTL time.Duration
+	validator  *validator.Validate
+}
+
+// NewHandler creates the sign-in handler. Failed sign-ins are slowed down
+// and locked out by throttle; sign-ins, failures and lockouts are recorded
+// in the audit log.
+func NewHandler(store *Store, throttle *Throttle, logger *zap.Logger, sessionTTL time.Duration) *Handler {
+	return &Handler{
+		store:      store,
+		throttle:   throttle,
+		logger:     logger,
+		sessionTTL: sessionTTL,
+		validator:  validator.New(),
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/login", h.Login)
+	router.POST("/admin/logout", h.Logout)
+	router.GET("/admin/me", h.Me)
+}
+
+type LoginInput struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,max=1024"`
+}
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input LoginInput
+	if err := request.Decode(r, &input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(input); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	ip := audit.ActorFrom(ctx).IP
+	if wait, locked := h.throttle.Check(input.Email, ip); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		if locked {
+			http.Error(w, "Too many failed sign-ins, try again later", http.StatusTooManyRequests)
+		} else {
+			http.Error(w, "Too many failed sign-ins, slow down", http.StatusTooManyRequests)
+		}
+		return
+	}
+
+	user, err := h.store.Authenticate(ctx, input.Email, input.Password)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			h.throttle.Release(input.Email, ip)
+			h.logger.Error("Failed to authenticate admin user", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.failed(r, input.Email, ip)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	h.throttle.Success(input.Email, ip)
+
+	session, err := h.store.CreateSession(ctx, user, h.sessionTTL)
+	if err != nil {
+		h.logger.Error("Failed to create admin session", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.event(r, user.ID, audit.ActionLogin, map[string]string{"email": user.Email})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// failed records a failed sign-in and any lockout it caused. Unknown
+// emails are recorded against entity 0.
+func (h *Handler) failed(r *http.Request, email, ip string) {
+	accountLocked, ipLocked := h.throttle.Failure(email, ip)
+
+	var userID int64
+	if user, err := h.store.FindByEmail(r.Context(), email); err == nil {
+		userID = user.ID
+	}
+	h.event(r, userID, audit.ActionLoginFailed, map[string]string{"email": email})
+
+	if accountLocked || ipLocked {
+		h.logger.Warn("Admin sign-in locked out",
+			zap.String("email", email),
+			zap.String("ip", ip),
+			zap.Bool("account_locked", accountLocked),
+			zap.Bool("ip_locked", ipLocked))
+		h.event(r, userID, audit.ActionLockout, map[string]interface{}{
+			"email":          email,
+			"account_locked": accountLocked,
+			"ip_locked":      ipLocked,
+		})
+	}
+}
+
+func (h *Handler) event(r *http.Request, userID int64, action string, details interface{}) {
+	if err := h.store.RecordEvent(r.Context(), userID, action, details); err != nil {
+		h.logger.Error("Failed to record security event", zap.String("action", action), zap.Error(err))
+	}
+}
+
+// Logout ends the session of the bearer token, which Guard has checked.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := h.store.DeleteSession(r.Context(), token); err != nil {
+		h.logger.Error("Failed to delete admin session", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.event(r, user.ID, audit.ActionLogout, map[string]string{"email": user.Email})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me returns the admin user signed in with the bearer token, which Guard
+// has checked.
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,199 @@
+package admin
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTracked is how many accounts or IPs the throttle tracks before it
+// forgets those that have been quiet for a while
+const maxTracked = 10000
+
+// ThrottleConfig sets how failed sign-ins are slowed down and locked out.
+type ThrottleConfig struct {
+	// DelayAfter is how many failures in a row an account may have before
+	// each further attempt must wait, one second doubling per failure
+	DelayAfter int
+	MaxDelay   time.Duration
+	// LockoutAfter is how many failures in a row lock an account for
+	// LockoutDuration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+	// IPLockoutAfter is how many failures from one client IP, across any
+	// accounts, lock out that IP for LockoutDuration
+	IPLockoutAfter int
+	// Window is how long a failure counts; quiet accounts start over
+	Window time.Duration
+}
+
+// Throttle tracks failed sign-ins by account and by client IP. Keying on
+// the account stops a distributed guess at one password; keying on the IP
+// stops one client trying a password across many accounts.
+type Throttle struct {
+	cfg ThrottleConfig
+
+	mu       sync.Mutex
+	accounts map[string]*failures
+	ips      map[string]*failures
+}
+
+type failures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+func NewThrottle(cfg ThrottleConfig) *Throttle {
+	return &Throttle{
+		cfg:      cfg,
+		accounts: make(map[string]*failures),
+		ips:      make(map[string]*failures),
+	}
+}
+
+// Check reports how long a sign-in to email from ip must wait, and whether
+// that is because the account or IP is locked out. Zero means it may go
+// ahead, and the attempt is then counted as a failure straight away, so
+// parallel guesses cannot all pass the check before any of them fails.
+// Success or Release takes the attempt back.
+func (t *Throttle) Check(email, ip string) (time.Duration, bool) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if wait, locked := t.wait(accountKey(email), ip, now); wait > 0 {
+		return wait, locked
+	}
+	t.fail(t.accounts, accountKey(email), t.cfg.LockoutAfter, now)
+	t.fail(t.ips, ip, t.cfg.IPLockoutAfter, now)
+	return 0, false
+}
+
+func (t *Throttle) wait(account, ip string, now time.Time) (time.Duration, bool) {
+	if f := t.current(t.ips, ip, now); f != nil && now.Before(f.lockedUntil) {
+		return f.lockedUntil.Sub(now), true
+	}
+
+	f := t.current(t.accounts, account, now)
+	if f == nil {
+		return 0, false
+	}
+	if now.Before(f.lockedUntil) {
+		return f.lockedUntil.Sub(now), true
+	}
+	if f.count < t.cfg.DelayAfter {
+		return 0, false
+	}
+	delay := time.Second << min(f.count-t.cfg.DelayAfter, 16)
+	if delay > t.cfg.MaxDelay {
+		delay = t.cfg.MaxDelay
+	}
+	if wait := f.last.Add(delay).Sub(now); wait > 0 {
+		return wait, false
+	}
+	return 0, false
+}
+
+// Failure confirms that a sign-in to email from ip, counted by Check,
+// failed, and reports whether the account or the IP is now locked out.
+func (t *Throttle) Failure(email, ip string) (accountLocked, ipLocked bool) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if f := t.current(t.accounts, accountKey(email), now); f != nil {
+		accountLocked = now.Before(f.lockedUntil)
+	}
+	if f := t.current(t.ips, ip, now); f != nil {
+		ipLocked = now.Before(f.lockedUntil)
+	}
+	return accountLocked, ipLocked
+}
+
+// Success forgets the failures of email after a successful sign-in and
+// takes back the attempt Check counted against ip. The IP keeps its other
+// failures, so guessing right once does not reset a spray.
+func (t *Throttle) Success(email, ip string) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.accounts, accountKey(email))
+	t.release(t.ips, ip, t.cfg.IPLockoutAfter, now)
+}
+
+// Release takes back the attempt Check counted for a sign-in that could
+// not be decided, such as one that failed on a database error, so server
+// faults do not lock admins out.
+func (t *Throttle) Release(email, ip string) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.release(t.accounts, accountKey(email), t.cfg.LockoutAfter, now)
+	t.release(t.ips, ip, t.cfg.IPLockoutAfter, now)
+}
+
+// current returns the failures tracked for key, dropping them once their
+// lockout is over or they have aged out of the window.
+func (t *Throttle) current(tracked map[string]*failures, key string, now time.Time) *failures {
+	f, ok := tracked[key]
+	if !ok {
+		return nil
+	}
+	if now.Before(f.lockedUntil) {
+		return f
+	}
+	if !f.lockedUntil.IsZero() || now.Sub(f.last) > t.cfg.Window {
+		delete(tracked, key)
+		return nil
+	}
+	return f
+}
+
+func (t *Throttle) fail(tracked map[string]*failures, key string, lockoutAfter int, now time.Time) bool {
+	f := t.current(tracked, key, now)
+	if f == nil {
+		if len(tracked) >= maxTracked {
+			t.prune(tracked, now)
+		}
+		f = &failures{}
+		tracked[key] = f
+	}
+	f.count++
+	f.last = now
+	if lockoutAfter > 0 && f.count >= lockoutAfter && f.lockedUntil.IsZero() {
+		f.lockedUntil = now.Add(t.cfg.LockoutDuration)
+		return true
+	}
+	return false
+}
+
+func (t *Throttle) release(tracked map[string]*failures, key string, lockoutAfter int, now time.Time) {
+	f := t.current(tracked, key, now)
+	if f == nil {
+		return
+	}
+	f.count--
+	if f.count < lockoutAfter {
+		f.lockedUntil = time.Time{}
+	}
+	if f.count <= 0 {
+		delete(tracked, key)
+	}
+}
+
+func (t *Throttle) prune(tracked map[string]*failures, now time.Time) {
+	for key := range tracked {
+		t.current(tracked, key, now)
+	}
+}
+
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
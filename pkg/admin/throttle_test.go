@@ -0,0 +1,241 @@
+package admin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/admin"
+)
+
+// attempt is one sign-in that passed Check and then failed, succeeded or,
+// on a server error, was released
+type attempt struct {
+	email    string
+	ip       string
+	success  bool
+	released bool
+}
+
+// failed returns n failed sign-ins to email from ip
+func failed(n int, email, ip string) []attempt {
+	attempts := make([]attempt, n)
+	for i := range attempts {
+		attempts[i] = attempt{email: email, ip: ip}
+	}
+	return attempts
+}
+
+// spray returns one failed sign-in from ip to each of emails
+func spray(ip string, emails ...string) []attempt {
+	attempts := make([]attempt, 0, len(emails))
+	for _, email := range emails {
+		attempts = append(attempts, attempt{email: email, ip: ip})
+	}
+	return attempts
+}
+
+func TestThrottleCheck(t *testing.T) {
+	// Delays are pushed out of the way unless a case is about them, so the
+	// attempts run back to back
+	cfg := admin.ThrottleConfig{
+		DelayAfter:      100,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    3,
+		LockoutDuration: 15 * time.Minute,
+		IPLockoutAfter:  5,
+		Window:          time.Hour,
+	}
+
+	tests := []struct {
+		name       string
+		cfg        *admin.ThrottleConfig
+		attempts   []attempt
+		email      string
+		ip         string
+		wantWait   bool
+		wantLocked bool
+	}{
+		{
+			name:  "first attempt",
+			email: "admin@example.com",
+			ip:    "203.0.113.1",
+		},
+		{
+			name:     "below the account lockout",
+			attempts: failed(2, "admin@example.com", "203.0.113.1"),
+			email:    "admin@example.com",
+			ip:       "203.0.113.1",
+		},
+		{
+			name:       "account locked out",
+			attempts:   failed(3, "admin@example.com", "203.0.113.1"),
+			email:      "admin@example.com",
+			ip:         "203.0.113.1",
+			wantWait:   true,
+			wantLocked: true,
+		},
+		{
+			name:       "account locked out from any IP",
+			attempts:   failed(3, "admin@example.com", "203.0.113.1"),
+			email:      "admin@example.com",
+			ip:         "198.51.100.7",
+			wantWait:   true,
+			wantLocked: true,
+		},
+		{
+			name:       "account matched regardless of case",
+			attempts:   failed(3, "Admin@Example.com ", "203.0.113.1"),
+			email:      "admin@example.com",
+			ip:         "198.51.100.7",
+			wantWait:   true,
+			wantLocked: true,
+		},
+		{
+			name:     "other accounts unaffected",
+			attempts: failed(3, "admin@example.com", "203.0.113.1"),
+			email:    "owner@example.com",
+			ip:       "198.51.100.7",
+		},
+		{
+			name: "IP locked out across accounts",
+			attempts: spray("203.0.113.1", "a@example.com", "b@example.com", "c@example.com",
+				"d@example.com", "e@example.com"),
+			email:      "f@example.com",
+			ip:         "203.0.113.1",
+			wantWait:   true,
+			wantLocked: true,
+		},
+		{
+			name: "other IPs unaffected",
+			attempts: spray("203.0.113.1", "a@example.com", "b@example.com", "c@example.com",
+				"d@example.com", "e@example.com"),
+			email: "f@example.com",
+			ip:    "198.51.100.7",
+		},
+		{
+			name: "success forgets the account failures",
+			attempts: append(failed(2, "admin@example.com", "203.0.113.1"),
+				attempt{email: "admin@example.com", ip: "203.0.113.1", success: true},
+				attempt{email: "admin@example.com", ip: "203.0.113.1"}),
+			email: "admin@example.com",
+			ip:    "203.0.113.1",
+		},
+		{
+			name: "success does not count against the IP",
+			attempts: append(failed(2, "spray@example.com", "203.0.113.1"),
+				attempt{email: "admin@example.com", ip: "203.0.113.1", success: true},
+				attempt{email: "admin@example.com", ip: "203.0.113.1", success: true},
+				attempt{email: "other@example.com", ip: "203.0.113.1"},
+				attempt{email: "third@example.com", ip: "203.0.113.1"}),
+			email: "fourth@example.com",
+			ip:    "203.0.113.1",
+		},
+		{
+			name: "released attempts do not count",
+			attempts: append(failed(2, "admin@example.com", "203.0.113.1"),
+				attempt{email: "admin@example.com", ip: "203.0.113.1", released: true},
+				attempt{email: "admin@example.com", ip: "203.0.113.1", released: true}),
+			email: "admin@example.com",
+			ip:    "203.0.113.1",
+		},
+		{
+			name: "delayed after DelayAfter failures",
+			cfg: &admin.ThrottleConfig{
+				DelayAfter:      2,
+				MaxDelay:        time.Minute,
+				LockoutAfter:    10,
+				LockoutDuration: 15 * time.Minute,
+				IPLockoutAfter:  10,
+				Window:          time.Hour,
+			},
+			attempts: failed(2, "admin@example.com", "203.0.113.1"),
+			email:    "admin@example.com",
+			ip:       "203.0.113.1",
+			wantWait: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.cfg != nil {
+				c = *tt.cfg
+			}
+			throttle := admin.NewThrottle(c)
+
+			for i, a := range tt.attempts {
+				if wait, _ := throttle.Check(a.email, a.ip); wait > 0 {
+					t.Fatalf("attempt %d: Check() wait = %v, want 0", i+1, wait)
+				}
+				switch {
+				case a.success:
+					throttle.Success(a.email, a.ip)
+				case a.released:
+					throttle.Release(a.email, a.ip)
+				default:
+					throttle.Failure(a.email, a.ip)
+				}
+			}
+
+			wait, locked := throttle.Check(tt.email, tt.ip)
+			if (wait > 0) != tt.wantWait {
+				t.Errorf("Check() wait = %v, want wait %v", wait, tt.wantWait)
+			}
+			if locked != tt.wantLocked {
+				t.Errorf("Check() locked = %v, want %v", locked, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestThrottleFailureReportsLockout(t *testing.T) {
+	throttle := admin.NewThrottle(admin.ThrottleConfig{
+		DelayAfter:      100,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    2,
+		LockoutDuration: 15 * time.Minute,
+		IPLockoutAfter:  3,
+		Window:          time.Hour,
+	})
+
+	tests := []struct {
+		email             string
+		wantAccountLocked bool
+		wantIPLocked      bool
+	}{
+		{email: "admin@example.com"},
+		{email: "admin@example.com", wantAccountLocked: true},
+		{email: "owner@example.com", wantIPLocked: true},
+	}
+	for i, tt := range tests {
+		if wait, _ := throttle.Check(tt.email, "203.0.113.1"); wait > 0 {
+			t.Fatalf("attempt %d: Check() wait = %v, want 0", i+1, wait)
+		}
+		accountLocked, ipLocked := throttle.Failure(tt.email, "203.0.113.1")
+		if accountLocked != tt.wantAccountLocked || ipLocked != tt.wantIPLocked {
+			t.Errorf("attempt %d: Failure() = %v, %v, want %v, %v",
+				i+1, accountLocked, ipLocked, tt.wantAccountLocked, tt.wantIPLocked)
+		}
+	}
+}
+
+// Check counts the attempt before the password is compared, so a burst of
+// parallel guesses cannot all get through before the first one fails
+func TestThrottleCheckReservesAttempt(t *testing.T) {
+	throttle := admin.NewThrottle(admin.ThrottleConfig{
+		DelayAfter:      100,
+		MaxDelay:        time.Minute,
+		LockoutAfter:    3,
+		LockoutDuration: 15 * time.Minute,
+		IPLockoutAfter:  100,
+		Window:          time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		if wait, _ := throttle.Check("admin@example.com", "203.0.113.1"); wait > 0 {
+			t.Fatalf("attempt %d: Check() wait = %v, want 0", i+1, wait)
+		}
+	}
+	if wait, locked := throttle.Check("admin@example.com", "203.0.113.1"); wait == 0 || !locked {
+		t.Errorf("Check() = %v, %v, want a lockout", wait, locked)
+	}
+}
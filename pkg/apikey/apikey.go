@@ -0,0 +1,230 @@
+// Package apikey authenticates machine clients with scoped API keys sent in
+// the X-API-Key header. Keys are shown once when created and only their
+// SHA-256 hash is stored.
+//
+// Requests are rate limited per minute by the cost of their route, so a
+// search or export counts more than a GET by ID. Authenticated requests
+// count against their key and anonymous ones against their client IP.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// HeaderKey is the request header carrying the API key
+const HeaderKey = "X-API-Key"
+
+// Scopes a key can be granted
+const (
+	ScopeCatalogRead     = "catalog:read"
+	ScopeCatalogWrite    = "catalog:write"
+	ScopeGiftCardsRedeem = "giftcards:redeem"
+	ScopeInventoryWrite  = "inventory:write"
+	// ScopeAdmin lets a key call the admin routes, as a signed-in admin
+	// would, and holds every other scope
+	ScopeAdmin = "admin"
+)
+
+// keyPrefix marks API keys so they are recognisable in logs and secret scanners
+const keyPrefix = "ak_"
+
+var (
+	ErrKeyNotFound = errors.New("api key not found")
+	ErrWrongStore  = errors.New("api key belongs to another store")
+)
+
+type Key struct {
+	ID         int64          `db:"id" json:"id"`
+	StoreID    int64          `db:"store_id" json:"store_id"`
+	Name       string         `db:"name" json:"name"`
+	Prefix     string         `db:"prefix" json:"prefix"`
+	Hash       string         `db:"key_hash" json:"-"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	RateLimit  int            `db:"rate_limit" json:"rate_limit"`
+	VendorID   *int64         `db:"vendor_id" json:"vendor_id,omitempty"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope or ScopeAdmin.
+func (k *Key) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateKeyInput struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=catalog:read catalog:write giftcards:redeem inventory:write admin"`
+	// RateLimit is requests per minute and defaults to the configured limit
+	RateLimit *int `json:"rate_limit" validate:"omitempty,min=1,max=100000"`
+}
+
+// CreatedKey is returned once, when the key is issued.
+type CreatedKey struct {
+	*Key
+	Secret string `json:"key"`
+}
+
+// Store persists API keys.
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// Create issues a new key for the current store and returns it with its
+// secret. Keys issued to a vendor act only on that vendor's products.
+func (s *Store) Create(ctx context.Context, name string, scopes []string, rateLimit int, vendorID *int64) (*CreatedKey, error) {
+	secret, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		Name:      name,
+		Prefix:    secret[:len(keyPrefix)+8],
+		Hash:      Hash(secret),
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		VendorID:  vendorID,
+		StoreID:   tenant.StoreIDOrDefault(ctx),
+	}
+
+	query := `
+		INSERT INTO api_keys (store_id, name, prefix, key_hash, scopes, rate_limit, vendor_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err = s.db.QueryRowxContext(ctx, query, key.StoreID, key.Name, key.Prefix, key.Hash, key.Scopes, key.RateLimit, key.VendorID).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating api key: %w", err)
+	}
+
+	return &CreatedKey{Key: key, Secret: secret}, nil
+}
+
+// List retrieves the keys of the current store, including revoked ones,
+// newest first.
+func (s *Store) List(ctx context.Context) ([]*Key, error) {
+	keys := []*Key{}
+	query := `SELECT * FROM api_keys WHERE ($1::integer IS NULL OR store_id = $1) ORDER BY id DESC`
+	if err := s.db.SelectContext(ctx, &keys, query, tenant.StoreArg(ctx)); err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke disables a key. Revoking a revoked key is a no-op.
+func (s *Store) Revoke(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND ($2::integer IS NULL OR store_id = $2)`
+
+	result, err := s.db.ExecContext(ctx, query, id, tenant.StoreArg(ctx))
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// FindActive retrieves the unrevoked key with the given secret, of any
+// store.
+func (s *Store) FindActive(ctx context.Context, secret string) (*Key, error) {
+	var key Key
+	query := `SELECT * FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+	if err := s.db.GetContext(ctx, &key, query, Hash(secret)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("error finding api key: %w", err)
+	}
+	return &key, nil
+}
+
+// Touch records that a key was used. Writes are limited to one a minute
+// per key.
+func (s *Store) Touch(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error recording api key use: %w", err)
+	}
+	return nil
+}
+
+// Hash returns the stored form of a key.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating api key: %w", err)
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// inStore reports whether key belongs to the store ctx is scoped to. Keys
+// act only within their own store; unscoped contexts accept any key.
+func inStore(ctx context.Context, key *Key) bool {
+	storeID, ok := tenant.StoreID(ctx)
+	return !ok || key.StoreID == storeID
+}
+
+type keyContextKey struct{}
+
+// WithKey returns a context carrying key as the request's API key.
+func WithKey(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// FromContext returns the key that authenticated the request, or nil.
+func FromContext(ctx context.Context) *Key {
+	key, _ := ctx.Value(keyContextKey{}).(*Key)
+	return key
+}
+
+type adminContextKey struct{}
+
+// WithAdmin marks ctx as made by a signed-in admin. Admins may do anything
+// a key may, so scope checks let them through.
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminContextKey{}, true)
+}
+
+// IsAdmin reports whether ctx was marked by WithAdmin.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminContextKey{}).(bool)
+	return admin
+}
@@ -0,0 +1,100 @@
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/request"
+	"github.com/go-playground/validator"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Handler lets admins issue and revoke API keys.
+type Handler struct {
+	store            *Store
+	keys             *Authenticator
+	logger           *zap.Logger
+	defaultRateLimit int
+	validator        *validator.Validate
+}
+
+// NewHandler creates the key management handler. Its routes need a
+// signed-in admin or a key with ScopeAdmin, checked by keys.
+func NewHandler(store *Store, keys *Authenticator, logger *zap.Logger, defaultRateLimit int) *Handler {
+	return &Handler{
+		store:            store,
+		keys:             keys,
+		logger:           logger,
+		defaultRateLimit: defaultRateLimit,
+		validator:        validator.New(),
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.POST("/admin/api-keys", h.keys.RequireKey(ScopeAdmin, h.CreateKey))
+	router.GET("/admin/api-keys", h.keys.RequireKey(ScopeAdmin, h.ListKeys))
+	router.DELETE("/admin/api-keys/:id", h.keys.RequireKey(ScopeAdmin, h.RevokeKey))
+}
+
+func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input CreateKeyInput
+	if err := request.Decode(r, &input); err != nil {
+		h.logger.Error("Failed to decode create api key input", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(input); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	rateLimit := h.defaultRateLimit
+	if input.RateLimit != nil {
+		rateLimit = *input.RateLimit
+	}
+
+	key, err := h.store.Create(r.Context(), input.Name, input.Scopes, rateLimit, nil)
+	if err != nil {
+		h.logger.Error("Failed to create api key", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	keys, err := h.store.List(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list api keys", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h *Handler) RevokeKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.Revoke(r.Context(), id); err != nil {
+		h.logger.Error("Failed to revoke api key", zap.Error(err))
+		if err == ErrKeyNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
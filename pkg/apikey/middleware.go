@@ -0,0 +1,160 @@
+package apikey
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// rateWindow is the window rate limits are counted over
+const rateWindow = time.Minute
+
+// Authenticator guards routes with API key scopes. A nil *Authenticator
+// passes requests through untouched.
+type Authenticator struct {
+	store    *Store
+	logger   *zap.Logger
+	required bool
+
+	// anonymousLimit and costs are set by LimitRoutes
+	anonymousLimit int
+	costs          []routeCost
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewAuthenticator creates the API key middleware. When required is false,
+// requests without a key run anonymously; requests with an invalid key are
+// always rejected.
+func NewAuthenticator(store *Store, logger *zap.Logger, required bool) *Authenticator {
+	return &Authenticator{
+		store:    store,
+		logger:   logger,
+		required: required,
+		windows:  make(map[string]*window),
+	}
+}
+
+// Require returns next guarded by the given scope. An authenticated key is
+// stored in the request context and becomes the audit actor.
+func (a *Authenticator) Require(scope string, next httprouter.Handle) httprouter.Handle {
+	if a == nil {
+		return next
+	}
+	return a.guard(scope, next, a.required)
+}
+
+// RequireKey is Require for routes only machine clients call, such as
+// integrations, which need a key even when keys are optional elsewhere.
+func (a *Authenticator) RequireKey(scope string, next httprouter.Handle) httprouter.Handle {
+	if a == nil {
+		return next
+	}
+	return a.guard(scope, next, true)
+}
+
+func (a *Authenticator) guard(scope string, next httprouter.Handle, required bool) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if IsAdmin(r.Context()) {
+			next(w, r, ps)
+			return
+		}
+
+		// Limit has already authenticated the key and counted the request
+		key := FromContext(r.Context())
+		counted := key != nil
+		if key == nil {
+			secret := r.Header.Get(HeaderKey)
+			if secret == "" {
+				if required {
+					http.Error(w, "Missing "+HeaderKey+" header", http.StatusUnauthorized)
+					return
+				}
+				next(w, r, ps)
+				return
+			}
+
+			var err error
+			key, err = a.store.FindActive(r.Context(), secret)
+			if err != nil {
+				if err != ErrKeyNotFound {
+					a.logger.Error("Failed to authenticate api key", zap.Error(err))
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+		}
+		if !inStore(r.Context(), key) {
+			http.Error(w, ErrWrongStore.Error(), http.StatusForbidden)
+			return
+		}
+		if !key.HasScope(scope) {
+			http.Error(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+			return
+		}
+		if !counted {
+			if retryAfter, ok := a.allow(keyBucket(key), key.RateLimit, 1); !ok {
+				tooManyRequests(w, retryAfter, "API key rate limit exceeded")
+				return
+			}
+		}
+
+		if err := a.store.Touch(r.Context(), key.ID); err != nil {
+			a.logger.Warn("Failed to record api key use", zap.Int64("api_key_id", key.ID), zap.Error(err))
+		}
+
+		actor := audit.ActorFrom(r.Context())
+		actor.Name = "api_key:" + strconv.FormatInt(key.ID, 10)
+		ctx := WithKey(audit.WithActor(r.Context(), actor), key)
+		next(w, r.WithContext(ctx), ps)
+	}
+}
+
+// allow counts cost against the per-minute limit of bucket and reports
+// how long to wait when it is exhausted.
+func (a *Authenticator) allow(bucket string, limit, cost int) (time.Duration, bool) {
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	win, ok := a.windows[bucket]
+	if !ok || now.Sub(win.start) >= rateWindow {
+		if !ok && len(a.windows) >= maxWindows {
+			a.prune(now)
+		}
+		win = &window{start: now}
+		a.windows[bucket] = win
+	}
+	if win.count+cost > limit {
+		return rateWindow - now.Sub(win.start), false
+	}
+	win.count += cost
+	return 0, true
+}
+
+// prune forgets buckets whose window has ended.
+func (a *Authenticator) prune(now time.Time) {
+	for bucket, win := range a.windows {
+		if now.Sub(win.start) >= rateWindow {
+			delete(a.windows, bucket)
+		}
+	}
+}
+
+func keyBucket(key *Key) string {
+	return "key:" + strconv.FormatInt(key.ID, 10)
+}
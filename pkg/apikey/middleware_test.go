@@ -0,0 +1,159 @@
+package apikey_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// offlineStore returns a store whose database cannot be reached. The cases
+// below authenticate keys through the request context, so the only query
+// is the best-effort last-used update, whose failure is only logged.
+func offlineStore(t *testing.T) *apikey.Store {
+	t.Helper()
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return apikey.NewStore(sqlx.NewDb(db, "postgres"))
+}
+
+func TestGuard(t *testing.T) {
+	store := offlineStore(t)
+	reader := &apikey.Key{ID: 1, StoreID: 1, Scopes: []string{apikey.ScopeCatalogRead}, RateLimit: 60}
+	admin := &apikey.Key{ID: 2, StoreID: 1, Scopes: []string{apikey.ScopeAdmin}, RateLimit: 60}
+
+	tests := []struct {
+		name     string
+		required bool
+		// requireKey guards the route with RequireKey instead of Require
+		requireKey bool
+		scope      string
+		ctx        func(context.Context) context.Context
+		want       int
+	}{
+		{
+			name:  "key with the scope",
+			scope: apikey.ScopeCatalogRead,
+			ctx:   func(ctx context.Context) context.Context { return apikey.WithKey(ctx, reader) },
+			want:  http.StatusOK,
+		},
+		{
+			name:     "key without the scope",
+			required: true,
+			scope:    apikey.ScopeCatalogWrite,
+			ctx:      func(ctx context.Context) context.Context { return apikey.WithKey(ctx, reader) },
+			want:     http.StatusForbidden,
+		},
+		{
+			name:  "admin key has every scope",
+			scope: apikey.ScopeInventoryWrite,
+			ctx:   func(ctx context.Context) context.Context { return apikey.WithKey(ctx, admin) },
+			want:  http.StatusOK,
+		},
+		{
+			name:  "key without the scope when keys are optional",
+			scope: apikey.ScopeCatalogWrite,
+			ctx:   func(ctx context.Context) context.Context { return apikey.WithKey(ctx, reader) },
+			want:  http.StatusForbidden,
+		},
+		{
+			name:  "anonymous when keys are optional",
+			scope: apikey.ScopeCatalogWrite,
+			want:  http.StatusOK,
+		},
+		{
+			name:     "anonymous when keys are required",
+			required: true,
+			scope:    apikey.ScopeCatalogRead,
+			want:     http.StatusUnauthorized,
+		},
+		{
+			name:       "anonymous on a key-only route when keys are optional",
+			requireKey: true,
+			scope:      apikey.ScopeGiftCardsRedeem,
+			want:       http.StatusUnauthorized,
+		},
+		{
+			name:     "signed-in admin without a key",
+			required: true,
+			scope:    apikey.ScopeCatalogWrite,
+			ctx:      apikey.WithAdmin,
+			want:     http.StatusOK,
+		},
+		{
+			name:       "signed-in admin on a key-only route",
+			requireKey: true,
+			scope:      apikey.ScopeAdmin,
+			ctx:        apikey.WithAdmin,
+			want:       http.StatusOK,
+		},
+		{
+			name:  "key in its own store",
+			scope: apikey.ScopeCatalogRead,
+			ctx: func(ctx context.Context) context.Context {
+				return apikey.WithKey(tenant.WithStore(ctx, 1), reader)
+			},
+			want: http.StatusOK,
+		},
+		{
+			name:  "key of another store",
+			scope: apikey.ScopeCatalogRead,
+			ctx: func(ctx context.Context) context.Context {
+				return apikey.WithKey(tenant.WithStore(ctx, 2), admin)
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:       "scoped key on an admin route",
+			requireKey: true,
+			scope:      apikey.ScopeAdmin,
+			ctx:        func(ctx context.Context) context.Context { return apikey.WithKey(ctx, reader) },
+			want:       http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := apikey.NewAuthenticator(store, zap.NewNop(), tt.required)
+			next := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {}
+			handle := auth.Require(tt.scope, next)
+			if tt.requireKey {
+				handle = auth.RequireKey(tt.scope, next)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ctx != nil {
+				r = r.WithContext(tt.ctx(r.Context()))
+			}
+			w := httptest.NewRecorder()
+			handle(w, r, nil)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardNilAuthenticator(t *testing.T) {
+	var auth *apikey.Authenticator
+	called := false
+	handle := auth.RequireKey(apikey.ScopeAdmin, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		called = true
+	})
+
+	handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	if !called {
+		t.Error("nil Authenticator did not pass the request through")
+	}
+}
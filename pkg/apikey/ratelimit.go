@@ -0,0 +1,133 @@
+package apikey
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"go.uber.org/zap"
+)
+
+// maxWindows is how many rate limit buckets are tracked before those whose
+// window has ended are forgotten
+const maxWindows = 10000
+
+// routeCost is a parsed config.RouteCost
+type routeCost struct {
+	method   string
+	segments []string
+	cost     int
+}
+
+// LimitRoutes sets the per-minute limit of clients without a key, zero for
+// none, and how much requests to each route count against the limits.
+// Unlisted routes cost 1.
+func (a *Authenticator) LimitRoutes(anonymousLimit int, costs []config.RouteCost) error {
+	parsed := make([]routeCost, 0, len(costs))
+	for _, c := range costs {
+		method, path, ok := strings.Cut(strings.TrimSpace(c.Route), " ")
+		path = strings.TrimSpace(path)
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("route %q is not of the form \"METHOD /path\"", c.Route)
+		}
+		if c.Cost < 1 {
+			return fmt.Errorf("route %q has cost %d, want at least 1", c.Route, c.Cost)
+		}
+		parsed = append(parsed, routeCost{
+			method:   strings.ToUpper(method),
+			segments: strings.Split(strings.Trim(path, "/"), "/"),
+			cost:     c.Cost,
+		})
+	}
+	a.anonymousLimit = anonymousLimit
+	a.costs = parsed
+	return nil
+}
+
+// Limit rate limits every request by the cost of its route. Requests with
+// a valid key count against the key's limit, shared by all its clients;
+// the rest count against the anonymous limit of their client IP. The key
+// is stored in the request context for Require, which checks its scopes.
+func (a *Authenticator) Limit(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost := a.cost(r.Method, r.URL.Path)
+
+		var key *Key
+		if secret := r.Header.Get(HeaderKey); secret != "" {
+			found, err := a.store.FindActive(r.Context(), secret)
+			switch {
+			case err == nil:
+				if !inStore(r.Context(), found) {
+					http.Error(w, ErrWrongStore.Error(), http.StatusForbidden)
+					return
+				}
+				key = found
+			case err != ErrKeyNotFound:
+				a.logger.Error("Failed to authenticate api key", zap.Error(err))
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			// Invalid keys are rejected by Require on guarded routes and
+			// count as anonymous on the rest
+		}
+
+		if key != nil {
+			if retryAfter, ok := a.allow(keyBucket(key), key.RateLimit, cost); !ok {
+				tooManyRequests(w, retryAfter, "API key rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithKey(r.Context(), key)))
+			return
+		}
+
+		if a.anonymousLimit > 0 {
+			bucket := "ip:" + clientip.FromRequest(r)
+			if retryAfter, ok := a.allow(bucket, a.anonymousLimit, cost); !ok {
+				tooManyRequests(w, retryAfter, "Rate limit exceeded")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cost returns the cost of the first route matching method and path.
+func (a *Authenticator) cost(method, path string) int {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, c := range a.costs {
+		if c.method == method && matchSegments(c.segments, segments) {
+			return c.cost
+		}
+	}
+	return 1
+}
+
+// matchSegments matches path segments against a pattern where ":name"
+// matches any one segment and a trailing "*" everything below.
+func matchSegments(pattern, segments []string) bool {
+	for i, p := range pattern {
+		if p == "*" && i == len(pattern)-1 {
+			return len(segments) > i
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(p, ":") && p != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
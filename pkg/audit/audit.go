@@ -0,0 +1,207 @@
+// Package audit records who changed what. Writes call Record inside the
+// transaction that performs the change, so the audit log cannot drift from
+// the data it describes.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// ActionRestore replaces data wholesale, such as a catalog snapshot
+	ActionRestore = "restore"
+
+	// Security events, recorded with RecordEvent
+	ActionLogin       = "login"
+	ActionLogout      = "logout"
+	ActionLoginFailed = "login_failed"
+	ActionLockout     = "lockout"
+)
+
+// HeaderActor names the caller until the API has authentication
+const HeaderActor = "X-Actor"
+
+// anonymousActor is recorded when a request carries no actor
+const anonymousActor = "anonymous"
+
+type Entry struct {
+	ID         int64           `db:"id" json:"id"`
+	Actor      string          `db:"actor" json:"actor"`
+	Action     string          `db:"action" json:"action"`
+	EntityType string          `db:"entity_type" json:"entity_type"`
+	EntityID   int64           `db:"entity_id" json:"entity_id"`
+	Before     json.RawMessage `db:"before" json:"before,omitempty"`
+	After      json.RawMessage `db:"after" json:"after,omitempty"`
+	Diff       json.RawMessage `db:"diff" json:"diff"`
+	IP         *string         `db:"ip" json:"ip,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Actor identifies who made a change and from where.
+type Actor struct {
+	Name string
+	IP   string
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored in ctx, or an anonymous actor.
+func ActorFrom(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Name: anonymousActor}
+}
+
+// Middleware stores the request's actor and client IP in its context.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := Actor{Name: r.Header.Get(HeaderActor), IP: clientip.FromRequest(r)}
+		if actor.Name == "" {
+			actor.Name = anonymousActor
+		}
+		next.ServeHTTP(w, r.WithContext(WithActor(r.Context(), actor)))
+	})
+}
+
+// Record stores an audit entry inside tx. before is nil for creates and
+// after is nil for deletes; the diff lists the top-level fields that differ.
+func Record(ctx context.Context, tx *sqlx.Tx, entityType string, entityID int64, action string, before, after interface{}) error {
+	beforeJSON, beforeFields, err := encode(before)
+	if err != nil {
+		return fmt.Errorf("error encoding audit state: %w", err)
+	}
+	afterJSON, afterFields, err := encode(after)
+	if err != nil {
+		return fmt.Errorf("error encoding audit state: %w", err)
+	}
+	diff, err := json.Marshal(Diff(beforeFields, afterFields))
+	if err != nil {
+		return fmt.Errorf("error encoding audit diff: %w", err)
+	}
+
+	actor := ActorFrom(ctx)
+	var ip *string
+	if actor.IP != "" {
+		ip = &actor.IP
+	}
+
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, before, after, diff, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = tx.ExecContext(ctx, query, actor.Name, action, entityType, entityID, beforeJSON, afterJSON, diff, ip)
+	if err != nil {
+		return fmt.Errorf("error recording audit entry: %w", err)
+	}
+	return nil
+}
+
+// RecordEvent stores an entry for something that happened to an entity
+// without changing it, such as a failed sign-in. details is stored as the
+// entry's after state.
+func RecordEvent(ctx context.Context, db *sqlx.DB, entityType string, entityID int64, action string, details interface{}) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := Record(ctx, tx, entityType, entityID, action, nil, details); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordCreates stores a create entry for each of entityIDs inside tx with
+// a single insert. created[i] is the new state of entityIDs[i].
+func RecordCreates(ctx context.Context, tx *sqlx.Tx, entityType string, entityIDs []int64, created []interface{}) error {
+	afters := make([]string, len(created))
+	diffs := make([]string, len(created))
+	for i, after := range created {
+		afterJSON, afterFields, err := encode(after)
+		if err != nil {
+			return fmt.Errorf("error encoding audit state: %w", err)
+		}
+		diff, err := json.Marshal(Diff(nil, afterFields))
+		if err != nil {
+			return fmt.Errorf("error encoding audit diff: %w", err)
+		}
+		afters[i], diffs[i] = string(afterJSON), string(diff)
+	}
+
+	actor := ActorFrom(ctx)
+	var ip *string
+	if actor.IP != "" {
+		ip = &actor.IP
+	}
+
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, after, diff, ip)
+		SELECT $1, $2, $3, entry.id, entry.after::jsonb, entry.diff::jsonb, $4
+		FROM unnest($5::bigint[], $6::text[], $7::text[]) AS entry (id, after, diff)`
+
+	_, err := tx.ExecContext(ctx, query, actor.Name, ActionCreate, entityType, ip, pq.Array(entityIDs), pq.Array(afters), pq.Array(diffs))
+	if err != nil {
+		return fmt.Errorf("error recording audit entries: %w", err)
+	}
+	return nil
+}
+
+// Change is the before and after value of one field.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff compares two JSON objects field by field.
+func Diff(before, after map[string]interface{}) map[string]Change {
+	diff := make(map[string]Change)
+	for field, old := range before {
+		if updated, ok := after[field]; !ok || !reflect.DeepEqual(old, updated) {
+			diff[field] = Change{Before: old, After: after[field]}
+		}
+	}
+	for field, updated := range after {
+		if _, ok := before[field]; !ok {
+			diff[field] = Change{After: updated}
+		}
+	}
+	return diff
+}
+
+// encode marshals v and decodes it back into its fields. A nil v encodes
+// to SQL NULL.
+func encode(v interface{}) ([]byte, map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, err
+	}
+	return data, fields, nil
+}
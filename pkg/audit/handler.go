@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Handler exposes the audit log to admins.
+type Handler struct {
+	log    *Log
+	logger *zap.Logger
+}
+
+func NewHandler(log *Log, logger *zap.Logger) *Handler {
+	return &Handler{
+		log:    log,
+		logger: logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/admin/audit-log", h.ListEntries)
+}
+
+func (h *Handler) ListEntries(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	var filter Filter
+
+	if actor := query.Get("actor"); actor != "" {
+		filter.Actor = &actor
+	}
+	if action := query.Get("action"); action != "" {
+		filter.Action = &action
+	}
+	if entityType := query.Get("entity_type"); entityType != "" {
+		filter.EntityType = &entityType
+	}
+	if entityID := query.Get("entity_id"); entityID != "" {
+		id, err := strconv.ParseInt(entityID, 10, 64)
+		if err == nil {
+			filter.EntityID = &id
+		}
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Error("Invalid audit log time filter", zap.String("param", param), zap.Error(err))
+			http.Error(w, "Invalid "+param+" time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		*target = &t
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	entries, totalCount, err := h.log.List(r.Context(), filter, limit, (page-1)*limit)
+	if err != nil {
+		h.logger.Error("Failed to list audit log", zap.Error(err))
+		response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response.List(w, r, entries, totalCount, page, limit)
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Filter narrows an audit log query; nil fields are ignored.
+type Filter struct {
+	Actor      *string
+	Action     *string
+	EntityType *string
+	EntityID   *int64
+	From       *time.Time
+	To         *time.Time
+}
+
+// Log reads the audit log.
+type Log struct {
+	db *sqlx.DB
+}
+
+func NewLog(db *sqlx.DB) *Log {
+	return &Log{db: db}
+}
+
+// List returns audit entries matching filter, newest first, and the total
+// number of matches.
+func (l *Log) List(ctx context.Context, filter Filter, limit, offset int) ([]*Entry, int, error) {
+	query := `SELECT * FROM audit_log`
+	countQuery := `SELECT COUNT(*) FROM audit_log`
+	whereClause := []string{}
+	args := []interface{}{}
+	argID := 1
+
+	if filter.Actor != nil {
+		whereClause = append(whereClause, fmt.Sprintf("actor = $%d", argID))
+		args = append(args, *filter.Actor)
+		argID++
+	}
+	if filter.Action != nil {
+		whereClause = append(whereClause, fmt.Sprintf("action = $%d", argID))
+		args = append(args, *filter.Action)
+		argID++
+	}
+	if filter.EntityType != nil {
+		whereClause = append(whereClause, fmt.Sprintf("entity_type = $%d", argID))
+		args = append(args, *filter.EntityType)
+		argID++
+	}
+	if filter.EntityID != nil {
+		whereClause = append(whereClause, fmt.Sprintf("entity_id = $%d", argID))
+		args = append(args, *filter.EntityID)
+		argID++
+	}
+	if filter.From != nil {
+		whereClause = append(whereClause, fmt.Sprintf("created_at >= $%d", argID))
+		args = append(args, *filter.From)
+		argID++
+	}
+	if filter.To != nil {
+		whereClause = append(whereClause, fmt.Sprintf("created_at < $%d", argID))
+		args = append(args, *filter.To)
+		argID++
+	}
+
+	if len(whereClause) > 0 {
+		query += " WHERE " + strings.Join(whereClause, " AND ")
+		countQuery += " WHERE " + strings.Join(whereClause, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argID, argID+1)
+	args = append(args, limit, offset)
+
+	entries := []*Entry{}
+	if err := l.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("error listing audit log: %w", err)
+	}
+
+	var totalCount int
+	if err := l.db.GetContext(ctx, &totalCount, countQuery, args[:len(args)-2]...); err != nil {
+		return nil, 0, fmt.Errorf("error counting audit log: %w", err)
+	}
+
+	return entries, totalCount, nil
+}
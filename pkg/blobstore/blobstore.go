@@ -0,0 +1,45 @@
+// Package blobstore keeps generated files, such as catalog feeds, in object
+// storage. The local driver writes to a directory for development and
+// single-node deployments; the s3 driver talks to any S3 compatible store
+// (AWS S3, MinIO, Cloudflare R2) over its HTTP API.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+)
+
+// ErrNotFound is returned by Get for keys that have never been written.
+var ErrNotFound = errors.New("blob not found")
+
+// Object is a stored blob.
+type Object struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store reads and writes blobs by key. Keys are slash separated paths.
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (*Object, error)
+}
+
+// New builds the Store selected by cfg.BlobDriver.
+func New(cfg *config.Config) (Store, error) {
+	switch cfg.BlobDriver {
+	case "s3":
+		if cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return nil, fmt.Errorf("s3 blob driver requires s3_bucket, s3_access_key and s3_secret_key")
+		}
+		return NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey), nil
+	case "local", "":
+		return NewLocalStore(cfg.BlobDir), nil
+	default:
+		return nil, fmt.Errorf("unknown blob driver %q", cfg.BlobDriver)
+	}
+}
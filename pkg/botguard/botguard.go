@@ -0,0 +1,243 @@
+// Package botguard protects catalog endpoints from aggressive scraping. It
+// scores requests with cheap heuristics (request rate, missing or
+// automation user agents, missing browser headers, honeypot hits) and can
+// defer to an external bot-management Provider for the final verdict.
+package botguard
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/dotslashbit/ecommerce-api/pkg/i18n"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Action is what the guard does with a request it considers automated.
+type Action string
+
+const (
+	// ActionLog only logs suspicious requests
+	ActionLog Action = "log"
+	// ActionThrottle answers suspicious requests with 429 Too Many Requests
+	ActionThrottle Action = "throttle"
+	// ActionChallenge answers with 403 and a challenge URL to complete
+	ActionChallenge Action = "challenge"
+	// ActionBlock answers with 403 Forbidden
+	ActionBlock Action = "block"
+)
+
+// suspicionThreshold is the heuristic score at which a request is treated
+// as automated.
+const suspicionThreshold = 2
+
+// automationAgents are user-agent fragments sent by common scraping tools.
+var automationAgents = []string{
+	"curl", "wget", "python-requests", "python-urllib", "scrapy", "go-http-client",
+	"httpclient", "headlesschrome", "phantomjs", "selenium", "puppeteer",
+}
+
+var flaggedRequests = expvar.NewMap("bot_requests_flagged")
+
+// Verdict is the outcome of assessing a request.
+type Verdict struct {
+	Bot    bool
+	Reason string
+}
+
+// Provider is a hook for a third-party bot-management service. A verdict
+// from the provider replaces the built-in heuristics.
+type Provider interface {
+	Assess(ctx context.Context, r *http.Request) (*Verdict, error)
+}
+
+type Config struct {
+	Action        Action
+	RateLimit     int // requests per client per minute before the client is suspicious
+	BlockDuration time.Duration
+	ChallengeURL  string
+	HoneypotPaths []string
+}
+
+// Guard applies the configured Action to requests it considers automated.
+// A nil *Guard lets every request through.
+type Guard struct {
+	cfg      Config
+	provider Provider
+	logger   *zap.Logger
+
+	mu          sync.Mutex
+	window      time.Time
+	counts      map[string]int
+	blockedTill map[string]time.Time
+}
+
+// New creates a guard. provider may be nil to rely on the heuristics alone.
+func New(cfg Config, provider Provider, logger *zap.Logger) *Guard {
+	return &Guard{
+		cfg:         cfg,
+		provider:    provider,
+		logger:      logger,
+		counts:      make(map[string]int),
+		blockedTill: make(map[string]time.Time),
+	}
+}
+
+// RegisterRoutes registers the honeypot paths. They are linked nowhere, so
+// any client requesting them is crawling blindly and gets blocked.
+func (g *Guard) RegisterRoutes(router *httprouter.Router) {
+	for _, path := range g.cfg.HoneypotPaths {
+		router.GET(path, g.Honeypot)
+	}
+}
+
+func (g *Guard) Honeypot(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ip := clientip.FromRequest(r)
+	g.logger.Warn("Honeypot requested", zap.String("ip", ip), zap.String("path", r.URL.Path))
+
+	g.mu.Lock()
+	g.blockedTill[ip] = time.Now().Add(g.cfg.BlockDuration)
+	g.mu.Unlock()
+
+	http.NotFound(w, r)
+}
+
+// Wrap returns next guarded against automated clients.
+func (g *Guard) Wrap(next httprouter.Handle) httprouter.Handle {
+	if g == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		// Machine clients with an API key are expected to look automated
+		if apikey.FromContext(r.Context()) != nil {
+			next(w, r, ps)
+			return
+		}
+
+		verdict := g.assess(r)
+		if !verdict.Bot {
+			next(w, r, ps)
+			return
+		}
+
+		action := g.cfg.Action
+		if verdict.Reason == "honeypot" {
+			action = ActionBlock
+		}
+		flaggedRequests.Add(string(action), 1)
+		g.logger.Warn("Suspected bot request",
+			zap.String("ip", clientip.FromRequest(r)),
+			zap.String("path", r.URL.Path),
+			zap.String("reason", verdict.Reason),
+			zap.String("action", string(action)))
+
+		switch action {
+		case ActionThrottle:
+			w.Header().Set("Retry-After", strconv.Itoa(60))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		case ActionChallenge:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(struct {
+				Error        string `json:"error"`
+				ChallengeURL string `json:"challenge_url,omitempty"`
+			}{
+				Error:        i18n.Message(r.Context(), "challenge required"),
+				ChallengeURL: g.cfg.ChallengeURL,
+			})
+		case ActionBlock:
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			next(w, r, ps)
+		}
+	}
+}
+
+func (g *Guard) assess(r *http.Request) *Verdict {
+	ip := clientip.FromRequest(r)
+	if g.blocked(ip) {
+		return &Verdict{Bot: true, Reason: "honeypot"}
+	}
+	// Count every request, even ones the provider judges, so the rate
+	// heuristic still works if the provider goes down
+	overLimit := g.count(ip) > g.cfg.RateLimit
+
+	if g.provider != nil {
+		verdict, err := g.provider.Assess(r.Context(), r)
+		if err == nil {
+			return verdict
+		}
+		g.logger.Error("Bot provider failed, falling back to heuristics", zap.Error(err))
+	}
+
+	if overLimit {
+		return &Verdict{Bot: true, Reason: "rate"}
+	}
+
+	score := 0
+	var reasons []string
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		score += suspicionThreshold
+		reasons = append(reasons, "no user agent")
+	}
+	for _, agent := range automationAgents {
+		if strings.Contains(ua, agent) {
+			score += suspicionThreshold
+			reasons = append(reasons, "automation user agent")
+			break
+		}
+	}
+	if r.Header.Get("Accept") == "" {
+		score++
+		reasons = append(reasons, "no accept header")
+	}
+	if r.Header.Get("Accept-Language") == "" {
+		score++
+		reasons = append(reasons, "no accept-language header")
+	}
+
+	if score >= suspicionThreshold {
+		return &Verdict{Bot: true, Reason: strings.Join(reasons, ", ")}
+	}
+	return &Verdict{}
+}
+
+func (g *Guard) blocked(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	until, ok := g.blockedTill[ip]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(g.blockedTill, ip)
+		return false
+	}
+	return true
+}
+
+// count records a request from ip in the current one-minute window and
+// returns the window's total so far.
+func (g *Guard) count(ip string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	window := time.Now().Truncate(time.Minute)
+	if !window.Equal(g.window) {
+		g.window = window
+		g.counts = make(map[string]int)
+	}
+	g.counts[ip]++
+	return g.counts[ip]
+}
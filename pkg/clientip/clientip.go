@@ -0,0 +1,140 @@
+// Package clientip finds the address of the client behind a request. The
+// X-Forwarded-For and X-Real-IP headers are only believed when the peer
+// that sent them is a trusted proxy, such as the load balancer in front of
+// the API; anyone else could set them to pose as another client.
+//
+// The server resolves every request once with Wrap; middleware reads the
+// result with FromRequest.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver resolves client IPs behind a list of trusted proxies. A nil
+// *Resolver trusts no proxy and returns the peer address.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver creates a resolver trusting the given proxy addresses or
+// CIDR ranges.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	trusted, err := ParseNetworks(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+	return &Resolver{trusted: trusted}, nil
+}
+
+// ClientIP returns the IP of the client that made r. Behind trusted
+// proxies it is the right-most X-Forwarded-For address that is not itself
+// a trusted proxy, or X-Real-IP when there is no X-Forwarded-For.
+func (res *Resolver) ClientIP(r *http.Request) string {
+	peer := host(r.RemoteAddr)
+	if res == nil || !res.trustedAddr(peer) {
+		return peer
+	}
+
+	// Proxies append the address they received the request from, so the
+	// list is read from the right, stopping at the first untrusted hop
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			client = hop
+			if !res.trustedAddr(hop) {
+				break
+			}
+		}
+		return client
+	}
+
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(real) != nil {
+		return real
+	}
+	return peer
+}
+
+type ipKey struct{}
+
+// Wrap stores the client IP of every request in its context.
+func (res *Resolver) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ipKey{}, res.ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromRequest returns the client IP stored by Wrap, or the peer address of
+// requests that did not pass through it.
+func FromRequest(r *http.Request) string {
+	if ip, ok := r.Context().Value(ipKey{}).(string); ok {
+		return ip
+	}
+	return host(r.RemoteAddr)
+}
+
+func (res *Resolver) trustedAddr(addr string) bool {
+	return Contains(res.trusted, net.ParseIP(addr))
+}
+
+// ParseNetworks parses CIDR ranges; plain addresses are taken as ranges of
+// one address.
+func ParseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Contains reports whether ip is in any of networks. A nil ip is in none.
+func Contains(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// host strips the port from a host:port address
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
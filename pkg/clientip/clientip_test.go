@@ -0,0 +1,213 @@
+package clientip_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8", "192.0.2.10"})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		noProxies bool
+		peer      string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{
+			name: "direct client",
+			peer: "203.0.113.5:41000",
+			want: "203.0.113.5",
+		},
+		{
+			name:      "headers ignored from untrusted peer",
+			peer:      "203.0.113.5:41000",
+			forwarded: []string{"198.51.100.1"},
+			realIP:    "198.51.100.2",
+			want:      "203.0.113.5",
+		},
+		{
+			name:      "nil resolver trusts no proxy",
+			noProxies: true,
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"198.51.100.1"},
+			want:      "10.0.0.2",
+		},
+		{
+			name:      "one trusted proxy",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"198.51.100.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "spoofed hops left of the client are skipped",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"1.2.3.4, 198.51.100.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "chain of trusted proxies",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"1.2.3.4, 198.51.100.1, 192.0.2.10, 10.1.1.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "repeated headers are one list",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"1.2.3.4, 198.51.100.1", "10.1.1.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "every hop trusted",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"10.9.9.9, 10.1.1.1"},
+			want:      "10.9.9.9",
+		},
+		{
+			name:      "stops at a malformed hop",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"198.51.100.1, not-an-ip, 10.1.1.1"},
+			want:      "10.1.1.1",
+		},
+		{
+			name:      "malformed last hop falls back to the peer",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"198.51.100.1, not-an-ip"},
+			want:      "10.0.0.2",
+		},
+		{
+			name:   "X-Real-IP without X-Forwarded-For",
+			peer:   "10.0.0.2:41000",
+			realIP: "198.51.100.2",
+			want:   "198.51.100.2",
+		},
+		{
+			name:      "X-Forwarded-For wins over X-Real-IP",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"198.51.100.1"},
+			realIP:    "198.51.100.2",
+			want:      "198.51.100.1",
+		},
+		{
+			name:   "malformed X-Real-IP",
+			peer:   "10.0.0.2:41000",
+			realIP: "not-an-ip",
+			want:   "10.0.0.2",
+		},
+		{
+			name:      "IPv6 client",
+			peer:      "10.0.0.2:41000",
+			forwarded: []string{"2001:db8::1"},
+			want:      "2001:db8::1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.peer
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			res := resolver
+			if tt.noProxies {
+				res = nil
+			}
+			if got := res.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		in      []string
+		out     []string
+		wantErr bool
+	}{
+		{
+			name:   "CIDR ranges",
+			values: []string{"10.0.0.0/8", "2001:db8::/32"},
+			in:     []string{"10.0.0.1", "10.255.255.255", "2001:db8::1"},
+			out:    []string{"11.0.0.1", "2001:db9::1"},
+		},
+		{
+			name:   "plain addresses",
+			values: []string{"192.0.2.10", " 2001:db8::1 "},
+			in:     []string{"192.0.2.10", "2001:db8::1"},
+			out:    []string{"192.0.2.11", "2001:db8::2"},
+		},
+		{
+			name:   "blank entries skipped",
+			values: []string{"", "  "},
+			out:    []string{"192.0.2.10"},
+		},
+		{
+			name:    "invalid address",
+			values:  []string{"192.0.2.300"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			values:  []string{"10.0.0.0/40"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networks, err := clientip.ParseNetworks(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNetworks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, ip := range tt.in {
+				if !clientip.Contains(networks, net.ParseIP(ip)) {
+					t.Errorf("Contains(%s) = false, want true", ip)
+				}
+			}
+			for _, ip := range tt.out {
+				if clientip.Contains(networks, net.ParseIP(ip)) {
+					t.Errorf("Contains(%s) = true, want false", ip)
+				}
+			}
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	var got string
+	handler := resolver.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = clientip.FromRequest(r)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:41000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "198.51.100.1" {
+		t.Errorf("FromRequest() behind Wrap = %q, want %q", got, "198.51.100.1")
+	}
+
+	if got := clientip.FromRequest(r); got != "10.0.0.2" {
+		t.Errorf("FromRequest() without Wrap = %q, want %q", got, "10.0.0.2")
+	}
+}
@@ -0,0 +1,150 @@
+// Package crypto encrypts personal data stored in the database. Values are
+// sealed with AES-256-GCM under the primary key of a keyring and tagged
+// with the key ID, so older keys keep decrypting after a rotation until
+// the re-encryption job has moved every row to the new primary key.
+//
+// Encrypted columns cannot be searched or made unique, so a column that
+// needs equality lookups keeps a blind index next to it: an HMAC of the
+// normalised value under a separate, stable key.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks encrypted values: "enc:<key id>:<base64 nonce+ciphertext>".
+// Values without it are plaintext written before encryption was enabled.
+const prefix = "enc:"
+
+var ErrUnknownKey = errors.New("value was encrypted with an unknown key")
+
+// Keyring holds the encryption keys. A nil *Keyring stores values in
+// plaintext.
+type Keyring struct {
+	primary  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring parses keys given as "<id>:<base64 32-byte key>". The first
+// key encrypts new values; the rest only decrypt. indexKey is the base64
+// blind index key and must not change once data is written.
+func NewKeyring(keys []string, indexKey string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys configured")
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for i, entry := range keys {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("encryption key %d is not in <id>:<base64 key> form", i+1)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes of base64", id)
+		}
+		if _, exists := k.keys[id]; exists {
+			return nil, fmt.Errorf("encryption key %q is configured twice", id)
+		}
+
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cipher for key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cipher for key %q: %w", id, err)
+		}
+
+		k.keys[id] = aead
+		if i == 0 {
+			k.primary = id
+		}
+	}
+
+	index, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil || len(index) < 32 {
+		return nil, errors.New("blind index key must be at least 32 bytes of base64")
+	}
+	k.indexKey = index
+
+	return k, nil
+}
+
+// Encrypt seals plaintext under the primary key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil {
+		return plaintext, nil
+	}
+
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value written by Encrypt. Plaintext values are returned
+// unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	if k == nil {
+		return "", ErrUnknownKey
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("error decrypting value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Current reports whether value is already encrypted under the primary key.
+func (k *Keyring) Current(value string) bool {
+	if k == nil {
+		return !strings.HasPrefix(value, prefix)
+	}
+	return strings.HasPrefix(value, prefix+k.primary+":")
+}
+
+// BlindIndex returns a deterministic hex digest of value, ignoring case and
+// surrounding space, for equality lookups on an encrypted column.
+func (k *Keyring) BlindIndex(value string) string {
+	normalised := []byte(strings.ToLower(strings.TrimSpace(value)))
+	if k == nil {
+		sum := sha256.Sum256(normalised)
+		return hex.EncodeToString(sum[:])
+	}
+
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write(normalised)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// JobReencrypt is the periodic job that moves encrypted columns onto the
+// primary key and encrypts rows written before encryption was enabled
+const JobReencrypt = "pii_reencrypt"
+
+// reencryptBatch is the number of rows rewritten per query
+const reencryptBatch = 500
+
+// Field is an encrypted column. HashColumn, when set, holds the column's
+// blind index and is rewritten with it.
+type Field struct {
+	Table      string
+	Column     string
+	HashColumn string
+}
+
+type row struct {
+	ID    int64  `db:"id"`
+	Value string `db:"value"`
+}
+
+// Reencrypt returns the JobReencrypt job handler for fields. Table and
+// column names come from code, never from input.
+func (k *Keyring) Reencrypt(db *sqlx.DB, fields ...Field) func(ctx context.Context, payload json.RawMessage) error {
+	return func(ctx context.Context, _ json.RawMessage) error {
+		for _, field := range fields {
+			if err := k.reencryptField(ctx, db, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (k *Keyring) reencryptField(ctx context.Context, db *sqlx.DB, field Field) error {
+	selectQuery := fmt.Sprintf(
+		`SELECT id, %s AS value FROM %s WHERE %s NOT LIKE $1 AND id > $2 ORDER BY id LIMIT $3`,
+		field.Column, field.Table, field.Column)
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE id = $2 AND %s = $3`,
+		field.Table, field.Column, field.Column)
+	if field.HashColumn != "" {
+		updateQuery = fmt.Sprintf(`UPDATE %s SET %s = $1, %s = $4 WHERE id = $2 AND %s = $3`,
+			field.Table, field.Column, field.HashColumn, field.Column)
+	}
+
+	current := prefix + k.primary + ":%"
+	var lastID int64
+	for {
+		var rows []row
+		if err := db.SelectContext(ctx, &rows, selectQuery, current, lastID, reencryptBatch); err != nil {
+			return fmt.Errorf("error listing %s.%s for re-encryption: %w", field.Table, field.Column, err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, r := range rows {
+			lastID = r.ID
+
+			plaintext, err := k.Decrypt(r.Value)
+			if err != nil {
+				return fmt.Errorf("error decrypting %s.%s of row %d: %w", field.Table, field.Column, r.ID, err)
+			}
+			encrypted, err := k.Encrypt(plaintext)
+			if err != nil {
+				return err
+			}
+
+			// The old value guards against overwriting a concurrent update
+			args := []interface{}{encrypted, r.ID, r.Value}
+			if field.HashColumn != "" {
+				args = append(args, k.BlindIndex(plaintext))
+			}
+			if _, err := db.ExecContext(ctx, updateQuery, args...); err != nil {
+				return fmt.Errorf("error re-encrypting %s.%s of row %d: %w", field.Table, field.Column, r.ID, err)
+			}
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	config "github.com/dotslashbit/ecommerce-api/configs"
 	"github.com/jmoiron/sqlx"
@@ -9,8 +11,23 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// firstRetryDelay is the wait after the first failed connection
+	// attempt; it doubles after every further failure up to maxRetryDelay
+	firstRetryDelay = 500 * time.Millisecond
+	maxRetryDelay   = 5 * time.Second
+
+	// attemptTimeout bounds a single connection attempt
+	attemptTimeout = 5 * time.Second
+)
+
+// NewDB connects to Postgres. While the database is unreachable, for
+// instance because it starts more slowly than the API under docker-compose
+// or Kubernetes, it retries with exponential backoff, giving up when the
+// next attempt would start after cfg.DBConnectTimeout.
 func NewDB(cfg *config.Config, logger *zap.Logger) (*sqlx.DB, error) {
-	// Construct the connection string with host and port separated
+	// Construct the connection string with host and port separated. It
+	// holds the password, so it is never logged.
 	connectionString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 
@@ -20,19 +37,32 @@ func NewDB(cfg *config.Config, logger *zap.Logger) (*sqlx.DB, error) {
 		zap.String("user", cfg.DBUser),
 		zap.String("dbname", cfg.DBName))
 
-	db, err := sqlx.Connect("postgres", connectionString)
-	if err != nil {
-		logger.Error("Failed to connect to database",
-			zap.Error(err),
-			zap.String("connection_string", connectionString))
-		return nil, fmt.Errorf("error connecting to db: %w", err)
-	}
+	deadline := time.Now().Add(cfg.DBConnectTimeout)
+	delay := firstRetryDelay
+	for attempt := 1; ; attempt++ {
+		db, err := connect(connectionString)
+		if err == nil {
+			logger.Info("Successfully connected to database", zap.Int("attempts", attempt))
+			return db, nil
+		}
 
-	if err = db.Ping(); err != nil {
-		logger.Error("Failed to ping database", zap.Error(err))
-		return nil, fmt.Errorf("error pinging db: %w", err)
+		if time.Now().Add(delay).After(deadline) {
+			logger.Error("Failed to connect to database", zap.Error(err), zap.Int("attempts", attempt))
+			return nil, fmt.Errorf("error connecting to db after %d attempts: %w", attempt, err)
+		}
+		logger.Warn("Database not reachable, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay))
+		time.Sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
 	}
+}
 
-	logger.Info("Successfully connected to database")
-	return db, nil
+// connect opens a pool and pings the database, closing the pool again
+// when the ping fails
+func connect(connectionString string) (*sqlx.DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), attemptTimeout)
+	defer cancel()
+	return sqlx.ConnectContext(ctx, "postgres", connectionString)
 }
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Migration is an up migration file, named "<version>_<description>.up.sql"
+type Migration struct {
+	Version int
+	Name    string
+	Path    string
+}
+
+// LoadMigrations returns the up migrations in dir, ordered by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no migrations found in %s", dir)
+	}
+
+	migrations := make([]Migration, 0, len(paths))
+	seen := make(map[int]string)
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".up.sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no numeric version prefix", filepath.Base(path))
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+		migrations = append(migrations, Migration{Version: version, Name: name, Path: path})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies the migrations in dir that schema_migrations does not
+// list yet, each in its own transaction, and returns how many it applied.
+// Databases migrated by hand before schema_migrations existed should be
+// baselined first, as their migrations are not safe to run twice.
+func Migrate(ctx context.Context, db *sqlx.DB, dir string, logger *zap.Logger) (int, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		sql, err := os.ReadFile(m.Path)
+		if err != nil {
+			return count, err
+		}
+		if err := apply(ctx, db, m, string(sql)); err != nil {
+			return count, err
+		}
+		logger.Info("Applied migration", zap.String("migration", m.Name))
+		count++
+	}
+	return count, nil
+}
+
+// Baseline records every migration in dir up to and including version as
+// applied without running it, for databases migrated before schema
+// migrations were tracked.
+func Baseline(ctx context.Context, db *sqlx.DB, dir string, version int) (int, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := appliedVersions(ctx, db); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if m.Version > version {
+			break
+		}
+		result, err := db.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING`,
+			m.Version, m.Name)
+		if err != nil {
+			return count, fmt.Errorf("error recording migration %s: %w", m.Name, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// appliedVersions creates schema_migrations if needed and returns the
+// versions it lists
+func appliedVersions(ctx context.Context, db *sqlx.DB) (map[int]bool, error) {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating schema_migrations table: %w", err)
+	}
+
+	var versions []int
+	if err := db.SelectContext(ctx, &versions, `SELECT version FROM schema_migrations`); err != nil {
+		return nil, fmt.Errorf("error loading applied migrations: %w", err)
+	}
+	applied := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+func apply(ctx context.Context, db *sqlx.DB, m Migration, sql string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("applying %s: %w", m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+		return fmt.Errorf("error recording migration %s: %w", m.Name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrStatementsClosed is returned by Statements.Get after Close
+var ErrStatementsClosed = errors.New("prepared statements are closed")
+
+// Statements caches prepared statements by query text, so that hot queries
+// are parsed by Postgres once per connection instead of on every request.
+// database/sql prepares a statement on each pooled connection it runs on
+// and forgets it with the connection, so cached statements follow the pool
+// as it grows and shrinks. Close the cache before the pool.
+//
+// Only cache queries with a fixed text; queries built from request input
+// would grow the cache without bound.
+type Statements struct {
+	db *sqlx.DB
+
+	mu     sync.Mutex
+	stmts  map[string]*sqlx.Stmt
+	closed bool
+}
+
+// NewStatements creates an empty statement cache for db
+func NewStatements(db *sqlx.DB) *Statements {
+	return &Statements{db: db, stmts: make(map[string]*sqlx.Stmt)}
+}
+
+// Get returns the prepared statement for query, preparing it on first use
+func (s *Statements) Get(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStatementsClosed
+	}
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing statement: %w", err)
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
+
+// Close closes every cached statement. Get fails afterwards.
+func (s *Statements) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, stmt := range s.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.stmts = nil
+	s.closed = true
+	return errors.Join(errs...)
+}
@@ -0,0 +1,255 @@
+// Package deprecation marks endpoints, query parameters and request fields
+// as deprecated. Calls that use a deprecated surface get Deprecation,
+// Sunset, Link and Warning response headers, and are counted per client so
+// admins can see who still depends on a surface before it is removed.
+package deprecation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type Kind string
+
+const (
+	// KindEndpoint deprecates a whole route
+	KindEndpoint Kind = "endpoint"
+	// KindParam deprecates a query parameter of a route
+	KindParam Kind = "param"
+	// KindField deprecates a top-level field of a JSON request body
+	KindField Kind = "field"
+)
+
+// flushInterval is how often buffered usage counts are written to the database
+const flushInterval = 30 * time.Second
+
+// Notice describes one deprecated surface.
+type Notice struct {
+	ID           string     `json:"id"`
+	Kind         Kind       `json:"kind"`
+	Route        string     `json:"route"`
+	Name         string     `json:"name,omitempty"` // parameter or field name
+	Message      string     `json:"message"`
+	DeprecatedAt time.Time  `json:"deprecated_at"`
+	Sunset       *time.Time `json:"sunset,omitempty"`
+	Link         string     `json:"link,omitempty"`
+}
+
+type usageKey struct {
+	noticeID string
+	client   string
+}
+
+type usage struct {
+	calls     int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Tracker holds the registered notices and counts their use. A nil
+// *Tracker leaves handlers untouched.
+type Tracker struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	notices map[string]*Notice
+	order   []string
+	pending map[usageKey]*usage
+
+	wg sync.WaitGroup
+}
+
+func NewTracker(db *sqlx.DB, logger *zap.Logger) *Tracker {
+	return &Tracker{
+		db:      db,
+		logger:  logger,
+		notices: make(map[string]*Notice),
+		pending: make(map[usageKey]*usage),
+	}
+}
+
+// Register adds a notice. It must be called before routes are registered.
+func (t *Tracker) Register(notice Notice) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.notices[notice.ID] = &notice
+	t.order = append(t.order, notice.ID)
+}
+
+// Wrap returns next with the given notices applied. Endpoint notices apply
+// to every call; param and field notices only when the request uses them.
+func (t *Tracker) Wrap(next httprouter.Handle, noticeIDs ...string) httprouter.Handle {
+	if t == nil {
+		return next
+	}
+
+	t.mu.Lock()
+	notices := make([]*Notice, 0, len(noticeIDs))
+	for _, id := range noticeIDs {
+		notice, ok := t.notices[id]
+		if !ok {
+			t.mu.Unlock()
+			panic(fmt.Sprintf("deprecation: unknown notice %q", id))
+		}
+		notices = append(notices, notice)
+	}
+	t.mu.Unlock()
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		var fields map[string]json.RawMessage
+		for _, notice := range notices {
+			used := false
+			switch notice.Kind {
+			case KindEndpoint:
+				used = true
+			case KindParam:
+				used = r.URL.Query().Has(notice.Name)
+			case KindField:
+				if fields == nil {
+					fields = bodyFields(r)
+				}
+				_, used = fields[notice.Name]
+			}
+			if used {
+				t.apply(w, r, notice)
+			}
+		}
+
+		next(w, r, ps)
+	}
+}
+
+func (t *Tracker) apply(w http.ResponseWriter, r *http.Request, notice *Notice) {
+	w.Header().Set("Deprecation", "@"+strconv.FormatInt(notice.DeprecatedAt.Unix(), 10))
+	if notice.Sunset != nil {
+		w.Header().Set("Sunset", notice.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if notice.Link != "" {
+		w.Header().Add("Link", "<"+notice.Link+`>; rel="deprecation"`)
+	}
+	w.Header().Add("Warning", `299 - "`+notice.Message+`"`)
+
+	t.record(notice.ID, ClientID(r))
+}
+
+func (t *Tracker) record(noticeID, client string) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := usageKey{noticeID: noticeID, client: client}
+	u, ok := t.pending[key]
+	if !ok {
+		u = &usage{firstSeen: now}
+		t.pending[key] = u
+	}
+	u.calls++
+	u.lastSeen = now
+}
+
+// ClientID identifies the caller in usage reports: the API key that
+// authenticated the request, otherwise the client IP.
+func ClientID(r *http.Request) string {
+	if key := apikey.FromContext(r.Context()); key != nil {
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	}
+	return "ip:" + clientip.FromRequest(r)
+}
+
+// bodyFields decodes the top-level fields of a JSON body and restores the
+// body for the wrapped handler. Bodies that are not JSON objects have no
+// fields.
+func bodyFields(r *http.Request) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if r.Body == nil {
+		return fields
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(body, &fields)
+	return fields
+}
+
+// Start flushes usage counts to the database every flushInterval until ctx
+// is cancelled, then flushes once more.
+func (t *Tracker) Start(ctx context.Context) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				if err := t.flush(context.Background()); err != nil {
+					t.logger.Error("Failed to flush deprecated API usage", zap.Error(err))
+				}
+				return
+			case <-ticker.C:
+				if err := t.flush(ctx); err != nil {
+					t.logger.Error("Failed to flush deprecated API usage", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until the flush loop has returned.
+func (t *Tracker) Wait() {
+	t.wg.Wait()
+}
+
+func (t *Tracker) flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[usageKey]*usage)
+	t.mu.Unlock()
+
+	query := `
+		INSERT INTO deprecated_usage (notice_id, client, calls, first_seen, last_seen)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (notice_id, client) DO UPDATE SET
+			calls = deprecated_usage.calls + EXCLUDED.calls,
+			last_seen = GREATEST(deprecated_usage.last_seen, EXCLUDED.last_seen)`
+
+	for key, u := range pending {
+		_, err := t.db.ExecContext(ctx, query, key.noticeID, key.client, u.calls, u.firstSeen, u.lastSeen)
+		if err != nil {
+			// Keep the counts for the next flush
+			t.mu.Lock()
+			for key, u := range pending {
+				t.pending[key] = u
+			}
+			t.mu.Unlock()
+			return fmt.Errorf("error recording deprecated API usage: %w", err)
+		}
+		delete(pending, key)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package deprecation_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/deprecation"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+func TestWrap(t *testing.T) {
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	tracker := deprecation.NewTracker(nil, zap.NewNop())
+	tracker.Register(deprecation.Notice{
+		ID:           "widgets.list.colour",
+		Kind:         deprecation.KindParam,
+		Route:        "GET /widgets",
+		Name:         "colour",
+		Message:      "colour is deprecated, use color",
+		DeprecatedAt: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       &sunset,
+	})
+	tracker.Register(deprecation.Notice{
+		ID:           "widgets.create.size",
+		Kind:         deprecation.KindField,
+		Route:        "POST /widgets",
+		Name:         "size",
+		Message:      "size is deprecated, use dimensions",
+		DeprecatedAt: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Link:         "https://example.com/changelog",
+	})
+
+	next := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {}
+	handle := tracker.Wrap(next, "widgets.list.colour", "widgets.create.size")
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		wantWarning string
+		wantSunset  string
+		wantLink    string
+	}{
+		{
+			name:   "current parameter",
+			method: http.MethodGet,
+			target: "/widgets?color=red",
+		},
+		{
+			name:        "deprecated parameter",
+			method:      http.MethodGet,
+			target:      "/widgets?colour=red",
+			wantWarning: `299 - "colour is deprecated, use color"`,
+			wantSunset:  "Thu, 01 Apr 2027 00:00:00 GMT",
+		},
+		{
+			name:   "current field",
+			method: http.MethodPost,
+			target: "/widgets",
+			body:   `{"dimensions": "10x10"}`,
+		},
+		{
+			name:        "deprecated field",
+			method:      http.MethodPost,
+			target:      "/widgets",
+			body:        `{"size": 10}`,
+			wantWarning: `299 - "size is deprecated, use dimensions"`,
+			wantLink:    `<https://example.com/changelog>; rel="deprecation"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handle(w, r, nil)
+
+			if got := w.Header().Get("Warning"); got != tt.wantWarning {
+				t.Errorf("Warning = %q, want %q", got, tt.wantWarning)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+			if deprecated := w.Header().Get("Deprecation") != ""; deprecated != (tt.wantWarning != "") {
+				t.Errorf("Deprecation header set = %v, want %v", deprecated, tt.wantWarning != "")
+			}
+		})
+	}
+}
+
+func TestWrapUnknownNotice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Wrap() with an unknown notice did not panic")
+		}
+	}()
+	deprecation.NewTracker(nil, zap.NewNop()).Wrap(nil, "missing")
+}
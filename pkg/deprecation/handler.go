@@ -0,0 +1,42 @@
+package deprecation
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Handler exposes deprecated API usage to admins.
+type Handler struct {
+	tracker *Tracker
+	logger  *zap.Logger
+}
+
+func NewHandler(tracker *Tracker, logger *zap.Logger) *Handler {
+	return &Handler{
+		tracker: tracker,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/admin/deprecations", h.GetReport)
+}
+
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	report, err := h.tracker.Report(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to build deprecation report", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Deprecations []*NoticeUsage `json:"deprecations"`
+	}{
+		Deprecations: report,
+	})
+}
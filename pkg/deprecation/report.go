@@ -0,0 +1,76 @@
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ClientUsage is how often one client has used a deprecated surface.
+type ClientUsage struct {
+	NoticeID  string    `db:"notice_id" json:"-"`
+	Client    string    `db:"client" json:"client"`
+	Calls     int64     `db:"calls" json:"calls"`
+	FirstSeen time.Time `db:"first_seen" json:"first_seen"`
+	LastSeen  time.Time `db:"last_seen" json:"last_seen"`
+}
+
+// NoticeUsage is one registered notice and the clients still calling it,
+// most recent first.
+type NoticeUsage struct {
+	*Notice
+	Clients []*ClientUsage `json:"clients"`
+}
+
+// Report returns every registered notice with its recorded usage. Counts
+// not yet flushed to the database are included.
+func (t *Tracker) Report(ctx context.Context) ([]*NoticeUsage, error) {
+	var rows []*ClientUsage
+	query := `SELECT * FROM deprecated_usage ORDER BY last_seen DESC`
+	if err := t.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("error listing deprecated API usage: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]*NoticeUsage, 0, len(t.order))
+	byID := make(map[string]*NoticeUsage, len(t.order))
+	for _, id := range t.order {
+		entry := &NoticeUsage{Notice: t.notices[id], Clients: []*ClientUsage{}}
+		report = append(report, entry)
+		byID[id] = entry
+	}
+
+	byClient := make(map[usageKey]*ClientUsage)
+	for _, row := range rows {
+		entry, ok := byID[row.NoticeID]
+		if !ok {
+			// Usage of a notice that has since been removed
+			continue
+		}
+		entry.Clients = append(entry.Clients, row)
+		byClient[usageKey{noticeID: row.NoticeID, client: row.Client}] = row
+	}
+
+	for key, u := range t.pending {
+		entry, ok := byID[key.noticeID]
+		if !ok {
+			continue
+		}
+		if row, ok := byClient[key]; ok {
+			row.Calls += u.calls
+			row.LastSeen = u.lastSeen
+			continue
+		}
+		entry.Clients = append([]*ClientUsage{{
+			NoticeID:  key.noticeID,
+			Client:    key.client,
+			Calls:     u.calls,
+			FirstSeen: u.firstSeen,
+			LastSeen:  u.lastSeen,
+		}}, entry.Clients...)
+	}
+
+	return report, nil
+}
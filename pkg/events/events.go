@@ -0,0 +1,166 @@
+// Package events publishes domain events to a message broker so downstream
+// services (analytics, ERP) can consume catalog and order changes.
+//
+// Every message body is a JSON Envelope:
+//
+//	{
+//	  "id": "42",                          // outbox event ID, unique and increasing
+//	  "type": "product.updated",           // event type, see below
+//	  "source": "ecommerce-api",
+//	  "store_id": 1,                       // store the aggregate belongs to
+//	  "aggregate_type": "product",
+//	  "aggregate_id": 7,
+//	  "occurred_at": "2024-05-01T12:00:00Z",
+//	  "data": { ... }                      // event specific payload
+//	}
+//
+// Event types and their data:
+//
+//	product.created            the full product resource as returned by GET /products/:id
+//	product.updated            the full product resource after the update
+//	product.deleted            {"id": 7}
+//	product.status_changed     {"id": 7, "from": "pending_review", "to": "published"}
+//	product.stock_changed      {"id": 7, "stock_quantity": 3, "delta": -1}
+//	product.low_stock          {"id": 7, "name": "Mug", "stock_quantity": 3, "low_stock_threshold": 5}
+//	product.back_in_stock      {"id": 7, "name": "Mug", "stock_quantity": 10}
+//	product.preorder_released  {"id": 7, "name": "Mug", "stock_quantity": 6, "backordered": 4}
+//
+// Delivery is at least once; consumers should de-duplicate on id. Kafka
+// messages are keyed by "<aggregate_type>:<aggregate_id>" so events for one
+// aggregate stay ordered within a partition. NATS subjects are
+// "<subject_prefix>.<type>", e.g. "ecommerce.product.updated".
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"github.com/dotslashbit/ecommerce-api/pkg/outbox"
+	"go.uber.org/zap"
+)
+
+// Source is the value of Envelope.Source for events emitted by this service.
+const Source = "ecommerce-api"
+
+// Envelope is the documented wire format of every published event.
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Source        string          `json:"source"`
+	StoreID       int64           `json:"store_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Key is the partitioning key of the envelope.
+func (e *Envelope) Key() string {
+	return e.AggregateType + ":" + strconv.FormatInt(e.AggregateID, 10)
+}
+
+// Publisher sends envelopes to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, envelope *Envelope) error
+	Close() error
+}
+
+// New builds the Publisher selected by cfg.EventsDriver.
+func New(cfg *config.Config, logger *zap.Logger) (Publisher, error) {
+	switch cfg.EventsDriver {
+	case "kafka":
+		return NewKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	case "nats":
+		return NewNATSPublisher(cfg.NATSURL, cfg.NATSSubjectPrefix)
+	case "log", "":
+		return NewLogPublisher(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown events driver %q", cfg.EventsDriver)
+	}
+}
+
+// FromOutbox converts an outbox event into its wire envelope.
+func FromOutbox(event *outbox.Event) *Envelope {
+	return &Envelope{
+		ID:            strconv.FormatInt(event.ID, 10),
+		Type:          event.EventType,
+		Source:        Source,
+		StoreID:       event.StoreID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt.UTC(),
+		Data:          event.Payload,
+	}
+}
+
+// outboxPublisher lets the outbox relay publish through a Publisher.
+type outboxPublisher struct {
+	publisher Publisher
+}
+
+// NewOutboxPublisher adapts p to the outbox.Publisher interface
+func NewOutboxPublisher(p Publisher) outbox.Publisher {
+	return &outboxPublisher{publisher: p}
+}
+
+func (o *outboxPublisher) Publish(ctx context.Context, event *outbox.Event) error {
+	return o.publisher.Publish(ctx, FromOutbox(event))
+}
+
+// logPublisher writes envelopes to the log. It is the default driver.
+type logPublisher struct {
+	logger *zap.Logger
+}
+
+// NewLogPublisher creates a Publisher that only logs events
+func NewLogPublisher(logger *zap.Logger) Publisher {
+	return &logPublisher{logger: logger}
+}
+
+func (p *logPublisher) Publish(_ context.Context, envelope *Envelope) error {
+	p.logger.Info("Event published",
+		zap.String("event_id", envelope.ID),
+		zap.String("event_type", envelope.Type),
+		zap.String("key", envelope.Key()))
+	return nil
+}
+
+func (p *logPublisher) Close() error {
+	return nil
+}
+
+// multiPublisher fans every envelope out to several publishers
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher creates a Publisher that publishes to all of publishers.
+// An error from any of them fails the publish, so the outbox relay retries
+// and the others may see the event again.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+func (m *multiPublisher) Publish(ctx context.Context, envelope *Envelope) error {
+	for _, p := range m.publishers {
+		if err := p.Publish(ctx, envelope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiPublisher) Close() error {
+	var errs []error
+	for _, p := range m.publishers {
+		if err := p.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
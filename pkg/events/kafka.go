@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaPublisher writes envelopes to a single Kafka topic
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a Publisher for a comma separated broker list
+func NewKafkaPublisher(brokers, topic string) Publisher {
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, envelope *Envelope) error {
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(envelope.Key()),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(envelope.Type)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error publishing event to kafka: %w", err)
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
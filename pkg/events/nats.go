@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsPublisher publishes envelopes on one subject per event type
+type natsPublisher struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+// NewNATSPublisher connects to the NATS server at url
+func NewNATSPublisher(url, subjectPrefix string) (Publisher, error) {
+	conn, err := nats.Connect(url, nats.Name(Source))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %w", err)
+	}
+	return &natsPublisher{conn: conn, subjectPrefix: subjectPrefix}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+
+	msg := nats.NewMsg(p.subjectPrefix + "." + envelope.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, envelope.ID)
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("error publishing event to nats: %w", err)
+	}
+	// Flush so an unreachable server surfaces as a publish error and the
+	// outbox relay retries the event
+	if err := p.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("error flushing nats connection: %w", err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
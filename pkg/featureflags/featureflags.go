@@ -0,0 +1,262 @@
+// Package featureflags toggles risky features, such as a new checkout or a
+// search backend, per environment and per store without a redeploy. Flags
+// are read from a chain of providers: the config file, then the
+// environment, then an optional remote Provider, each overriding the rules
+// of the one before. Flags nobody defines are off.
+package featureflags
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Known flags
+const (
+	// NewCheckout switches checkouts to the new flow
+	NewCheckout = "new_checkout"
+	// SearchBackend sends product search to the external search engine
+	SearchBackend = "search_backend"
+)
+
+// EnvPrefix starts the environment variables read by the env provider, e.g.
+// FEATURE_NEW_CHECKOUT=true and FEATURE_NEW_CHECKOUT_STORES=2,5
+const EnvPrefix = "FEATURE_"
+
+// Rule is the state of one flag.
+type Rule struct {
+	// Enabled is the state for stores without an override
+	Enabled bool
+	// Stores overrides Enabled for the listed store IDs
+	Stores map[int64]bool
+}
+
+// On reports whether the rule enables the flag for storeID.
+func (r Rule) On(storeID int64) bool {
+	if on, ok := r.Stores[storeID]; ok {
+		return on
+	}
+	return r.Enabled
+}
+
+// Provider returns flag rules by name. Implement it to read flags from a
+// remote flag service; a rule it returns replaces the local one.
+type Provider interface {
+	Rules(ctx context.Context) (map[string]Rule, error)
+}
+
+// configProvider serves the feature_flags section of the config file.
+type configProvider struct {
+	rules map[string]Rule
+}
+
+// NewConfigProvider creates a Provider of the configured flags
+func NewConfigProvider(flags map[string]config.FeatureFlag) Provider {
+	rules := make(map[string]Rule, len(flags))
+	for name, flag := range flags {
+		rule := Rule{Enabled: flag.Enabled, Stores: make(map[int64]bool)}
+		for _, id := range flag.Stores {
+			rule.Stores[id] = true
+		}
+		for _, id := range flag.DisabledStores {
+			rule.Stores[id] = false
+		}
+		rules[strings.ToLower(name)] = rule
+	}
+	return &configProvider{rules: rules}
+}
+
+func (p *configProvider) Rules(context.Context) (map[string]Rule, error) {
+	return p.rules, nil
+}
+
+// envProvider reads FEATURE_<NAME> and FEATURE_<NAME>_STORES variables.
+type envProvider struct{}
+
+// NewEnvProvider creates a Provider of the flags set in the environment.
+// FEATURE_<NAME> sets whether the flag is on, FEATURE_<NAME>_STORES lists
+// store IDs it is on for regardless. Either replaces the config file rule
+// of the flag.
+func NewEnvProvider() Provider {
+	return &envProvider{}
+}
+
+func (p *envProvider) Rules(context.Context) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	var stores []string
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		name, ok := strings.CutPrefix(key, EnvPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		if strings.HasSuffix(name, "_STORES") {
+			stores = append(stores, kv)
+			continue
+		}
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			continue
+		}
+		name = strings.ToLower(name)
+		rule := rules[name]
+		rule.Enabled = on
+		rules[name] = rule
+	}
+
+	// Store lists are applied last so they land on the rule of their flag
+	// whatever order the environment is in
+	for _, kv := range stores {
+		key, value, _ := strings.Cut(kv, "=")
+		name := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(key, EnvPrefix), "_STORES"))
+		rule := rules[name]
+		if rule.Stores == nil {
+			rule.Stores = make(map[int64]bool)
+		}
+		for _, s := range strings.Split(value, ",") {
+			if id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+				rule.Stores[id] = true
+			}
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// Flags answers whether a flag is on. A nil *Flags has every flag off.
+type Flags struct {
+	providers       []Provider
+	refreshInterval time.Duration
+	logger          *zap.Logger
+
+	mu    sync.RWMutex
+	rules map[string]Rule
+
+	wg sync.WaitGroup
+}
+
+// New creates the flags of providers, later providers overriding earlier
+// ones. Nil providers are skipped. Call Load before serving.
+func New(refreshInterval time.Duration, logger *zap.Logger, providers ...Provider) *Flags {
+	f := &Flags{
+		refreshInterval: refreshInterval,
+		logger:          logger,
+		rules:           make(map[string]Rule),
+	}
+	for _, p := range providers {
+		if p != nil {
+			f.providers = append(f.providers, p)
+		}
+	}
+	return f
+}
+
+// Load reads the rules of every provider. When a provider fails, flags no
+// other provider defines keep their previous rules, so a remote outage does
+// not switch them off.
+func (f *Flags) Load(ctx context.Context) error {
+	f.mu.RLock()
+	previous := f.rules
+	f.mu.RUnlock()
+
+	var firstErr error
+	rules := make(map[string]Rule)
+	for _, p := range f.providers {
+		provided, err := p.Rules(ctx)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for name, rule := range provided {
+			rules[name] = rule
+		}
+	}
+	if firstErr != nil {
+		for name, rule := range previous {
+			if _, ok := rules[name]; !ok {
+				rules[name] = rule
+			}
+		}
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+	return firstErr
+}
+
+// Enabled reports whether the named flag is on for the store ctx is scoped
+// to. Unscoped contexts, such as background jobs, see the default state.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	if f == nil {
+		return false
+	}
+
+	f.mu.RLock()
+	rule, ok := f.rules[name]
+	f.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	if storeID, scoped := tenant.StoreID(ctx); scoped {
+		return rule.On(storeID)
+	}
+	return rule.Enabled
+}
+
+// Require returns next answering 404 Not Found while the named flag is off,
+// so a route under development stays hidden where it is not enabled.
+func (f *Flags) Require(name string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if !f.Enabled(r.Context(), name) {
+			http.NotFound(w, r)
+			return
+		}
+		next(w, r, ps)
+	}
+}
+
+// Start reloads the rules every refresh interval until ctx is cancelled, so
+// changes made in a remote provider apply without a restart.
+func (f *Flags) Start(ctx context.Context) {
+	if f.refreshInterval <= 0 {
+		return
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(f.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := f.Load(ctx); err != nil {
+				f.logger.Error("Failed to refresh feature flags", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Wait blocks until the refresh loop has returned.
+func (f *Flags) Wait() {
+	f.wg.Wait()
+}
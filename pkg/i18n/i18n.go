@@ -0,0 +1,148 @@
+// Package i18n translates API error messages into the locale a client
+// prefers. Messages are written in English in the code and translated by
+// message catalogs embedded from messages/<locale>.json, each mapping the
+// English message to its translation.
+//
+// A message is looked up in the client's preferred locales first, then in
+// the configured default locale. Messages no catalog translates, such as
+// ones naming a request parameter, are served in English.
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/locale"
+)
+
+// SourceLocale is the locale messages are written in
+const SourceLocale = "en"
+
+//go:embed messages/*.json
+var catalogFiles embed.FS
+
+// Catalog holds the translated messages of every embedded locale
+type Catalog struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// New loads the embedded message catalogs. defaultLocale ends the fallback
+// chain of clients whose preferred locales have no translation.
+func New(defaultLocale string) (*Catalog, error) {
+	normalized, ok := locale.Normalize(defaultLocale)
+	if !ok {
+		return nil, fmt.Errorf("invalid default locale %q", defaultLocale)
+	}
+
+	files, err := catalogFiles.ReadDir("messages")
+	if err != nil {
+		return nil, fmt.Errorf("error reading message catalogs: %w", err)
+	}
+	c := &Catalog{defaultLocale: normalized, messages: make(map[string]map[string]string)}
+	for _, file := range files {
+		tag, ok := locale.Normalize(strings.TrimSuffix(file.Name(), path.Ext(file.Name())))
+		if !ok {
+			return nil, fmt.Errorf("message catalog %s is not named after a locale", file.Name())
+		}
+		data, err := catalogFiles.ReadFile(path.Join("messages", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading message catalog %s: %w", file.Name(), err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("error decoding message catalog %s: %w", file.Name(), err)
+		}
+		c.messages[tag] = messages
+	}
+	return c, nil
+}
+
+// Translate returns message in the first of locales, followed by the
+// default locale, that translates it, and the locale of the result.
+func (c *Catalog) Translate(locales []string, message string) (string, string) {
+	chain := append(append([]string{}, locales...), c.defaultLocale, locale.Base(c.defaultLocale))
+	for _, tag := range chain {
+		if tag == SourceLocale {
+			return message, SourceLocale
+		}
+		if translated, ok := c.messages[tag][message]; ok {
+			return translated, tag
+		}
+	}
+	return message, SourceLocale
+}
+
+type contextKey struct{}
+
+type requestLocales struct {
+	catalog *Catalog
+	locales []string
+}
+
+// Message translates message for the request ctx belongs to. Handlers
+// writing errors as JSON use it; plain text errors are translated by Wrap.
+func Message(ctx context.Context, message string) string {
+	if rl, ok := ctx.Value(contextKey{}).(requestLocales); ok {
+		message, _ = rl.catalog.Translate(rl.locales, message)
+	}
+	return message
+}
+
+// Wrap translates the plain text error responses written with http.Error
+// and makes the client's locales available to Message.
+func (c *Catalog) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locales := locale.Preferred(r)
+		ctx := context.WithValue(r.Context(), contextKey{}, requestLocales{catalog: c, locales: locales})
+
+		ew := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r.WithContext(ctx))
+		if ew.status == 0 {
+			return
+		}
+
+		message, tag := c.Translate(locales, strings.TrimSuffix(ew.body.String(), "\n"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", tag)
+		w.WriteHeader(ew.status)
+		fmt.Fprintln(w, message)
+	})
+}
+
+// errorWriter holds back plain text error responses so Wrap can translate
+// them. Other responses pass straight through.
+type errorWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (ew *errorWriter) WriteHeader(status int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	if status >= http.StatusBadRequest && strings.HasPrefix(ew.Header().Get("Content-Type"), "text/plain") {
+		ew.status = status
+		return
+	}
+	ew.ResponseWriter.WriteHeader(status)
+}
+
+func (ew *errorWriter) Write(b []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.status != 0 {
+		return ew.body.Write(b)
+	}
+	return ew.ResponseWriter.Write(b)
+}
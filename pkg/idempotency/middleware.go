@@ -0,0 +1,162 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/apikey"
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/dotslashbit/ecommerce-api/pkg/tenant"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderKey is the request header clients set to make a request idempotent
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses served from a stored result
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// Middleware wraps unsafe handlers with idempotency key handling. A nil
+// *Middleware passes requests through untouched.
+type Middleware struct {
+	store  *Store
+	logger *zap.Logger
+	ttl    time.Duration
+}
+
+func NewMiddleware(store *Store, logger *zap.Logger, ttl time.Duration) *Middleware {
+	return &Middleware{
+		store:  store,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+// Wrap returns next guarded by the Idempotency-Key header. Requests without
+// the header run as usual. A key reused with a different request is
+// rejected, and a key whose first request is still running gets a 409.
+func (m *Middleware) Wrap(next httprouter.Handle) httprouter.Handle {
+	if m == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next(w, r, ps)
+			return
+		}
+		if len(key) > maxKeyLength {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			m.logger.Error("Failed to read request body", zap.Error(err))
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := requestScope(r)
+		rec, claimed, err := m.store.Claim(r.Context(), scope, key, requestHash(r, body), m.ttl)
+		if err != nil {
+			m.logger.Error("Failed to claim idempotency key", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if !claimed {
+			m.replay(w, r, rec, body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r, ps)
+
+		// The request may have been cancelled by now, but the result must
+		// still be stored or released.
+		ctx := context.Background()
+		if recorder.status >= http.StatusInternalServerError {
+			if err := m.store.Release(ctx, scope, key); err != nil {
+				m.logger.Error("Failed to release idempotency key", zap.Error(err))
+			}
+			return
+		}
+		contentType := recorder.Header().Get("Content-Type")
+		if err := m.store.Complete(ctx, scope, key, recorder.status, contentType, recorder.body.Bytes()); err != nil {
+			m.logger.Error("Failed to store idempotent response", zap.Error(err))
+		}
+	}
+}
+
+func (m *Middleware) replay(w http.ResponseWriter, r *http.Request, rec *Record, body []byte) {
+	if rec.RequestHash != requestHash(r, body) {
+		http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if !rec.Completed() {
+		http.Error(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+		return
+	}
+
+	m.logger.Info("Replaying idempotent response", zap.String("key", rec.Key))
+	if rec.ContentType != nil {
+		w.Header().Set("Content-Type", *rec.ContentType)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(*rec.StatusCode)
+	w.Write(rec.ResponseBody)
+}
+
+// requestScope names the store and client a key belongs to, so keys chosen
+// by different clients never collide. Clients are told apart by API key,
+// then by signed-in admin, and anonymous clients by IP.
+func requestScope(r *http.Request) string {
+	client := "ip:" + clientip.FromRequest(r)
+	if key := apikey.FromContext(r.Context()); key != nil {
+		client = "api_key:" + strconv.FormatInt(key.ID, 10)
+	} else if apikey.IsAdmin(r.Context()) {
+		// The admin guard sets the actor to the signed-in admin
+		client = audit.ActorFrom(r.Context()).Name
+	}
+	return "store:" + strconv.FormatInt(tenant.StoreIDOrDefault(r.Context()), 10) + " " + client
+}
+
+// requestHash fingerprints a request so a key cannot be reused for a
+// different endpoint or payload.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,98 @@
+// Package idempotency lets clients safely retry unsafe requests. A request
+// carrying an Idempotency-Key header runs once; repeats of the same key
+// within the TTL replay the stored response instead of running again.
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Record is a claimed idempotency key and, once the request finished, its
+// stored response.
+type Record struct {
+	Scope        string    `db:"scope"`
+	Key          string    `db:"key"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   *int      `db:"status_code"`
+	ContentType  *string   `db:"content_type"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+// Completed reports whether the original request has finished.
+func (r *Record) Completed() bool {
+	return r.StatusCode != nil
+}
+
+// Store persists idempotency keys in Postgres.
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// Claim reserves key within scope for a new request. If the key is already
+// held and has not expired, the existing record is returned with claimed
+// set to false. Expired keys are taken over as if they were new.
+func (s *Store) Claim(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (rec *Record, claimed bool, err error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+		ON CONFLICT (scope, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+		RETURNING *`
+
+	var claimedRec Record
+	err = s.db.GetContext(ctx, &claimedRec, query, scope, key, requestHash, ttl.Seconds())
+	if err == nil {
+		return &claimedRec, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("error claiming idempotency key: %w", err)
+	}
+
+	var existing Record
+	query = `SELECT * FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	err = s.db.GetContext(ctx, &existing, query, scope, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+	return &existing, false, nil
+}
+
+// Complete stores the response of the request that claimed key in scope.
+func (s *Store) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE scope = $4 AND key = $5`
+
+	if _, err := s.db.ExecContext(ctx, query, statusCode, contentType, body, scope, key); err != nil {
+		return fmt.Errorf("error completing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release drops a claimed key so the request can be retried, used when the
+// request failed without a result worth replaying.
+func (s *Store) Release(ctx context.Context, scope, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	if _, err := s.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+	return nil
+}
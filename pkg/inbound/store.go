@@ -0,0 +1,83 @@
+// Package inbound authenticates webhooks sent to this service by carriers
+// and payment providers. Each provider signs its deliveries with its own
+// secret; deliveries with a bad signature or a stale timestamp are
+// rejected, and an event that was already processed is not processed
+// again.
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// JobPrune is the periodic job that deletes old webhook receipts
+const JobPrune = "webhook_receipts_prune"
+
+// MinReceiptRetention is how long receipts are kept at least, so an event
+// re-signed by a provider's retries days later is still recognised.
+const MinReceiptRetention = 7 * 24 * time.Hour
+
+// receiptMargin is added to the longest time a delivery can be replayed,
+// to allow for clock skew between the service and the database
+const receiptMargin = time.Hour
+
+// ReceiptRetention returns how long receipts are kept under the given
+// timestamp tolerance. A delivery timestamped up to tolerance in the
+// future is accepted until tolerance after its timestamp, so its receipt
+// must outlive twice the tolerance or the replay would be processed again.
+func ReceiptRetention(tolerance time.Duration) time.Duration {
+	return max(MinReceiptRetention, 2*tolerance+receiptMargin)
+}
+
+// Store records the events received from each provider in Postgres.
+type Store struct {
+	db        *sqlx.DB
+	retention time.Duration
+}
+
+// NewStore creates the receipt store for deliveries verified with the
+// given timestamp tolerance.
+func NewStore(db *sqlx.DB, tolerance time.Duration) *Store {
+	return &Store{db: db, retention: ReceiptRetention(tolerance)}
+}
+
+// Claim records an event and reports whether it is new. An event already
+// claimed by an earlier delivery returns false.
+func (s *Store) Claim(ctx context.Context, provider, eventID string) (bool, error) {
+	query := `
+		INSERT INTO webhook_receipts (provider, event_id) VALUES ($1, $2)
+		ON CONFLICT (provider, event_id) DO NOTHING`
+	result, err := s.db.ExecContext(ctx, query, provider, eventID)
+	if err != nil {
+		return false, fmt.Errorf("error claiming webhook event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// Release forgets an event whose processing failed, so the provider's
+// retry is processed.
+func (s *Store) Release(ctx context.Context, provider, eventID string) error {
+	query := `DELETE FROM webhook_receipts WHERE provider = $1 AND event_id = $2`
+	if _, err := s.db.ExecContext(ctx, query, provider, eventID); err != nil {
+		return fmt.Errorf("error releasing webhook event: %w", err)
+	}
+	return nil
+}
+
+// Prune is the JobPrune job handler.
+func (s *Store) Prune(ctx context.Context, _ json.RawMessage) error {
+	query := `DELETE FROM webhook_receipts WHERE received_at < NOW() - $1 * INTERVAL '1 second'`
+	if _, err := s.db.ExecContext(ctx, query, s.retention.Seconds()); err != nil {
+		return fmt.Errorf("error pruning webhook receipts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package inbound_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/inbound"
+)
+
+func TestReceiptRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance time.Duration
+		want      time.Duration
+	}{
+		{name: "default tolerance", tolerance: 5 * time.Minute, want: inbound.MinReceiptRetention},
+		{name: "zero tolerance", want: inbound.MinReceiptRetention},
+		{name: "tolerance beyond the minimum", tolerance: 5 * 24 * time.Hour, want: 10*24*time.Hour + time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inbound.ReceiptRetention(tt.tolerance); got != tt.want {
+				t.Errorf("ReceiptRetention(%v) = %v, want %v", tt.tolerance, got, tt.want)
+			}
+		})
+	}
+}
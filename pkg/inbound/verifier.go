@@ -0,0 +1,171 @@
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderSignature carries "t=<unix>,v1=<hex>", where v1 is the
+	// HMAC-SHA256 of "<unix>.<event id>.<body>" keyed with the provider's
+	// secret. It is the scheme of the webhooks this service sends, with the
+	// event ID signed too, so a captured delivery cannot be replayed under
+	// a new ID.
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderEventID identifies an event across delivery attempts
+	HeaderEventID = "X-Webhook-ID"
+
+	maxBodySize    = 1 << 20
+	maxEventIDSize = 255
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+)
+
+// Provider is a third party that sends webhooks. Deliveries from a provider
+// without a secret are rejected.
+type Provider struct {
+	Name   string
+	Secret string
+}
+
+// Verifier guards webhook handlers. Deliveries must be signed with the
+// provider's secret and timestamped within the tolerance.
+type Verifier struct {
+	store     *Store
+	logger    *zap.Logger
+	tolerance time.Duration
+}
+
+func NewVerifier(store *Store, logger *zap.Logger, tolerance time.Duration) *Verifier {
+	return &Verifier{
+		store:     store,
+		logger:    logger,
+		tolerance: tolerance,
+	}
+}
+
+// Wrap returns next guarded for deliveries from provider. A repeated event
+// is acknowledged without running next; an event whose handler failed with
+// a server error is released so the provider's retry runs it.
+func (v *Verifier) Wrap(provider Provider, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			v.logger.Error("Failed to read webhook body", zap.String("provider", provider.Name), zap.Error(err))
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if provider.Secret == "" {
+			v.logger.Warn("Rejected webhook from provider without a secret", zap.String("provider", provider.Name))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		eventID := r.Header.Get(HeaderEventID)
+		if eventID == "" || len(eventID) > maxEventIDSize {
+			http.Error(w, "Missing or invalid "+HeaderEventID, http.StatusBadRequest)
+			return
+		}
+		if err := Verify(provider.Secret, r.Header.Get(HeaderSignature), eventID, body, time.Now(), v.tolerance); err != nil {
+			v.logger.Warn("Rejected webhook", zap.String("provider", provider.Name), zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		claimed, err := v.store.Claim(r.Context(), provider.Name, eventID)
+		if err != nil {
+			v.logger.Error("Failed to claim webhook event", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !claimed {
+			v.logger.Info("Ignored replayed webhook", zap.String("provider", provider.Name), zap.String("event_id", eventID))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r, ps)
+
+		if recorder.status >= http.StatusInternalServerError {
+			// The request may have been cancelled by now, but the event
+			// must still be released
+			if err := v.store.Release(context.Background(), provider.Name, eventID); err != nil {
+				v.logger.Error("Failed to release webhook event", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Verify checks a HeaderSignature value against the event ID and body. The
+// signature must be made with secret and timestamped within tolerance of
+// now.
+func Verify(secret, header, eventID string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	expected := sign(secret, timestamp, eventID, body)
+	for _, signature := range signatures {
+		if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func sign(secret, timestamp, eventID string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(eventID))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// statusRecorder remembers the status the handler responded with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,110 @@
+package inbound_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/inbound"
+)
+
+const secret = "whsec_test"
+
+// signature returns a HeaderSignature value for the event, signed at t
+func signature(key string, t time.Time, eventID, body string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%d.%s.%s", t.Unix(), eventID, body)
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	tolerance := 5 * time.Minute
+	body := `{"tracking_number":"1Z999","status":"delivered"}`
+
+	tests := []struct {
+		name    string
+		header  string
+		eventID string
+		body    string
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			header: signature(secret, now, "evt_1", body),
+		},
+		{
+			name:   "within tolerance",
+			header: signature(secret, now.Add(-tolerance), "evt_1", body),
+		},
+		{
+			name:   "clock ahead within tolerance",
+			header: signature(secret, now.Add(tolerance), "evt_1", body),
+		},
+		{
+			name:   "one of several signatures matches",
+			header: signature(secret, now, "evt_1", body) + ",v1=" + hex.EncodeToString([]byte("old secret")),
+		},
+		{
+			name:    "too old",
+			header:  signature(secret, now.Add(-tolerance-time.Second), "evt_1", body),
+			wantErr: inbound.ErrStaleTimestamp,
+		},
+		{
+			name:    "too far in the future",
+			header:  signature(secret, now.Add(tolerance+time.Second), "evt_1", body),
+			wantErr: inbound.ErrStaleTimestamp,
+		},
+		{
+			name:    "wrong secret",
+			header:  signature("whsec_other", now, "evt_1", body),
+			wantErr: inbound.ErrInvalidSignature,
+		},
+		{
+			name:    "tampered body",
+			header:  signature(secret, now, "evt_1", body),
+			body:    `{"tracking_number":"1Z999","status":"returned"}`,
+			wantErr: inbound.ErrInvalidSignature,
+		},
+		{
+			name:    "replayed under a new event ID",
+			header:  signature(secret, now, "evt_1", body),
+			eventID: "evt_2",
+			wantErr: inbound.ErrInvalidSignature,
+		},
+		{
+			name:    "missing timestamp",
+			header:  "v1=" + hex.EncodeToString([]byte("signature")),
+			wantErr: inbound.ErrInvalidSignature,
+		},
+		{
+			name:    "missing signature",
+			header:  fmt.Sprintf("t=%d", now.Unix()),
+			wantErr: inbound.ErrInvalidSignature,
+		},
+		{
+			name:    "empty header",
+			wantErr: inbound.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventID := tt.eventID
+			if eventID == "" {
+				eventID = "evt_1"
+			}
+			payload := tt.body
+			if payload == "" {
+				payload = body
+			}
+
+			err := inbound.Verify(secret, tt.header, eventID, []byte(payload), now, tolerance)
+			if err != tt.wantErr {
+				t.Errorf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
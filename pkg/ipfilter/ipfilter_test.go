@@ -0,0 +1,143 @@
+package ipfilter_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/ipfilter"
+	"go.uber.org/zap"
+)
+
+func TestFilter(t *testing.T) {
+	paths := []string{"/admin", "/metrics/"}
+
+	tests := []struct {
+		name string
+		cfg  ipfilter.Config
+		path string
+		ip   string
+		want int
+	}{
+		{
+			name: "allowed network",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"10.0.0.0/8"}},
+			path: "/admin/products",
+			ip:   "10.1.2.3",
+			want: http.StatusOK,
+		},
+		{
+			name: "outside the allowlist",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"10.0.0.0/8"}},
+			path: "/admin/products",
+			ip:   "203.0.113.5",
+			want: http.StatusForbidden,
+		},
+		{
+			name: "allowed single address",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"192.0.2.10"}},
+			path: "/admin",
+			ip:   "192.0.2.10",
+			want: http.StatusOK,
+		},
+		{
+			name: "next to an allowed single address",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"192.0.2.10"}},
+			path: "/admin",
+			ip:   "192.0.2.11",
+			want: http.StatusForbidden,
+		},
+		{
+			name: "deny wins over allow",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.6.0.0/16"}},
+			path: "/admin/products",
+			ip:   "10.6.1.1",
+			want: http.StatusForbidden,
+		},
+		{
+			name: "denylist only refuses denied networks",
+			cfg:  ipfilter.Config{Paths: paths, Deny: []string{"198.51.100.0/24"}},
+			path: "/admin/products",
+			ip:   "198.51.100.9",
+			want: http.StatusForbidden,
+		},
+		{
+			name: "denylist only allows everyone else",
+			cfg:  ipfilter.Config{Paths: paths, Deny: []string{"198.51.100.0/24"}},
+			path: "/admin/products",
+			ip:   "203.0.113.5",
+			want: http.StatusOK,
+		},
+		{
+			name: "IPv6 range",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"2001:db8::/32"}},
+			path: "/metrics",
+			ip:   "2001:db8::1",
+			want: http.StatusOK,
+		},
+		{
+			name: "IPv6 outside the range",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"2001:db8::/32"}},
+			path: "/metrics",
+			ip:   "2001:db9::1",
+			want: http.StatusForbidden,
+		},
+		{
+			name: "paths outside the filter are open",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"10.0.0.0/8"}},
+			path: "/products",
+			ip:   "203.0.113.5",
+			want: http.StatusOK,
+		},
+		{
+			name: "path prefix must end at a segment",
+			cfg:  ipfilter.Config{Paths: paths, Allow: []string{"10.0.0.0/8"}},
+			path: "/administrators",
+			ip:   "203.0.113.5",
+			want: http.StatusOK,
+		},
+		{
+			name: "no networks configured",
+			cfg:  ipfilter.Config{Paths: paths},
+			path: "/admin/products",
+			ip:   "203.0.113.5",
+			want: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ipfilter.New(tt.cfg, zap.NewNop())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			handler := filter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.RemoteAddr = net.JoinHostPort(tt.ip, "41000")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidNetworks(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ipfilter.Config
+	}{
+		{name: "allowlist", cfg: ipfilter.Config{Allow: []string{"10.0.0.0/33"}}},
+		{name: "denylist", cfg: ipfilter.Config{Deny: []string{"not-an-ip"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ipfilter.New(tt.cfg, zap.NewNop()); err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/response"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// Handler exposes the dead-letter view of the job queue to admins.
+type Handler struct {
+	queue  *Queue
+	logger *zap.Logger
+}
+
+func NewHandler(queue *Queue, logger *zap.Logger) *Handler {
+	return &Handler{
+		queue:  queue,
+		logger: logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/admin/jobs/dead-letter", h.ListDeadJobs)
+	router.POST("/admin/jobs/:id/retry", h.RetryJob)
+}
+
+func (h *Handler) ListDeadJobs(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	jobs, totalCount, err := h.queue.List(r.Context(), StatusDead, limit, (page-1)*limit)
+	if err != nil {
+		h.logger.Error("Failed to list dead jobs", zap.Error(err))
+		response.Errorf(w, r, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response.List(w, r, jobs, totalCount, page, limit)
+}
+
+func (h *Handler) RetryJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid job ID", zap.Error(err))
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.queue.Retry(r.Context(), id); err != nil {
+		h.logger.Error("Failed to retry job", zap.Error(err))
+		if err == ErrJobNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusDead    Status = "dead"
+)
+
+// DefaultMaxAttempts is the number of tries before a job is dead-lettered.
+const DefaultMaxAttempts = 5
+
+var ErrJobNotFound = errors.New("job not found")
+
+type Job struct {
+	ID          int64           `db:"id" json:"id"`
+	Type        string          `db:"type" json:"type"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	Status      Status          `db:"status" json:"status"`
+	Attempts    int             `db:"attempts" json:"attempts"`
+	MaxAttempts int             `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time       `db:"run_at" json:"run_at"`
+	LockedAt    *time.Time      `db:"locked_at" json:"locked_at,omitempty"`
+	LastError   *string         `db:"last_error" json:"last_error,omitempty"`
+	Periodic    bool            `db:"periodic" json:"periodic"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Enqueuer schedules jobs. Services depend on this instead of *Queue.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) error
+}
+
+type enqueueOptions struct {
+	runAt       time.Time
+	maxAttempts int
+}
+
+// Option customises a job at enqueue time.
+type Option func(*enqueueOptions)
+
+// RunAt delays the first attempt until t.
+func RunAt(t time.Time) Option {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// MaxAttempts overrides DefaultMaxAttempts.
+func MaxAttempts(n int) Option {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// Queue is the Postgres-backed job queue.
+type Queue struct {
+	db *sqlx.DB
+}
+
+func NewQueue(db *sqlx.DB) *Queue {
+	return &Queue{db: db}
+}
+
+// Enqueue stores a job whose payload is marshalled to JSON.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) error {
+	return q.enqueue(ctx, q.db, jobType, payload, opts...)
+}
+
+// EnqueueTx stores a job inside an existing transaction, so the job only
+// becomes visible if the surrounding write commits.
+func (q *Queue) EnqueueTx(ctx context.Context, tx *sqlx.Tx, jobType string, payload interface{}, opts ...Option) error {
+	return q.enqueue(ctx, tx, jobType, payload, opts...)
+}
+
+func (q *Queue) enqueue(ctx context.Context, execer sqlx.ExecerContext, jobType string, payload interface{}, opts ...Option) error {
+	options := enqueueOptions{
+		runAt:       time.Now(),
+		maxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding %s job payload: %w", jobType, err)
+	}
+
+	query := `
+		INSERT INTO jobs (type, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := execer.ExecContext(ctx, query, jobType, data, options.maxAttempts, options.runAt); err != nil {
+		return fmt.Errorf("error enqueueing %s job: %w", jobType, err)
+	}
+	return nil
+}
+
+// schedulePeriodic queues the next run of a periodic job at runAt unless a
+// run of that type is already pending or running.
+func (q *Queue) schedulePeriodic(ctx context.Context, jobType string, runAt time.Time) error {
+	query := `
+		INSERT INTO jobs (type, max_attempts, run_at, periodic)
+		VALUES ($1, $2, $3, TRUE)
+		ON CONFLICT (type) WHERE periodic AND status IN ('pending', 'running') DO NOTHING`
+
+	if _, err := q.db.ExecContext(ctx, query, jobType, DefaultMaxAttempts, runAt); err != nil {
+		return fmt.Errorf("error scheduling %s job: %w", jobType, err)
+	}
+	return nil
+}
+
+// claim locks the next due job for this worker. Jobs left running past the
+// lease, e.g. by a crashed worker, are picked up again.
+func (q *Queue) claim(ctx context.Context, lease time.Duration) (*Job, error) {
+	query := `
+		UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'pending' AND run_at <= NOW())
+			   OR (status = 'running' AND locked_at < NOW() - $1 * INTERVAL '1 second')
+			ORDER BY run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`
+
+	var job Job
+	err := q.db.GetContext(ctx, &job, query, lease.Seconds())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error claiming job: %w", err)
+	}
+	return &job, nil
+}
+
+func (q *Queue) complete(ctx context.Context, id int64) error {
+	query := `UPDATE jobs SET status = 'done', locked_at = NULL, last_error = NULL, updated_at = NOW() WHERE id = $1`
+	if _, err := q.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error completing job: %w", err)
+	}
+	return nil
+}
+
+// fail schedules a retry at retryAt, or dead-letters the job when it has
+// used up its attempts.
+func (q *Queue) fail(ctx context.Context, job *Job, jobErr error, retryAt time.Time) error {
+	status := StatusPending
+	if job.Attempts >= job.MaxAttempts {
+		status = StatusDead
+	}
+
+	query := `
+		UPDATE jobs SET status = $1, run_at = $2, last_error = $3, locked_at = NULL, updated_at = NOW()
+		WHERE id = $4`
+
+	if _, err := q.db.ExecContext(ctx, query, status, retryAt, jobErr.Error(), job.ID); err != nil {
+		return fmt.Errorf("error failing job: %w", err)
+	}
+	return nil
+}
+
+// List retrieves jobs in the given status, newest first.
+func (q *Queue) List(ctx context.Context, status Status, limit, offset int) ([]*Job, int, error) {
+	var jobs []*Job
+	query := `SELECT * FROM jobs WHERE status = $1 ORDER BY updated_at DESC LIMIT $2 OFFSET $3`
+	if err := q.db.SelectContext(ctx, &jobs, query, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("error listing jobs: %w", err)
+	}
+
+	var totalCount int
+	if err := q.db.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM jobs WHERE status = $1`, status); err != nil {
+		return nil, 0, fmt.Errorf("error counting jobs: %w", err)
+	}
+
+	return jobs, totalCount, nil
+}
+
+// Retry moves a dead job back to pending with a fresh set of attempts.
+func (q *Queue) Retry(ctx context.Context, id int64) error {
+	query := `
+		UPDATE jobs SET status = 'pending', attempts = 0, run_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'`
+
+	result, err := q.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error retrying job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	pollInterval = time.Second
+	jobTimeout   = 5 * time.Minute
+	// lease is how long a running job may go without finishing before
+	// another worker assumes it was abandoned and claims it again.
+	lease       = 2 * jobTimeout
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// HandlerFunc processes the payload of one job. A returned error schedules
+// a retry with exponential backoff.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Worker runs registered job handlers on a pool of goroutines.
+type Worker struct {
+	queue       *Queue
+	logger      *zap.Logger
+	concurrency int
+	handlers    map[string]HandlerFunc
+	periodic    map[string]time.Duration
+	wg          sync.WaitGroup
+}
+
+func NewWorker(queue *Queue, logger *zap.Logger, concurrency int) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		queue:       queue,
+		logger:      logger,
+		concurrency: concurrency,
+		handlers:    make(map[string]HandlerFunc),
+		periodic:    make(map[string]time.Duration),
+	}
+}
+
+// Register sets the handler for a job type. It must be called before Start.
+func (w *Worker) Register(jobType string, handler HandlerFunc) {
+	w.handlers[jobType] = handler
+}
+
+// RegisterPeriodic sets the handler for a job type that runs every
+// interval. The next run is queued when a run finishes, whether it succeeded
+// or was dead-lettered; across instances only one run is queued at a time.
+// It must be called before Start.
+func (w *Worker) RegisterPeriodic(jobType string, interval time.Duration, handler HandlerFunc) {
+	w.handlers[jobType] = handler
+	w.periodic[jobType] = interval
+}
+
+// Start launches the worker pool and queues the first run of every periodic
+// job. Workers stop claiming jobs when ctx is cancelled; use Wait to block
+// until in-flight jobs have finished.
+func (w *Worker) Start(ctx context.Context) {
+	for jobType := range w.periodic {
+		if err := w.queue.schedulePeriodic(ctx, jobType, time.Now()); err != nil {
+			w.logger.Error("Failed to schedule periodic job", zap.String("job_type", jobType), zap.Error(err))
+		}
+	}
+
+	w.logger.Info("Starting job workers", zap.Int("concurrency", w.concurrency))
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+		go w.run(ctx)
+	}
+}
+
+// Wait blocks until every worker goroutine has returned.
+func (w *Worker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *Worker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain all due jobs before waiting for the next tick
+		for ctx.Err() == nil {
+			job, err := w.queue.claim(ctx, lease)
+			if err != nil {
+				w.logger.Error("Failed to claim job", zap.Error(err))
+				break
+			}
+			if job == nil {
+				break
+			}
+			w.process(job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) process(job *Job) {
+	// Jobs run on their own context so shutdown lets them finish
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	logger := w.logger.With(zap.Int64("job_id", job.ID), zap.String("job_type", job.Type), zap.Int("attempt", job.Attempts))
+
+	err := w.execute(ctx, job)
+	if err == nil {
+		if err := w.queue.complete(ctx, job.ID); err != nil {
+			logger.Error("Failed to mark job done", zap.Error(err))
+		}
+		w.scheduleNext(ctx, job, logger)
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		logger.Error("Job failed permanently, moved to dead letter", zap.Error(err))
+	} else {
+		logger.Warn("Job failed, will retry", zap.Error(err))
+	}
+
+	if err := w.queue.fail(ctx, job, err, time.Now().Add(backoff(job.Attempts))); err != nil {
+		logger.Error("Failed to record job failure", zap.Error(err))
+	}
+	if job.Attempts >= job.MaxAttempts {
+		w.scheduleNext(ctx, job, logger)
+	}
+}
+
+// scheduleNext queues the next run of a finished periodic job.
+func (w *Worker) scheduleNext(ctx context.Context, job *Job, logger *zap.Logger) {
+	interval, ok := w.periodic[job.Type]
+	if !ok || !job.Periodic {
+		return
+	}
+	if err := w.queue.schedulePeriodic(ctx, job.Type, time.Now().Add(interval)); err != nil {
+		logger.Error("Failed to schedule next periodic run", zap.Error(err))
+	}
+}
+
+func (w *Worker) execute(ctx context.Context, job *Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
+
+// backoff returns the delay before the next attempt: exponential in the
+// number of attempts so far, capped at maxBackoff, with up to 20% jitter.
+func backoff(attempts int) time.Duration {
+	delay := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempts-1)))
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay) / 5))
+	return delay + jitter
+}
@@ -0,0 +1,100 @@
+// Package locale resolves the locales a client prefers. A request names its
+// locale with the locale query parameter or, failing that, the
+// Accept-Language header. Locales are BCP 47 tags of a language and an
+// optional region, normalized to "de" or "de-AT".
+package locale
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QueryParam overrides Accept-Language when set
+const QueryParam = "locale"
+
+var tagFormat = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// Normalize lowercases the language and uppercases the region of tag and
+// reports whether the result is a supported locale.
+func Normalize(tag string) (string, bool) {
+	parts := strings.SplitN(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-", 2)
+	normalized := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		normalized += "-" + strings.ToUpper(parts[1])
+	}
+	return normalized, tagFormat.MatchString(normalized)
+}
+
+// Base returns the language of a normalized locale, e.g. "de" for "de-AT".
+func Base(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// Preferred returns the locales r asks for, most preferred first. Every
+// regional locale is followed by its base language, so "de-AT" falls back
+// to "de" before the next preference. Unsupported tags and wildcards are
+// skipped; the result is empty when the client states no preference.
+func Preferred(r *http.Request) []string {
+	if tag := r.URL.Query().Get(QueryParam); tag != "" {
+		if normalized, ok := Normalize(tag); ok {
+			return withBases([]string{normalized})
+		}
+	}
+	return withBases(parseAcceptLanguage(r.Header.Get("Accept-Language")))
+}
+
+// parseAcceptLanguage orders the tags of an Accept-Language header by
+// quality, keeping header order between equal qualities
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag, ok := Normalize(fields[0])
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if q, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if v, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func withBases(tags []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		for _, candidate := range []string{tag, Base(tag)} {
+			if !seen[candidate] {
+				seen[candidate] = true
+				result = append(result, candidate)
+			}
+		}
+	}
+	return result
+}
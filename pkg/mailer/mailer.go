@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	config "github.com/dotslashbit/ecommerce-api/configs"
+	"go.uber.org/zap"
+)
+
+// Message is a single rendered email.
+type Message struct {
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+}
+
+// Mailer delivers rendered messages through a mail provider.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New builds the Mailer selected by cfg.MailDriver.
+func New(cfg *config.Config, logger *zap.Logger) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "sendgrid":
+		if cfg.SendGridAPIKey == "" {
+			return nil, fmt.Errorf("sendgrid mail driver requires sendgrid_api_key")
+		}
+		return NewSendGridMailer(cfg.SendGridAPIKey, cfg.MailFrom), nil
+	case "log", "":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+}
+
+// logMailer writes messages to the log instead of sending them. It is the
+// default driver so local development does not need a mail server.
+type logMailer struct {
+	logger *zap.Logger
+}
+
+// NewLogMailer creates a Mailer that only logs messages
+func NewLogMailer(logger *zap.Logger) Mailer {
+	return &logMailer{logger: logger}
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	m.logger.Info("Email sent to log",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject))
+	return nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/jobs"
+)
+
+// JobSendEmail is the job type that delivers a queued Message.
+const JobSendEmail = "send_email"
+
+// queuedMailer enqueues messages so they are delivered by the job workers
+// outside the request path.
+type queuedMailer struct {
+	queue jobs.Enqueuer
+}
+
+// NewQueuedMailer creates a Mailer whose Send only enqueues the message.
+// Register SendEmailJob on the worker to deliver them.
+func NewQueuedMailer(queue jobs.Enqueuer) Mailer {
+	return &queuedMailer{queue: queue}
+}
+
+func (m *queuedMailer) Send(ctx context.Context, msg Message) error {
+	return m.queue.Enqueue(ctx, JobSendEmail, msg)
+}
+
+// SendEmailJob returns the job handler that delivers queued messages with m.
+func SendEmailJob(m Mailer) jobs.HandlerFunc {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var msg Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return fmt.Errorf("error decoding email job: %w", err)
+		}
+		return m.Send(ctx, msg)
+	}
+}
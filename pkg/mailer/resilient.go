@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/resilience"
+)
+
+// resilientMailer sends through a mail provider behind a circuit breaker,
+// retrying failed sends
+type resilientMailer struct {
+	next    Mailer
+	breaker *resilience.Breaker
+	retry   resilience.Retry
+}
+
+// NewResilientMailer wraps the provider mailer next with breaker and
+// retry. While the breaker is open Send fails at once, and queued emails
+// wait for the job queue's own retries.
+func NewResilientMailer(next Mailer, breaker *resilience.Breaker, retry resilience.Retry) Mailer {
+	return &resilientMailer{next: next, breaker: breaker, retry: retry}
+}
+
+func (m *resilientMailer) Send(ctx context.Context, msg Message) error {
+	return resilience.Call(ctx, m.breaker, m.retry, func(ctx context.Context) error {
+		return m.next.Send(ctx, msg)
+	})
+}
@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// Template names understood by Render
+const (
+	TemplateOrderConfirmation = "order_confirmation"
+	TemplateShipment          = "shipment"
+	TemplatePasswordReset     = "password_reset"
+	TemplateBackInStock       = "back_in_stock"
+	TemplateReturnLabel       = "return_label"
+	TemplateLowStock          = "low_stock"
+	TemplateReport            = "report"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates maps a template name to its parsed set. Every file defines a
+// "subject" and an "html" block.
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[string]*template.Template {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	parsed := make(map[string]*template.Template, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		parsed[name] = template.Must(template.ParseFS(templateFS, "templates/"+entry.Name()))
+	}
+	return parsed
+}
+
+// Render builds a Message for to from the named template and its data.
+func Render(name, to string, data interface{}) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("error rendering %s subject: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "html", data); err != nil {
+		return Message{}, fmt.Errorf("error rendering %s body: %w", name, err)
+	}
+
+	// Subjects are plain text, so undo the HTML escaping of the template
+	return Message{
+		To:       to,
+		Subject:  html.UnescapeString(strings.TrimSpace(subject.String())),
+		HTMLBody: body.String(),
+	}, nil
+}
+
+type OrderLine struct {
+	Name     string
+	Quantity int
+	Total    float64
+}
+
+// OrderConfirmationData is the data for TemplateOrderConfirmation
+type OrderConfirmationData struct {
+	CustomerName string
+	OrderNumber  string
+	Lines        []OrderLine
+	Total        float64
+}
+
+// ShipmentData is the data for TemplateShipment
+type ShipmentData struct {
+	CustomerName   string
+	OrderNumber    string
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+}
+
+// PasswordResetData is the data for TemplatePasswordReset
+type PasswordResetData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// BackInStockData is the data for TemplateBackInStock
+type BackInStockData struct {
+	ProductName    string
+	ProductURL     string
+	UnsubscribeURL string
+}
+
+// ReturnLabelData is the data for TemplateReturnLabel
+type ReturnLabelData struct {
+	ReturnID            int64
+	LabelURL            string
+	TrackingNumber      string
+	Carrier             string
+	DropOffInstructions string
+}
+
+// LowStockData is the data for TemplateLowStock
+type LowStockData struct {
+	ProductID     int64
+	ProductName   string
+	StockQuantity int
+	Threshold     int
+}
+
+// ReportData is the data for TemplateReport. Rows holds at most the lines
+// shown in the email; TotalRows counts them all.
+type ReportData struct {
+	Name      string
+	Period    string
+	Columns   []string
+	Rows      [][]string
+	TotalRows int
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Event is a domain event stored in the outbox table.
+type Event struct {
+	ID            int64           `db:"id" json:"id"`
+	StoreID       int64           `db:"store_id" json:"store_id"`
+	AggregateType string          `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   int64           `db:"aggregate_id" json:"aggregate_id"`
+	EventType     string          `db:"event_type" json:"event_type"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	Attempts      int             `db:"attempts" json:"-"`
+	LastError     *string         `db:"last_error" json:"-"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+	PublishedAt   *time.Time      `db:"published_at" json:"-"`
+}
+
+// Record stores an event of storeID inside tx. The event is only published
+// if the transaction carrying the state change commits.
+func Record(ctx context.Context, tx *sqlx.Tx, storeID int64, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %w", eventType, err)
+	}
+
+	query := `
+		INSERT INTO outbox (store_id, aggregate_type, aggregate_id, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := tx.ExecContext(ctx, query, storeID, aggregateType, aggregateID, eventType, data); err != nil {
+		return fmt.Errorf("error recording %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// RecordBatch stores an event of eventType for each of aggregateIDs inside
+// tx with a single insert. payloads[i] is the payload of aggregateIDs[i].
+func RecordBatch(ctx context.Context, tx *sqlx.Tx, storeID int64, aggregateType string, aggregateIDs []int64, eventType string, payloads []interface{}) error {
+	data := make([]string, len(payloads))
+	for i, payload := range payloads {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error encoding %s event: %w", eventType, err)
+		}
+		data[i] = string(encoded)
+	}
+
+	query := `
+		INSERT INTO outbox (store_id, aggregate_type, aggregate_id, event_type, payload)
+		SELECT $1, $2, event.id, $3, event.payload::jsonb
+		FROM unnest($4::bigint[], $5::text[]) AS event (id, payload)`
+
+	if _, err := tx.ExecContext(ctx, query, storeID, aggregateType, eventType, pq.Array(aggregateIDs), pq.Array(data)); err != nil {
+		return fmt.Errorf("error recording %s events: %w", eventType, err)
+	}
+	return nil
+}
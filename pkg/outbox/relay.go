@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const (
+	relayInterval  = time.Second
+	relayBatchSize = 100
+)
+
+// Publisher delivers outbox events to a message broker.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// Relay publishes unpublished outbox events in insertion order. Events are
+// delivered at least once: a crash between publishing and marking an event
+// published causes it to be sent again.
+type Relay struct {
+	db        *sqlx.DB
+	publisher Publisher
+	logger    *zap.Logger
+	wg        sync.WaitGroup
+}
+
+func NewRelay(db *sqlx.DB, publisher Publisher, logger *zap.Logger) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Start runs the relay loop until ctx is cancelled.
+func (r *Relay) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(relayInterval)
+		defer ticker.Stop()
+
+		for {
+			for ctx.Err() == nil {
+				published, err := r.relayBatch(ctx)
+				if err != nil {
+					r.logger.Error("Failed to relay outbox events", zap.Error(err))
+					break
+				}
+				if published < relayBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the relay loop has returned.
+func (r *Relay) Wait() {
+	r.wg.Wait()
+}
+
+// relayBatch publishes up to relayBatchSize events and returns how many were
+// published. It stops at the first failure so events stay in order.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var events []*Event
+	query := `
+		SELECT * FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &events, query, relayBatchSize); err != nil {
+		return 0, fmt.Errorf("error loading outbox events: %w", err)
+	}
+
+	published := 0
+	for _, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			r.logger.Warn("Failed to publish outbox event",
+				zap.Int64("event_id", event.ID),
+				zap.String("event_type", event.EventType),
+				zap.Error(err))
+
+			_, updateErr := tx.ExecContext(ctx,
+				`UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2`,
+				err.Error(), event.ID)
+			if updateErr != nil {
+				return published, fmt.Errorf("error recording outbox failure: %w", updateErr)
+			}
+			break
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE outbox SET published_at = NOW() WHERE id = $1`, event.ID)
+		if err != nil {
+			return published, fmt.Errorf("error marking outbox event published: %w", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing outbox batch: %w", err)
+	}
+	return published, nil
+}
@@ -0,0 +1,159 @@
+// Package reportcache keeps the results of expensive admin reports in
+// memory. Reports are recomputed in the background every refresh interval,
+// so admins normally read a cached result together with the time it was
+// computed, and can force a recompute when they need fresh numbers.
+package reportcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ComputeFunc builds a report.
+type ComputeFunc func(ctx context.Context) (interface{}, error)
+
+// Result is a cached report.
+type Result struct {
+	Data       interface{}
+	ComputedAt time.Time
+	// Stale is set when the background refresh has fallen behind, e.g.
+	// because the last recompute failed.
+	Stale bool
+}
+
+type entry struct {
+	data       interface{}
+	computedAt time.Time
+}
+
+// Cache holds the latest result of every registered report.
+type Cache struct {
+	refreshInterval time.Duration
+	logger          *zap.Logger
+
+	mu      sync.RWMutex
+	reports map[string]ComputeFunc
+	entries map[string]*entry
+	// computing serialises recomputes of the same report
+	computing map[string]*sync.Mutex
+
+	wg sync.WaitGroup
+}
+
+func New(refreshInterval time.Duration, logger *zap.Logger) *Cache {
+	return &Cache{
+		refreshInterval: refreshInterval,
+		logger:          logger,
+		reports:         make(map[string]ComputeFunc),
+		entries:         make(map[string]*entry),
+		computing:       make(map[string]*sync.Mutex),
+	}
+}
+
+// Register adds a report to the cache. It must be called before Start.
+func (c *Cache) Register(name string, compute ComputeFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reports[name] = compute
+	c.computing[name] = &sync.Mutex{}
+}
+
+// Get returns the cached result of the named report, computing it first if
+// it has never been computed or refresh is set.
+func (c *Cache) Get(ctx context.Context, name string, refresh bool) (*Result, error) {
+	c.mu.RLock()
+	e, ok := c.entries[name]
+	c.mu.RUnlock()
+
+	if !ok || refresh {
+		var err error
+		if e, err = c.compute(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{
+		Data:       e.data,
+		ComputedAt: e.computedAt,
+		Stale:      time.Since(e.computedAt) > 2*c.refreshInterval,
+	}, nil
+}
+
+// Invalidate drops the cached result of the named report, so the next Get
+// recomputes it. Call it when an input of the report changes.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, name)
+}
+
+func (c *Cache) compute(ctx context.Context, name string) (*entry, error) {
+	c.mu.RLock()
+	compute, ok := c.reports[name]
+	lock := c.computing[name]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown report %q", name)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	data, err := compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{data: data, computedAt: time.Now().UTC()}
+	c.mu.Lock()
+	c.entries[name] = e
+	c.mu.Unlock()
+	return e, nil
+}
+
+// Start recomputes every registered report each refresh interval until ctx
+// is cancelled.
+func (c *Cache) Start(ctx context.Context) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			c.mu.RLock()
+			names := make([]string, 0, len(c.reports))
+			for name := range c.reports {
+				names = append(names, name)
+			}
+			c.mu.RUnlock()
+
+			for _, name := range names {
+				if ctx.Err() != nil {
+					return
+				}
+				if _, err := c.compute(ctx, name); err != nil {
+					c.logger.Error("Failed to refresh report", zap.String("report", name), zap.Error(err))
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the refresh loop has returned.
+func (c *Cache) Wait() {
+	c.wg.Wait()
+}
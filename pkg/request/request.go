@@ -0,0 +1,31 @@
+// Package request decodes and validates JSON request bodies. Decode is
+// strict: fields the target does not have and data after the JSON value are
+// errors, so typos in field names are reported instead of being silently
+// ignored. Endpoints may additionally check bodies against a JSON Schema
+// embedded in this package before they are decoded; see Schema.
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrTrailingData is returned by Decode when the body holds more than one
+// JSON value
+var ErrTrailingData = errors.New("unexpected data after JSON body")
+
+// Decode decodes the JSON body of r into v, rejecting fields v does not
+// have. An empty body returns io.EOF, as json.Decoder does.
+func Decode(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return ErrTrailingData
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package request
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// MaxBodySize is the largest body Schema reads, in bytes
+const MaxBodySize = 1 << 20
+
+// MaxImportSize is the largest body read for bulk imports, in bytes
+const MaxImportSize = 32 << 20
+
+// files holds one JSON Schema per endpoint, named after the endpoint, such
+// as product.create.json
+//
+//go:embed schemas/*.json
+var files embed.FS
+
+// schemas are the compiled schemas by name. They are compiled at start-up,
+// so a broken schema stops the API instead of failing requests.
+var schemas = compile()
+
+func compile() map[string]*jsonschema.Schema {
+	entries, err := files.ReadDir("schemas")
+	if err != nil {
+		panic(fmt.Sprintf("request: reading schemas: %v", err))
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+
+	// Schemas may refer to each other by file name, so all of them are
+	// loaded before any is compiled
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("request: reading schema %s: %v", entry.Name(), err))
+		}
+		if err := compiler.AddResource("schema:///"+entry.Name(), bytes.NewReader(data)); err != nil {
+			panic(fmt.Sprintf("request: loading schema %s: %v", entry.Name(), err))
+		}
+	}
+
+	compiled := make(map[string]*jsonschema.Schema, len(entries))
+	for _, entry := range entries {
+		schema, err := compiler.Compile("schema:///" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("request: compiling schema %s: %v", entry.Name(), err))
+		}
+		compiled[strings.TrimSuffix(entry.Name(), ".json")] = schema
+	}
+	return compiled
+}
+
+// Schema returns next guarded by the embedded schema name. Bodies that are
+// not valid JSON or do not match the schema are rejected with 400 and a
+// message naming every offending field; next receives the body unread. It
+// panics when there is no such schema, so typos surface when routes are
+// registered.
+func Schema(name string, next httprouter.Handle) httprouter.Handle {
+	return SchemaSized(name, MaxBodySize, next)
+}
+
+// SchemaSized is Schema for endpoints that take bodies of up to maxBytes,
+// such as imports
+func SchemaSized(name string, maxBytes int64, next httprouter.Handle) httprouter.Handle {
+	schema, ok := schemas[name]
+	if !ok {
+		panic("request: no schema named " + name)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if err := schema.Validate(document); err != nil {
+			http.Error(w, "Invalid input: "+describe(err), http.StatusBadRequest)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r, ps)
+	}
+}
+
+// describe lists the innermost validation failures of err as
+// "location: message", in location order
+func describe(err error) string {
+	var validation *jsonschema.ValidationError
+	if !errors.As(err, &validation) {
+		return err.Error()
+	}
+
+	var failures []string
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			failures = append(failures, location+": "+e.Message)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(validation)
+
+	sort.Strings(failures)
+	return strings.Join(failures, "; ")
+}
@@ -0,0 +1,165 @@
+// Package resilience guards calls to external services with circuit
+// breakers and retries. A breaker stops calling a service that keeps
+// failing, so requests fail fast instead of waiting on timeouts, and tries
+// it again after a cool-down. Breaker states are published as the expvar
+// variable circuit_breakers and reported by the health check.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned instead of calling a service whose breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker
+type State int
+
+const (
+	// StateClosed lets calls through and counts consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects calls until the cool-down has passed
+	StateOpen
+	// StateHalfOpen lets one trial call through, which closes the breaker
+	// on success and opens it again on failure
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a circuit breaker for one external service. It opens after
+// threshold consecutive failures and stays open for cooldown.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Breaker)
+)
+
+func init() {
+	expvar.Publish("circuit_breakers", expvar.Func(func() interface{} {
+		states := make(map[string]string)
+		for name, state := range States() {
+			states[name] = state.String()
+		}
+		return states
+	}))
+}
+
+// NewBreaker creates the breaker for the service name and registers it
+// for States. A threshold below 1 is treated as 1.
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	b := &Breaker{name: name, threshold: max(threshold, 1), cooldown: cooldown}
+
+	registryMu.Lock()
+	registry[name] = b
+	registryMu.Unlock()
+	return b
+}
+
+// States returns the state of every registered breaker by name
+func States() map[string]State {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	states := make(map[string]State, len(registry))
+	for name, b := range registry {
+		states[name] = b.State()
+	}
+	return states
+}
+
+// Name is the service the breaker guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the breaker's current state. An open breaker whose
+// cool-down has passed is half-open.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current()
+}
+
+// current is State with b.mu held
+func (b *Breaker) current() State {
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do calls fn unless the breaker is open, and records its outcome.
+// Cancellation by ctx is not counted as a failure of the service.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+	err := fn(ctx)
+	b.record(err, ctx.Err() != nil)
+	return err
+}
+
+// allow reports whether a call may go through, claiming the trial call of
+// a half-open breaker
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.current() {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.state, b.trial = StateHalfOpen, true
+		return true
+	default:
+		return false
+	}
+}
+
+// record counts the outcome of a call. Canceled calls only give up the
+// trial call they may hold.
+func (b *Breaker) record(err error, canceled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if canceled {
+		return
+	}
+	if err == nil {
+		b.state, b.failures = StateClosed, 0
+		return
+	}
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state, b.openedAt = StateOpen, time.Now()
+	}
+}
@@ -0,0 +1,63 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Retry retries failed calls with exponential backoff and full jitter:
+// the wait before retry n is random between zero and BaseDelay*2^(n-1),
+// capped at MaxDelay. Jitter keeps instances that failed together from
+// retrying together.
+type Retry struct {
+	// Attempts is the most calls made, including the first; values below
+	// 1 make a single call
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Do calls fn until it succeeds, the attempts are used up or ctx is done.
+// Calls rejected by an open breaker are not retried. It returns the last
+// error.
+func (r Retry) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || errors.Is(err, ErrOpen) || attempt >= r.Attempts {
+			return err
+		}
+
+		timer := time.NewTimer(r.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay is the wait after the given failed attempt
+func (r Retry) delay(attempt int) time.Duration {
+	ceiling := r.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if backoff := r.BaseDelay << shift; backoff > 0 && backoff < ceiling {
+			ceiling = backoff
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// Call calls fn through breaker, retrying failures with retry. Each
+// attempt counts towards the breaker, so a service that fails every retry
+// opens it sooner.
+func Call(ctx context.Context, breaker *Breaker, retry Retry, fn func(ctx context.Context) error) error {
+	return retry.Do(ctx, func(ctx context.Context) error {
+		return breaker.Do(ctx, fn)
+	})
+}
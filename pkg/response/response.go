@@ -0,0 +1,119 @@
+// Package response writes the JSON envelope shared by list endpoints:
+//
+//	{"data": [...], "meta": {"total": 42, "page": 2, "per_page": 10, ...}}
+//
+// Failures are written as {"errors": [{"message": "..."}]}. Paginated
+// responses also carry RFC 5988 Link headers pointing at the first, last,
+// previous and next pages; uncounted lists have no last page.
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dotslashbit/ecommerce-api/pkg/i18n"
+)
+
+// Envelope is the body of every list response
+type Envelope struct {
+	Data   interface{} `json:"data"`
+	Meta   *Meta       `json:"meta,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Meta describes the page of a paginated list. Next and Prev are empty on
+// the last and first page. Total is left out of lists that were not
+// counted.
+type Meta struct {
+	Total   *int   `json:"total,omitempty"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Next    string `json:"next,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+}
+
+// Error is one entry of the errors list
+type Error struct {
+	Message string `json:"message"`
+}
+
+// List writes one page of a list of total items, page counting from 1.
+// data should be a slice, or an object holding one; a nil slice is written
+// as [] so that clients can always iterate over data.
+func List(w http.ResponseWriter, r *http.Request, data interface{}, total, page, perPage int) {
+	last := 1
+	if perPage > 0 && total > 0 {
+		last = (total + perPage - 1) / perPage
+	}
+	writeList(w, r, data, &Meta{Total: &total, Page: page, PerPage: perPage}, page < last, last)
+}
+
+// ListUncounted writes one page of a list whose items were not counted,
+// for clients that only page forward. more reports whether there is a next
+// page; there is no total and no link to the last page.
+func ListUncounted(w http.ResponseWriter, r *http.Request, data interface{}, page, perPage int, more bool) {
+	writeList(w, r, data, &Meta{Page: page, PerPage: perPage}, more, 0)
+}
+
+// writeList writes a list with the Link header of its page. last is the
+// number of the last page, or 0 when it is unknown.
+func writeList(w http.ResponseWriter, r *http.Request, data interface{}, meta *Meta, more bool, last int) {
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice && v.IsNil() {
+		data = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	links := []string{link(r, 1, "first")}
+	if last > 0 {
+		links = append(links, link(r, last, "last"))
+	}
+	if more {
+		meta.Next = pageURL(r, meta.Page+1)
+		links = append(links, link(r, meta.Page+1, "next"))
+	}
+	if meta.Page > 1 {
+		prev := meta.Page - 1
+		if last > 0 {
+			prev = min(prev, last)
+		}
+		meta.Prev = pageURL(r, prev)
+		links = append(links, link(r, prev, "prev"))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	write(w, http.StatusOK, Envelope{Data: data, Meta: meta})
+}
+
+// Errorf writes an error envelope with the given status. The message is
+// translated into the client's language, since the i18n middleware only
+// translates plain text errors.
+func Errorf(w http.ResponseWriter, r *http.Request, status int, format string, args ...interface{}) {
+	message := i18n.Message(r.Context(), fmt.Sprintf(format, args...))
+	write(w, status, Envelope{Errors: []Error{{Message: message}}})
+}
+
+func write(w http.ResponseWriter, status int, envelope Envelope) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	// Keep the & in page links readable
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(envelope)
+}
+
+// pageURL is the request URL with its page parameter set to page. It is
+// relative to the host, which clients already know.
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+func link(r *http.Request, page int, rel string) string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, pageURL(r, page), rel)
+}
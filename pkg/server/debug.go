@@ -0,0 +1,58 @@
+package server
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// EnableDebug makes Start serve pprof profiles under /debug/pprof/ and
+// expvar variables under /debug/vars on addr, a port that should only be
+// reachable from inside the network. When token is set, every debug
+// request must send it as a bearer token. An empty addr leaves the debug
+// endpoints off.
+func (s *Server) EnableDebug(addr, token string) {
+	if addr == "" {
+		return
+	}
+	s.debug = &http.Server{
+		Addr:    addr,
+		Handler: s.requireToken(token, debugMux()),
+	}
+}
+
+// debugMux routes the runtime debug endpoints. The pprof handlers are
+// registered explicitly so that nothing depends on http.DefaultServeMux.
+func debugMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+// An empty token lets every request through.
+func (s *Server) requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			s.Logger.Warn("Rejected debug request", zap.String("path", r.URL.Path), zap.String("remote_addr", r.RemoteAddr))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -9,6 +9,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/dotslashbit/ecommerce-api/pkg/audit"
+	"github.com/dotslashbit/ecommerce-api/pkg/clientip"
+	"github.com/dotslashbit/ecommerce-api/pkg/resilience"
 	"github.com/jmoiron/sqlx"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
@@ -19,8 +22,20 @@ type Server struct {
 	DB     *sqlx.DB
 	Logger *zap.Logger
 	server *http.Server
+
+	// debug serves the runtime debug endpoints when enabled
+	debug *http.Server
+
+	limits Limits
+
+	// proxies resolves client IPs behind trusted proxies
+	proxies *clientip.Resolver
+
+	middleware []func(http.Handler) http.Handler
 }
 
+// NewServer creates a server with a health check route. db may be nil when
+// the API runs without a database, as in demo mode.
 func NewServer(db *sqlx.DB, logger *zap.Logger) *Server {
 	router := httprouter.New()
 
@@ -35,6 +50,35 @@ func NewServer(db *sqlx.DB, logger *zap.Logger) *Server {
 	return s
 }
 
+// Limits bound how long clients may take and how much header data they
+// may send. Zero values mean no limit, as in http.Server.
+type Limits struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+}
+
+// SetLimits applies limits to the API listener. It must be called before
+// Start; the debug listener is left unlimited so long profiles complete.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+}
+
+// TrustProxies makes every middleware see client IPs resolved behind the
+// given proxies instead of the peer address. It must be called before
+// Start.
+func (s *Server) TrustProxies(proxies *clientip.Resolver) {
+	s.proxies = proxies
+}
+
+// Use adds middleware that runs around every route, in the order added.
+// It must be called before Start.
+func (s *Server) Use(middleware func(http.Handler) http.Handler) {
+	s.middleware = append(s.middleware, middleware)
+}
+
 func (s *Server) setupRoutes() {
 	s.Router.GET("/health", s.HandleHealth())
 }
@@ -43,22 +87,34 @@ func (s *Server) HandleHealth() httprouter.Handle {
 	type HealthResponse struct {
 		Status    string `json:"status"`
 		Timestamp string `json:"timestamp"`
+		// Breakers are the states of the circuit breakers around external
+		// services. An open breaker reports the API as DEGRADED but keeps
+		// it ready, as the requests it serves do not wait on those services.
+		Breakers map[string]string `json:"breakers,omitempty"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		s.Logger.Info("Health check requested")
 
-		// Check database connection
-		err := s.DB.Ping()
-		if err != nil {
-			s.Logger.Error("Database health check failed", zap.Error(err))
-			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
-			return
+		// Check database connection, unless running without one
+		if s.DB != nil {
+			if err := s.DB.Ping(); err != nil {
+				s.Logger.Error("Database health check failed", zap.Error(err))
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
 		}
 
 		response := HealthResponse{
 			Status:    "OK",
 			Timestamp: time.Now().Format(time.RFC3339),
+			Breakers:  make(map[string]string),
+		}
+		for name, state := range resilience.States() {
+			response.Breakers[name] = state.String()
+			if state == resilience.StateOpen {
+				response.Status = "DEGRADED"
+			}
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -68,9 +124,19 @@ func (s *Server) HandleHealth() httprouter.Handle {
 }
 
 func (s *Server) Start(addr string) error {
+	var handler http.Handler = s.Router
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+
 	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.Router,
+		Addr:              addr,
+		Handler:           s.proxies.Wrap(audit.Middleware(handler)),
+		ReadHeaderTimeout: s.limits.ReadHeaderTimeout,
+		ReadTimeout:       s.limits.ReadTimeout,
+		WriteTimeout:      s.limits.WriteTimeout,
+		IdleTimeout:       s.limits.IdleTimeout,
+		MaxHeaderBytes:    s.limits.MaxHeaderBytes,
 	}
 
 	// Channel to listen for errors coming from the listener.
@@ -82,6 +148,17 @@ func (s *Server) Start(addr string) error {
 		serverErrors <- s.server.ListenAndServe()
 	}()
 
+	// The debug endpoints listen separately; failing to serve them does not
+	// stop the API.
+	if s.debug != nil {
+		go func() {
+			s.Logger.Info("Debug endpoints listening", zap.String("addr", s.debug.Addr))
+			if err := s.debug.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.Logger.Error("Debug server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	// Channel to listen for an interrupt or terminate signal from the OS.
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -98,6 +175,11 @@ func (s *Server) Start(addr string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
+		// Profiles in progress are cut short.
+		if s.debug != nil {
+			s.debug.Close()
+		}
+
 		// Asking listener to shut down and shed load.
 		if err := s.server.Shutdown(ctx); err != nil {
 			s.Logger.Error("Graceful shutdown did not complete", zap.Error(err))
@@ -0,0 +1,107 @@
+package slug
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Unique returns base, or base with the lowest numeric suffix ("-2", "-3",
+// ...) that no row of table in storeID uses as its slug and no redirect of
+// entityType still points from. Slugs of deleted or renamed entities stay
+// reserved so that old links never lead to a different entity. fallback
+// is used when base is empty.
+//
+// The check is not locked; a concurrent insert of the same slug fails on
+// the table's unique index.
+func Unique(ctx context.Context, q sqlx.QueryerContext, table, entityType string, storeID int64, base, fallback string) (string, error) {
+	slugs, err := UniqueAll(ctx, q, table, entityType, storeID, []string{base}, fallback, nil)
+	if err != nil {
+		return "", err
+	}
+	return slugs[0], nil
+}
+
+// UniqueAll is Unique for entities created together, with one query for
+// all of them. Besides the slugs taken in the database, each slug avoids
+// those picked for earlier bases and the reserved slugs the batch already
+// uses.
+func UniqueAll(ctx context.Context, q sqlx.QueryerContext, table, entityType string, storeID int64, bases []string, fallback string, reserved []string) ([]string, error) {
+	bases = append([]string(nil), bases...)
+	prefixes := make([]string, len(bases))
+	for i, base := range bases {
+		if base == "" {
+			bases[i] = fallback
+		}
+		prefixes[i] = bases[i] + "-%"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT slug FROM %s WHERE store_id = $1 AND (slug = ANY($2) OR slug LIKE ANY($3))
+		UNION
+		SELECT old_slug FROM slug_redirects WHERE store_id = $1 AND entity_type = $4 AND (old_slug = ANY($2) OR old_slug LIKE ANY($3))`, table)
+	var taken []string
+	if err := sqlx.SelectContext(ctx, q, &taken, query, storeID, pq.Array(bases), pq.Array(prefixes), entityType); err != nil {
+		return nil, fmt.Errorf("error checking slugs: %w", err)
+	}
+
+	used := make(map[string]bool, len(taken)+len(reserved)+len(bases))
+	for _, s := range append(taken, reserved...) {
+		used[s] = true
+	}
+	slugs := make([]string, len(bases))
+	for i, base := range bases {
+		slugs[i] = Pick(base, func(s string) bool { return used[s] })
+		used[slugs[i]] = true
+	}
+	return slugs, nil
+}
+
+// Pick returns base, or base with the lowest numeric suffix for which taken
+// reports false, shortening base so the result fits MaxLength. Unique uses
+// it with the slugs found in the database; in-memory repositories pass
+// their own lookup.
+func Pick(base string, taken func(string) bool) string {
+	candidate := base
+	for n := 2; taken(candidate); n++ {
+		suffix := "-" + strconv.Itoa(n)
+		if len(base)+len(suffix) > MaxLength {
+			base = strings.TrimRight(base[:MaxLength-len(suffix)], "-")
+		}
+		candidate = base + suffix
+	}
+	return candidate
+}
+
+// Redirect records inside tx that oldSlug of an entity of entityType now
+// leads to entityID. Earlier redirects to the entity stay, so every slug it
+// ever had keeps resolving.
+func Redirect(ctx context.Context, tx *sqlx.Tx, storeID int64, entityType string, entityID int64, oldSlug string) error {
+	query := `
+		INSERT INTO slug_redirects (store_id, entity_type, old_slug, entity_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (store_id, entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = NOW()`
+	if _, err := tx.ExecContext(ctx, query, storeID, entityType, oldSlug, entityID); err != nil {
+		return fmt.Errorf("error recording slug redirect: %w", err)
+	}
+	return nil
+}
+
+// Resolve returns the entity a former slug leads to. It returns an error
+// wrapping sql.ErrNoRows when there is no redirect.
+func Resolve(ctx context.Context, q sqlx.QueryerContext, storeID int64, entityType, oldSlug string) (int64, error) {
+	var id int64
+	query := `SELECT entity_id FROM slug_redirects WHERE store_id = $1 AND entity_type = $2 AND old_slug = $3`
+	if err := sqlx.GetContext(ctx, q, &id, query, storeID, entityType, oldSlug); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("slug redirect not found: %w", err)
+		}
+		return 0, fmt.Errorf("error resolving slug: %w", err)
+	}
+	return id, nil
+}
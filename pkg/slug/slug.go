@@ -0,0 +1,50 @@
+// Package slug builds the URL path segments resources are addressed by,
+// such as "logitech-g" for a brand named "Logitech G", and keeps former
+// slugs resolving through redirects after they change.
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest slug Make returns
+const MaxLength = 100
+
+var format = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Make derives a slug from s: accents are dropped, letters lowercased and
+// runs of other characters replaced by single hyphens. It returns an empty
+// string when s has no letters or digits.
+func Make(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLength {
+		slug = strings.TrimRight(slug[:MaxLength], "-")
+	}
+	return slug
+}
+
+// Valid reports whether s is a well-formed slug
+func Valid(s string) bool {
+	return len(s) <= MaxLength && format.MatchString(s)
+}
@@ -0,0 +1,161 @@
+// Package tenant resolves which store a request is for. A store is picked
+// by the X-Store header or, when a base domain is configured, by the
+// subdomain of the request host; requests naming neither use the default
+// store. Repositories scope their queries with StoreArg.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HeaderStore names the store by slug
+const HeaderStore = "X-Store"
+
+// DefaultStoreID is the store created by the migration. Requests that do
+// not name a store, and rows written before stores existed, belong to it.
+const DefaultStoreID int64 = 1
+
+// cacheTTL is how long a resolved slug is reused before looking it up again
+const cacheTTL = time.Minute
+
+var ErrStoreNotFound = errors.New("store not found")
+
+// Resolver looks up a store ID by slug, returning ErrStoreNotFound for
+// unknown slugs.
+type Resolver interface {
+	ResolveStore(ctx context.Context, slug string) (int64, error)
+}
+
+type storeKey struct{}
+
+// WithStore returns a context scoped to storeID.
+func WithStore(ctx context.Context, storeID int64) context.Context {
+	return context.WithValue(ctx, storeKey{}, storeID)
+}
+
+// WithoutStore returns ctx unscoped, for work that covers every store
+// such as a cached report shared by all of them.
+func WithoutStore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, storeKey{}, nil)
+}
+
+// StoreID returns the store the context is scoped to. Background jobs run
+// unscoped and see every store.
+func StoreID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(storeKey{}).(int64)
+	return id, ok
+}
+
+// StoreArg returns the store ID as a query argument, or nil when ctx is
+// unscoped, for use with "($n::integer IS NULL OR store_id = $n)".
+func StoreArg(ctx context.Context) interface{} {
+	if id, ok := StoreID(ctx); ok {
+		return id
+	}
+	return nil
+}
+
+// StoreIDOrDefault returns the store new rows belong to.
+func StoreIDOrDefault(ctx context.Context) int64 {
+	if id, ok := StoreID(ctx); ok {
+		return id
+	}
+	return DefaultStoreID
+}
+
+// Middleware scopes every request to a store.
+type Middleware struct {
+	resolver   Resolver
+	logger     *zap.Logger
+	baseDomain string
+
+	mu    sync.Mutex
+	cache map[string]cachedStore
+}
+
+type cachedStore struct {
+	id      int64
+	expires time.Time
+}
+
+// NewMiddleware creates the tenant middleware. baseDomain enables
+// subdomain routing, e.g. "shop.example.com" maps acme.shop.example.com to
+// the store with slug acme; leave it empty to use the header only.
+func NewMiddleware(resolver Resolver, logger *zap.Logger, baseDomain string) *Middleware {
+	return &Middleware{
+		resolver:   resolver,
+		logger:     logger,
+		baseDomain: strings.ToLower(strings.TrimPrefix(baseDomain, ".")),
+		cache:      make(map[string]cachedStore),
+	}
+}
+
+// Wrap scopes requests to their store. Unknown stores get a 404.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := m.slug(r)
+		if slug == "" {
+			next.ServeHTTP(w, r.WithContext(WithStore(r.Context(), DefaultStoreID)))
+			return
+		}
+
+		id, err := m.resolve(r.Context(), slug)
+		if err != nil {
+			if errors.Is(err, ErrStoreNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			m.logger.Error("Failed to resolve store", zap.String("store", slug), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithStore(r.Context(), id)))
+	})
+}
+
+func (m *Middleware) slug(r *http.Request) string {
+	if slug := r.Header.Get(HeaderStore); slug != "" {
+		return strings.ToLower(slug)
+	}
+	if m.baseDomain == "" {
+		return ""
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if sub, ok := strings.CutSuffix(host, "."+m.baseDomain); ok && !strings.Contains(sub, ".") {
+		return sub
+	}
+	return ""
+}
+
+func (m *Middleware) resolve(ctx context.Context, slug string) (int64, error) {
+	m.mu.Lock()
+	cached, ok := m.cache[slug]
+	m.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.id, nil
+	}
+
+	id, err := m.resolver.ResolveStore(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	m.cache[slug] = cachedStore{id: id, expires: time.Now().Add(cacheTTL)}
+	m.mu.Unlock()
+	return id, nil
+}